@@ -0,0 +1,78 @@
+// Package bench menjalankan load test singkat di dalam benchmark Go
+// (go test -bench) dan melaporkan hasilnya sebagai metrik benchmark, agar
+// performa service bisa dipantau dari test suite-nya sendiri.
+//
+//    func BenchmarkOrders(b *testing.B) {
+//        bench.Handler(b, newOrderHandler(), "-c", "20", "-duration", "2s")
+//    }
+//
+// Hasilnya dilaporkan dengan b.ReportMetric sebagai req/s, p50-ms, p99-ms dan
+// err-% sehingga bisa dibandingkan dengan benchstat.
+package bench
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "loadtest/engine"
+)
+
+// defaultArgs jadwal jika args kosong, sepanjang -benchtime default agar
+// benchmark hanya dijalankan sekali
+var defaultArgs = []string{"-c", "10", "-duration", "1s"}
+
+// Handler menjalankan load test ke h lewat httptest.Server. args sama dengan
+// argumen CLI tanpa URL (default "-c 10 -duration 1s").
+//
+// b.N tidak dipakai: setiap pemanggilan menjalankan seluruh test, sehingga
+// jadwal sebaiknya minimal sepanjang -benchtime (default 1s) atau jalankan
+// dengan -benchtime=1x agar test tidak diulang.
+func Handler(b *testing.B, h http.Handler, args ...string) *engine.Report {
+    b.Helper()
+    server := httptest.NewServer(h)
+    defer server.Close()
+    return URL(b, server.URL, args...)
+}
+
+// URL seperti Handler untuk server yang sudah berjalan. Pengaman target
+// (-allow-hosts, deteksi production) tetap berlaku.
+func URL(b *testing.B, target string, args ...string) *engine.Report {
+    b.Helper()
+    if len(args) == 0 {
+        args = defaultArgs
+    }
+    config, err := engine.ParseArgs(append(append([]string(nil), args...), target)...)
+    if err != nil {
+        b.Fatalf("loadtest: %v", err)
+    }
+
+    report, output, err := runQuiet(b.Context(), config)
+    var thresholdErr *engine.ThresholdError
+    switch {
+    case errors.As(err, &thresholdErr):
+        b.Errorf("loadtest: %v", err)
+    case err != nil:
+        b.Logf("%s", output)
+        b.Fatalf("loadtest: %v", err)
+    }
+
+    b.ReportMetric(report.Throughput(), "req/s")
+    b.ReportMetric(float64(report.Latencies().P50)/1e6, "p50-ms")
+    b.ReportMetric(float64(report.Latencies().P99)/1e6, "p99-ms")
+    b.ReportMetric(report.ErrorRate, "err-%")
+    // ns/op bawaan adalah durasi seluruh test dibagi b.N, tidak bermakna di sini
+    b.ReportMetric(0, "ns/op")
+    return report
+}
+
+// runQuiet menjalankan test dengan output terminal ditampung, agar hasil
+// go test tetap terbaca. Output dikembalikan untuk ditampilkan saat gagal.
+func runQuiet(ctx context.Context, config *engine.Config) (*engine.Report, []byte, error) {
+    var output bytes.Buffer
+    report, err := engine.Run(ctx, config, engine.WithOutput(&output))
+    return report, output.Bytes(), err
+}
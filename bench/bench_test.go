@@ -0,0 +1,17 @@
+package bench_test
+
+import (
+    "net/http"
+    "testing"
+    "time"
+
+    "loadtest/bench"
+)
+
+func BenchmarkHandler(b *testing.B) {
+    handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        time.Sleep(2 * time.Millisecond)
+        w.Write([]byte(`{"ok":true}`))
+    })
+    bench.Handler(b, handler, "-c", "8", "-duration", "1s")
+}
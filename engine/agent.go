@@ -0,0 +1,437 @@
+package engine
+
+import (
+    "bytes"
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "runtime"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// Kebijakan -on-drift saat konfigurasi agent berbeda dari acuan controller
+const (
+    driftAbort      = "abort"      // Hentikan test, hasil agent tidak dipakai
+    driftQuarantine = "quarantine" // Test jalan terus, report ditandai agar dilewati saat digabung
+)
+
+// agentHeartbeat jarak heartbeat agent ke controller
+const agentHeartbeat = 5 * time.Second
+
+// agentActionPause instruksi controller agar agent berhenti mengirim beban
+// sementara karena tidak sehat
+const agentActionPause = "pause"
+
+// AgentHello identitas dan konfigurasi agent yang dikirim saat mendaftar
+// dan setiap heartbeat
+type AgentHello struct {
+    Agent      string       `json:"agent"`
+    Version    string       `json:"version"`
+    ConfigHash string       `json:"config_hash"`
+    Health     *AgentHealth `json:"health,omitempty"` // Hanya di heartbeat
+
+    GroupRate float64 `json:"group_rate,omitempty"` // -group-rate, dibagi controller ke agent
+    Balance   string  `json:"balance,omitempty"`
+    Weight    float64 `json:"weight,omitempty"`
+}
+
+// AgentHealth kondisi generator sejak test dimulai, dikirim setiap heartbeat
+type AgentHealth struct {
+    CPUPct   float64 `json:"cpu_pct"` // CPU proses sejak heartbeat sebelumnya, persen dari semua core
+    Requests int64   `json:"requests"`
+    Drops    int64   `json:"drops"` // Job yang terlambat dari jadwal: antre >= 10 ms atau iterasi -pacing terlambat
+}
+
+// AgentReply jawaban controller untuk pendaftaran dan heartbeat
+type AgentReply struct {
+    Status string  `json:"status"`           // ok atau drift
+    Reason string  `json:"reason,omitempty"` // Perbedaan dengan acuan grup jika drift
+    Action string  `json:"action,omitempty"` // pause jika agent harus berhenti mengirim beban sementara
+    Health string  `json:"health,omitempty"` // Alasan agent dianggap tidak sehat
+    Rate   float64 `json:"rate,omitempty"`   // Bagian -group-rate untuk agent ini
+    Error  string  `json:"error,omitempty"`  // Alasan controller menolak permintaan
+}
+
+// AgentInfo identitas agent di metadata report run terdistribusi
+type AgentInfo struct {
+    Group       string `json:"group"`
+    Agent       string `json:"agent"`
+    Version     string `json:"version"`
+    ConfigHash  string `json:"config_hash"`
+    Quarantined bool   `json:"quarantined,omitempty"` // Konfigurasi berbeda dari controller, jangan digabung
+    Drift       string `json:"drift,omitempty"`
+
+    PeakCPUPct float64      `json:"peak_cpu_pct,omitempty"`
+    Drops      int64        `json:"drops,omitempty"`
+    Shed       int64        `json:"shed_jobs,omitempty"` // Job open-loop yang dibuang selama dijeda
+    Pauses     []AgentPause `json:"pauses,omitempty"`
+
+    GroupRate float64      `json:"group_rate,omitempty"`
+    Balance   string       `json:"balance,omitempty"`
+    Weight    float64      `json:"weight,omitempty"`
+    Shares    []AgentShare `json:"rate_shares,omitempty"` // Bagian rate dari controller selama test
+}
+
+// AgentPause satu jeda beban atas instruksi controller
+type AgentPause struct {
+    AtSec  float64 `json:"at_s"` // Detik sejak test dimulai
+    Sec    float64 `json:"duration_s"`
+    Reason string  `json:"reason"`
+}
+
+// configHash sidik jari konfigurasi yang harus sama di semua agent satu
+// run: target, body, header, skenario, data dan opsi koneksi. Concurrency,
+// rate dan durasi boleh berbeda per agent. File skenario dan -param-file
+// dibaca ulang agar perubahan isi file di tengah run ikut terdeteksi.
+func configHash(config *Config) string {
+    h := sha256.New()
+    fmt.Fprintf(h, "%s %s\n", config.Method, config.URL)
+    fmt.Fprintf(h, "body %s\n", sha256Hex([]byte(config.Body)))
+    for _, header := range config.Headers {
+        fmt.Fprintf(h, "header %s\n", header)
+    }
+    fmt.Fprintf(h, "timeout %d keep-alive %t pool %s seed %d\n", config.Timeout, config.KeepAlive, config.ConnPool, config.Seed)
+    if config.GroupRate > 0 {
+        fmt.Fprintf(h, "group-rate %g balance %s\n", config.GroupRate, config.Balance)
+    }
+    files := []string{config.Scenario}
+    for _, spec := range config.ParamFiles {
+        if _, path, found := strings.Cut(spec, "="); found {
+            files = append(files, path)
+        }
+    }
+    for _, path := range files {
+        if path == "" {
+            continue
+        }
+        data, err := os.ReadFile(path)
+        if err != nil {
+            fmt.Fprintf(h, "file %s tidak terbaca\n", path)
+            continue
+        }
+        fmt.Fprintf(h, "file %s %s\n", path, sha256Hex(data))
+    }
+    return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// agentLink hubungan agent dengan controller (-run-group): mendaftar
+// sebelum test, lalu heartbeat berisi kesehatan generator selama test. Jika
+// controller melaporkan konfigurasi agent berbeda dari acuan grup, test
+// dihentikan (-on-drift abort) atau report ditandai quarantined. Jika
+// controller menilai agent tidak sehat, beban dijeda sampai dilanjutkan.
+type agentLink struct {
+    config *Config
+    base   string
+    group  string
+    token  string // Dari env LOADTEST_TOKEN jika server memakai -tokens
+    client *http.Client
+    hello  AgentHello
+
+    ctx    context.Context // Dibatalkan saat drift dengan -on-drift abort
+    cancel context.CancelFunc
+    done   chan struct{}
+    once   sync.Once
+
+    stats   *Stats
+    start   time.Time
+    paused  atomic.Bool
+    shed    atomic.Int64
+    share   atomic.Uint64 // Bagian -group-rate dalam bit float64
+    lastCPU time.Duration
+    lastAt  time.Time
+
+    mu          sync.Mutex
+    quarantined bool
+    drift       string
+    aborted     bool
+    peakCPU     float64
+    drops       int64
+    pauses      []AgentPause
+    shares      []AgentShare
+}
+
+// startAgent mendaftarkan agent ke controller. Error jika controller tidak
+// bisa dihubungi atau konfigurasi berbeda dengan -on-drift abort. Nil jika
+// -run-group kosong.
+func startAgent(config *Config) (*agentLink, error) {
+    if config.RunGroup == "" {
+        return nil, nil
+    }
+    host, _ := os.Hostname()
+    a := &agentLink{
+        config: config,
+        base:   strings.TrimSuffix(config.ClockSync, "/"),
+        group:  config.RunGroup,
+        token:  os.Getenv("LOADTEST_TOKEN"),
+        client: &http.Client{Timeout: 5 * time.Second},
+        hello: AgentHello{
+            Agent:      fmt.Sprintf("%s-%d", host, os.Getpid()),
+            Version:    version,
+            ConfigHash: configHash(config),
+        },
+        done: make(chan struct{}),
+    }
+    if config.GroupRate > 0 {
+        a.hello.GroupRate, a.hello.Balance, a.hello.Weight = config.GroupRate, config.Balance, config.AgentWeight
+    }
+    a.ctx, a.cancel = context.WithCancel(context.Background())
+    reply, err := a.post("register", a.hello)
+    if err != nil {
+        return nil, fmt.Errorf("mendaftar ke controller %s (config agent %s): %w", a.base, a.hello.ConfigHash, err)
+    }
+    fmt.Fprintf(config.out, "🛰️  Agent %s terdaftar di grup %s (config %s)\n", a.hello.Agent, a.group, a.hello.ConfigHash)
+    if reply.Status != "ok" && a.onDrift(reply.Reason) {
+        a.stop()
+        return nil, fmt.Errorf("konfigurasi agent berbeda dari controller (grup %s): %s", a.group, reply.Reason)
+    }
+    a.setRate(reply.Rate)
+    return a, nil
+}
+
+// run mengirim heartbeat sampai stop
+func (a *agentLink) run(stats *Stats) {
+    if a == nil {
+        return
+    }
+    a.stats, a.start = stats, time.Now()
+    a.lastCPU, _ = processCPUTime()
+    a.lastAt = a.start
+    go func() {
+        ticker := time.NewTicker(agentHeartbeat)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-a.done:
+                return
+            case <-ticker.C:
+                a.heartbeat()
+            }
+        }
+    }()
+}
+
+func (a *agentLink) heartbeat() {
+    hello := a.hello
+    hello.ConfigHash = configHash(a.config)
+    hello.Health = a.health()
+    reply, err := a.post("heartbeat", hello)
+    if err != nil {
+        // Controller tidak terjangkau sesaat tidak menghentikan test, dan
+        // jeda tidak boleh tertahan tanpa controller yang melanjutkannya
+        a.setPaused(false, "")
+        return
+    }
+    if reply.Status != "ok" {
+        a.onDrift(reply.Reason)
+    }
+    a.setPaused(reply.Action == agentActionPause, reply.Health)
+    a.setRate(reply.Rate)
+}
+
+// health mengukur CPU proses sejak heartbeat sebelumnya. Di platform tanpa
+// getrusage CPU selalu 0 sehingga hanya drop yang dinilai controller.
+func (a *agentLink) health() *AgentHealth {
+    h := &AgentHealth{
+        Requests: a.stats.TotalRequests.Load(),
+        Drops:    a.stats.queue.late.Load() + a.stats.pacingMissed.Load(),
+    }
+    now := time.Now()
+    if cpu, ok := processCPUTime(); ok {
+        if wall := now.Sub(a.lastAt); wall > 0 {
+            h.CPUPct = float64(cpu-a.lastCPU) / float64(wall) / float64(runtime.NumCPU()) * 100
+        }
+        a.lastCPU = cpu
+    }
+    a.lastAt = now
+    a.mu.Lock()
+    a.peakCPU, a.drops = max(a.peakCPU, h.CPUPct), h.Drops
+    a.mu.Unlock()
+    return h
+}
+
+// setPaused menerapkan instruksi jeda dari controller
+func (a *agentLink) setPaused(paused bool, reason string) {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if a.paused.Load() == paused {
+        return
+    }
+    now := time.Since(a.start).Seconds()
+    if paused {
+        a.pauses = append(a.pauses, AgentPause{AtSec: now, Reason: reason})
+        fmt.Fprintf(a.config.out, "⏸️  Controller menjeda beban agent ini: %s\n", reason)
+    } else {
+        last := &a.pauses[len(a.pauses)-1]
+        last.Sec = now - last.AtSec
+        fmt.Fprintf(a.config.out, "▶️  Beban agent dilanjutkan setelah dijeda %.0f detik\n", last.Sec)
+    }
+    a.paused.Store(paused)
+}
+
+// hold menahan job selama controller menjeda agent. Job open-loop (due
+// terisi) dibuang karena jadwalnya sudah lewat saat jeda selesai; job
+// closed-loop menunggu. False jika job tidak dijalankan.
+func (a *agentLink) hold(ctx context.Context, due time.Time) bool {
+    if a == nil || !a.paused.Load() {
+        return true
+    }
+    if !due.IsZero() {
+        a.shed.Add(1)
+        return false
+    }
+    ticker := time.NewTicker(100 * time.Millisecond)
+    defer ticker.Stop()
+    for a.paused.Load() {
+        select {
+        case <-ctx.Done():
+            return false
+        case <-a.done:
+            return true
+        case <-ticker.C:
+        }
+    }
+    return true
+}
+
+// onDrift menerapkan -on-drift, true jika test harus dihentikan
+func (a *agentLink) onDrift(reason string) bool {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if a.drift != "" {
+        return a.aborted
+    }
+    a.drift = reason
+    if a.config.OnDrift == driftQuarantine {
+        a.quarantined = true
+        fmt.Fprintf(a.config.out, "⚠️  Konfigurasi agent berbeda dari controller: %s; hasil agent ini ditandai quarantined\n", reason)
+        return false
+    }
+    a.aborted = true
+    fmt.Fprintf(a.config.out, "❌ Konfigurasi agent berbeda dari controller: %s; test dihentikan\n", reason)
+    a.cancel()
+    return true
+}
+
+// context dibatalkan jika test dihentikan karena drift
+func (a *agentLink) context() context.Context {
+    if a == nil {
+        return context.Background()
+    }
+    return a.ctx
+}
+
+// err error jika test dihentikan karena drift
+func (a *agentLink) err() error {
+    if a == nil {
+        return nil
+    }
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if a.aborted {
+        return fmt.Errorf("test dihentikan karena konfigurasi agent berbeda dari controller: %s", a.drift)
+    }
+    return nil
+}
+
+// stop menghentikan heartbeat dan memberi tahu controller agent selesai
+func (a *agentLink) stop() {
+    if a == nil {
+        return
+    }
+    a.once.Do(func() {
+        close(a.done)
+        a.post("done", a.hello)
+        a.cancel()
+        // Jeda yang masih berjalan saat test selesai ditutup tanpa melanjutkan
+        a.mu.Lock()
+        if a.paused.Load() {
+            last := &a.pauses[len(a.pauses)-1]
+            last.Sec = time.Since(a.start).Seconds() - last.AtSec
+        }
+        a.mu.Unlock()
+    })
+}
+
+func (a *agentLink) info() *AgentInfo {
+    if a == nil {
+        return nil
+    }
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    return &AgentInfo{
+        Group:       a.group,
+        Agent:       a.hello.Agent,
+        Version:     a.hello.Version,
+        ConfigHash:  a.hello.ConfigHash,
+        Quarantined: a.quarantined,
+        Drift:       a.drift,
+        PeakCPUPct:  a.peakCPU,
+        Drops:       a.drops,
+        Shed:        a.shed.Load(),
+        Pauses:      append([]AgentPause(nil), a.pauses...),
+        GroupRate:   a.hello.GroupRate,
+        Balance:     a.hello.Balance,
+        Weight:      a.hello.Weight,
+        Shares:      append([]AgentShare(nil), a.shares...),
+    }
+}
+
+func printAgentHealth(w io.Writer, report *Report) {
+    a := report.Metadata.Agent
+    if a == nil {
+        return
+    }
+    fmt.Fprintf(w, "\n🛰️  Kesehatan Agent %s (grup %s):\n", a.Agent, a.Group)
+    fmt.Fprintf(w, "  CPU puncak:            %.0f%%\n", a.PeakCPUPct)
+    fmt.Fprintf(w, "  Job terlambat jadwal:  %d\n", a.Drops)
+    if a.GroupRate > 0 && len(a.Shares) > 0 {
+        fmt.Fprintf(w, "  Bagian rate (%s):  %.1f dari %.1f req/s grup, %d kali berubah\n", a.Balance, a.Shares[len(a.Shares)-1].Rate, a.GroupRate, len(a.Shares)-1)
+    }
+    if len(a.Pauses) == 0 {
+        return
+    }
+    fmt.Fprintf(w, "  Dijeda controller:     %d kali, %d job open-loop dibuang\n", len(a.Pauses), a.Shed)
+    for _, p := range a.Pauses {
+        fmt.Fprintf(w, "    detik %.0f selama %.0f detik: %s\n", p.AtSec, p.Sec, p.Reason)
+    }
+}
+
+func (a *agentLink) post(action string, hello AgentHello) (*AgentReply, error) {
+    data, err := json.Marshal(hello)
+    if err != nil {
+        return nil, err
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    endpoint := a.base + "/agents/" + a.group + "/" + action
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    if a.token != "" {
+        req.Header.Set("Authorization", "Bearer "+a.token)
+    }
+    resp, err := a.client.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+    var reply AgentReply
+    err = json.NewDecoder(resp.Body).Decode(&reply)
+    if err == nil && reply.Error != "" {
+        return nil, fmt.Errorf("%s: HTTP %d: %s", endpoint, resp.StatusCode, reply.Error)
+    }
+    if err != nil || (resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusConflict) {
+        return nil, fmt.Errorf("%s: HTTP %d", endpoint, resp.StatusCode)
+    }
+    return &reply, nil
+}
@@ -0,0 +1,163 @@
+package engine
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "strings"
+    "time"
+)
+
+// alertKey kunci deduplikasi incident per target, sehingga run berikutnya
+// yang lulus threshold bisa menutup incident yang sama
+func alertKey(report *Report) string {
+    return fmt.Sprintf("loadtest:%s %s", report.Method, report.URL)
+}
+
+func alertSummary(report *Report, breached []ThresholdResult) string {
+    var failed []string
+    for _, result := range breached {
+        failed = append(failed, result.String())
+    }
+    return fmt.Sprintf("Load test %s %s melanggar SLO: %s", report.Method, report.URL, strings.Join(failed, ", "))
+}
+
+func postJSON(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, payload interface{}) error {
+    body, err := json.Marshal(payload)
+    if err != nil {
+        return err
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    for k, v := range headers {
+        req.Header.Set(k, v)
+    }
+
+    resp, err := client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode/100 != 2 {
+        msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+        return newSinkError(resp, fmt.Sprintf("%s merespon %s: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(msg))))
+    }
+    return nil
+}
+
+// pagerDutyNotifier membuka incident lewat Events API v2 saat threshold
+// dilanggar dan me-resolve-nya saat run berikutnya lulus
+type pagerDutyNotifier struct {
+    routingKey string
+    endpoint   string
+    client     *http.Client
+}
+
+func newPagerDutyNotifier(routingKey string) *pagerDutyNotifier {
+    endpoint := os.Getenv("PAGERDUTY_EVENTS_URL")
+    if endpoint == "" {
+        endpoint = "https://events.pagerduty.com/v2/enqueue"
+    }
+    return &pagerDutyNotifier{routingKey: routingKey, endpoint: endpoint, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (p *pagerDutyNotifier) RunStarted(runID string, config *Config, startTime time.Time) error {
+    return nil
+}
+
+func (p *pagerDutyNotifier) RunFinished(report *Report, breached []ThresholdResult) error {
+    if len(report.Thresholds) == 0 {
+        return nil
+    }
+
+    event := map[string]interface{}{
+        "routing_key":  p.routingKey,
+        "dedup_key":    alertKey(report),
+        "event_action": "resolve",
+    }
+    if len(breached) > 0 {
+        host, _ := os.Hostname()
+        event["event_action"] = "trigger"
+        event["payload"] = map[string]interface{}{
+            "summary":        alertSummary(report, breached),
+            "source":         host,
+            "severity":       "error",
+            "component":      report.URL,
+            "class":          "load-test",
+            "custom_details": report,
+        }
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+    return postJSON(ctx, p.client, p.endpoint, nil, event)
+}
+
+// opsgenieNotifier membuat alert Opsgenie saat threshold dilanggar dan
+// menutupnya (berdasarkan alias) saat run berikutnya lulus
+type opsgenieNotifier struct {
+    apiKey  string
+    baseURL string
+    client  *http.Client
+}
+
+func newOpsgenieNotifier(apiKey string) *opsgenieNotifier {
+    baseURL := os.Getenv("OPSGENIE_API_URL")
+    if baseURL == "" {
+        baseURL = "https://api.opsgenie.com"
+    }
+    return &opsgenieNotifier{apiKey: apiKey, baseURL: strings.TrimSuffix(baseURL, "/"), client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (o *opsgenieNotifier) RunStarted(runID string, config *Config, startTime time.Time) error {
+    return nil
+}
+
+func (o *opsgenieNotifier) RunFinished(report *Report, breached []ThresholdResult) error {
+    if len(report.Thresholds) == 0 {
+        return nil
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+    headers := map[string]string{"Authorization": "GenieKey " + o.apiKey}
+    alias := alertKey(report)
+
+    if len(breached) == 0 {
+        endpoint := fmt.Sprintf("%s/v2/alerts/%s/close?identifierType=alias", o.baseURL, url.PathEscape(alias))
+        return postJSON(ctx, o.client, endpoint, headers, map[string]string{"source": "loadtest"})
+    }
+
+    details := map[string]string{
+        "run_id":      report.RunID,
+        "rps":         fmt.Sprintf("%.2f", report.RPS),
+        "avg_latency": fmt.Sprintf("%.2f ms", report.AvgLatencyMs),
+        "error_rate":  fmt.Sprintf("%.2f%%", report.ErrorRate),
+    }
+    alert := map[string]interface{}{
+        "message":     truncate(alertSummary(report, breached), 130),
+        "alias":       alias,
+        "description": alertSummary(report, breached),
+        "tags":        []string{"loadtest"},
+        "details":     details,
+        "priority":    "P2",
+    }
+    return postJSON(ctx, o.client, o.baseURL+"/v2/alerts", headers, alert)
+}
+
+// truncate memotong string ke maksimal n karakter
+func truncate(s string, n int) string {
+    runes := []rune(s)
+    if len(runes) <= n {
+        return s
+    }
+    return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,284 @@
+package engine
+
+import (
+    "bytes"
+    "encoding/hex"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+    "sync"
+
+    "github.com/antchfx/xmlquery"
+    "github.com/antchfx/xpath"
+)
+
+// Assertion memeriksa response yang body-nya sudah dibaca lengkap. Request
+// dengan assertion gagal dihitung sebagai request gagal.
+type Assertion interface {
+    Check(resp *http.Response, body []byte) error
+    String() string
+}
+
+// streamAssertion Assertion yang juga bisa diperiksa sambil body di-stream,
+// sehingga response besar tidak perlu disimpan utuh di memori
+type streamAssertion interface {
+    Assertion
+    matcher() streamMatcher
+}
+
+// streamMatcher menerima body potongan demi potongan lewat Write, lalu
+// result memberi hasil assertion setelah body habis
+type streamMatcher interface {
+    io.Writer
+    result() error
+}
+
+func newAssertions(config *Config) ([]Assertion, error) {
+    var assertions []Assertion
+    for _, expr := range config.AssertXPath {
+        a, err := newXPathAssertion(expr)
+        if err != nil {
+            return nil, err
+        }
+        assertions = append(assertions, a)
+    }
+    if config.AssertPrefix != "" {
+        prefix, err := parseAssertBytes(config.AssertPrefix)
+        if err != nil {
+            return nil, err
+        }
+        assertions = append(assertions, &prefixAssertion{raw: config.AssertPrefix, want: prefix})
+    }
+    for _, raw := range config.AssertTokens {
+        token, err := parseAssertBytes(raw)
+        if err != nil {
+            return nil, err
+        }
+        assertions = append(assertions, &containsAssertion{raw: raw, token: token})
+    }
+    return assertions, nil
+}
+
+// streamable true jika semua assertion bisa diperiksa secara streaming
+func streamable(assertions []Assertion) bool {
+    for _, a := range assertions {
+        if _, ok := a.(streamAssertion); !ok {
+            return false
+        }
+    }
+    return true
+}
+
+// streamCheck memeriksa beberapa streamAssertion sekaligus; body ditulis
+// ke Write (biasanya lewat io.TeeReader) lalu hasilnya dibaca dengan err
+type streamCheck struct {
+    assertions []Assertion
+    matchers   []streamMatcher
+    head       []byte // Awal body untuk contoh error jika assertion gagal
+}
+
+func newStreamCheck(assertions []Assertion) *streamCheck {
+    c := &streamCheck{assertions: assertions, matchers: make([]streamMatcher, len(assertions))}
+    for i, a := range assertions {
+        c.matchers[i] = a.(streamAssertion).matcher()
+    }
+    return c
+}
+
+func (c *streamCheck) Write(p []byte) (int, error) {
+    if len(c.head) < errorSampleSize {
+        c.head = append(c.head, p[:min(len(p), errorSampleSize-len(c.head))]...)
+    }
+    for _, m := range c.matchers {
+        m.Write(p)
+    }
+    return len(p), nil
+}
+
+func (c *streamCheck) err() error {
+    for i, m := range c.matchers {
+        if err := m.result(); err != nil {
+            return &assertionError{assertion: c.assertions[i], err: err}
+        }
+    }
+    return nil
+}
+
+// checkStream menjalankan streamAssertion pada body yang sudah dibaca utuh
+func checkStream(a streamAssertion, body []byte) error {
+    m := a.matcher()
+    m.Write(body)
+    return m.result()
+}
+
+// parseAssertBytes membaca nilai assertion body, awalan 'hex:' untuk data
+// biner (contoh hex:89504e47)
+func parseAssertBytes(raw string) ([]byte, error) {
+    if value, ok := strings.CutPrefix(raw, "hex:"); ok {
+        data, err := hex.DecodeString(value)
+        if err != nil {
+            return nil, fmt.Errorf("nilai hex %q tidak valid: %w", value, err)
+        }
+        raw = string(data)
+    }
+    if raw == "" {
+        return nil, fmt.Errorf("nilai assertion body tidak boleh kosong")
+    }
+    return []byte(raw), nil
+}
+
+// prefixAssertion lulus jika N byte pertama body sama persis dengan want
+type prefixAssertion struct {
+    raw  string
+    want []byte
+}
+
+func (a *prefixAssertion) Check(resp *http.Response, body []byte) error { return checkStream(a, body) }
+func (a *prefixAssertion) String() string                               { return "prefix " + a.raw }
+func (a *prefixAssertion) matcher() streamMatcher                       { return &prefixMatcher{want: a.want} }
+
+type prefixMatcher struct {
+    want     []byte
+    got      int
+    mismatch bool
+}
+
+func (m *prefixMatcher) Write(p []byte) (int, error) {
+    if m.mismatch || m.got >= len(m.want) {
+        return len(p), nil
+    }
+    n := min(len(p), len(m.want)-m.got)
+    if !bytes.Equal(p[:n], m.want[m.got:m.got+n]) {
+        m.mismatch = true
+    }
+    m.got += n
+    return len(p), nil
+}
+
+func (m *prefixMatcher) result() error {
+    switch {
+    case m.mismatch:
+        return fmt.Errorf("%d byte pertama tidak cocok", len(m.want))
+    case m.got < len(m.want):
+        return fmt.Errorf("body hanya %d byte, lebih pendek dari prefix", m.got)
+    }
+    return nil
+}
+
+// containsAssertion lulus jika token muncul di mana saja dalam body. Saat
+// streaming hanya len(token)-1 byte terakhir yang disimpan untuk token yang
+// terpotong di batas potongan.
+type containsAssertion struct {
+    raw   string
+    token []byte
+}
+
+func (a *containsAssertion) Check(resp *http.Response, body []byte) error {
+    return checkStream(a, body)
+}
+func (a *containsAssertion) String() string         { return "contains " + a.raw }
+func (a *containsAssertion) matcher() streamMatcher { return &containsMatcher{token: a.token} }
+
+type containsMatcher struct {
+    token []byte
+    tail  []byte
+    found bool
+}
+
+func (m *containsMatcher) Write(p []byte) (int, error) {
+    if m.found {
+        return len(p), nil
+    }
+    keep := len(m.token) - 1
+    if bytes.Contains(p, m.token) || bytes.Contains(append(m.tail, p[:min(keep, len(p))]...), m.token) {
+        m.found = true
+        return len(p), nil
+    }
+    m.tail = append(m.tail, p[max(0, len(p)-keep):]...)
+    if len(m.tail) > keep {
+        m.tail = append(m.tail[:0], m.tail[len(m.tail)-keep:]...)
+    }
+    return len(p), nil
+}
+
+func (m *containsMatcher) result() error {
+    if !m.found {
+        return fmt.Errorf("token tidak ditemukan")
+    }
+    return nil
+}
+
+func checkAssertions(assertions []Assertion, resp *http.Response, body []byte) error {
+    for _, a := range assertions {
+        if err := a.Check(resp, body); err != nil {
+            return &assertionError{assertion: a, err: err}
+        }
+    }
+    return nil
+}
+
+// assertionError request berhasil dikirim tetapi response tidak lolos assertion
+type assertionError struct {
+    assertion Assertion
+    err       error
+}
+
+func (e *assertionError) Error() string {
+    return fmt.Sprintf("assertion %s gagal: %v", e.assertion, e.err)
+}
+
+func (e *assertionError) Unwrap() error { return e.err }
+
+// xpathAssertion lulus jika ekspresi XPath pada body XML menghasilkan node,
+// true, angka bukan nol atau string tidak kosong. Contoh:
+// "//*[local-name()='Status']='OK'" atau "count(//*[local-name()='Fault'])=0".
+type xpathAssertion struct {
+    raw string
+    // xpath.Expr menyimpan state saat evaluasi, jadi setiap worker memakai
+    // hasil compile sendiri
+    exprs sync.Pool
+}
+
+func newXPathAssertion(raw string) (*xpathAssertion, error) {
+    if _, err := xpath.Compile(raw); err != nil {
+        return nil, fmt.Errorf("XPath %q tidak valid: %w", raw, err)
+    }
+    a := &xpathAssertion{raw: raw}
+    a.exprs.New = func() any { return xpath.MustCompile(raw) }
+    return a, nil
+}
+
+func (a *xpathAssertion) Check(resp *http.Response, body []byte) error {
+    doc, err := xmlquery.Parse(bytes.NewReader(body))
+    if err != nil {
+        return fmt.Errorf("body bukan XML: %w", err)
+    }
+
+    expr := a.exprs.Get().(*xpath.Expr)
+    defer a.exprs.Put(expr)
+
+    var ok bool
+    switch v := expr.Evaluate(xmlquery.CreateXPathNavigator(doc)).(type) {
+    case bool:
+        ok = v
+    case float64:
+        ok = v != 0
+    case string:
+        ok = v != ""
+    case *xpath.NodeIterator:
+        ok = v.MoveNext()
+    }
+    if !ok {
+        return fmt.Errorf("tidak cocok")
+    }
+    return nil
+}
+
+func (a *xpathAssertion) String() string { return "xpath " + a.raw }
+
+// stringList flag yang bisa diisi berulang kali
+type stringList []string
+
+func (l *stringList) String() string     { return fmt.Sprint([]string(*l)) }
+func (l *stringList) Set(v string) error { *l = append(*l, v); return nil }
@@ -0,0 +1,161 @@
+package engine
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "time"
+)
+
+// Cara controller membagi -group-rate ke agent grup (-balance)
+const (
+    balanceEven     = "even"     // Sama rata
+    balanceWeight   = "weight"   // Sebanding -agent-weight
+    balanceCapacity = "capacity" // Sebanding kapasitas terukur dari CPU agent
+)
+
+// balanceMinCPU CPU minimal agar kapasitas agent bisa diukur. Di bawah ini
+// pemakaian CPU terlalu kecil dan kapasitas hasil ekstrapolasi tidak stabil.
+const balanceMinCPU = 5.0
+
+// balanceShareChange perubahan relatif bagian rate yang dicetak dan dicatat
+// di report agent
+const balanceShareChange = 0.05
+
+// groupRateScheduler mengirim request dengan rate bagian agent ini dari
+// -group-rate. Bagiannya dihitung controller dan bisa berubah setiap
+// heartbeat saat agent bergabung, hilang, dijeda atau kapasitasnya berubah.
+type groupRateScheduler struct {
+    groupRate float64
+    balance   string
+    duration  time.Duration
+    agent     *agentLink // Diisi setelah agent mendaftar
+}
+
+func (s *groupRateScheduler) Run(ctx context.Context, jobs chan<- job) {
+    ctx, cancel := context.WithTimeout(ctx, s.duration)
+    defer cancel()
+
+    start := time.Now()
+    next := start
+    for i := 0; ; i++ {
+        rate := s.agent.rate()
+        for rate <= 0 {
+            // Bagian kosong: agent dijeda atau konfigurasinya berbeda, tunggu
+            // bagian baru tanpa menumpuk jadwal yang terlewat
+            if !sleepUntil(ctx, time.Now().Add(100*time.Millisecond)) {
+                return
+            }
+            rate, next = s.agent.rate(), time.Now()
+        }
+        if next.Sub(start) >= s.duration || !sleepUntil(ctx, next) {
+            return
+        }
+        if !send(ctx, jobs, i, next) {
+            return
+        }
+        next = next.Add(time.Duration(float64(time.Second) / rate))
+    }
+}
+
+// Total perkiraan dari bagian rate saat ini
+func (s *groupRateScheduler) Total() int {
+    return int(s.agent.rate() * s.duration.Seconds())
+}
+
+func (s *groupRateScheduler) String() string {
+    return fmt.Sprintf("bagian dari %.1f req/s grup (%s) selama %v", s.groupRate, s.balance, s.duration)
+}
+
+// AgentShare bagian rate agent sejak detik tertentu
+type AgentShare struct {
+    AtSec float64 `json:"at_s"` // Detik sejak test dimulai
+    Rate  float64 `json:"rate"`
+}
+
+// carries true jika agent ikut menanggung -group-rate. Bagian agent yang
+// dijeda, berbeda konfigurasi, selesai atau hilang dibagi ke agent lain.
+func (a *AgentState) carries() bool {
+    return a.Status == agentActive && a.Health != agentPaused
+}
+
+// capacityKnown true jika kapasitas semua agent yang menanggung beban
+// sudah terukur. Selama belum, -balance capacity memakai bobot agent.
+func (g *AgentGroup) capacityKnown() bool {
+    for _, a := range g.Agents {
+        if a.carries() && a.Capacity <= 0 {
+            return false
+        }
+    }
+    return true
+}
+
+// share bagian -group-rate untuk agent sesuai -balance. Dipanggil dengan
+// mu terkunci.
+func (g *AgentGroup) share(target *AgentState) float64 {
+    if g.GroupRate <= 0 || !target.carries() {
+        return 0
+    }
+    byCapacity := g.Balance == balanceCapacity && g.capacityKnown()
+    var total, own float64
+    for _, a := range g.Agents {
+        if !a.carries() {
+            continue
+        }
+        weight := a.Weight
+        switch {
+        case g.Balance == balanceEven:
+            weight = 1
+        case byCapacity:
+            weight = a.Capacity
+        }
+        total += weight
+        if a == target {
+            own = weight
+        }
+    }
+    if total <= 0 {
+        return 0
+    }
+    return g.GroupRate * own / total
+}
+
+// measure memperkirakan kapasitas agent (req/s pada CPU 100%) dari rate
+// tercapai dan CPU sejak heartbeat sebelumnya
+func (a *AgentState) measure(jobs int64, elapsed time.Duration, cpuPct float64) {
+    if cpuPct < balanceMinCPU || elapsed <= 0 || jobs <= 0 {
+        return
+    }
+    a.Capacity = float64(jobs) / elapsed.Seconds() / cpuPct * 100
+}
+
+// rate bagian -group-rate agent ini saat ini, dalam req/s
+func (a *agentLink) rate() float64 {
+    if a == nil {
+        return 0
+    }
+    return math.Float64frombits(a.share.Load())
+}
+
+// setRate menerapkan bagian rate dari controller. Perubahan kecil dari
+// pengukuran kapasitas diterapkan tanpa dicatat.
+func (a *agentLink) setRate(rate float64) {
+    if a.config.GroupRate <= 0 {
+        return
+    }
+    a.share.Store(math.Float64bits(rate))
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if n := len(a.shares); n > 0 {
+        last := a.shares[n-1].Rate
+        if rate == last || (last != 0 && rate != 0 && math.Abs(rate-last) < last*balanceShareChange) {
+            return
+        }
+    }
+    at := 0.0
+    if !a.start.IsZero() {
+        at = time.Since(a.start).Seconds()
+    }
+    a.shares = append(a.shares, AgentShare{AtSec: at, Rate: rate})
+    fmt.Fprintf(a.config.out, "⚖️  Bagian rate agent: %.1f dari %.1f req/s grup\n", rate, a.config.GroupRate)
+}
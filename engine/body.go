@@ -0,0 +1,77 @@
+package engine
+
+import (
+    "fmt"
+    "os"
+
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+    "google.golang.org/protobuf/reflect/protodesc"
+    "google.golang.org/protobuf/reflect/protoreflect"
+    "google.golang.org/protobuf/types/descriptorpb"
+    "google.golang.org/protobuf/types/dynamicpb"
+)
+
+// loadBody mengisi config.Body dari -body-file dan meng-encode-nya ke
+// protobuf jika -proto-msg diisi. Body dari file dipakai byte per byte.
+func loadBody(config *Config) error {
+    if config.BodyFile != "" {
+        if config.Body != "" {
+            return fmt.Errorf("-d dan -body-file tidak bisa dipakai bersamaan")
+        }
+        data, err := os.ReadFile(config.BodyFile)
+        if err != nil {
+            return err
+        }
+        config.Body = string(data)
+    }
+
+    if config.ProtoMsg == "" {
+        return nil
+    }
+    if config.ProtoSet == "" {
+        return fmt.Errorf("-proto-msg membutuhkan -proto-set")
+    }
+    data, err := encodeProtoBody(config.ProtoSet, config.ProtoMsg, []byte(config.Body))
+    if err != nil {
+        return err
+    }
+    config.Body = string(data)
+    if config.ContentType == "" {
+        config.ContentType = "application/x-protobuf"
+    }
+    return nil
+}
+
+// encodeProtoBody mengubah body JSON menjadi protobuf binary untuk message
+// messageName. Descriptor dibaca dari file descriptor set hasil
+// `protoc --include_imports --descriptor_set_out=api.pb api.proto`.
+func encodeProtoBody(descriptorSet, messageName string, jsonBody []byte) ([]byte, error) {
+    data, err := os.ReadFile(descriptorSet)
+    if err != nil {
+        return nil, err
+    }
+    var set descriptorpb.FileDescriptorSet
+    if err := proto.Unmarshal(data, &set); err != nil {
+        return nil, fmt.Errorf("%s bukan descriptor set protobuf yang valid: %w", descriptorSet, err)
+    }
+    files, err := protodesc.NewFiles(&set)
+    if err != nil {
+        return nil, fmt.Errorf("%s: %w (pastikan dibuat dengan --include_imports)", descriptorSet, err)
+    }
+
+    desc, err := files.FindDescriptorByName(protoreflect.FullName(messageName))
+    if err != nil {
+        return nil, fmt.Errorf("message %q tidak ditemukan di %s", messageName, descriptorSet)
+    }
+    msgDesc, ok := desc.(protoreflect.MessageDescriptor)
+    if !ok {
+        return nil, fmt.Errorf("%q bukan message protobuf", messageName)
+    }
+
+    msg := dynamicpb.NewMessage(msgDesc)
+    if err := (protojson.UnmarshalOptions{Resolver: dynamicpb.NewTypes(files)}).Unmarshal(jsonBody, msg); err != nil {
+        return nil, fmt.Errorf("body JSON tidak sesuai dengan %s: %w", messageName, err)
+    }
+    return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
@@ -0,0 +1,108 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "math"
+    "sync"
+)
+
+// sizeStats distribusi ukuran body response. Zero value siap dipakai.
+type sizeStats struct {
+    hist histogram
+
+    mu       sync.Mutex
+    min, max int64
+    mean, m2 float64 // Welford, untuk standar deviasi
+}
+
+func (s *sizeStats) add(bytes int64) {
+    s.hist.add(bytes)
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    n := float64(s.hist.count())
+    if n == 1 || bytes < s.min {
+        s.min = bytes
+    }
+    if bytes > s.max {
+        s.max = bytes
+    }
+    delta := float64(bytes) - s.mean
+    s.mean += delta / n
+    s.m2 += delta * (float64(bytes) - s.mean)
+}
+
+// BodySizeReport ringkasan ukuran body response dalam byte
+type BodySizeReport struct {
+    Min         int64   `json:"min_bytes"`
+    Avg         float64 `json:"avg_bytes"`
+    Max         int64   `json:"max_bytes"`
+    P50         int64   `json:"p50_bytes"`
+    P95         int64   `json:"p95_bytes"`
+    P99         int64   `json:"p99_bytes"`
+    StdDev      float64 `json:"stddev_bytes"`
+    CV          float64 `json:"cv"`           // Koefisien variasi (stddev / avg)
+    OutlierRate float64 `json:"outlier_rate"` // Persen response < 1/2 atau > 2x median
+    Warning     string  `json:"warning,omitempty"`
+}
+
+// Batas variasi ukuran yang dianggap mencurigakan
+const (
+    sizeMaxCV          = 0.5
+    sizeMaxOutlierRate = 1.0
+)
+
+func (s *sizeStats) report() *BodySizeReport {
+    total := s.hist.count()
+    if total == 0 {
+        return nil
+    }
+    s.mu.Lock()
+    r := &BodySizeReport{Min: s.min, Avg: s.mean, Max: s.max}
+    if total > 1 {
+        r.StdDev = math.Sqrt(s.m2 / float64(total-1))
+    }
+    s.mu.Unlock()
+
+    // Nilai bucket histogram hanya perkiraan, jangan sampai keluar dari min/max
+    quantile := func(q float64) int64 {
+        return max(r.Min, min(r.Max, s.hist.quantile(q)))
+    }
+    r.P50 = quantile(0.50)
+    r.P95 = quantile(0.95)
+    r.P99 = quantile(0.99)
+    if r.Avg > 0 {
+        r.CV = r.StdDev / r.Avg
+    }
+
+    // Response yang jauh dari median biasanya halaman error atau body
+    // terpotong, meskipun status code-nya terlihat normal
+    var outliers int64
+    for i := range s.hist.counts {
+        v := histValue(i)
+        if v*2 < r.P50 || v > r.P50*2 {
+            outliers += s.hist.counts[i].Load()
+        }
+    }
+    r.OutlierRate = float64(outliers) / float64(total) * 100
+
+    if r.CV > sizeMaxCV || r.OutlierRate >= sizeMaxOutlierRate {
+        r.Warning = fmt.Sprintf("ukuran response sangat bervariasi (%.1f%% jauh dari median), kemungkinan ada halaman error atau body tidak lengkap yang tercampur dengan response normal", r.OutlierRate)
+    }
+    return r
+}
+
+func printBodySizes(w io.Writer, report *Report) {
+    s := report.BodySize
+    if s == nil {
+        return
+    }
+    fmt.Fprintln(w, "\n📦 Ukuran Response:")
+    fmt.Fprintf(w, "  %-23s %d / %.0f / %d bytes\n", "Min / avg / max:", s.Min, s.Avg, s.Max)
+    fmt.Fprintf(w, "  %-23s %d / %d / %d bytes\n", "p50 / p95 / p99:", s.P50, s.P95, s.P99)
+    fmt.Fprintf(w, "  %-23s %.0f bytes (CV %.2f)\n", "Std dev:", s.StdDev, s.CV)
+    if s.Warning != "" {
+        fmt.Fprintf(w, "  ⚠️  %s\n", s.Warning)
+    }
+}
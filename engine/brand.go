@@ -0,0 +1,229 @@
+package engine
+
+import (
+    "fmt"
+    htmltemplate "html/template"
+    "os"
+    "path/filepath"
+    "strings"
+    "text/template"
+)
+
+// defaultReportTitle judul report HTML/Markdown tanpa -report-title
+const defaultReportTitle = "Hasil Load Test"
+
+// ReportBrand tampilan report HTML/Markdown (judul, logo, footer dan
+// template custom) untuk deliverable yang siap diberikan ke klien
+type ReportBrand struct {
+    Title  string
+    Logo   string // Data URI, atau URL http(s) jika logo gagal diunduh
+    Footer string
+
+    html     *htmltemplate.Template // Dari -report-template *.html
+    markdown *template.Template     // Dari -report-template *.md
+}
+
+// newReportBrand membaca logo dan template custom. Format yang diganti
+// template ditentukan dari ekstensi filenya.
+func newReportBrand(config *Config) (*ReportBrand, error) {
+    b := &ReportBrand{Title: config.ReportTitle, Footer: config.ReportFooter}
+    if b.Title == "" {
+        b.Title = defaultReportTitle
+    }
+
+    if logo := config.ReportLogo; logo != "" {
+        // Logo selalu disematkan agar report bisa dibuka tanpa internet
+        if strings.HasPrefix(logo, "http://") || strings.HasPrefix(logo, "https://") {
+            b.Logo = fetchLogo(config.out, logo)
+        } else {
+            var err error
+            if b.Logo, err = logoDataURI(logo); err != nil {
+                return nil, err
+            }
+        }
+    }
+
+    for _, path := range config.ReportTemplates {
+        data, err := os.ReadFile(path)
+        if err != nil {
+            return nil, fmt.Errorf("membaca template report: %w", err)
+        }
+        name := filepath.Base(path)
+        switch strings.ToLower(filepath.Ext(path)) {
+        case ".html", ".htm":
+            checkOfflineTemplate(config.out, path, data)
+            b.html, err = htmltemplate.New(name).Parse(string(data))
+        case ".md", ".markdown":
+            b.markdown, err = template.New(name).Parse(string(data))
+        default:
+            return nil, fmt.Errorf("template report %q harus berekstensi .html atau .md", path)
+        }
+        if err != nil {
+            return nil, fmt.Errorf("template report %s: %w", path, err)
+        }
+    }
+    return b, nil
+}
+
+// ReportTitle judul report, dipakai di template sebagai {{.ReportTitle}}
+func (r *Report) ReportTitle() string {
+    if r.Brand == nil || r.Brand.Title == "" {
+        return defaultReportTitle
+    }
+    return r.Brand.Title
+}
+
+// ReportLogo URL logo yang aman dipakai di atribut src, termasuk data URI
+func (r *Report) ReportLogo() htmltemplate.URL {
+    if r.Brand == nil {
+        return ""
+    }
+    return htmltemplate.URL(r.Brand.Logo)
+}
+
+// ReportFooter teks footer report
+func (r *Report) ReportFooter() string {
+    if r.Brand == nil {
+        return ""
+    }
+    return r.Brand.Footer
+}
+
+var markdownReportTemplate = template.Must(template.New("report").Parse(`{{if .ReportLogo}}![logo]({{.ReportLogo}})
+
+{{end}}# {{.ReportTitle}}
+
+| | |
+|---|---|
+| URL | {{.URL}} |
+| Method | {{.Method}} |
+| Jadwal | {{.Schedule}} |
+| Concurrency | {{.Concurrency}} |
+| Mulai | {{.StartTime.Format "2006-01-02 15:04:05 MST"}} |
+| Total waktu | {{$.FloatN .TotalTimeMs 0}} ms |
+| Total requests | {{$.Int .TotalRequests}} |
+| Requests sukses | {{$.Int .Successful}} |
+| Requests gagal | {{$.Int .Failed}} |
+| Requests per detik | {{$.Float .RPS}} |
+{{if .TotalRequests}}| Rata-rata latency | {{$.Float .AvgLatencyMs}} ms |
+| Latency p50 / p95 / p99 | {{$.Float .P50LatencyMs}} / {{$.Float .P95LatencyMs}} / {{$.Float .P99LatencyMs}} ms |
+| Success rate | {{$.FloatN .SuccessRate 1}}% |
+{{else}}| Latency | - (tidak ada request yang selesai) |
+{{end}}
+## Status Codes
+
+| Code | Requests |
+|---|---|
+{{range .SortedStatusCodes}}| {{.Code}} | {{$.Int .Count}} |
+{{else}}| - | Tidak ada response{{if .Failed}}: semua request gagal sebelum server membalas{{end}} |
+{{end}}{{if .Challenges}}
+## Diblokir WAF/Bot Detection
+
+⚠️ Request berikut ditolak sebelum sampai ke aplikasi; latency dan error rate tidak mencerminkan performa aplikasi.
+
+| Vendor | Requests |
+|---|---|
+{{range $vendor, $n := .Challenges}}| {{$vendor}} | {{$.Int $n}} |
+{{end}}{{end}}{{with .ErrorBodies}}{{if .Reasons}}
+## Alasan Error dari Server
+
+{{$.Int .Parsed}} dari {{$.Int .Responses}} response 4xx/5xx berisi kode atau pesan error JSON.
+
+| Status | Kode | Requests | % | Contoh pesan |
+|---|---|---|---|---|
+{{range $.TopErrorReasons}}| {{.Status}} | {{.Code}} | {{$.Int .Count}} | {{$.FloatN .Pct 1}} | {{.Message}} |
+{{end}}{{end}}{{end}}{{with .Budget}}{{if .Events}}
+## Budget Resource Generator
+
+⚠️ Generator menurunkan beban karena mendekati batas resource; hasil setelah degradasi tidak setara dengan beban yang direncanakan.
+
+| Detik | Penyebab | Tindakan |
+|---|---|---|
+{{range .Events}}| {{$.FloatN .AtSec 0}} | {{.Reason}} | {{.Action}} |
+{{end}}{{end}}{{end}}{{with .Metadata.Agent}}{{if .Pauses}}
+## Kesehatan Agent
+
+⚠️ Controller menjeda beban agent {{.Agent}} karena generator tidak sehat; {{$.Int .Shed}} job open-loop dibuang selama jeda.
+
+| Detik | Lama (detik) | Penyebab |
+|---|---|---|
+{{range .Pauses}}| {{$.FloatN .AtSec 0}} | {{$.FloatN .Sec 0}} | {{.Reason}} |
+{{end}}{{end}}{{end}}{{if .Merged}}
+## Run Digabung
+
+Percentile dihitung dari histogram latency gabungan semua run; requests per detik dari rentang waktu semua run.
+
+| Run | Generator | Requests | Req/s | p95 ms | Error % |
+|---|---|---|---|---|---|
+{{range .Merged}}| {{.RunID}} | {{.Host}} | {{$.Int .TotalRequests}} | {{$.FloatN .RPS 1}} | {{$.Float .P95LatencyMs}} | {{$.Float .ErrorRate}} |
+{{end}}{{end}}{{if .Thresholds}}
+## Threshold
+
+| Threshold | Nilai | Hasil |
+|---|---|---|
+{{range .Thresholds}}| {{.Threshold}} | {{if .NoData}}-{{else}}{{$.Float .Actual}}{{end}} | {{if .Passed}}✅{{else}}❌{{end}} |
+{{end}}{{end}}{{with .Trend}}
+## Tren Historis
+
+Dibandingkan dengan {{len .Baseline}} run sebelumnya berprofil sama, batas {{.Sigma}}σ.
+
+| Metrik | Run ini | Rata-rata | Std dev | σ | Hasil |
+|---|---|---|---|---|---|
+{{range .Metrics}}| {{.Metric}} | {{$.Float .Value}} | {{$.Float .Mean}} | {{$.Float .StdDev}} | {{$.FloatN .Z 1}} | {{if .Anomaly}}❌{{else}}✅{{end}} |
+{{end}}{{end}}{{if .Stages}}
+## Hasil per Stage
+
+| Stage | Target | Requests | Req/s | p95 ms | p99 ms | Error % |
+|---|---|---|---|---|---|---|
+{{range .Stages}}| {{.Name}} | {{.Target}} | {{$.Int .Requests}} | {{$.FloatN .RPS 1}} | {{$.FloatN .P95Ms 1}} | {{$.FloatN .P99Ms 1}} | {{$.Float .ErrorRate}} |
+{{end}}{{end}}{{if .Workflows}}
+## Latency Workflow
+
+| Workflow | Selesai | Gagal | p50 ms | p95 ms | p99 ms |
+|---|---|---|---|---|---|
+{{range $name, $w := .Workflows}}| {{$w.From}} → {{$name}} | {{$.Int $w.Completed}} | {{$.Int $w.Failed}} | {{$.FloatN $w.P50Ms 1}} | {{$.FloatN $w.P95Ms 1}} | {{$.FloatN $w.P99Ms 1}} |
+{{end}}{{end}}{{if .Consistency}}
+## Read-after-write
+
+| Tulis → baca | Baca | Basi % | Tidak pernah konsisten | Lag p50 ms | Lag p95 ms | Lag p99 ms |
+|---|---|---|---|---|---|---|
+{{range $name, $c := .Consistency}}| {{$c.From}} → {{$name}} | {{$.Int $c.Reads}} | {{$.Float $c.StalePct}} | {{$.Int $c.Diverged}} | {{$.FloatN $c.LagP50Ms 1}} | {{$.FloatN $c.LagP95Ms 1}} | {{$.FloatN $c.LagP99Ms 1}} |
+{{end}}{{end}}{{with .Security}}
+## Audit Security Header
+
+| Header | Status | Ada | Keterangan |
+|---|---|---|---|
+{{range .Headers}}| {{.Header}} | {{if eq .Status "pass"}}✅ PASS{{else}}⚠️ WARN{{end}} | {{$.FloatN .PresentPct 1}}% | {{.Note}} |
+{{end}}{{end}}{{with .Shadow}}
+## Shadow Traffic
+
+{{.URL}}: {{$.Int .Mirrored}} request disalin, {{$.Int .Dropped}} dilewati, status berbeda {{$.Int .Mismatches}} ({{$.Float .MismatchPct}}%)
+
+| | Requests | Error % | p50 ms | p95 ms | p99 ms |
+|---|---|---|---|---|---|
+| Utama | {{$.Int .Primary.Requests}} | {{$.Float .Primary.ErrorRate}} | {{$.FloatN .Primary.P50Ms 1}} | {{$.FloatN .Primary.P95Ms 1}} | {{$.FloatN .Primary.P99Ms 1}} |
+| Shadow | {{$.Int .Shadow.Requests}} | {{$.Float .Shadow.ErrorRate}} | {{$.FloatN .Shadow.P50Ms 1}} | {{$.FloatN .Shadow.P95Ms 1}} | {{$.FloatN .Shadow.P99Ms 1}} |
+{{with .Diff}}
+Body berbeda: {{$.Int .Different}} dari {{$.Int .Compared}} yang dibandingkan ({{$.Float .DifferentPct}}%)
+{{if .Paths}}
+| Path | Response | Contoh (utama → shadow) |
+|---|---|---|
+{{range .Paths}}| {{.Path}} | {{$.Int .Count}} | {{.Example}} |
+{{end}}{{end}}{{end}}{{end}}{{if .ReportFooter}}
+---
+
+{{.ReportFooter}}
+{{end}}`))
+
+// Markdown report dalam format Markdown, memakai template custom jika ada
+func (r *Report) Markdown() ([]byte, error) {
+    tmpl := markdownReportTemplate
+    if r.Brand != nil && r.Brand.markdown != nil {
+        tmpl = r.Brand.markdown
+    }
+    var sb strings.Builder
+    if err := tmpl.Execute(&sb, r); err != nil {
+        return nil, err
+    }
+    return []byte(sb.String()), nil
+}
@@ -0,0 +1,336 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "runtime"
+    "runtime/debug"
+    "strconv"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// byteSizeValue flag.Value untuk ukuran seperti 2GB, 512MiB atau 1500000
+type byteSizeValue uint64
+
+var byteSizeUnits = []struct {
+    suffix string
+    size   uint64
+}{
+    {"kib", 1 << 10}, {"mib", 1 << 20}, {"gib", 1 << 30}, {"tib", 1 << 40},
+    {"kb", 1e3}, {"mb", 1e6}, {"gb", 1e9}, {"tb", 1e12},
+    {"k", 1e3}, {"m", 1e6}, {"g", 1e9}, {"t", 1e12}, {"b", 1},
+}
+
+func (v *byteSizeValue) String() string {
+    if v == nil || *v == 0 {
+        return ""
+    }
+    return formatByteSize(uint64(*v))
+}
+
+func (v *byteSizeValue) Set(value string) error {
+    s := strings.ToLower(strings.TrimSpace(value))
+    multiplier := uint64(1)
+    for _, unit := range byteSizeUnits {
+        if strings.HasSuffix(s, unit.suffix) {
+            s, multiplier = strings.TrimSpace(strings.TrimSuffix(s, unit.suffix)), unit.size
+            break
+        }
+    }
+    n, err := strconv.ParseFloat(s, 64)
+    if err != nil || n < 0 {
+        return fmt.Errorf("ukuran %q tidak valid (contoh: 2GB, 512MiB)", value)
+    }
+    *v = byteSizeValue(n * float64(multiplier))
+    return nil
+}
+
+// formatByteSize ukuran dalam satuan desimal, contoh 1.5 GB
+func formatByteSize(n uint64) string {
+    switch {
+    case n >= 1e9:
+        return fmt.Sprintf("%.2f GB", float64(n)/1e9)
+    case n >= 1e6:
+        return fmt.Sprintf("%.1f MB", float64(n)/1e6)
+    case n >= 1e3:
+        return fmt.Sprintf("%.1f KB", float64(n)/1e3)
+    }
+    return fmt.Sprintf("%d B", n)
+}
+
+// countValue flag.Value untuk jumlah seperti 50k atau 1.5m
+type countValue int64
+
+func (v *countValue) String() string {
+    if v == nil || *v == 0 {
+        return ""
+    }
+    return strconv.FormatInt(int64(*v), 10)
+}
+
+func (v *countValue) Set(value string) error {
+    s := strings.ToLower(strings.TrimSpace(value))
+    multiplier := 1.0
+    switch {
+    case strings.HasSuffix(s, "k"):
+        s, multiplier = strings.TrimSuffix(s, "k"), 1e3
+    case strings.HasSuffix(s, "m"):
+        s, multiplier = strings.TrimSuffix(s, "m"), 1e6
+    }
+    n, err := strconv.ParseFloat(s, 64)
+    if err != nil || n < 0 {
+        return fmt.Errorf("jumlah %q tidak valid (contoh: 50k)", value)
+    }
+    *v = countValue(n * multiplier)
+    return nil
+}
+
+const (
+    budgetInterval     = time.Second     // Jarak pemeriksaan pemakaian resource
+    budgetCooldown     = 5 * time.Second // Jeda minimal antar penurunan concurrency
+    budgetMemoryWarn   = 0.8             // Porsi -max-memory yang memicu degradasi
+    budgetConnsWarn    = 0.9             // Porsi -max-open-conns yang memicu degradasi
+    budgetRawSampling  = 10              // Raw samples yang ditulis: 1 dari N
+    budgetConcurrencyC = 0.75            // Concurrency setelah diturunkan, relatif ke sebelumnya
+)
+
+// resourceBudget menjaga generator tetap di bawah -max-memory dan
+// -max-open-conns. Jika mendekati batas, generator menurunkan beban secara
+// bertahap (raw samples disampling, lalu concurrency diturunkan) alih-alih
+// kehabisan memori di tengah test. Setiap degradasi dicatat di report.
+// Memori diukur untuk seluruh proses, sedangkan koneksi dihitung per run.
+type resourceBudget struct {
+    maxMemory   uint64
+    maxConns    int64
+    concurrency int
+    raw         *sampleWriter
+    closeIdle   func()       // Opsional, dari IdleCloser
+    openConns   func() int64 // Opsional, dari ConnCounter
+    out         io.Writer
+    start       time.Time
+    prevLimit   int64 // Memory limit GC sebelum run, dikembalikan saat stop
+
+    limit     atomic.Int64 // Worker dengan id >= limit diparkir
+    released  chan struct{}
+    stopOnce  sync.Once
+    done      chan struct{}
+    peakMem   uint64
+    peakConns int64
+    lastCut   time.Time
+
+    mu     sync.Mutex
+    events []BudgetEvent
+}
+
+// newResourceBudget nil jika tidak ada batas yang diisi
+func newResourceBudget(config *Config, raw *sampleWriter) *resourceBudget {
+    if config.MaxMemory == 0 && config.MaxOpenConns == 0 {
+        return nil
+    }
+    b := &resourceBudget{
+        maxMemory:   config.MaxMemory,
+        maxConns:    config.MaxOpenConns,
+        concurrency: config.Concurrency,
+        raw:         raw,
+        out:         config.out,
+        released:    make(chan struct{}),
+        done:        make(chan struct{}),
+    }
+    b.limit.Store(int64(config.Concurrency))
+    if b.maxMemory > 0 {
+        // GC bekerja lebih keras sebelum batas tercapai. Limit berlaku untuk
+        // seluruh proses, jadi dikembalikan saat run selesai agar program
+        // yang memakai Run tidak terus membawa limit ini.
+        b.prevLimit = debug.SetMemoryLimit(int64(b.maxMemory))
+    }
+    return b
+}
+
+// run memeriksa pemakaian resource setiap budgetInterval sampai stop
+func (b *resourceBudget) run(start time.Time) {
+    if b == nil {
+        return
+    }
+    b.start = start
+    go func() {
+        ticker := time.NewTicker(budgetInterval)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-b.done:
+                return
+            case <-ticker.C:
+                b.check()
+            }
+        }
+    }()
+}
+
+// generatorMemory perkiraan memori yang dipegang proses dari runtime Go
+func generatorMemory() uint64 {
+    var m runtime.MemStats
+    runtime.ReadMemStats(&m)
+    return m.Sys - m.HeapReleased
+}
+
+func (b *resourceBudget) check() {
+    mem, conns := generatorMemory(), int64(0)
+    if b.openConns != nil {
+        conns = b.openConns()
+    }
+    b.peakMem, b.peakConns = max(b.peakMem, mem), max(b.peakConns, conns)
+
+    if b.maxMemory > 0 && float64(mem) >= float64(b.maxMemory)*budgetMemoryWarn {
+        reason := fmt.Sprintf("memori %s (%.0f%% dari %s)", formatByteSize(mem), float64(mem)/float64(b.maxMemory)*100, formatByteSize(b.maxMemory))
+        if b.raw != nil && b.raw.every.Load() == 1 {
+            b.raw.every.Store(budgetRawSampling)
+            b.record(reason, fmt.Sprintf("raw samples hanya ditulis 1 dari %d", budgetRawSampling))
+            return
+        }
+        b.cut(reason)
+        return
+    }
+    if b.maxConns > 0 && float64(conns) >= float64(b.maxConns)*budgetConnsWarn {
+        // Koneksi idle milik worker yang diparkir tetap terhitung terbuka
+        if b.cut(fmt.Sprintf("%d koneksi terbuka (%.0f%% dari %d)", conns, float64(conns)/float64(b.maxConns)*100, b.maxConns)) && b.closeIdle != nil {
+            b.closeIdle()
+        }
+    }
+}
+
+// cut menurunkan concurrency, paling sering sekali per budgetCooldown agar
+// efek penurunan sebelumnya sempat terlihat. False jika concurrency tidak
+// berubah.
+func (b *resourceBudget) cut(reason string) bool {
+    if time.Since(b.lastCut) < budgetCooldown {
+        return false
+    }
+    current := b.limit.Load()
+    next := max(1, int64(float64(current)*budgetConcurrencyC))
+    if next == current {
+        return false
+    }
+    b.lastCut = time.Now()
+    b.limit.Store(next)
+    b.record(reason, fmt.Sprintf("concurrency diturunkan %d → %d", current, next))
+    return true
+}
+
+func (b *resourceBudget) record(reason, action string) {
+    event := BudgetEvent{AtSec: time.Since(b.start).Seconds(), Reason: reason, Action: action}
+    fmt.Fprintf(b.out, "⚠️  Budget resource: %s, %s\n", reason, action)
+    b.mu.Lock()
+    b.events = append(b.events, event)
+    b.mu.Unlock()
+}
+
+// admit menahan worker id selama concurrency diturunkan di bawahnya.
+// Worker dilepas lagi setelah jadwal selesai (release) agar job yang
+// dipegangnya tetap dijalankan.
+func (b *resourceBudget) admit(id int) {
+    if b == nil || int64(id) < b.limit.Load() {
+        return
+    }
+    ticker := time.NewTicker(100 * time.Millisecond)
+    defer ticker.Stop()
+    for int64(id) >= b.limit.Load() {
+        select {
+        case <-b.released:
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// release melepas semua worker yang diparkir, dipanggil setelah jadwal
+// selesai mengirim job
+func (b *resourceBudget) release() {
+    if b != nil {
+        close(b.released)
+    }
+}
+
+// IdleCloser diimplementasikan Requester yang bisa menutup koneksi idle di
+// tengah test, dipakai -max-open-conns setelah concurrency diturunkan
+type IdleCloser interface {
+    CloseIdleConnections()
+}
+
+func (b *resourceBudget) stop() {
+    if b != nil {
+        b.stopOnce.Do(func() {
+            close(b.done)
+            if b.maxMemory > 0 {
+                debug.SetMemoryLimit(b.prevLimit)
+            }
+        })
+    }
+}
+
+// BudgetReport batas resource generator dan degradasi yang terjadi
+type BudgetReport struct {
+    MaxMemory        uint64        `json:"max_memory_bytes,omitempty"`
+    MaxOpenConns     int64         `json:"max_open_conns,omitempty"`
+    PeakMemory       uint64        `json:"peak_memory_bytes"`
+    PeakOpenConns    int64         `json:"peak_open_conns"`
+    Concurrency      int           `json:"concurrency"`
+    FinalConcurrency int           `json:"final_concurrency"`
+    RawSampleEvery   int64         `json:"raw_sample_every,omitempty"` // Raw samples ditulis 1 dari N
+    Events           []BudgetEvent `json:"events,omitempty"`
+}
+
+// BudgetEvent satu degradasi karena resource generator mendekati batas
+type BudgetEvent struct {
+    AtSec  float64 `json:"at_s"` // Detik sejak test dimulai
+    Reason string  `json:"reason"`
+    Action string  `json:"action"`
+}
+
+func (b *resourceBudget) report() *BudgetReport {
+    if b == nil {
+        return nil
+    }
+    b.stop()
+    r := &BudgetReport{
+        MaxMemory:        b.maxMemory,
+        MaxOpenConns:     b.maxConns,
+        PeakMemory:       max(b.peakMem, generatorMemory()),
+        PeakOpenConns:    b.peakConns,
+        Concurrency:      b.concurrency,
+        FinalConcurrency: int(b.limit.Load()),
+    }
+    if b.raw != nil && b.raw.every.Load() > 1 {
+        r.RawSampleEvery = b.raw.every.Load()
+    }
+    b.mu.Lock()
+    r.Events = append(r.Events, b.events...)
+    b.mu.Unlock()
+    return r
+}
+
+func printBudget(w io.Writer, report *Report) {
+    b := report.Budget
+    if b == nil {
+        return
+    }
+    fmt.Fprintln(w, "\n🧯 Budget Resource Generator:")
+    if b.MaxMemory > 0 {
+        fmt.Fprintf(w, "  Memori puncak:         %s dari %s\n", formatByteSize(b.PeakMemory), formatByteSize(b.MaxMemory))
+    }
+    if b.MaxOpenConns > 0 {
+        fmt.Fprintf(w, "  Koneksi puncak:        %d dari %d\n", b.PeakOpenConns, b.MaxOpenConns)
+    }
+    if len(b.Events) == 0 {
+        fmt.Fprintln(w, "  ✅ Tidak ada degradasi")
+        return
+    }
+    for _, e := range b.Events {
+        fmt.Fprintf(w, "  ⚠️  %6.0fs  %s: %s\n", e.AtSec, e.Reason, e.Action)
+    }
+    if b.FinalConcurrency < b.Concurrency {
+        fmt.Fprintf(w, "  Concurrency akhir %d dari %d; hasil setelah degradasi tidak setara dengan beban yang direncanakan\n", b.FinalConcurrency, b.Concurrency)
+    }
+}
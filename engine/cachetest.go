@@ -0,0 +1,385 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    mathrand "math/rand/v2"
+    "net/http"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+)
+
+// Jenis request pada mode -cache-test
+const (
+    cacheCold        = "cold"        // Tanpa validator, seperti klien baru
+    cacheConditional = "conditional" // If-None-Match / If-Modified-Since dari response sebelumnya
+    cacheRevalidate  = "revalidate"  // Cache-Control: max-age=0, memaksa cache memvalidasi ulang
+)
+
+var cacheKinds = []string{cacheCold, cacheConditional, cacheRevalidate}
+
+// cacheChecks aturan semantik caching yang diperiksa, urut sesuai tampilan
+var cacheChecks = []struct{ name, desc string }{
+    {"cache-control", "response 200 punya Cache-Control atau Expires"},
+    {"validator", "response 200 punya ETag atau Last-Modified"},
+    {"conditional-304", "request kondisional dengan validator yang masih berlaku dijawab 304"},
+    {"304-no-body", "response 304 tanpa body"},
+    {"304-etag", "response 304 menyertakan ETag jika response 200 memilikinya"},
+    {"vary-encoding", "response dengan Content-Encoding punya Vary: Accept-Encoding"},
+    {"no-store-age", "response no-store/private tidak disajikan dari shared cache (Age)"},
+    {"age-freshness", "Age tidak melebihi max-age + stale-while-revalidate"},
+    {"request-max-age", "request max-age=0 tidak dijawab dari cache tanpa revalidasi"},
+}
+
+// parseCacheMix membaca format 'cold=40,conditional=40,revalidate=20'
+func parseCacheMix(value string) (map[string]float64, error) {
+    mix := make(map[string]float64)
+    total := 0.0
+    for _, part := range strings.Split(value, ",") {
+        kind, raw, found := strings.Cut(strings.TrimSpace(part), "=")
+        weight, err := strconv.ParseFloat(raw, 64)
+        if !found || err != nil || weight < 0 {
+            return nil, fmt.Errorf("mix cache %q tidak valid (format: jenis=bobot)", part)
+        }
+        if kind != cacheCold && kind != cacheConditional && kind != cacheRevalidate {
+            return nil, fmt.Errorf("jenis request cache %q tidak dikenal (cold, conditional, revalidate)", kind)
+        }
+        mix[kind] = weight
+        total += weight
+    }
+    if total == 0 {
+        return nil, fmt.Errorf("mix cache kosong")
+    }
+    return mix, nil
+}
+
+// cacheValidators validator terakhir untuk satu URL
+type cacheValidators struct {
+    etag, lastModified string
+}
+
+type cacheKindStats struct {
+    requests, hits, stale, notModified int64
+}
+
+type cacheCheckStats struct {
+    checked, violations int64
+    example             string
+}
+
+// cacheTester mengatur jenis request dan memeriksa header caching setiap
+// response pada mode -cache-test
+type cacheTester struct {
+    mix map[string]float64
+
+    mu         sync.Mutex
+    validators map[string]cacheValidators // URL -> validator terakhir
+    kinds      map[string]*cacheKindStats
+    checks     map[string]*cacheCheckStats
+}
+
+func newCacheTester(mix map[string]float64) *cacheTester {
+    t := &cacheTester{
+        mix:        mix,
+        validators: make(map[string]cacheValidators),
+        kinds:      make(map[string]*cacheKindStats),
+        checks:     make(map[string]*cacheCheckStats),
+    }
+    for _, kind := range cacheKinds {
+        t.kinds[kind] = &cacheKindStats{}
+    }
+    for _, c := range cacheChecks {
+        t.checks[c.name] = &cacheCheckStats{}
+    }
+    return t
+}
+
+// prepare memilih jenis request dan menambahkan header-nya. Request
+// kondisional sebelum ada validator dikirim sebagai cold. Accept-Encoding
+// diselang-seling agar penanganan Vary ikut teruji.
+func (t *cacheTester) prepare(req *http.Request, rng *mathrand.Rand) string {
+    kind := t.pick(rng)
+    if rng.IntN(2) == 0 {
+        req.Header.Set("Accept-Encoding", "gzip")
+    } else {
+        req.Header.Set("Accept-Encoding", "identity")
+    }
+
+    t.mu.Lock()
+    v, ok := t.validators[req.URL.String()]
+    t.mu.Unlock()
+    switch {
+    case kind == cacheConditional && ok:
+        if v.etag != "" {
+            req.Header.Set("If-None-Match", v.etag)
+        } else {
+            req.Header.Set("If-Modified-Since", v.lastModified)
+        }
+    case kind == cacheConditional:
+        kind = cacheCold
+    case kind == cacheRevalidate:
+        req.Header.Set("Cache-Control", "max-age=0")
+    }
+    return kind
+}
+
+// cacheRand sumber acak pemilihan jenis request, terpisah dari sumber acak
+// template agar pilihan jenis tidak berkorelasi dengan nilai template
+func cacheRand(seed int64, requestNum int) *mathrand.Rand {
+    if seed == 0 {
+        return mathrand.New(mathrand.NewPCG(mathrand.Uint64(), mathrand.Uint64()))
+    }
+    return mathrand.New(mathrand.NewPCG(uint64(seed)^0x6361636865, uint64(requestNum)))
+}
+
+func (t *cacheTester) pick(rng *mathrand.Rand) string {
+    total := 0.0
+    for _, w := range t.mix {
+        total += w
+    }
+    target := rng.Float64() * total
+    for _, kind := range cacheKinds {
+        if target < t.mix[kind] {
+            return kind
+        }
+        target -= t.mix[kind]
+    }
+    return cacheCold
+}
+
+// observe memeriksa response terhadap aturan caching. bodyBytes adalah
+// jumlah byte body yang diterima.
+func (t *cacheTester) observe(kind string, req *http.Request, resp *http.Response, bodyBytes int64) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    key := req.URL.String()
+    prev := t.validators[key]
+    h := resp.Header
+    directives := parseCacheControl(h.Get("Cache-Control"))
+    age, hasAge := headerSeconds(h.Get("Age"))
+    status := cacheStatus(h)
+
+    k := t.kinds[kind]
+    k.requests++
+    hit := status == "hit" || (status == "" && hasAge && age > 0)
+    if hit {
+        k.hits++
+    }
+    if status == "stale" {
+        k.stale++
+    }
+    if resp.StatusCode == http.StatusNotModified {
+        k.notModified++
+    }
+
+    check := func(name string, ok bool, example string) {
+        c := t.checks[name]
+        c.checked++
+        if !ok {
+            c.violations++
+            if c.example == "" {
+                c.example = example
+            }
+        }
+    }
+
+    switch resp.StatusCode {
+    case http.StatusOK:
+        _, hasExpires := h["Expires"]
+        check("cache-control", h.Get("Cache-Control") != "" || hasExpires, "tanpa Cache-Control/Expires: "+key)
+        etag, lastModified := h.Get("ETag"), h.Get("Last-Modified")
+        check("validator", etag != "" || lastModified != "", "tanpa ETag/Last-Modified: "+key)
+        if kind == cacheConditional {
+            // Validator yang sama berarti representasi belum berubah
+            sent := req.Header.Get("If-None-Match")
+            same := (sent != "" && sent == etag) || (sent == "" && req.Header.Get("If-Modified-Since") == lastModified)
+            check("conditional-304", !same, "200 untuk validator yang masih berlaku: "+sent+req.Header.Get("If-Modified-Since"))
+        }
+        if etag != "" || lastModified != "" {
+            t.validators[key] = cacheValidators{etag: etag, lastModified: lastModified}
+        }
+    case http.StatusNotModified:
+        check("304-no-body", bodyBytes == 0, fmt.Sprintf("304 dengan body %d byte", bodyBytes))
+        if prev.etag != "" {
+            check("304-etag", h.Get("ETag") != "", "304 tanpa ETag: "+key)
+        }
+    }
+
+    if enc := h.Get("Content-Encoding"); enc != "" && enc != "identity" {
+        check("vary-encoding", headerHasToken(h.Values("Vary"), "Accept-Encoding") || headerHasToken(h.Values("Vary"), "*"),
+            "Content-Encoding "+enc+" tanpa Vary: Accept-Encoding")
+    }
+    if hasDirective(directives, "no-store") || hasDirective(directives, "private") {
+        check("no-store-age", !hasAge || age == 0, fmt.Sprintf("response %s disajikan dari cache (Age %d)", h.Get("Cache-Control"), age))
+    }
+    if hasAge {
+        if maxAge, ok := freshnessLifetime(directives); ok {
+            swr, _ := headerSeconds(directives["stale-while-revalidate"])
+            check("age-freshness", age <= maxAge+swr, fmt.Sprintf("Age %d melebihi max-age %d + stale-while-revalidate %d", age, maxAge, swr))
+        }
+    }
+    if kind == cacheRevalidate && resp.StatusCode < 400 {
+        check("request-max-age", !hit || status == "revalidated", fmt.Sprintf("request max-age=0 dijawab dari cache (Age %d, status %q)", age, status))
+    }
+}
+
+// cacheStatus membaca status cache CDN/proxy dari header umum (X-Cache,
+// CF-Cache-Status, X-Cache-Status, Cache-Status): hit, miss, stale,
+// revalidated, atau kosong jika tidak ada
+func cacheStatus(h http.Header) string {
+    for _, name := range []string{"Cf-Cache-Status", "X-Cache-Status", "X-Cache", "Cache-Status"} {
+        value := strings.ToUpper(h.Get(name))
+        if value == "" {
+            continue
+        }
+        switch {
+        case strings.Contains(value, "STALE"), strings.Contains(value, "UPDATING"):
+            return "stale"
+        case strings.Contains(value, "REVALIDATED"):
+            return "revalidated"
+        case strings.Contains(value, "HIT"):
+            return "hit"
+        default:
+            return "miss"
+        }
+    }
+    return ""
+}
+
+// parseCacheControl memecah directive Cache-Control menjadi map
+func parseCacheControl(value string) map[string]string {
+    directives := make(map[string]string)
+    for _, part := range strings.Split(value, ",") {
+        name, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+        if name != "" {
+            directives[strings.ToLower(name)] = strings.Trim(arg, `"`)
+        }
+    }
+    return directives
+}
+
+func hasDirective(directives map[string]string, name string) bool {
+    _, ok := directives[name]
+    return ok
+}
+
+// freshnessLifetime s-maxage (untuk shared cache) atau max-age dalam detik
+func freshnessLifetime(directives map[string]string) (int64, bool) {
+    for _, name := range []string{"s-maxage", "max-age"} {
+        if v, ok := headerSeconds(directives[name]); ok {
+            return v, true
+        }
+    }
+    return 0, false
+}
+
+func headerSeconds(value string) (int64, bool) {
+    v, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+    return v, err == nil && v >= 0
+}
+
+func headerHasToken(values []string, token string) bool {
+    for _, value := range values {
+        for _, part := range strings.Split(value, ",") {
+            if strings.EqualFold(strings.TrimSpace(part), token) {
+                return true
+            }
+        }
+    }
+    return false
+}
+
+// CacheKindReport hasil satu jenis request pada mode -cache-test
+type CacheKindReport struct {
+    Requests    int64   `json:"requests"`
+    Hits        int64   `json:"hits"`
+    Stale       int64   `json:"stale"`
+    NotModified int64   `json:"not_modified"`
+    HitRatio    float64 `json:"hit_ratio"` // Persen
+}
+
+// CacheCheck hasil satu aturan caching
+type CacheCheck struct {
+    Name        string `json:"name"`
+    Description string `json:"description"`
+    Checked     int64  `json:"checked"`
+    Violations  int64  `json:"violations"`
+    Example     string `json:"example,omitempty"`
+}
+
+// CacheReport hasil mode -cache-test
+type CacheReport struct {
+    Kinds       map[string]CacheKindReport `json:"kinds"`
+    Checks      []CacheCheck               `json:"checks"`
+    Conformance float64                    `json:"conformance_pct"` // Persen pemeriksaan yang lolos
+}
+
+func (t *cacheTester) report() *CacheReport {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+
+    r := &CacheReport{Kinds: make(map[string]CacheKindReport)}
+    for kind, k := range t.kinds {
+        if k.requests == 0 {
+            continue
+        }
+        r.Kinds[kind] = CacheKindReport{
+            Requests:    k.requests,
+            Hits:        k.hits,
+            Stale:       k.stale,
+            NotModified: k.notModified,
+            HitRatio:    float64(k.hits) / float64(k.requests) * 100,
+        }
+    }
+    var checked, violations int64
+    for _, c := range cacheChecks {
+        s := t.checks[c.name]
+        if s.checked == 0 {
+            continue
+        }
+        r.Checks = append(r.Checks, CacheCheck{Name: c.name, Description: c.desc, Checked: s.checked, Violations: s.violations, Example: s.example})
+        checked += s.checked
+        violations += s.violations
+    }
+    if checked > 0 {
+        r.Conformance = float64(checked-violations) / float64(checked) * 100
+    }
+    return r
+}
+
+// CacheReporter diimplementasikan Requester yang menjalankan -cache-test
+type CacheReporter interface {
+    CacheReport() *CacheReport
+}
+
+func printCache(w io.Writer, report *Report) {
+    c := report.Cache
+    if c == nil {
+        return
+    }
+    fmt.Fprintln(w, "\n🗄️  Semantik Caching:")
+    fmt.Fprintf(w, "  %-12s %9s %9s %9s %9s %8s\n", "Jenis", "Requests", "Hit", "Stale", "304", "Hit %")
+    kinds := make([]string, 0, len(c.Kinds))
+    for kind := range c.Kinds {
+        kinds = append(kinds, kind)
+    }
+    sort.Strings(kinds)
+    for _, kind := range kinds {
+        k := c.Kinds[kind]
+        fmt.Fprintf(w, "  %-12s %9d %9d %9d %9d %7.1f%%\n", kind, k.Requests, k.Hits, k.Stale, k.NotModified, k.HitRatio)
+    }
+    fmt.Fprintf(w, "\n  Kesesuaian: %.1f%%\n", c.Conformance)
+    for _, check := range c.Checks {
+        icon := "✅"
+        if check.Violations > 0 {
+            icon = "❌"
+        }
+        fmt.Fprintf(w, "  %s %-16s %d/%d  %s\n", icon, check.Name, check.Checked-check.Violations, check.Checked, check.Description)
+        if check.Example != "" {
+            fmt.Fprintf(w, "       contoh: %s\n", check.Example)
+        }
+    }
+}
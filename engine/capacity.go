@@ -0,0 +1,180 @@
+package engine
+
+import (
+    "encoding/csv"
+    "fmt"
+    "io"
+    "os"
+    "path/filepath"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// CapacityPoint satu titik kurva throughput vs latency, diambil dari satu stage
+type CapacityPoint struct {
+    Stage     string  `json:"stage"`
+    RPS       float64 `json:"requests_per_second"`
+    P50Ms     float64 `json:"p50_latency_ms"`
+    P95Ms     float64 `json:"p95_latency_ms"`
+    P99Ms     float64 `json:"p99_latency_ms"`
+    ErrorRate float64 `json:"error_rate"`
+}
+
+// capacityCurve mengubah hasil per stage menjadi titik kurva urut dari
+// throughput terendah. Stage tanpa request dilewati.
+func capacityCurve(stages []StageReport) []CapacityPoint {
+    var points []CapacityPoint
+    for _, s := range stages {
+        if s.Requests == 0 {
+            continue
+        }
+        points = append(points, CapacityPoint{
+            Stage:     s.Name,
+            RPS:       s.RPS,
+            P50Ms:     s.P50Ms,
+            P95Ms:     s.P95Ms,
+            P99Ms:     s.P99Ms,
+            ErrorRate: s.ErrorRate,
+        })
+    }
+    sort.SliceStable(points, func(i, j int) bool { return points[i].RPS < points[j].RPS })
+    return points
+}
+
+// kneeIndex mencari titik lutut kurva (awal "hockey stick") dengan metode
+// Kneedle: setelah throughput dan p95 dinormalisasi ke 0..1, lutut adalah
+// titik dengan jarak terbesar di bawah garis lurus dari titik pertama ke
+// titik terakhir. Mengembalikan -1 jika titik kurang dari 3 atau kurva tidak
+// melengkung naik.
+func kneeIndex(points []CapacityPoint) int {
+    if len(points) < 3 {
+        return -1
+    }
+    first, last := points[0], points[len(points)-1]
+    xRange := last.RPS - first.RPS
+    yRange := last.P95Ms - first.P95Ms
+    if xRange <= 0 || yRange <= 0 {
+        return -1
+    }
+
+    knee, best := -1, 0.0
+    for i, p := range points {
+        x := (p.RPS - first.RPS) / xRange
+        y := (p.P95Ms - first.P95Ms) / yRange
+        if d := x - y; d > best {
+            knee, best = i, d
+        }
+    }
+    return knee
+}
+
+// writeCapacity menulis kurva ke path, format dari ekstensi: .csv atau .svg
+func writeCapacity(points []CapacityPoint, knee int, path string) error {
+    var data []byte
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".csv":
+        var sb strings.Builder
+        w := csv.NewWriter(&sb)
+        w.Write([]string{"stage", "rps", "p50_ms", "p95_ms", "p99_ms", "error_rate", "knee"})
+        for i, p := range points {
+            w.Write([]string{
+                p.Stage,
+                strconv.FormatFloat(p.RPS, 'f', 2, 64),
+                strconv.FormatFloat(p.P50Ms, 'f', 2, 64),
+                strconv.FormatFloat(p.P95Ms, 'f', 2, 64),
+                strconv.FormatFloat(p.P99Ms, 'f', 2, 64),
+                strconv.FormatFloat(p.ErrorRate, 'f', 2, 64),
+                strconv.FormatBool(i == knee),
+            })
+        }
+        w.Flush()
+        if err := w.Error(); err != nil {
+            return err
+        }
+        data = []byte(sb.String())
+    case ".svg":
+        data = capacitySVG(points, knee)
+    default:
+        return fmt.Errorf("format kurva kapasitas %q tidak dikenal (gunakan .csv atau .svg)", filepath.Ext(path))
+    }
+    return os.WriteFile(path, data, 0644)
+}
+
+// capacitySVG menggambar kurva p50/p95/p99 terhadap throughput sebagai SVG
+// mandiri agar bisa dibuka langsung di browser tanpa spreadsheet
+func capacitySVG(points []CapacityPoint, knee int) []byte {
+    const (
+        width, height = 720.0, 420.0
+        left, right   = 70.0, 20.0
+        top, bottom   = 30.0, 50.0
+    )
+    maxX, maxY := 0.0, 0.0
+    for _, p := range points {
+        if p.RPS > maxX {
+            maxX = p.RPS
+        }
+        if p.P99Ms > maxY {
+            maxY = p.P99Ms
+        }
+    }
+    if maxX == 0 {
+        maxX = 1
+    }
+    if maxY == 0 {
+        maxY = 1
+    }
+    px := func(x float64) float64 { return left + x/maxX*(width-left-right) }
+    py := func(y float64) float64 { return height - bottom - y/maxY*(height-top-bottom) }
+
+    var sb strings.Builder
+    fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" font-family="sans-serif" font-size="12">`+"\n", width, height)
+    fmt.Fprintf(&sb, `<rect width="100%%" height="100%%" fill="white"/>`+"\n")
+    fmt.Fprintf(&sb, `<text x="%.0f" y="18" font-size="14">Kurva kapasitas: latency vs throughput</text>`+"\n", left)
+
+    // Sumbu dan label skala
+    fmt.Fprintf(&sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#333"/>`+"\n", left, height-bottom, width-right, height-bottom)
+    fmt.Fprintf(&sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#333"/>`+"\n", left, top, left, height-bottom)
+    for i := 0; i <= 4; i++ {
+        x, y := maxX*float64(i)/4, maxY*float64(i)/4
+        fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="middle">%.0f</text>`+"\n", px(x), height-bottom+16, x)
+        fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="end">%.0f</text>`+"\n", left-6, py(y)+4, y)
+    }
+    fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="middle">Throughput (req/s)</text>`+"\n", (left+width-right)/2, height-12)
+    fmt.Fprintf(&sb, `<text x="16" y="%.1f" transform="rotate(-90 16 %.1f)" text-anchor="middle">Latency (ms)</text>`+"\n", (top+height-bottom)/2, (top+height-bottom)/2)
+
+    series := []struct {
+        name   string
+        color  string
+        metric func(p CapacityPoint) float64
+    }{
+        {"p50", "#2a9d8f", func(p CapacityPoint) float64 { return p.P50Ms }},
+        {"p95", "#e9c46a", func(p CapacityPoint) float64 { return p.P95Ms }},
+        {"p99", "#e76f51", func(p CapacityPoint) float64 { return p.P99Ms }},
+    }
+    for i, s := range series {
+        coords := make([]string, len(points))
+        for j, p := range points {
+            coords[j] = fmt.Sprintf("%.1f,%.1f", px(p.RPS), py(s.metric(p)))
+        }
+        fmt.Fprintf(&sb, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`+"\n", s.color, strings.Join(coords, " "))
+        fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" fill="%s">%s</text>`+"\n", width-right-90+float64(i)*30, top+4, s.color, s.name)
+    }
+
+    if knee >= 0 {
+        p := points[knee]
+        fmt.Fprintf(&sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#999" stroke-dasharray="4 4"/>`+"\n", px(p.RPS), top, px(p.RPS), height-bottom)
+        fmt.Fprintf(&sb, `<circle cx="%.1f" cy="%.1f" r="5" fill="#e9c46a" stroke="#333"/>`+"\n", px(p.RPS), py(p.P95Ms))
+        fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f">knee: %.0f req/s, p95 %.0f ms</text>`+"\n", px(p.RPS)+8, py(p.P95Ms)-8, p.RPS, p.P95Ms)
+    }
+    sb.WriteString("</svg>\n")
+    return []byte(sb.String())
+}
+
+func printCapacity(w io.Writer, report *Report) {
+    if report.CapacityKnee == nil {
+        return
+    }
+    k := report.CapacityKnee
+    fmt.Fprintf(w, "\n📈 Titik lutut kapasitas: ~%.0f req/s (stage %s, p95 %.1f ms, p99 %.1f ms)\n", k.RPS, k.Stage, k.P95Ms, k.P99Ms)
+}
@@ -0,0 +1,260 @@
+package engine
+
+import (
+    "context"
+    "encoding/binary"
+    "encoding/json"
+    "fmt"
+    "net"
+    "net/http"
+    "strings"
+    "time"
+)
+
+// ntpEpochOffset selisih detik epoch NTP (1900) dan Unix (1970)
+const ntpEpochOffset = 2208988800
+
+// clockSamples jumlah query per pengukuran; sampel dengan RTT terkecil dipakai
+const clockSamples = 4
+
+// runClock acuan waktu satu run. Timestamp sampel dihitung dari jam
+// monotonic sejak epoch sehingga tidak terpengaruh jam sistem yang
+// melompat (NTP step, VM suspend) selama test, lalu dikoreksi offset jam
+// terhadap NTP (-ntp) atau server controller (-clock-sync) agar hasil
+// beberapa agent bisa disejajarkan satu sama lain dan dengan log server.
+type runClock struct {
+    epoch     time.Time // Membawa pembacaan monotonic
+    reference string    // Server NTP atau URL controller
+    measure   func() (*clockSample, error)
+    start     *clockSample // Offset sebelum test, nil jika tanpa referensi atau gagal
+    end       *clockSample // Offset setelah test
+}
+
+// clockSample offset jam referensi dikurangi jam lokal
+type clockSample struct {
+    offset, rtt time.Duration
+}
+
+func newRunClock(config *Config) *runClock {
+    c := &runClock{}
+    switch {
+    case config.NTPServer != "":
+        c.reference = config.NTPServer
+        c.measure = func() (*clockSample, error) { return measureNTP(config.NTPServer) }
+    case config.ClockSync != "":
+        c.reference = config.ClockSync
+        c.measure = func() (*clockSample, error) { return measureController(config.ClockSync) }
+    }
+    if c.measure != nil {
+        sample, err := c.measure()
+        if err != nil {
+            fmt.Fprintf(config.out, "⚠️  Sinkronisasi jam %s: %v, timestamp tidak dikoreksi\n", c.reference, err)
+        } else {
+            c.start = sample
+            fmt.Fprintf(config.out, "🕒 Offset jam terhadap %s: %+.1fms (RTT %.1fms)\n", c.reference, durationMs(sample.offset), durationMs(sample.rtt))
+        }
+    }
+    c.epoch = time.Now()
+    return c
+}
+
+// mono durasi monotonic sejak epoch
+func (c *runClock) mono(t time.Time) time.Duration {
+    return t.Sub(c.epoch)
+}
+
+// corrected waktu t menurut jam referensi, zero jika offset tidak
+// diketahui
+func (c *runClock) corrected(t time.Time) time.Time {
+    if c.start == nil {
+        return time.Time{}
+    }
+    return c.epoch.Round(0).Add(c.start.offset).Add(c.mono(t))
+}
+
+// adjust menggeser waktu dinding t dengan offset jam, atau t apa adanya
+// jika offset tidak diketahui. Dipakai untuk data yang hanya punya waktu
+// dinding seperti interval timeline.
+func (c *runClock) adjust(t time.Time) time.Time {
+    if c == nil || c.start == nil {
+        return t
+    }
+    return t.Add(c.start.offset)
+}
+
+// finish mengukur offset sekali lagi setelah test untuk melihat drift jam
+func (c *runClock) finish() {
+    if c.start == nil {
+        return
+    }
+    if sample, err := c.measure(); err == nil {
+        c.end = sample
+    }
+}
+
+// ClockInfo acuan waktu raw samples dan offset jam generator terhadap
+// NTP atau controller
+type ClockInfo struct {
+    MonoEpoch   time.Time `json:"mono_epoch"` // Waktu dinding saat mono_ns = 0
+    NTPServer   string    `json:"ntp_server,omitempty"`
+    SyncURL     string    `json:"sync_url,omitempty"`  // Controller -clock-sync
+    OffsetMs    *float64  `json:"offset_ms,omitempty"` // Jam referensi dikurangi jam lokal
+    RTTMs       *float64  `json:"rtt_ms,omitempty"`
+    OffsetEndMs *float64  `json:"offset_end_ms,omitempty"`
+    DriftMs     *float64  `json:"drift_ms,omitempty"` // Perubahan offset selama test
+}
+
+func (c *runClock) info() *ClockInfo {
+    info := &ClockInfo{MonoEpoch: c.epoch.UTC().Round(0)}
+    if c.reference != "" && strings.Contains(c.reference, "://") {
+        info.SyncURL = c.reference
+    } else {
+        info.NTPServer = c.reference
+    }
+    if c.start == nil {
+        return info
+    }
+    offset, rtt := durationMs(c.start.offset), durationMs(c.start.rtt)
+    info.OffsetMs, info.RTTMs = &offset, &rtt
+    if c.end != nil {
+        end, drift := durationMs(c.end.offset), durationMs(c.end.offset-c.start.offset)
+        info.OffsetEndMs, info.DriftMs = &end, &drift
+    }
+    return info
+}
+
+// measureNTP mengirim beberapa query SNTP v4 dan mengembalikan sampel
+// dengan RTT terkecil, karena offset paling akurat saat jalur paling cepat
+func measureNTP(server string) (*clockSample, error) {
+    if _, _, err := net.SplitHostPort(server); err != nil {
+        server = net.JoinHostPort(server, "123")
+    }
+    conn, err := net.DialTimeout("udp", server, 5*time.Second)
+    if err != nil {
+        return nil, err
+    }
+    defer conn.Close()
+
+    var best *clockSample
+    var lastErr error
+    for i := 0; i < clockSamples; i++ {
+        sample, err := queryNTP(conn)
+        if err != nil {
+            lastErr = err
+            continue
+        }
+        if best == nil || sample.rtt < best.rtt {
+            best = sample
+        }
+    }
+    if best == nil {
+        return nil, lastErr
+    }
+    return best, nil
+}
+
+func queryNTP(conn net.Conn) (*clockSample, error) {
+    packet := make([]byte, 48)
+    packet[0] = 0x23 // LI 0, versi 4, mode client
+    if err := conn.SetDeadline(time.Now().Add(2 * time.Second)); err != nil {
+        return nil, err
+    }
+
+    t1 := time.Now()
+    binary.BigEndian.PutUint64(packet[40:], toNTPTime(t1))
+    if _, err := conn.Write(packet); err != nil {
+        return nil, err
+    }
+    n, err := conn.Read(packet)
+    if err != nil {
+        return nil, err
+    }
+    t4 := t1.Add(time.Since(t1)) // Dari jam monotonic, bukan jam dinding yang bisa melompat
+    if n < 48 {
+        return nil, fmt.Errorf("response NTP terlalu pendek (%d byte)", n)
+    }
+    if mode := packet[0] & 0x7; mode != 4 {
+        return nil, fmt.Errorf("response NTP bukan mode server (%d)", mode)
+    }
+    if stratum := packet[1]; stratum == 0 || stratum > 15 {
+        return nil, fmt.Errorf("server NTP belum sinkron (stratum %d)", stratum)
+    }
+
+    t2 := fromNTPTime(binary.BigEndian.Uint64(packet[32:]))
+    t3 := fromNTPTime(binary.BigEndian.Uint64(packet[40:]))
+    return &clockSample{
+        offset: (t2.Sub(t1.Round(0)) + t3.Sub(t4.Round(0))) / 2,
+        rtt:    t4.Sub(t1) - t3.Sub(t2),
+    }, nil
+}
+
+func toNTPTime(t time.Time) uint64 {
+    seconds := uint64(t.Unix() + ntpEpochOffset)
+    fraction := uint64(t.Nanosecond()) << 32 / uint64(time.Second)
+    return seconds<<32 | fraction
+}
+
+func fromNTPTime(v uint64) time.Time {
+    seconds := int64(v>>32) - ntpEpochOffset
+    nanos := (v & 0xffffffff) * uint64(time.Second) >> 32
+    return time.Unix(seconds, int64(nanos))
+}
+
+// ServerTime response GET /time server controller
+type ServerTime struct {
+    UnixNano int64 `json:"unix_nano"`
+}
+
+// measureController mengukur offset jam terhadap server controller
+// (loadtest server) dengan handshake GET /time. Waktu server diasumsikan
+// diambil di tengah round trip, sehingga galat maksimal RTT/2; sampel
+// dengan RTT terkecil dipakai.
+func measureController(base string) (*clockSample, error) {
+    endpoint := strings.TrimSuffix(base, "/") + "/time"
+    client := &http.Client{Timeout: 5 * time.Second}
+
+    var best *clockSample
+    var lastErr error
+    // Handshake pertama hanya membuka koneksi agar RTT sampel berikutnya
+    // tidak termasuk TCP/TLS handshake
+    for i := 0; i <= clockSamples; i++ {
+        sample, err := queryController(client, endpoint)
+        if err != nil {
+            lastErr = err
+            continue
+        }
+        if i > 0 && (best == nil || sample.rtt < best.rtt) {
+            best = sample
+        }
+    }
+    if best == nil {
+        return nil, lastErr
+    }
+    return best, nil
+}
+
+func queryController(client *http.Client, endpoint string) (*clockSample, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+    if err != nil {
+        return nil, err
+    }
+    t1 := time.Now()
+    resp, err := client.Do(req)
+    if err != nil {
+        return nil, err
+    }
+    defer resp.Body.Close()
+    var st ServerTime
+    err = json.NewDecoder(resp.Body).Decode(&st)
+    rtt := time.Since(t1)
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("%s: HTTP %d", endpoint, resp.StatusCode)
+    }
+    if err != nil || st.UnixNano == 0 {
+        return nil, fmt.Errorf("%s: response bukan waktu server", endpoint)
+    }
+    local := t1.Round(0).Add(rtt / 2)
+    return &clockSample{offset: time.Unix(0, st.UnixNano).Sub(local), rtt: rtt}, nil
+}
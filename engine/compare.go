@@ -0,0 +1,319 @@
+package engine
+
+import (
+    "flag"
+    "fmt"
+    "math"
+    "os"
+    "path/filepath"
+    "strings"
+)
+
+// runCompare menjalankan subcommand 'compare' yang membandingkan dua report
+// JSON (baseline dan candidate)
+func runCompare(args []string) int {
+    fs := flag.NewFlagSet("compare", flag.ExitOnError)
+    strict := fs.Bool("strict", false, "Exit code 1 jika konfigurasi kedua run berbeda")
+    fs.Usage = func() {
+        fmt.Fprintf(os.Stderr, "Usage: loadtest compare [options] baseline candidate\n\n")
+        fmt.Fprintf(os.Stderr, "baseline dan candidate berupa file report JSON, glob ('base-*.json') atau\n")
+        fmt.Fprintf(os.Stderr, "daftar dipisah koma. Dengan >= 2 run per sisi ditampilkan confidence interval.\n\n")
+        fmt.Fprintf(os.Stderr, "Options:\n")
+        fs.PrintDefaults()
+    }
+    fs.Parse(args)
+
+    if fs.NArg() != 2 {
+        fs.Usage()
+        return 1
+    }
+
+    baselines, err := loadReportGroup(fs.Arg(0))
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    candidates, err := loadReportGroup(fs.Arg(1))
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+
+    // Semua run dibandingkan dengan baseline pertama sebagai acuan konfigurasi
+    var mismatches []string
+    for _, report := range append(baselines[1:], candidates...) {
+        for _, m := range compareMetadata(baselines[0], report) {
+            mismatches = append(mismatches, fmt.Sprintf("%s: %s", report.RunID, m))
+        }
+    }
+    if len(mismatches) > 0 {
+        fmt.Println("⚠️  Konfigurasi run berbeda, hasil perbandingan bisa menyesatkan:")
+        for _, m := range mismatches {
+            fmt.Printf("   - %s\n", m)
+        }
+        fmt.Println()
+    }
+
+    if len(baselines) == 1 && len(candidates) == 1 {
+        printComparison(baselines[0], candidates[0])
+        if len(mismatches) == 0 {
+            fmt.Println("ℹ️  Hanya 1 run per sisi; ulangi test beberapa kali untuk confidence interval")
+        }
+    } else {
+        printStatComparison(baselines, candidates)
+    }
+
+    if *strict && len(mismatches) > 0 {
+        return 1
+    }
+    return 0
+}
+
+// loadReportGroup membaca satu atau lebih report dari glob atau daftar file
+// dipisah koma
+func loadReportGroup(spec string) ([]*Report, error) {
+    var reports []*Report
+    for _, pattern := range strings.Split(spec, ",") {
+        pattern = strings.TrimSpace(pattern)
+        if pattern == "" {
+            continue
+        }
+        paths, err := filepath.Glob(pattern)
+        if err != nil {
+            return nil, err
+        }
+        if len(paths) == 0 {
+            return nil, fmt.Errorf("tidak ada report yang cocok dengan %q", pattern)
+        }
+        for _, path := range paths {
+            report, err := loadReport(path)
+            if err != nil {
+                return nil, err
+            }
+            reports = append(reports, report)
+        }
+    }
+    if len(reports) == 0 {
+        return nil, fmt.Errorf("tidak ada report di %q", spec)
+    }
+    return reports, nil
+}
+
+// compareMetadata mengembalikan daftar perbedaan konfigurasi yang membuat
+// dua run tidak setara untuk dibandingkan
+func compareMetadata(a, b *Report) []string {
+    var diffs []string
+    check := func(name string, va, vb interface{}) {
+        sa, sb := fmt.Sprint(va), fmt.Sprint(vb)
+        if sa == sb {
+            return
+        }
+        if sa == "" {
+            sa = "(kosong)"
+        }
+        if sb == "" {
+            sb = "(kosong)"
+        }
+        diffs = append(diffs, fmt.Sprintf("%s: %s vs %s", name, sa, sb))
+    }
+
+    check("target", a.Method+" "+a.URL, b.Method+" "+b.URL)
+    check("concurrency", a.Concurrency, b.Concurrency)
+    check("jadwal", a.Schedule, b.Schedule)
+    check("generator host", a.Metadata.GeneratorHost, b.Metadata.GeneratorHost)
+    check("CPU generator", a.Metadata.NumCPU, b.Metadata.NumCPU)
+    check("timeout", a.Metadata.TimeoutSec, b.Metadata.TimeoutSec)
+    check("keep-alive", a.Metadata.KeepAlive, b.Metadata.KeepAlive)
+    check("pool koneksi", a.Metadata.ConnPool, b.Metadata.ConnPool)
+    check("seed", a.Metadata.Seed, b.Metadata.Seed)
+    check("header", strings.Join(a.Metadata.HeaderNames, ","), strings.Join(b.Metadata.HeaderNames, ","))
+    if a.Metadata.BodySHA256 != b.Metadata.BodySHA256 {
+        diffs = append(diffs, "request body berbeda")
+    }
+    return diffs
+}
+
+func printComparison(baseline, candidate *Report) {
+    fmt.Println(strings.Repeat("=", 72))
+    fmt.Println("📊 PERBANDINGAN RUN")
+    fmt.Println(strings.Repeat("=", 72))
+    fmt.Printf("Baseline:  %s (%s)\n", baseline.RunID, baseline.StartTime.Format("2006-01-02 15:04:05"))
+    fmt.Printf("Candidate: %s (%s)\n\n", candidate.RunID, candidate.StartTime.Format("2006-01-02 15:04:05"))
+
+    fmt.Printf("%-22s %14s %14s %10s\n", "Metrik", "Baseline", "Candidate", "Selisih")
+    row := func(name string, a, b float64, higherIsBetter bool) {
+        diff := percentDiff(a, b)
+        mark := ""
+        switch {
+        case math.Abs(diff) < 5:
+        case (diff > 0) == higherIsBetter:
+            mark = " ✅"
+        default:
+            mark = " ❌"
+        }
+        fmt.Printf("%-22s %14.2f %14.2f %9.1f%%%s\n", name, a, b, diff, mark)
+    }
+    row("Requests per detik", baseline.RPS, candidate.RPS, true)
+    row("Avg latency (ms)", baseline.AvgLatencyMs, candidate.AvgLatencyMs, false)
+    row("Min latency (ms)", baseline.MinLatencyMs, candidate.MinLatencyMs, false)
+    row("Max latency (ms)", baseline.MaxLatencyMs, candidate.MaxLatencyMs, false)
+    row("p95 latency (ms)", baseline.P95LatencyMs, candidate.P95LatencyMs, false)
+    row("p99 latency (ms)", baseline.P99LatencyMs, candidate.P99LatencyMs, false)
+    row("Error rate (%)", baseline.ErrorRate, candidate.ErrorRate, false)
+    row("Success rate (%)", baseline.SuccessRate, candidate.SuccessRate, true)
+    fmt.Println(strings.Repeat("=", 72))
+}
+
+// percentDiff selisih b terhadap a dalam persen
+func percentDiff(a, b float64) float64 {
+    if a == 0 {
+        if b == 0 {
+            return 0
+        }
+        return 100
+    }
+    return (b - a) / a * 100
+}
+
+// printStatComparison membandingkan dua kelompok run berulang dengan Welch's
+// t-test: selisih rata-rata, 95% confidence interval dan effect size (Cohen's d).
+// Perubahan dianggap signifikan jika CI tidak melewati 0.
+func printStatComparison(baselines, candidates []*Report) {
+    fmt.Println(strings.Repeat("=", 96))
+    fmt.Println("📊 PERBANDINGAN RUN (STATISTIK)")
+    fmt.Println(strings.Repeat("=", 96))
+    fmt.Printf("Baseline: %d run, Candidate: %d run\n\n", len(baselines), len(candidates))
+
+    fmt.Printf("%-20s %18s %18s %22s %7s  %s\n", "Metrik", "Baseline (±sd)", "Candidate (±sd)", "Selisih [95% CI]", "d", "Kesimpulan")
+    row := func(name string, metric func(r *Report) float64, higherIsBetter bool) {
+        a := reportValues(baselines, metric)
+        b := reportValues(candidates, metric)
+        cmp := welchCompare(a, b)
+
+        verdict := "tidak signifikan"
+        if cmp.significant() {
+            if (cmp.diff > 0) == higherIsBetter {
+                verdict = "✅ lebih baik"
+            } else {
+                verdict = "❌ regresi"
+            }
+            verdict += " (" + effectLabel(cmp.effect) + ")"
+        }
+        fmt.Printf("%-20s %10.2f ±%6.2f %10.2f ±%6.2f %8.2f [%5.2f, %5.2f] %7.2f  %s\n",
+            name, cmp.meanA, cmp.sdA, cmp.meanB, cmp.sdB, cmp.diff, cmp.ciLow, cmp.ciHigh, cmp.effect, verdict)
+    }
+    row("Requests per detik", func(r *Report) float64 { return r.RPS }, true)
+    row("Avg latency (ms)", func(r *Report) float64 { return r.AvgLatencyMs }, false)
+    row("Max latency (ms)", func(r *Report) float64 { return r.MaxLatencyMs }, false)
+    row("p95 latency (ms)", func(r *Report) float64 { return r.P95LatencyMs }, false)
+    row("p99 latency (ms)", func(r *Report) float64 { return r.P99LatencyMs }, false)
+    row("Error rate (%)", func(r *Report) float64 { return r.ErrorRate }, false)
+    fmt.Println(strings.Repeat("=", 96))
+
+    if len(baselines) < 2 || len(candidates) < 2 {
+        fmt.Println("ℹ️  Butuh minimal 2 run per sisi agar variansi bisa dihitung")
+    }
+}
+
+func reportValues(reports []*Report, metric func(r *Report) float64) []float64 {
+    values := make([]float64, len(reports))
+    for i, r := range reports {
+        values[i] = metric(r)
+    }
+    return values
+}
+
+// meanStd menghitung rata-rata dan standar deviasi sampel
+func meanStd(values []float64) (mean, sd float64) {
+    if len(values) == 0 {
+        return 0, 0
+    }
+    for _, v := range values {
+        mean += v
+    }
+    mean /= float64(len(values))
+    if len(values) < 2 {
+        return mean, 0
+    }
+    for _, v := range values {
+        sd += (v - mean) * (v - mean)
+    }
+    return mean, math.Sqrt(sd / float64(len(values)-1))
+}
+
+type welchResult struct {
+    meanA, sdA, meanB, sdB float64
+    diff                   float64 // meanB - meanA
+    ciLow, ciHigh          float64
+    effect                 float64 // Cohen's d
+}
+
+func (w welchResult) significant() bool {
+    return w.ciLow > 0 || w.ciHigh < 0
+}
+
+func welchCompare(a, b []float64) welchResult {
+    var w welchResult
+    w.meanA, w.sdA = meanStd(a)
+    w.meanB, w.sdB = meanStd(b)
+    w.diff = w.meanB - w.meanA
+    na, nb := float64(len(a)), float64(len(b))
+
+    if len(a) < 2 || len(b) < 2 {
+        // Variansi tidak diketahui, CI tidak bisa dihitung
+        w.ciLow, w.ciHigh = math.Inf(-1), math.Inf(1)
+        return w
+    }
+
+    va, vb := w.sdA*w.sdA/na, w.sdB*w.sdB/nb
+    se := math.Sqrt(va + vb)
+    if se == 0 {
+        w.ciLow, w.ciHigh = w.diff, w.diff
+    } else {
+        // Derajat kebebasan Welch–Satterthwaite
+        df := (va + vb) * (va + vb) / (va*va/(na-1) + vb*vb/(nb-1))
+        margin := tCritical95(df) * se
+        w.ciLow, w.ciHigh = w.diff-margin, w.diff+margin
+    }
+
+    pooled := math.Sqrt(((na-1)*w.sdA*w.sdA + (nb-1)*w.sdB*w.sdB) / (na + nb - 2))
+    if pooled > 0 {
+        w.effect = w.diff / pooled
+    }
+    return w
+}
+
+// tTable nilai kritis t dua sisi 95% untuk df 1..30
+var tTable = []float64{
+    12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+    2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+    2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// tCritical95 nilai kritis t untuk CI 95% dengan df pecahan (interpolasi linear)
+func tCritical95(df float64) float64 {
+    if df < 1 {
+        df = 1
+    }
+    if df >= float64(len(tTable)) {
+        // Mendekati distribusi normal untuk df besar
+        return 1.96 + (tTable[len(tTable)-1]-1.96)*float64(len(tTable))/df
+    }
+    lo := int(df)
+    frac := df - float64(lo)
+    return tTable[lo-1] + (tTable[lo]-tTable[lo-1])*frac
+}
+
+// effectLabel kategori effect size Cohen's d
+func effectLabel(d float64) string {
+    switch d = math.Abs(d); {
+    case d < 0.2:
+        return "efek sangat kecil"
+    case d < 0.5:
+        return "efek kecil"
+    case d < 0.8:
+        return "efek sedang"
+    default:
+        return "efek besar"
+    }
+}
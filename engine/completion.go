@@ -0,0 +1,306 @@
+package engine
+
+import (
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "sort"
+    "strings"
+)
+
+// subcommands perintah selain mode load test biasa
+var subcommands = []string{"compare", "server", "init", "selftest", "completion", "merge", "version"}
+
+// flagValues nilai yang bisa dilengkapi untuk flag tertentu: daftar nilai
+// tetap, atau ekstensi file (diawali titik)
+var flagValues = map[string][]string{
+    "m":               {"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
+    "locale":          {"en", "id", "de", "nl", "es", "it", "pt", "fr", "raw"},
+    "conn-pool":       {connPoolVU, connPoolShared},
+    "param-file-mode": {"random", "sequential", "shard", "unique"},
+    "balance":         {balanceEven, balanceWeight, balanceCapacity},
+    "dns":             {dnsSystem, dnsOnce, dnsTTL, dnsRequest},
+    "out":             {".json", ".yaml", ".yml", ".html", ".htm", ".md", ".bin"},
+    "raw":             {".csv"},
+    "capacity":        {".csv", ".svg"},
+    "scenario":        {".json"},
+    "report-template": {".html", ".md"},
+    "replay":          {".txt", ".har", ".log"},
+    "pcap":            {".pcap"},
+    "proto-set":       {".pb", ".protoset", ".bin"},
+}
+
+// completionFlag satu flag untuk script completion
+type completionFlag struct {
+    name, usage string
+    takesValue  bool
+}
+
+// completionFlags daftar flag loadtest, diambil dari parseFlags agar selalu
+// sama dengan flag yang benar-benar ada
+func completionFlags() []completionFlag {
+    fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+    fs.SetOutput(io.Discard)
+    parseFlags(fs, nil)
+
+    var flags []completionFlag
+    fs.VisitAll(func(f *flag.Flag) {
+        usage, _, _ := strings.Cut(f.Usage, ";")
+        usage, _, _ = strings.Cut(usage, " (")
+        isBool := false
+        if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok {
+            isBool = b.IsBoolFlag()
+        }
+        flags = append(flags, completionFlag{name: f.Name, usage: usage, takesValue: !isBool})
+    })
+    return flags
+}
+
+// runCompletion mencetak script completion untuk bash, zsh atau fish
+func runCompletion(args []string) int {
+    if len(args) != 1 {
+        fmt.Fprintf(os.Stderr, "Usage: loadtest completion bash|zsh|fish\n\n")
+        fmt.Fprintf(os.Stderr, "Contoh:\n")
+        fmt.Fprintf(os.Stderr, "  source <(loadtest completion bash)\n")
+        fmt.Fprintf(os.Stderr, "  loadtest completion zsh > \"${fpath[1]}/_loadtest\"\n")
+        fmt.Fprintf(os.Stderr, "  loadtest completion fish > ~/.config/fish/completions/loadtest.fish\n")
+        return 1
+    }
+    flags := completionFlags()
+    switch args[0] {
+    case "bash":
+        writeBashCompletion(os.Stdout, flags)
+    case "zsh":
+        writeZshCompletion(os.Stdout, flags)
+    case "fish":
+        writeFishCompletion(os.Stdout, flags)
+    default:
+        fmt.Fprintf(os.Stderr, "Error: shell %q tidak didukung (bash, zsh, fish)\n", args[0])
+        return 1
+    }
+    return 0
+}
+
+// splitValues memisahkan nilai tetap dan ekstensi file
+func splitValues(values []string) (words, exts []string) {
+    for _, v := range values {
+        if strings.HasPrefix(v, ".") {
+            exts = append(exts, v[1:])
+        } else {
+            words = append(words, v)
+        }
+    }
+    return words, exts
+}
+
+func sortedValueFlags() []string {
+    names := make([]string, 0, len(flagValues))
+    for name := range flagValues {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+func writeBashCompletion(w io.Writer, flags []completionFlag) {
+    var names, valueFlags []string
+    for _, f := range flags {
+        names = append(names, "-"+f.name)
+        if f.takesValue {
+            valueFlags = append(valueFlags, "-"+f.name)
+        }
+    }
+
+    fmt.Fprintf(w, "# bash completion untuk loadtest\n")
+    fmt.Fprintf(w, "_loadtest() {\n")
+    fmt.Fprintf(w, "    local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+    fmt.Fprintf(w, "    if [[ $COMP_CWORD -eq 1 && $cur != -* ]]; then\n")
+    fmt.Fprintf(w, "        COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(subcommands, " "))
+    fmt.Fprintf(w, "        return\n")
+    fmt.Fprintf(w, "    fi\n")
+    fmt.Fprintf(w, "    case \"${COMP_WORDS[1]}\" in\n")
+    fmt.Fprintf(w, "        completion) COMPREPLY=($(compgen -W \"bash zsh fish\" -- \"$cur\")); return ;;\n")
+    fmt.Fprintf(w, "        compare) COMPREPLY=($(compgen -f -X '!*.json' -- \"$cur\")); compopt -o filenames; return ;;\n")
+    fmt.Fprintf(w, "    esac\n")
+    fmt.Fprintf(w, "    case \"$prev\" in\n")
+    for _, name := range sortedValueFlags() {
+        words, exts := splitValues(flagValues[name])
+        if len(words) > 0 {
+            fmt.Fprintf(w, "        -%s) COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return ;;\n", name, strings.Join(words, " "))
+        } else {
+            fmt.Fprintf(w, "        -%s) COMPREPLY=($(compgen -f -X '!*.@(%s)' -- \"$cur\") $(compgen -d -- \"$cur\")); compopt -o filenames; return ;;\n", name, strings.Join(exts, "|"))
+        }
+    }
+    fmt.Fprintf(w, "        %s) COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n", strings.Join(valueFlags, "|"))
+    fmt.Fprintf(w, "    esac\n")
+    fmt.Fprintf(w, "    if [[ $cur == -* ]]; then\n")
+    fmt.Fprintf(w, "        COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(names, " "))
+    fmt.Fprintf(w, "    fi\n")
+    fmt.Fprintf(w, "}\n")
+    fmt.Fprintf(w, "shopt -s extglob\n")
+    fmt.Fprintf(w, "complete -F _loadtest loadtest\n")
+}
+
+// zshEscape mengamankan deskripsi di dalam spesifikasi _arguments
+func zshEscape(s string) string {
+    return strings.NewReplacer("'", "'\\''", "[", "\\[", "]", "\\]", ":", "\\:").Replace(s)
+}
+
+func writeZshCompletion(w io.Writer, flags []completionFlag) {
+    fmt.Fprintf(w, "#compdef loadtest\n\n")
+    fmt.Fprintf(w, "_loadtest() {\n")
+    fmt.Fprintf(w, "    if (( CURRENT == 2 )) && [[ $words[2] != -* ]]; then\n")
+    fmt.Fprintf(w, "        _values 'perintah' %s\n", strings.Join(subcommands, " "))
+    fmt.Fprintf(w, "        return\n")
+    fmt.Fprintf(w, "    fi\n")
+    fmt.Fprintf(w, "    case $words[2] in\n")
+    fmt.Fprintf(w, "        completion) _values 'shell' bash zsh fish; return ;;\n")
+    fmt.Fprintf(w, "        compare) _files -g '*.json'; return ;;\n")
+    fmt.Fprintf(w, "    esac\n")
+    fmt.Fprintf(w, "    _arguments -S \\\n")
+    for _, f := range flags {
+        spec := fmt.Sprintf("-%s[%s]", f.name, zshEscape(f.usage))
+        if f.takesValue {
+            words, exts := splitValues(flagValues[f.name])
+            switch {
+            case len(words) > 0:
+                spec += fmt.Sprintf(":%s:(%s)", f.name, strings.Join(words, " "))
+            case len(exts) > 0:
+                spec += fmt.Sprintf(":%s:_files -g \"*.(%s)\"", f.name, strings.Join(exts, "|"))
+            default:
+                spec += fmt.Sprintf(":%s:_files", f.name)
+            }
+        }
+        fmt.Fprintf(w, "        '%s' \\\n", spec)
+    }
+    fmt.Fprintf(w, "        '*:url:_urls'\n")
+    fmt.Fprintf(w, "}\n\n")
+    fmt.Fprintf(w, "_loadtest \"$@\"\n")
+}
+
+func writeFishCompletion(w io.Writer, flags []completionFlag) {
+    quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "\\'") + "'" }
+
+    fmt.Fprintf(w, "# fish completion untuk loadtest\n")
+    fmt.Fprintf(w, "complete -c loadtest -f -n __fish_use_subcommand -a %s\n", quote(strings.Join(subcommands, " ")))
+    fmt.Fprintf(w, "complete -c loadtest -f -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'\n")
+    fmt.Fprintf(w, "complete -c loadtest -n '__fish_seen_subcommand_from compare' -k -a '(__fish_complete_suffix .json)'\n")
+    for _, f := range flags {
+        line := fmt.Sprintf("complete -c loadtest -o %s -d %s", f.name, quote(f.usage))
+        if f.takesValue {
+            words, exts := splitValues(flagValues[f.name])
+            switch {
+            case len(words) > 0:
+                line += " -x -a " + quote(strings.Join(words, " "))
+            case len(exts) > 0:
+                suffixes := make([]string, len(exts))
+                for i, ext := range exts {
+                    suffixes[i] = "(__fish_complete_suffix ." + ext + ")"
+                }
+                line += " -r -k -a " + quote(strings.Join(suffixes, " "))
+            default:
+                line += " -r"
+            }
+        }
+        fmt.Fprintln(w, line)
+    }
+}
+
+// checkFlagNames memeriksa flag yang tidak dikenal sebelum fs.Parse agar
+// salah ketik mendapat saran flag terdekat, bukan hanya error parse
+func checkFlagNames(fs *flag.FlagSet, args []string) error {
+    for i := 0; i < len(args); i++ {
+        arg := args[i]
+        if arg == "--" || len(arg) < 2 || arg[0] != '-' {
+            return nil // Setelah argumen posisi flag package berhenti mem-parse
+        }
+        name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+        if name == "h" || name == "help" {
+            return nil
+        }
+        f := fs.Lookup(name)
+        if f == nil {
+            if suggestion := closestFlag(fs, name); suggestion != "" {
+                return fmt.Errorf("flag -%s tidak dikenal, mungkin maksudnya -%s?", name, suggestion)
+            }
+            return fmt.Errorf("flag -%s tidak dikenal", name)
+        }
+        if b, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && b.IsBoolFlag() {
+            continue
+        }
+        if !hasValue {
+            i++ // Nilai flag
+        }
+    }
+    return nil
+}
+
+// flagAliases nama panjang yang biasa dipakai tool lain untuk flag pendek
+var flagAliases = map[string]string{
+    "concurrency": "c",
+    "requests":    "n",
+    "number":      "n",
+    "timeout":     "t",
+    "method":      "m",
+    "data":        "d",
+    "body":        "d",
+    "header":      "H",
+    "headers":     "H",
+    "url":         "u",
+}
+
+// closestFlag nama flag dengan jarak edit terkecil, "" jika terlalu jauh.
+// Jarak yang sama dimenangkan flag dengan awalan sama terpanjang.
+func closestFlag(fs *flag.FlagSet, name string) string {
+    best, bestDist, bestPrefix := "", len(name)/2+2, 0
+    consider := func(candidate, target string) {
+        d := editDistance(name, candidate)
+        if strings.HasPrefix(candidate, name) && len(name) >= 3 {
+            d = min(d, 1)
+        }
+        prefix := commonPrefix(name, candidate)
+        if d < bestDist || (d == bestDist && best != "" && prefix > bestPrefix) {
+            best, bestDist, bestPrefix = target, d, prefix
+        }
+    }
+    aliases := make([]string, 0, len(flagAliases))
+    for alias := range flagAliases {
+        aliases = append(aliases, alias)
+    }
+    sort.Strings(aliases)
+    for _, alias := range aliases {
+        consider(alias, flagAliases[alias])
+    }
+    fs.VisitAll(func(f *flag.Flag) { consider(f.Name, f.Name) })
+    return best
+}
+
+func commonPrefix(a, b string) int {
+    n := 0
+    for n < len(a) && n < len(b) && a[n] == b[n] {
+        n++
+    }
+    return n
+}
+
+// editDistance jarak Levenshtein
+func editDistance(a, b string) int {
+    prev := make([]int, len(b)+1)
+    cur := make([]int, len(b)+1)
+    for j := range prev {
+        prev[j] = j
+    }
+    for i := 1; i <= len(a); i++ {
+        cur[0] = i
+        for j := 1; j <= len(b); j++ {
+            cost := 1
+            if a[i-1] == b[j-1] {
+                cost = 0
+            }
+            cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+        }
+        prev, cur = cur, prev
+    }
+    return prev[len(b)]
+}
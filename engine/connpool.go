@@ -0,0 +1,72 @@
+package engine
+
+import (
+    "fmt"
+    "net/http"
+    "sync"
+)
+
+// Kebijakan berbagi koneksi antar VU (-conn-pool)
+const (
+    connPoolVU     = "vu"     // Setiap VU punya transport sendiri, seperti satu pengguna = satu set koneksi
+    connPoolShared = "shared" // Semua VU berbagi satu transport, reuse lebih tinggi dari klien nyata
+)
+
+func validateConnPool(policy string) error {
+    switch policy {
+    case connPoolVU, connPoolShared:
+        return nil
+    default:
+        return fmt.Errorf("-conn-pool %q tidak dikenal (vu atau shared)", policy)
+    }
+}
+
+// transportPool memilih transport per VU berdasarkan worker id di context
+// request. Transport VU di-clone dari base, sehingga dial hook (conntrack,
+// preconnect) tetap sama; hanya pool koneksi idle yang terpisah.
+type transportPool struct {
+    base  idleTransport
+    clone func() idleTransport
+    perVU bool
+    vus   sync.Map // worker id -> idleTransport
+}
+
+// idleTransport transport HTTP/1.1 (*http.Transport) atau HTTP/2
+// (*http2.Transport)
+type idleTransport interface {
+    http.RoundTripper
+    CloseIdleConnections()
+}
+
+func newTransportPool(base *http.Transport, policy string) *transportPool {
+    return &transportPool{
+        base:  base,
+        clone: func() idleTransport { return base.Clone() },
+        perVU: policy != connPoolShared,
+    }
+}
+
+// get transport untuk VU id; id negatif (setup global) memakai base
+func (p *transportPool) get(id int) idleTransport {
+    if !p.perVU || id < 0 {
+        return p.base
+    }
+    if t, ok := p.vus.Load(id); ok {
+        return t.(idleTransport)
+    }
+    t, _ := p.vus.LoadOrStore(id, p.clone())
+    return t.(idleTransport)
+}
+
+func (p *transportPool) RoundTrip(req *http.Request) (*http.Response, error) {
+    return p.get(workerID(req.Context())).RoundTrip(req)
+}
+
+// CloseIdleConnections dipanggil http.Client.CloseIdleConnections
+func (p *transportPool) CloseIdleConnections() {
+    p.base.CloseIdleConnections()
+    p.vus.Range(func(_, value any) bool {
+        value.(idleTransport).CloseIdleConnections()
+        return true
+    })
+}
@@ -0,0 +1,260 @@
+package engine
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "syscall"
+    "time"
+)
+
+// dialFunc signature DialContext milik net.Dialer / http.Transport
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// connTracker membungkus setiap koneksi untuk mendeteksi siapa yang menutup
+// koneksi: server (FIN/RST) atau client
+type connTracker struct {
+    dialer  *net.Dialer
+    dials   dialStats
+    sources sourceStats
+    capture *packetCapture // Opsional, merekam sampel koneksi ke PCAP
+    dns     *dnsResolver   // Opsional, -dns selain system
+    h2      *h2Stats       // Opsional, frame koneksi -http2
+    out     io.Writer      // Output terminal untuk pesan -drain
+
+    draining atomic.Pointer[drainWatch] // Diisi selama -drain
+
+    live          atomic.Int64 // Koneksi yang belum di-Close, dipakai -max-open-conns
+    opened        atomic.Int64
+    reused        atomic.Int64
+    serverFIN     atomic.Int64
+    serverRST     atomic.Int64
+    clientClosed  atomic.Int64
+    reuseFailures atomic.Int64
+    resetErrors   atomic.Int64
+    eofErrors     atomic.Int64
+}
+
+func newConnTracker(out io.Writer) *connTracker {
+    return &connTracker{out: out, dialer: &net.Dialer{
+        Timeout:        30 * time.Second,
+        KeepAlive:      30 * time.Second,
+        ControlContext: controlDial,
+    }}
+}
+
+func (t *connTracker) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+    if t.capture != nil {
+        t.capture.expect(addr)
+    }
+    trace := &dialTrace{}
+    ctx = context.WithValue(ctx, dialTraceKey{}, trace)
+    var conn net.Conn
+    var err error
+    if t.dns != nil {
+        conn, err = t.dns.dial(ctx, t.dialer, network, addr)
+    } else {
+        conn, err = t.dialer.DialContext(ctx, network, addr)
+    }
+    t.dials.record(trace, conn, err)
+    if err != nil {
+        return nil, err
+    }
+    if t.capture != nil {
+        t.capture.decide(conn)
+    }
+    t.opened.Add(1)
+    t.live.Add(1)
+    t.sources.dialed(conn.LocalAddr())
+    return &trackedConn{Conn: conn, tracker: t}, nil
+}
+
+// recordRequest mencatat pemakaian koneksi oleh satu request dan
+// mengklasifikasikan error yang disebabkan koneksi diputus server
+func (t *connTracker) recordRequest(reused bool, err error) {
+    if reused {
+        t.reused.Add(1)
+    }
+    if err == nil {
+        return
+    }
+    switch classifyConnError(err) {
+    case "rst":
+        t.resetErrors.Add(1)
+    case "eof":
+        t.eofErrors.Add(1)
+    default:
+        return
+    }
+    if reused {
+        t.reuseFailures.Add(1)
+    }
+}
+
+// recordSource mencatat hasil request per IP sumber dan IP target. local dan
+// remote alamat koneksi dari httptrace GotConn, nil jika request tidak
+// mendapat koneksi.
+func (t *connTracker) recordSource(local, remote net.Addr, result Result) {
+    t.sources.record(local, result)
+    t.dns.observe(remote, result)
+}
+
+// classifyConnError mengenali error akibat koneksi diputus server. Error dari
+// net/http sering hanya berupa string, jadi dicek juga teksnya.
+func classifyConnError(err error) string {
+    msg := err.Error()
+    switch {
+    case errors.Is(err, syscall.ECONNRESET), strings.Contains(msg, "connection reset by peer"):
+        return "rst"
+    case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+        strings.Contains(msg, "server closed idle connection"), strings.Contains(msg, "EOF"),
+        errors.Is(err, syscall.EPIPE), strings.Contains(msg, "broken pipe"):
+        return "eof"
+    default:
+        return ""
+    }
+}
+
+// ConnReport statistik koneksi selama run
+type ConnReport struct {
+    Opened          int64          `json:"opened"`
+    Reused          int64          `json:"reused"`
+    ServerFIN       int64          `json:"server_fin"`
+    ServerRST       int64          `json:"server_rst"`
+    ClientClosed    int64          `json:"client_closed"`
+    ReuseFailures   int64          `json:"reuse_failures"`
+    ResetErrors     int64          `json:"reset_errors"`
+    EOFErrors       int64          `json:"eof_errors"`
+    ServerCloseRate float64        `json:"server_close_rate"` // Persen koneksi yang ditutup server
+    Dials           *DialReport    `json:"dials,omitempty"`
+    HTTP2           *HTTP2Report   `json:"http2,omitempty"`
+    DNS             *DNSReport     `json:"dns,omitempty"`
+    Sources         []SourceReport `json:"sources,omitempty"`
+}
+
+func (t *connTracker) report() *ConnReport {
+    r := &ConnReport{
+        Opened:        t.opened.Load(),
+        Reused:        t.reused.Load(),
+        ServerFIN:     t.serverFIN.Load(),
+        ServerRST:     t.serverRST.Load(),
+        ClientClosed:  t.clientClosed.Load(),
+        ReuseFailures: t.reuseFailures.Load(),
+        ResetErrors:   t.resetErrors.Load(),
+        EOFErrors:     t.eofErrors.Load(),
+        Dials:         t.dials.report(),
+        Sources:       t.sources.report(),
+        DNS:           t.dns.report(),
+        HTTP2:         t.h2.report(),
+    }
+    if r.Opened > 0 {
+        r.ServerCloseRate = float64(r.ServerFIN+r.ServerRST) / float64(r.Opened) * 100
+    }
+    return r
+}
+
+// open jumlah koneksi tracker yang belum ditutup
+func (t *connTracker) open() int64 {
+    return t.opened.Load() - t.serverFIN.Load() - t.serverRST.Load() - t.clientClosed.Load()
+}
+
+// trackedConn mencatat penutupan koneksi satu kali: error saat Read berarti
+// server menutup (EOF = FIN, ECONNRESET = RST), Close tanpa error berarti client
+type trackedConn struct {
+    net.Conn
+    tracker    *connTracker
+    once       sync.Once
+    closed     atomic.Bool
+    h2         bool                   // Koneksi -http2, diisi sebelum koneksi dipakai
+    lastGoAway atomic.Pointer[string] // Error code GOAWAY terakhir dari server
+}
+
+func (c *trackedConn) Read(b []byte) (int, error) {
+    n, err := c.Conn.Read(b)
+    if err != nil {
+        c.classify(err)
+    }
+    return n, err
+}
+
+func (c *trackedConn) classify(err error) {
+    var netErr net.Error
+    switch {
+    case errors.Is(err, io.EOF):
+        c.closedBy(closedFIN)
+    case errors.Is(err, syscall.ECONNRESET):
+        c.closedBy(closedRST)
+    case errors.As(err, &netErr) && netErr.Timeout():
+        // Deadline dari sisi client, bukan penutupan koneksi
+    }
+}
+
+// Siapa yang menutup koneksi
+const (
+    closedFIN    = "fin"
+    closedRST    = "rst"
+    closedClient = "client"
+)
+
+// closedBy mencatat penutupan pertama koneksi
+func (c *trackedConn) closedBy(kind string) {
+    c.once.Do(func() {
+        switch kind {
+        case closedFIN:
+            c.tracker.serverFIN.Add(1)
+        case closedRST:
+            c.tracker.serverRST.Add(1)
+        default:
+            c.tracker.clientClosed.Add(1)
+        }
+        if w := c.tracker.draining.Load(); w != nil {
+            w.closed(c, kind)
+        }
+    })
+}
+
+// goAway mencatat GOAWAY dari server pada koneksi -http2
+func (c *trackedConn) goAway(code string) {
+    c.lastGoAway.Store(&code)
+    if w := c.tracker.draining.Load(); w != nil {
+        w.goAway(code)
+    }
+}
+
+func (c *trackedConn) Close() error {
+    c.closedBy(closedClient)
+    if c.closed.CompareAndSwap(false, true) {
+        c.tracker.live.Add(-1)
+    }
+    return c.Conn.Close()
+}
+
+func printConnections(w io.Writer, report *Report) {
+    c := report.Connections
+    if c == nil || c.Opened == 0 {
+        return
+    }
+    fmt.Fprintln(w, "\n🔌 Koneksi:")
+    fmt.Fprintf(w, "  Dibuka:                %d\n", c.Opened)
+    fmt.Fprintf(w, "  Request via reuse:     %d\n", c.Reused)
+    fmt.Fprintf(w, "  Ditutup server (FIN):  %d\n", c.ServerFIN)
+    fmt.Fprintf(w, "  Direset server (RST):  %d\n", c.ServerRST)
+    fmt.Fprintf(w, "  Ditutup client:        %d\n", c.ClientClosed)
+    fmt.Fprintf(w, "  Server close rate:     %.1f%%\n", c.ServerCloseRate)
+    if c.ReuseFailures > 0 || c.ResetErrors > 0 || c.EOFErrors > 0 {
+        fmt.Fprintf(w, "  ⚠️  Request gagal karena koneksi diputus: %d reset, %d EOF (%d pada koneksi reuse)\n",
+            c.ResetErrors, c.EOFErrors, c.ReuseFailures)
+    }
+    printDials(w, c.Dials)
+    printHTTP2(w, c.HTTP2)
+    printDNS(w, c.DNS)
+    printSources(w, c.Sources)
+    if c.DNS != nil {
+        printFailover(w, c.DNS.Failovers)
+    }
+}
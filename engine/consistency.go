@@ -0,0 +1,134 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "sort"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// consistencySample hasil satu step read-after-write (consistency): apakah
+// baca pertama sudah melihat hasil tulis (step poll.from), dan jika tidak,
+// berapa lama sampai hasil tulis terlihat
+type consistencySample struct {
+    name      string // Nama step baca
+    from      string // Nama step tulis
+    reads     int    // Percobaan baca sampai konsisten atau menyerah
+    converged bool
+    at        time.Time     // Response baca terakhir diterima
+    lag       time.Duration // Dari response tulis diterima sampai baca konsisten
+}
+
+// stale true jika baca pertama belum melihat hasil tulis
+func (s consistencySample) stale() bool {
+    return s.reads > 1 || !s.converged
+}
+
+// consistencyStats agregat satu pasangan tulis → baca
+type consistencyStats struct {
+    from     string
+    reads    atomic.Int64 // Iterasi yang diperiksa
+    stale    atomic.Int64 // Baca pertama basi
+    diverged atomic.Int64 // Tidak pernah konsisten sampai polling habis
+    attempts atomic.Int64 // Total percobaan baca
+    staleLag histogram    // Lag baca basi yang akhirnya konsisten
+    staleMax atomic.Int64
+}
+
+// consistencySet kumpulan consistencyStats per nama step baca. Zero value
+// siap dipakai.
+type consistencySet struct {
+    steps sync.Map // string -> *consistencyStats
+}
+
+func (s *consistencySet) add(sample consistencySample) {
+    v, ok := s.steps.Load(sample.name)
+    if !ok {
+        v, _ = s.steps.LoadOrStore(sample.name, &consistencyStats{from: sample.from})
+    }
+    stats := v.(*consistencyStats)
+    stats.reads.Add(1)
+    stats.attempts.Add(int64(sample.reads))
+    switch {
+    case !sample.stale():
+    case !sample.converged:
+        stats.stale.Add(1)
+        stats.diverged.Add(1)
+    default:
+        stats.stale.Add(1)
+        stats.staleLag.addDuration(sample.lag)
+        for {
+            current := stats.staleMax.Load()
+            if int64(sample.lag) <= current || stats.staleMax.CompareAndSwap(current, int64(sample.lag)) {
+                break
+            }
+        }
+    }
+}
+
+// ConsistencyReport hasil read-after-write satu step baca
+type ConsistencyReport struct {
+    From        string  `json:"from"`
+    Reads       int64   `json:"reads"`
+    Stale       int64   `json:"stale_reads"` // Baca pertama belum melihat hasil tulis
+    StalePct    float64 `json:"stale_pct"`
+    Diverged    int64   `json:"never_consistent"` // Polling habis sebelum konsisten
+    AvgAttempts float64 `json:"avg_attempts"`
+    // Lag dari response tulis diterima sampai baca konsisten, hanya untuk
+    // baca basi yang akhirnya konsisten
+    LagP50Ms float64 `json:"stale_lag_p50_ms"`
+    LagP95Ms float64 `json:"stale_lag_p95_ms"`
+    LagP99Ms float64 `json:"stale_lag_p99_ms"`
+    LagMaxMs float64 `json:"stale_lag_max_ms"`
+}
+
+func (s *consistencySet) report() map[string]ConsistencyReport {
+    reports := make(map[string]ConsistencyReport)
+    s.steps.Range(func(key, value any) bool {
+        c := value.(*consistencyStats)
+        r := ConsistencyReport{From: c.from, Reads: c.reads.Load(), Stale: c.stale.Load(), Diverged: c.diverged.Load()}
+        if r.Reads > 0 {
+            r.StalePct = float64(r.Stale) / float64(r.Reads) * 100
+            r.AvgAttempts = float64(c.attempts.Load()) / float64(r.Reads)
+        }
+        if c.staleLag.count() > 0 {
+            maxMs := durationMs(time.Duration(c.staleMax.Load()))
+            r.LagP50Ms = min(maxMs, durationMs(c.staleLag.quantileDuration(0.50)))
+            r.LagP95Ms = min(maxMs, durationMs(c.staleLag.quantileDuration(0.95)))
+            r.LagP99Ms = min(maxMs, durationMs(c.staleLag.quantileDuration(0.99)))
+            r.LagMaxMs = maxMs
+        }
+        reports[key.(string)] = r
+        return true
+    })
+    if len(reports) == 0 {
+        return nil
+    }
+    return reports
+}
+
+func printConsistency(w io.Writer, report *Report) {
+    if len(report.Consistency) == 0 {
+        return
+    }
+    names := make([]string, 0, len(report.Consistency))
+    for name := range report.Consistency {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    fmt.Fprintln(w, "\n🪞 Read-after-write:")
+    fmt.Fprintf(w, "  %-24s %8s %8s %9s %10s %10s %10s\n", "Tulis → baca", "Baca", "Basi %", "Tak konv.", "Lag p50", "Lag p95", "Lag max")
+    for _, name := range names {
+        c := report.Consistency[name]
+        fmt.Fprintf(w, "  %-24s %8d %7.2f%% %9d %7.1f ms %7.1f ms %7.1f ms\n",
+            truncate(c.From+" → "+name, 24), c.Reads, c.StalePct, c.Diverged, c.LagP50Ms, c.LagP95Ms, c.LagMaxMs)
+    }
+    for _, name := range names {
+        if c := report.Consistency[name]; c.Diverged > 0 {
+            fmt.Fprintf(w, "  ⚠️  %s: %d baca tidak pernah melihat hasil tulis sampai polling habis\n", name, c.Diverged)
+        }
+    }
+}
@@ -0,0 +1,83 @@
+package engine
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net"
+    "net/http"
+)
+
+// controlServer API HTTP selama run (-control-addr) untuk mengubah kondisi
+// test tanpa menghentikannya. Saat ini berisi pengalihan IP target.
+type controlServer struct {
+    srv *http.Server
+    dns *dnsResolver
+}
+
+// DNSSwitchRequest body POST /dns
+type DNSSwitchRequest struct {
+    Host string   `json:"host,omitempty"` // Default host dari -u
+    IPs  []string `json:"ips"`
+}
+
+// startControl membuka -control-addr, nil jika tidak diisi
+func startControl(config *Config, dns *dnsResolver) (*controlServer, error) {
+    if config.ControlAddr == "" {
+        return nil, nil
+    }
+    if dns == nil {
+        return nil, fmt.Errorf("-control-addr tidak didukung untuk mode ini")
+    }
+    ln, err := net.Listen("tcp", config.ControlAddr)
+    if err != nil {
+        return nil, fmt.Errorf("-control-addr: %w", err)
+    }
+    c := &controlServer{dns: dns}
+    mux := http.NewServeMux()
+    mux.HandleFunc("GET /dns", c.handleDNS)
+    mux.HandleFunc("POST /dns", c.handleSwitch(targetHost(config.URL)))
+    c.srv = &http.Server{Handler: mux}
+    go func() {
+        if err := c.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+            fmt.Fprintf(config.out, "⚠️  Control API: %v\n", err)
+        }
+    }()
+    fmt.Fprintf(config.out, "🎛️  Control API di http://%s (GET/POST /dns)\n", ln.Addr())
+    return c, nil
+}
+
+func (c *controlServer) handleDNS(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, c.dns.report())
+}
+
+func (c *controlServer) handleSwitch(defaultHost string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        var req DNSSwitchRequest
+        if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+            writeError(w, http.StatusBadRequest, "body tidak valid: %v", err)
+            return
+        }
+        if req.Host == "" {
+            req.Host = defaultHost
+        }
+        if req.Host == "" {
+            writeError(w, http.StatusBadRequest, "host wajib diisi, -u tidak berisi nama host")
+            return
+        }
+        ips, err := parseSwitchIPs(req.IPs)
+        if err != nil {
+            writeError(w, http.StatusBadRequest, "%v", err)
+            return
+        }
+        c.dns.switchTo(req.Host, ips, "api")
+        writeJSON(w, http.StatusOK, c.dns.report())
+    }
+}
+
+// Close menutup control API, aman dipanggil pada nil
+func (c *controlServer) Close() {
+    if c != nil {
+        c.srv.Close()
+    }
+}
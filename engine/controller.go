@@ -0,0 +1,347 @@
+package engine
+
+import (
+    "encoding/json"
+    "flag"
+    "fmt"
+    "io"
+    "net/http"
+    "sort"
+    "sync"
+    "time"
+)
+
+// Status agent di controller
+const (
+    agentActive = "active"
+    agentDrift  = "drift" // Konfigurasi berbeda dari acuan grup
+    agentDone   = "done"
+    agentLost   = "lost" // Tidak ada heartbeat lebih dari agentTimeout
+)
+
+// Kesehatan agent dari heartbeat
+const (
+    agentHealthy   = "healthy"
+    agentUnhealthy = "unhealthy"
+    agentPaused    = "paused"
+)
+
+// agentTimeout agent tanpa heartbeat selama ini dianggap hilang
+const agentTimeout = 6 * agentHeartbeat
+
+// Batas kesehatan agent. Agent yang tidak sehat agentStrikes heartbeat
+// berturut-turut dijeda selama agentPause agar generator yang jenuh tidak
+// ikut mengukur dirinya sendiri sebagai latency server.
+const (
+    agentCPUSaturated = 90.0 // Persen CPU proses generator
+    agentDropLimit    = 0.05 // Bagian job yang terlambat dari jadwal
+    agentStrikes      = 2
+    agentPause        = 3 * agentHeartbeat
+)
+
+// AgentState satu agent yang terdaftar di controller
+type AgentState struct {
+    Agent      string    `json:"agent"`
+    Version    string    `json:"version"`
+    ConfigHash string    `json:"config_hash"`
+    Status     string    `json:"status"`
+    Drift      string    `json:"drift,omitempty"`
+    Registered time.Time `json:"registered"`
+    LastSeen   time.Time `json:"last_seen"`
+
+    Health    string  `json:"health,omitempty"`
+    Unhealthy string  `json:"unhealthy_reason,omitempty"`
+    CPUPct    float64 `json:"cpu_pct"`
+    Requests  int64   `json:"requests"`
+    Drops     int64   `json:"drops"`
+    Pauses    int     `json:"pauses,omitempty"`
+
+    Weight   float64 `json:"weight,omitempty"`
+    Capacity float64 `json:"capacity_rps,omitempty"` // Perkiraan req/s pada CPU 100%
+    Rate     float64 `json:"rate,omitempty"`         // Bagian -group-rate saat ini
+
+    strikes     int
+    pausedUntil time.Time
+    beatAt      time.Time
+}
+
+// AgentGroup agent-agent satu run terdistribusi (-run-group yang sama).
+// Acuan versi, config hash dan -group-rate berasal dari definisi grup di
+// controller, bukan dari agent yang kebetulan mendaftar pertama.
+type AgentGroup struct {
+    Name       string        `json:"name"`
+    Owner      string        `json:"owner,omitempty"` // Nama token yang mendefinisikan grup
+    Version    string        `json:"version"`
+    ConfigHash string        `json:"config_hash"`
+    GroupRate  float64       `json:"group_rate,omitempty"`
+    Balance    string        `json:"balance,omitempty"`
+    Agents     []*AgentState `json:"agents"`
+}
+
+// AgentGroupSpec definisi grup yang dikirim lewat PUT /agents/{group}: args
+// sama dengan yang dijalankan agent. Config hash dihitung controller dari
+// args, jadi -body-file, -scenario dan -param-file harus terbaca di
+// controller; jika file hanya ada di agent, isi config_hash langsung.
+type AgentGroupSpec struct {
+    Args       []string `json:"args"`
+    ConfigHash string   `json:"config_hash,omitempty"`
+}
+
+// groupKey grup dibedakan per pemilik token, sehingga tenant lain tidak bisa
+// memakai atau mendahului definisi grup dengan nama yang sama
+type groupKey struct {
+    owner string
+    name  string
+}
+
+// agentRegistry grup agent yang dikelola controller (loadtest server)
+type agentRegistry struct {
+    mu     sync.Mutex
+    groups map[groupKey]*AgentGroup
+}
+
+func newAgentRegistry() *agentRegistry {
+    return &agentRegistry{groups: make(map[groupKey]*AgentGroup)}
+}
+
+// newAgentGroup menghitung acuan grup dari args agent dengan versi
+// controller sebagai acuan versi
+func newAgentGroup(name string, spec AgentGroupSpec, token *apiToken) (*AgentGroup, error) {
+    if len(spec.Args) == 0 {
+        return nil, fmt.Errorf("args kosong")
+    }
+    fs := flag.NewFlagSet("group", flag.ContinueOnError)
+    fs.SetOutput(io.Discard)
+    config, err := parseFlags(fs, spec.Args)
+    if err != nil {
+        return nil, fmt.Errorf("args tidak valid: %w", err)
+    }
+    if config.URL == "" && config.Scenario == "" {
+        return nil, fmt.Errorf("URL target kosong")
+    }
+    if config.Balance != balanceEven && config.Balance != balanceWeight && config.Balance != balanceCapacity {
+        return nil, fmt.Errorf("-balance harus even, weight atau capacity")
+    }
+    if token.MaxRate > 0 && config.GroupRate > token.MaxRate {
+        return nil, fmt.Errorf("-group-rate %.0f melebihi batas token %s (%.0f req/s)", config.GroupRate, token.Name, token.MaxRate)
+    }
+    hash := spec.ConfigHash
+    if hash == "" {
+        if err := loadBody(config); err != nil {
+            return nil, err
+        }
+        hash = configHash(config)
+    }
+    group := &AgentGroup{Name: name, Owner: token.Name, Version: version, ConfigHash: hash}
+    if config.GroupRate > 0 {
+        group.GroupRate, group.Balance = config.GroupRate, config.Balance
+    }
+    return group, nil
+}
+
+// expire menandai agent tanpa heartbeat sebagai hilang. Dipanggil dengan
+// mu terkunci.
+func (g *AgentGroup) expire(now time.Time) {
+    for _, a := range g.Agents {
+        if (a.Status == agentActive || a.Status == agentDrift) && now.Sub(a.LastSeen) > agentTimeout {
+            a.Status = agentLost
+        }
+    }
+}
+
+// idle true jika tidak ada agent yang masih berjalan, sehingga run
+// berikutnya dengan nama grup yang sama dimulai dengan daftar agent baru
+func (g *AgentGroup) idle() bool {
+    for _, a := range g.Agents {
+        if a.Status == agentActive || a.Status == agentDrift {
+            return false
+        }
+    }
+    return true
+}
+
+func (g *AgentGroup) find(name string) *AgentState {
+    for _, a := range g.Agents {
+        if a.Agent == name {
+            return a
+        }
+    }
+    return nil
+}
+
+// observe menilai kesehatan agent dari heartbeat. Mengembalikan
+// agentActionPause selama agent harus berhenti mengirim beban.
+func (a *AgentState) observe(group string, h *AgentHealth, now time.Time) string {
+    if h == nil {
+        return ""
+    }
+    jobs, drops := h.Requests-a.Requests, h.Drops-a.Drops
+    a.CPUPct, a.Requests, a.Drops = h.CPUPct, h.Requests, h.Drops
+    since := a.beatAt
+    if since.IsZero() {
+        since = a.Registered
+    }
+    a.measure(jobs, now.Sub(since), h.CPUPct)
+    a.beatAt = now
+    if now.Before(a.pausedUntil) {
+        return agentActionPause
+    }
+    reason := ""
+    switch {
+    case h.CPUPct >= agentCPUSaturated:
+        reason = fmt.Sprintf("CPU generator %.0f%%", h.CPUPct)
+    case jobs > 0 && float64(drops)/float64(jobs) >= agentDropLimit:
+        reason = fmt.Sprintf("%d dari %d job terlambat dari jadwal", drops, jobs)
+    }
+    if reason == "" {
+        a.Health, a.Unhealthy, a.strikes = agentHealthy, "", 0
+        return ""
+    }
+    a.Health, a.Unhealthy = agentUnhealthy, reason
+    if a.strikes++; a.strikes < agentStrikes {
+        return ""
+    }
+    a.Health, a.strikes = agentPaused, 0
+    a.Pauses++
+    a.pausedUntil = now.Add(agentPause)
+    fmt.Printf("⏸️  Agent %s di grup %s dijeda %v: %s\n", a.Agent, group, agentPause, reason)
+    return agentActionPause
+}
+
+// check membandingkan agent dengan acuan grup, kosong jika sama
+func (g *AgentGroup) check(hello AgentHello) string {
+    switch {
+    case hello.Version != g.Version:
+        return fmt.Sprintf("versi %s, acuan controller %s", hello.Version, g.Version)
+    case hello.ConfigHash != g.ConfigHash:
+        return fmt.Sprintf("config hash %s, acuan grup %s", hello.ConfigHash, g.ConfigHash)
+    }
+    return ""
+}
+
+// handleDefine membuat atau mengganti definisi grup milik token. Grup yang
+// agent-nya masih berjalan tidak bisa diganti.
+func (r *agentRegistry) handleDefine(w http.ResponseWriter, req *http.Request) {
+    var spec AgentGroupSpec
+    if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1<<20)).Decode(&spec); err != nil {
+        writeError(w, http.StatusBadRequest, "definisi grup tidak valid: %v", err)
+        return
+    }
+    token := tokenFrom(req)
+    group, err := newAgentGroup(req.PathValue("group"), spec, token)
+    if err != nil {
+        writeError(w, http.StatusBadRequest, "%v", err)
+        return
+    }
+    key := groupKey{owner: token.Name, name: group.Name}
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    if old := r.groups[key]; old != nil {
+        old.expire(time.Now().UTC())
+        if !old.idle() {
+            writeError(w, http.StatusConflict, "grup %s masih berjalan", group.Name)
+            return
+        }
+    }
+    r.groups[key] = group
+    writeJSON(w, http.StatusOK, group)
+}
+
+// handle menerima register, heartbeat dan done dari agent
+func (r *agentRegistry) handle(w http.ResponseWriter, req *http.Request) {
+    var hello AgentHello
+    if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1<<16)).Decode(&hello); err != nil || hello.Agent == "" {
+        writeError(w, http.StatusBadRequest, "data agent tidak valid")
+        return
+    }
+    name, action := req.PathValue("group"), req.PathValue("action")
+    now := time.Now().UTC()
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    group := r.groups[groupKey{owner: tokenFrom(req).Name, name: name}]
+    if group == nil {
+        writeError(w, http.StatusNotFound, "grup %s belum didefinisikan; buat dengan PUT /agents/%s", name, name)
+        return
+    }
+    group.expire(now)
+    switch action {
+    case "register":
+        if group.idle() {
+            group.Agents = nil
+        }
+        agent := group.find(hello.Agent)
+        if agent == nil {
+            agent = &AgentState{Agent: hello.Agent}
+            group.Agents = append(group.Agents, agent)
+        }
+        agent.Version, agent.ConfigHash, agent.Registered = hello.Version, hello.ConfigHash, now
+        agent.Weight = hello.Weight
+    case "heartbeat", "done":
+        if group.find(hello.Agent) == nil {
+            writeError(w, http.StatusNotFound, "agent %s belum terdaftar di grup %s", hello.Agent, name)
+            return
+        }
+    default:
+        writeError(w, http.StatusNotFound, "aksi agent %q tidak dikenal", action)
+        return
+    }
+
+    agent := group.find(hello.Agent)
+    agent.LastSeen, agent.ConfigHash = now, hello.ConfigHash
+    if action == "done" {
+        agent.Status = agentDone
+        writeJSON(w, http.StatusOK, AgentReply{Status: "ok"})
+        return
+    }
+    reply := AgentReply{Status: "ok", Action: agent.observe(name, hello.Health, now)}
+    if reply.Action != "" {
+        reply.Health = agent.Unhealthy
+    }
+    // Agent yang pernah drift tetap drift walau konfigurasinya kembali sama,
+    // karena sebagian sampelnya sudah diambil dengan konfigurasi berbeda
+    if reason := group.check(hello); reason != "" || agent.Drift != "" {
+        if agent.Drift == "" {
+            agent.Drift = reason
+            fmt.Printf("⚠️  Agent %s di grup %s: konfigurasi berbeda (%s)\n", agent.Agent, name, reason)
+        }
+        agent.Status, agent.Rate = agentDrift, 0
+        reply.Status, reply.Reason = "drift", agent.Drift
+        writeJSON(w, http.StatusConflict, reply)
+        return
+    }
+    agent.Status = agentActive
+    agent.Rate = group.share(agent)
+    reply.Rate = agent.Rate
+    writeJSON(w, http.StatusOK, reply)
+}
+
+// handleList menampilkan grup milik token, atau semua grup untuk token admin
+func (r *agentRegistry) handleList(w http.ResponseWriter, req *http.Request) {
+    token := tokenFrom(req)
+    now := time.Now().UTC()
+    r.mu.Lock()
+    groups := make([]AgentGroup, 0, len(r.groups))
+    for key, g := range r.groups {
+        if !token.Admin && key.owner != token.Name {
+            continue
+        }
+        g.expire(now)
+        snapshot := *g
+        snapshot.Agents = make([]*AgentState, len(g.Agents))
+        for i, a := range g.Agents {
+            copied := *a
+            snapshot.Agents[i] = &copied
+        }
+        groups = append(groups, snapshot)
+    }
+    r.mu.Unlock()
+    sort.Slice(groups, func(i, j int) bool {
+        if groups[i].Name != groups[j].Name {
+            return groups[i].Name < groups[j].Name
+        }
+        return groups[i].Owner < groups[j].Owner
+    })
+    writeJSON(w, http.StatusOK, groups)
+}
@@ -0,0 +1,436 @@
+package engine
+
+import (
+    "bufio"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// version versi loadtest, dicek controller agar semua agent satu run sama
+const version = "1.24"
+
+// defaultUserAgent User-Agent semua request, kecuali ditimpa -H
+const defaultUserAgent = "Go-Load-Tester/" + version
+
+// maxRetryAfter batas jeda dari satu header Retry-After
+const maxRetryAfter = 10 * time.Minute
+
+// userAgent menambahkan -contact agar pemilik target tahu siapa yang
+// mengirim traffic
+func userAgent(config *Config) string {
+    if config.Contact == "" {
+        return defaultUserAgent
+    }
+    return fmt.Sprintf("%s (+%s)", defaultUserAgent, config.Contact)
+}
+
+// parseRetryAfter membaca header Retry-After (detik atau HTTP-date) pada
+// response 429/503, 0 jika tidak ada
+func parseRetryAfter(resp *http.Response) time.Duration {
+    if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+        return 0
+    }
+    value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+    if value == "" {
+        return 0
+    }
+    var wait time.Duration
+    if secs, err := strconv.Atoi(value); err == nil {
+        wait = time.Duration(secs) * time.Second
+    } else if at, err := http.ParseTime(value); err == nil {
+        wait = time.Until(at)
+    }
+    return max(0, min(wait, maxRetryAfter))
+}
+
+// courtesyRequester membungkus Requester untuk target pihak ketiga: rate
+// dibatasi secara global untuk semua worker dan Retry-After dari target
+// menahan semua request berikutnya. Waktu tunggu tidak ikut diukur sebagai
+// latency.
+type courtesyRequester struct {
+    Requester
+    interval time.Duration
+    out      io.Writer
+
+    mu         sync.Mutex
+    next       time.Time
+    pauseUntil time.Time
+    pauses     int
+    paused     time.Duration
+}
+
+func newCourtesyRequester(inner Requester, rps float64, out io.Writer) *courtesyRequester {
+    return &courtesyRequester{Requester: inner, interval: time.Duration(float64(time.Second) / rps), out: out}
+}
+
+func (c *courtesyRequester) Do(ctx context.Context, requestNum int) Result {
+    // Slot diambil ulang jika selama menunggu target meminta jeda
+    for at := c.reserve(); ; at = c.reserve() {
+        if !sleepUntil(ctx, at) {
+            return Result{Start: time.Now(), Err: ctx.Err()}
+        }
+        c.mu.Lock()
+        paused := c.pauseUntil.After(time.Now())
+        c.mu.Unlock()
+        if !paused {
+            break
+        }
+    }
+    result := c.Requester.Do(ctx, requestNum)
+    if result.RetryAfter > 0 {
+        c.backoff(result.RetryAfter)
+    }
+    return result
+}
+
+// reserve mengambil slot kirim berikutnya sesuai interval dan jeda Retry-After
+func (c *courtesyRequester) reserve() time.Time {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    at := time.Now()
+    if c.next.After(at) {
+        at = c.next
+    }
+    if c.pauseUntil.After(at) {
+        at = c.pauseUntil
+    }
+    c.next = at.Add(c.interval)
+    return at
+}
+
+func (c *courtesyRequester) backoff(wait time.Duration) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    until := time.Now().Add(wait)
+    if !until.After(c.pauseUntil) {
+        return
+    }
+    if c.pauseUntil.After(time.Now()) {
+        c.paused += until.Sub(c.pauseUntil)
+    } else {
+        c.paused += wait
+        c.pauses++
+    }
+    c.pauseUntil = until
+    c.next = until
+}
+
+// Preconnect, ConnReport, CacheReport, SecurityReport, PageReport,
+// URLLimitReport, IdempotencyReport, ShadowReport, CloseIdleConnections,
+// CapturePackets, DNSResolver, DeadlineReport dan Drain diteruskan ke requester asli
+
+func (c *courtesyRequester) Preconnect(ctx context.Context, n int) error {
+    if p, ok := c.Requester.(Preconnector); ok {
+        return p.Preconnect(ctx, n)
+    }
+    return nil
+}
+
+func (c *courtesyRequester) ConnReport() *ConnReport {
+    if r, ok := c.Requester.(ConnReporter); ok {
+        return r.ConnReport()
+    }
+    return nil
+}
+
+func (c *courtesyRequester) OpenConns() int64 {
+    if r, ok := c.Requester.(ConnCounter); ok {
+        return r.OpenConns()
+    }
+    return 0
+}
+
+func (c *courtesyRequester) CacheReport() *CacheReport {
+    if r, ok := c.Requester.(CacheReporter); ok {
+        return r.CacheReport()
+    }
+    return nil
+}
+
+func (c *courtesyRequester) SecurityReport() *SecurityReport {
+    if r, ok := c.Requester.(SecurityReporter); ok {
+        return r.SecurityReport()
+    }
+    return nil
+}
+
+func (c *courtesyRequester) PageReport() *PageReport {
+    if r, ok := c.Requester.(PageReporter); ok {
+        return r.PageReport()
+    }
+    return nil
+}
+
+func (c *courtesyRequester) URLLimitReport() []URLLimitReport {
+    if r, ok := c.Requester.(URLLimitReporter); ok {
+        return r.URLLimitReport()
+    }
+    return nil
+}
+
+func (c *courtesyRequester) IdempotencyReport() *IdempotencyReport {
+    if r, ok := c.Requester.(IdempotencyReporter); ok {
+        return r.IdempotencyReport()
+    }
+    return nil
+}
+
+func (c *courtesyRequester) DeadlineReport() *DeadlineReport {
+    if r, ok := c.Requester.(DeadlineReporter); ok {
+        return r.DeadlineReport()
+    }
+    return nil
+}
+
+func (c *courtesyRequester) Drain(timeout time.Duration) *DrainReport {
+    if d, ok := c.Requester.(Drainer); ok {
+        return d.Drain(timeout)
+    }
+    return nil
+}
+
+func (c *courtesyRequester) DNSResolver() *dnsResolver {
+    if d, ok := c.Requester.(DNSSwitcher); ok {
+        return d.DNSResolver()
+    }
+    return nil
+}
+
+func (c *courtesyRequester) CloseIdleConnections() {
+    if i, ok := c.Requester.(IdleCloser); ok {
+        i.CloseIdleConnections()
+    }
+}
+
+func (c *courtesyRequester) ShadowReport() *ShadowReport {
+    if r, ok := c.Requester.(ShadowReporter); ok {
+        return r.ShadowReport()
+    }
+    return nil
+}
+
+func (c *courtesyRequester) CapturePackets(capture *packetCapture) {
+    if p, ok := c.Requester.(PacketCapturer); ok {
+        p.CapturePackets(capture)
+    }
+}
+
+func (c *courtesyRequester) Close() error {
+    c.mu.Lock()
+    if c.pauses > 0 {
+        fmt.Fprintf(c.out, "⏸️  Retry-After dihormati %d kali, total jeda %v\n", c.pauses, c.paused.Round(time.Second))
+    }
+    c.mu.Unlock()
+    return c.Requester.Close()
+}
+
+// robotsRules aturan robots.txt yang berlaku untuk loadtest
+type robotsRules struct {
+    allow, disallow []string
+    crawlDelay      time.Duration
+}
+
+// allowed mengikuti RFC 9309: aturan dengan pola terpanjang menang, Allow
+// menang jika sama panjang
+func (r *robotsRules) allowed(path string) bool {
+    best, allow := -1, true
+    for _, pattern := range r.allow {
+        if robotsMatch(pattern, path) && len(pattern) >= best {
+            best, allow = len(pattern), true
+        }
+    }
+    for _, pattern := range r.disallow {
+        if robotsMatch(pattern, path) && len(pattern) > best {
+            best, allow = len(pattern), false
+        }
+    }
+    return allow
+}
+
+// robotsMatch mencocokkan pola robots.txt ('*' wildcard, '$' akhir path)
+func robotsMatch(pattern, path string) bool {
+    expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSuffix(pattern, "$")), `\*`, ".*")
+    if strings.HasSuffix(pattern, "$") {
+        expr += "$"
+    }
+    matched, _ := regexp.MatchString(expr, path)
+    return matched
+}
+
+// parseRobots mengambil grup untuk agent ("go-load-tester"/"loadtest") atau
+// "*" jika tidak ada grup khusus
+func parseRobots(r io.Reader) *robotsRules {
+    groups := map[string]*robotsRules{}
+    var current []*robotsRules
+    inAgents := false
+    scanner := bufio.NewScanner(r)
+    for scanner.Scan() {
+        line, _, _ := strings.Cut(scanner.Text(), "#")
+        key, value, found := strings.Cut(line, ":")
+        if !found {
+            continue
+        }
+        key = strings.ToLower(strings.TrimSpace(key))
+        value = strings.TrimSpace(value)
+        if key == "user-agent" {
+            if !inAgents {
+                current = nil
+            }
+            inAgents = true
+            agent := strings.ToLower(value)
+            if groups[agent] == nil {
+                groups[agent] = &robotsRules{}
+            }
+            current = append(current, groups[agent])
+            continue
+        }
+        inAgents = false
+        for _, g := range current {
+            switch key {
+            case "allow":
+                if value != "" {
+                    g.allow = append(g.allow, value)
+                }
+            case "disallow":
+                if value != "" {
+                    g.disallow = append(g.disallow, value)
+                }
+            case "crawl-delay":
+                if secs, err := strconv.ParseFloat(value, 64); err == nil {
+                    g.crawlDelay = time.Duration(secs * float64(time.Second))
+                }
+            }
+        }
+    }
+    for _, agent := range []string{"go-load-tester", "loadtest"} {
+        if g := groups[agent]; g != nil {
+            return g
+        }
+    }
+    if g := groups["*"]; g != nil {
+        return g
+    }
+    return &robotsRules{}
+}
+
+// checkRobots memastikan semua path target diizinkan robots.txt host-nya
+// dan mengembalikan Crawl-delay terbesar
+func checkRobots(config *Config) (time.Duration, error) {
+    targets, err := courtesyTargets(config)
+    if err != nil {
+        return 0, err
+    }
+    client := &http.Client{Timeout: 10 * time.Second}
+    rulesByHost := map[string]*robotsRules{}
+    var crawlDelay time.Duration
+    for _, target := range targets {
+        origin := target.Scheme + "://" + target.Host
+        rules, ok := rulesByHost[origin]
+        if !ok {
+            rules, err = fetchRobots(client, config, origin)
+            if err != nil {
+                return 0, err
+            }
+            rulesByHost[origin] = rules
+            crawlDelay = max(crawlDelay, rules.crawlDelay)
+        }
+        if !rules.allowed(target.RequestURI()) {
+            return 0, fmt.Errorf("robots.txt %s tidak mengizinkan %s", origin, target.RequestURI())
+        }
+    }
+    return crawlDelay, nil
+}
+
+func fetchRobots(client *http.Client, config *Config, origin string) (*robotsRules, error) {
+    req, err := http.NewRequest(http.MethodGet, origin+"/robots.txt", nil)
+    if err != nil {
+        return nil, err
+    }
+    req.Header.Set("User-Agent", userAgent(config))
+    resp, err := client.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("mengambil robots.txt %s: %w", origin, err)
+    }
+    defer resp.Body.Close()
+    switch {
+    case resp.StatusCode >= 500:
+        // RFC 9309: robots.txt yang tidak bisa diambil berarti semua dilarang
+        return nil, fmt.Errorf("robots.txt %s merespon %s, dianggap melarang semua", origin, resp.Status)
+    case resp.StatusCode >= 400:
+        return &robotsRules{}, nil
+    }
+    return parseRobots(io.LimitReader(resp.Body, 512<<10)), nil
+}
+
+// courtesyTargets URL target yang bisa diperiksa tanpa menjalankan
+// template: URL utama dan URL step skenario yang statis
+func courtesyTargets(config *Config) ([]*url.URL, error) {
+    var base *url.URL
+    var targets []*url.URL
+    if config.URL != "" {
+        u, err := url.Parse(config.URL)
+        if err != nil {
+            return nil, fmt.Errorf("URL tidak valid: %w", err)
+        }
+        base = u
+        targets = append(targets, u)
+    }
+    if config.Scenario != "" {
+        data, err := os.ReadFile(config.Scenario)
+        if err != nil {
+            return nil, err
+        }
+        var sc Scenario
+        if err := json.Unmarshal(data, &sc); err != nil {
+            return nil, fmt.Errorf("%s bukan skenario JSON yang valid: %w", config.Scenario, err)
+        }
+        for _, block := range [][]Step{sc.Setup, sc.VUSetup, sc.Steps, sc.VUTeardown, sc.Teardown} {
+            for _, step := range block {
+                if strings.Contains(step.URL, "{{") {
+                    continue
+                }
+                u, err := url.Parse(step.URL)
+                if err != nil {
+                    return nil, fmt.Errorf("URL step %q tidak valid: %w", step.URL, err)
+                }
+                if base != nil {
+                    u = base.ResolveReference(u)
+                }
+                if u.IsAbs() {
+                    targets = append(targets, u)
+                }
+            }
+        }
+    }
+    return targets, nil
+}
+
+// prepareCourtesy memvalidasi -courtesy, memeriksa robots.txt dan
+// menurunkan -courtesy-rps jika robots.txt meminta Crawl-delay
+func prepareCourtesy(config *Config) error {
+    if config.Contact == "" {
+        return fmt.Errorf("-courtesy membutuhkan -contact agar pemilik target bisa menghubungi anda")
+    }
+    if config.CourtesyRPS <= 0 {
+        return fmt.Errorf("-courtesy-rps harus lebih dari 0")
+    }
+    crawlDelay, err := checkRobots(config)
+    if err != nil {
+        return err
+    }
+    if crawlDelay > 0 {
+        config.CourtesyRPS = min(config.CourtesyRPS, 1/crawlDelay.Seconds())
+    }
+    fmt.Fprintf(config.out, "🤝 Mode courtesy: maksimal %.2f req/s, User-Agent %q\n", config.CourtesyRPS, userAgent(config))
+    return nil
+}
@@ -0,0 +1,142 @@
+package engine
+
+import (
+    "bufio"
+    "context"
+    "errors"
+    "fmt"
+    "hash/fnv"
+    mathrand "math/rand/v2"
+    "os"
+    "strconv"
+    "strings"
+    "sync/atomic"
+)
+
+type workerIDKey struct{}
+
+// withWorkerID menyimpan nomor worker (virtual user) di context request
+func withWorkerID(ctx context.Context, id int) context.Context {
+    return context.WithValue(ctx, workerIDKey{}, id)
+}
+
+// workerID nomor worker dari context, 0 jika tidak ada
+func workerID(ctx context.Context) int {
+    id, _ := ctx.Value(workerIDKey{}).(int)
+    return id
+}
+
+// Cara memilih nilai dari file parameter
+const (
+    feedRandom     = "random"     // Nilai acak untuk setiap request, bisa diulang dengan -seed
+    feedSequential = "sequential" // Round-robin mengikuti nomor request, berulang dari awal
+    feedShard      = "shard"      // Setiap worker memakai bagian file sendiri secara berurutan
+    feedUnique     = "unique"     // Setiap nilai hanya dipakai sekali oleh satu worker
+)
+
+// errDataExhausted dikembalikan requester jika nilai mode unique sudah habis;
+// request tersebut tidak dikirim dan test dihentikan
+var errDataExhausted = errors.New("nilai param file habis")
+
+// agentShard bagian data untuk generator ini saat beberapa agent memakai
+// file yang sama: agent Index (mulai 1) dari Count memakai baris ke-i
+// dengan i % Count == Index-1
+type agentShard struct {
+    Index int
+    Count int
+}
+
+// parseAgentShard membaca format "i/n", contoh "2/3"; string kosong berarti 1/1
+func parseAgentShard(value string) (agentShard, error) {
+    if value == "" {
+        return agentShard{Index: 1, Count: 1}, nil
+    }
+    indexStr, countStr, found := strings.Cut(value, "/")
+    index, err1 := strconv.Atoi(indexStr)
+    count, err2 := strconv.Atoi(countStr)
+    if !found || err1 != nil || err2 != nil || count < 1 || index < 1 || index > count {
+        return agentShard{}, fmt.Errorf("agent shard %q tidak valid (format: i/n, contoh 1/3)", value)
+    }
+    return agentShard{Index: index, Count: count}, nil
+}
+
+// paramFeed sumber nilai parameter dari file, satu nilai per baris
+type paramFeed struct {
+    values  []string
+    mode    string
+    workers int
+    shards  []atomic.Int64 // Posisi per worker untuk mode shard
+    next    atomic.Int64   // Posisi bersama untuk mode unique
+    seed    uint64         // 0 = acak
+    salt    uint64         // Membedakan urutan acak antar file dengan seed yang sama
+}
+
+func loadParamFeed(path, mode string, workers int, agent agentShard, seed int64) (*paramFeed, error) {
+    switch mode {
+    case "round-robin":
+        mode = feedSequential
+    case feedRandom, feedSequential, feedShard, feedUnique:
+    default:
+        return nil, fmt.Errorf("mode param file %q tidak dikenal (random, sequential/round-robin, shard, unique)", mode)
+    }
+
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    h := fnv.New64a()
+    h.Write([]byte(path))
+    feed := &paramFeed{mode: mode, workers: max(workers, 1), seed: uint64(seed), salt: h.Sum64()}
+
+    scanner := bufio.NewScanner(f)
+    scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+    for lineNum := 0; scanner.Scan(); {
+        line := strings.TrimRight(scanner.Text(), "\r")
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        if lineNum%agent.Count == agent.Index-1 {
+            feed.values = append(feed.values, line)
+        }
+        lineNum++
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+    if len(feed.values) == 0 {
+        return nil, fmt.Errorf("%s: tidak ada nilai untuk agent %d/%d", path, agent.Index, agent.Count)
+    }
+    if mode == feedShard {
+        feed.shards = make([]atomic.Int64, feed.workers)
+    }
+    return feed, nil
+}
+
+// pick memilih nilai untuk request ke-requestNum yang dijalankan worker
+func (f *paramFeed) pick(requestNum, worker int) (string, error) {
+    switch f.mode {
+    case feedSequential:
+        return f.values[requestNum%len(f.values)], nil
+    case feedShard:
+        // Worker w memakai baris w, w+workers, w+2*workers, ...
+        w := worker % f.workers
+        i := w + int(f.shards[w].Add(1)-1)*f.workers
+        return f.values[i%len(f.values)], nil
+    case feedUnique:
+        i := int(f.next.Add(1) - 1)
+        if i >= len(f.values) {
+            return "", errDataExhausted
+        }
+        return f.values[i], nil
+    default:
+        if f.seed == 0 {
+            return f.values[mathrand.IntN(len(f.values))], nil
+        }
+        // Dengan seed, pilihan hanya bergantung pada nomor request sehingga
+        // sama di setiap run walau urutan eksekusi worker berbeda
+        rng := mathrand.New(mathrand.NewPCG(f.seed, f.salt^uint64(requestNum)))
+        return f.values[rng.IntN(len(f.values))], nil
+    }
+}
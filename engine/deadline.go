@@ -0,0 +1,170 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "net/http"
+    "sort"
+    "strconv"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// Format nilai header deadline (-deadline-format)
+const (
+    deadlineRFC3339 = "rfc3339" // 2026-01-02T15:04:05.123Z
+    deadlineUnixMs  = "unix-ms" // Milidetik sejak epoch
+)
+
+// deadlineStamper (-deadline-header) memberi setiap request deadline absolut
+// client di header, untuk menguji backend yang meneruskan deadline dan
+// seharusnya berhenti lebih awal saat deadline lewat. Response yang baru
+// datang setelah deadline dihitung sebagai terlambat.
+type deadlineStamper struct {
+    header  string
+    budget  time.Duration
+    format  string
+    timeout bool // budget diambil dari -t, boleh diganti timeout step
+
+    stamped atomic.Int64
+    late    atomic.Int64
+    maxOver atomic.Int64 // Nanodetik, keterlambatan terbesar
+
+    mu       sync.Mutex
+    statuses map[int]int64 // Status response terlambat
+}
+
+func newDeadlineStamper(config *Config) (*deadlineStamper, error) {
+    if config.DeadlineHdr == "" {
+        return nil, nil
+    }
+    budget, timeout := config.DeadlineMax, false
+    if budget == 0 {
+        budget, timeout = time.Duration(config.Timeout)*time.Second, true
+    }
+    if budget <= 0 {
+        return nil, fmt.Errorf("-deadline-header butuh -deadline-budget atau -t > 0")
+    }
+    switch config.DeadlineFmt {
+    case deadlineRFC3339, deadlineUnixMs:
+    default:
+        return nil, fmt.Errorf("-deadline-format harus %s atau %s", deadlineRFC3339, deadlineUnixMs)
+    }
+    return &deadlineStamper{
+        header:   http.CanonicalHeaderKey(config.DeadlineHdr),
+        budget:   budget,
+        format:   config.DeadlineFmt,
+        timeout:  timeout,
+        statuses: make(map[int]int64),
+    }, nil
+}
+
+// stamp mengisi header deadline req dihitung dari sekarang. timeout (timeout
+// step skenario) menggantikan budget dari -t jika > 0, tapi tidak
+// menggantikan -deadline-budget. Mengembalikan deadline, nol jika stamper
+// tidak aktif.
+func (d *deadlineStamper) stamp(req *http.Request, timeout time.Duration) time.Time {
+    if d == nil {
+        return time.Time{}
+    }
+    budget := d.budget
+    if timeout > 0 && d.timeout {
+        budget = timeout
+    }
+    deadline := time.Now().Add(budget)
+    switch d.format {
+    case deadlineUnixMs:
+        req.Header.Set(d.header, strconv.FormatInt(deadline.UnixMilli(), 10))
+    default:
+        req.Header.Set(d.header, deadline.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+    }
+    d.stamped.Add(1)
+    return deadline
+}
+
+// observe mencatat response yang header-nya diterima setelah deadline
+func (d *deadlineStamper) observe(deadline time.Time, result Result) {
+    if d == nil || deadline.IsZero() || result.StatusCode == 0 {
+        return
+    }
+    over := result.Start.Add(result.Duration).Sub(deadline)
+    if over <= 0 {
+        return
+    }
+    d.late.Add(1)
+    d.mu.Lock()
+    d.statuses[result.StatusCode]++
+    d.mu.Unlock()
+    for {
+        current := d.maxOver.Load()
+        if int64(over) <= current || d.maxOver.CompareAndSwap(current, int64(over)) {
+            break
+        }
+    }
+}
+
+// DeadlineReport hasil -deadline-header
+type DeadlineReport struct {
+    Header    string        `json:"header"`
+    BudgetMs  float64       `json:"budget_ms"`
+    Format    string        `json:"format"`
+    Stamped   int64         `json:"stamped"`
+    Late      int64         `json:"late"` // Response diterima setelah deadline
+    LatePct   float64       `json:"late_pct"`
+    MaxOverMs float64       `json:"max_over_ms"`
+    Statuses  map[int]int64 `json:"late_statuses,omitempty"`
+}
+
+// DeadlineReporter diimplementasikan Requester yang mendukung -deadline-header
+type DeadlineReporter interface {
+    DeadlineReport() *DeadlineReport
+}
+
+func (d *deadlineStamper) report() *DeadlineReport {
+    if d == nil {
+        return nil
+    }
+    r := &DeadlineReport{
+        Header:    d.header,
+        BudgetMs:  float64(d.budget) / float64(time.Millisecond),
+        Format:    d.format,
+        Stamped:   d.stamped.Load(),
+        Late:      d.late.Load(),
+        MaxOverMs: float64(d.maxOver.Load()) / float64(time.Millisecond),
+        Statuses:  make(map[int]int64),
+    }
+    d.mu.Lock()
+    for status, n := range d.statuses {
+        r.Statuses[status] = n
+    }
+    d.mu.Unlock()
+    if r.Stamped > 0 {
+        r.LatePct = float64(r.Late) / float64(r.Stamped) * 100
+    }
+    return r
+}
+
+func printDeadline(w io.Writer, report *Report) {
+    d := report.Deadline
+    if d == nil {
+        return
+    }
+    fmt.Fprintf(w, "\n⏱️  Deadline (header %s, budget %.0f ms):\n", d.Header, d.BudgetMs)
+    icon := "✅"
+    if d.Late > 0 {
+        icon = "⚠️ "
+    }
+    fmt.Fprintf(w, "  %s Response setelah deadline: %d dari %d request (%.2f%%)\n", icon, d.Late, d.Stamped, d.LatePct)
+    if d.Late > 0 {
+        fmt.Fprintf(w, "  Terlambat paling lama:    %.1f ms\n", d.MaxOverMs)
+        statuses := make([]int, 0, len(d.Statuses))
+        for status := range d.Statuses {
+            statuses = append(statuses, status)
+        }
+        sort.Ints(statuses)
+        for _, status := range statuses {
+            fmt.Fprintf(w, "       status %-6d %d\n", status, d.Statuses[status])
+        }
+    }
+}
@@ -0,0 +1,154 @@
+package engine
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "net"
+    "sync"
+    "sync/atomic"
+    "syscall"
+    "time"
+)
+
+// dialAttempt satu percobaan connect ke satu alamat IP
+type dialAttempt struct {
+    network string // tcp4 atau tcp6
+    address string
+    at      time.Time
+}
+
+// dialTrace mencatat semua percobaan connect dalam satu DialContext. Dialer
+// bawaan Go menjalankan Happy Eyeballs (IPv6 dan IPv4 berpacu) dan mencoba
+// alamat berikutnya jika gagal, tetapi hanya mengembalikan hasil akhir.
+type dialTrace struct {
+    mu       sync.Mutex
+    attempts []dialAttempt
+}
+
+type dialTraceKey struct{}
+
+// controlDial dipasang sebagai net.Dialer.ControlContext, dipanggil sekali
+// untuk setiap socket sebelum connect
+func controlDial(ctx context.Context, network, address string, _ syscall.RawConn) error {
+    if trace, ok := ctx.Value(dialTraceKey{}).(*dialTrace); ok {
+        trace.mu.Lock()
+        trace.attempts = append(trace.attempts, dialAttempt{network: network, address: address, at: time.Now()})
+        trace.mu.Unlock()
+    }
+    return nil
+}
+
+// dialStats agregat percobaan dial selama run
+type dialStats struct {
+    dials         atomic.Int64
+    failed        atomic.Int64
+    ipv4          atomic.Int64
+    ipv6          atomic.Int64
+    extraAttempts atomic.Int64 // Percobaan selain yang akhirnya dipakai
+    retried       atomic.Int64 // Dial yang butuh lebih dari satu percobaan
+    fallbacks     atomic.Int64 // Dial yang berhasil di keluarga IP berbeda dari percobaan pertama
+    addedLatency  atomic.Int64 // Total jeda dari percobaan pertama ke percobaan yang menang, ns
+    maxAdded      atomic.Int64
+}
+
+// record mencatat hasil satu dial dari trace-nya
+func (s *dialStats) record(trace *dialTrace, conn net.Conn, err error) {
+    s.dials.Add(1)
+    trace.mu.Lock()
+    attempts := trace.attempts
+    trace.mu.Unlock()
+
+    if err != nil || conn == nil {
+        s.failed.Add(1)
+        return
+    }
+    if len(attempts) == 0 {
+        return
+    }
+
+    winner := -1
+    remote := conn.RemoteAddr().String()
+    for i, attempt := range attempts {
+        if attempt.address == remote {
+            winner = i
+        }
+    }
+    if winner < 0 {
+        winner = len(attempts) - 1
+    }
+    if attempts[winner].network == "tcp6" {
+        s.ipv6.Add(1)
+    } else {
+        s.ipv4.Add(1)
+    }
+    if len(attempts) == 1 {
+        return
+    }
+
+    s.retried.Add(1)
+    s.extraAttempts.Add(int64(len(attempts) - 1))
+    if attempts[winner].network != attempts[0].network {
+        s.fallbacks.Add(1)
+    }
+    added := int64(attempts[winner].at.Sub(attempts[0].at))
+    s.addedLatency.Add(added)
+    for {
+        current := s.maxAdded.Load()
+        if added <= current || s.maxAdded.CompareAndSwap(current, added) {
+            break
+        }
+    }
+}
+
+// DialReport ringkasan percobaan dial, untuk mendeteksi dual-stack yang
+// salah konfigurasi (AAAA record tanpa route IPv6 dan sejenisnya)
+type DialReport struct {
+    Dials         int64   `json:"dials"`
+    Failed        int64   `json:"failed"`
+    IPv4          int64   `json:"ipv4"`
+    IPv6          int64   `json:"ipv6"`
+    ExtraAttempts int64   `json:"extra_attempts"`
+    Retried       int64   `json:"retried"`
+    Fallbacks     int64   `json:"fallbacks"`
+    FallbackRate  float64 `json:"fallback_rate"` // Persen dial yang pindah keluarga IP
+    AvgAddedMs    float64 `json:"avg_added_ms"`  // Rata-rata jeda tambahan pada dial yang butuh lebih dari satu percobaan
+    MaxAddedMs    float64 `json:"max_added_ms"`
+    Warning       string  `json:"warning,omitempty"`
+}
+
+func (s *dialStats) report() *DialReport {
+    r := &DialReport{
+        Dials:         s.dials.Load(),
+        Failed:        s.failed.Load(),
+        IPv4:          s.ipv4.Load(),
+        IPv6:          s.ipv6.Load(),
+        ExtraAttempts: s.extraAttempts.Load(),
+        Retried:       s.retried.Load(),
+        Fallbacks:     s.fallbacks.Load(),
+        MaxAddedMs:    durationMs(time.Duration(s.maxAdded.Load())),
+    }
+    if r.Dials == 0 {
+        return nil
+    }
+    r.FallbackRate = float64(r.Fallbacks) / float64(r.Dials) * 100
+    if r.Retried > 0 {
+        r.AvgAddedMs = durationMs(time.Duration(s.addedLatency.Load() / r.Retried))
+        r.Warning = fmt.Sprintf("%d dial butuh lebih dari satu percobaan (%d fallback IPv6/IPv4), connect time bertambah rata-rata %.1f ms: cek AAAA/A record dan routing dual-stack target",
+            r.Retried, r.Fallbacks, r.AvgAddedMs)
+    }
+    return r
+}
+
+func printDials(w io.Writer, d *DialReport) {
+    if d == nil {
+        return
+    }
+    fmt.Fprintf(w, "  Dial IPv4 / IPv6:      %d / %d (%d gagal)\n", d.IPv4, d.IPv6, d.Failed)
+    if d.Retried > 0 {
+        fmt.Fprintf(w, "  Dial > 1 percobaan:    %d (%d percobaan tambahan)\n", d.Retried, d.ExtraAttempts)
+        fmt.Fprintf(w, "  Fallback IP family:    %d (%.1f%%)\n", d.Fallbacks, d.FallbackRate)
+        fmt.Fprintf(w, "  Tambahan connect time: avg %.1f ms, max %.1f ms\n", d.AvgAddedMs, d.MaxAddedMs)
+        fmt.Fprintf(w, "  ⚠️  %s\n", d.Warning)
+    }
+}
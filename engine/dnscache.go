@@ -0,0 +1,377 @@
+package engine
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "io"
+    "math/rand/v2"
+    "net"
+    "os"
+    "slices"
+    "sort"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "golang.org/x/net/dns/dnsmessage"
+)
+
+// Cara resolve nama host target (-dns)
+const (
+    dnsSystem  = "system"  // Resolver bawaan Go di setiap dial, tanpa statistik
+    dnsOnce    = "once"    // Resolve sekali, dipakai sepanjang run
+    dnsTTL     = "ttl"     // Cache sesuai TTL record, resolve ulang setelah kedaluwarsa
+    dnsRequest = "request" // Query ke nameserver di setiap koneksi baru, tanpa cache
+)
+
+// dnsResolver resolver nama host target dengan cache yang bisa diatur, untuk
+// menguji failover berbasis DNS dan weighted record. Mode ttl dan request
+// mengirim query langsung ke nameserver agar TTL record terbaca dan cache
+// OS tidak ikut campur.
+type dnsResolver struct {
+    mode   string
+    server string // host:port nameserver untuk mode ttl dan request
+    out    io.Writer
+
+    mu    sync.Mutex
+    hosts map[string]*dnsHost
+    rng   *rand.Rand // Posisi awal IP di dial, dari -seed; dijaga mu
+
+    lookups    atomic.Int64
+    reresolves atomic.Int64 // Lookup ulang untuk host yang sudah pernah di-resolve
+    changes    atomic.Int64 // Lookup ulang yang mengubah daftar IP
+    failures   atomic.Int64
+
+    failover *failoverTracker // Opsional, -dns-switch atau -control-addr
+}
+
+// dnsHost hasil resolve satu nama host
+type dnsHost struct {
+    mu       sync.Mutex
+    ips      []net.IP
+    override []net.IP // IP hasil pengalihan failover, menggantikan hasil resolve
+    ttl      time.Duration
+    expires  time.Time
+    lookups  int64
+    changes  int64
+}
+
+// newDNSResolver nil untuk -dns system tanpa -dns-switch dan -control-addr.
+// Pengalihan failover butuh resolver sendiri, jadi pada -dns system resolver
+// OS dipanggil di setiap koneksi baru lewat resolver ini.
+func newDNSResolver(config *Config) (*dnsResolver, error) {
+    failover := config.DNSSwitch != "" || config.ControlAddr != ""
+    if config.DNSMode == dnsSystem && !failover {
+        return nil, nil
+    }
+    r := &dnsResolver{mode: config.DNSMode, hosts: make(map[string]*dnsHost), rng: cacheRand(config.Seed, 0), out: config.out}
+    if failover {
+        schedule, err := parseDNSSwitches(config.DNSSwitch, targetHost(config.URL))
+        if err != nil {
+            return nil, fmt.Errorf("-dns-switch: %w", err)
+        }
+        r.failover = &failoverTracker{schedule: schedule}
+    }
+    if r.mode == dnsTTL || r.mode == dnsRequest {
+        r.server = config.DNSServer
+        if r.server == "" {
+            server, err := systemNameserver()
+            if err != nil {
+                return nil, fmt.Errorf("-dns %s: %w, isi -dns-server", r.mode, err)
+            }
+            r.server = server
+        }
+        if _, _, err := net.SplitHostPort(r.server); err != nil {
+            r.server = net.JoinHostPort(r.server, "53")
+        }
+    }
+    return r, nil
+}
+
+// systemNameserver nameserver pertama dari /etc/resolv.conf
+func systemNameserver() (string, error) {
+    f, err := os.Open("/etc/resolv.conf")
+    if err != nil {
+        return "", fmt.Errorf("nameserver sistem tidak terbaca: %w", err)
+    }
+    defer f.Close()
+    scanner := bufio.NewScanner(f)
+    for scanner.Scan() {
+        fields := strings.Fields(scanner.Text())
+        if len(fields) >= 2 && fields[0] == "nameserver" {
+            return net.JoinHostPort(fields[1], "53"), nil
+        }
+    }
+    return "", fmt.Errorf("tidak ada nameserver di /etc/resolv.conf")
+}
+
+// dial membuka koneksi ke addr dengan IP dari resolver. IP dicoba berurutan
+// mulai dari posisi acak (berulang dengan -seed yang sama) agar beban
+// tersebar ke semua record.
+func (r *dnsResolver) dial(ctx context.Context, dialer *net.Dialer, network, addr string) (net.Conn, error) {
+    host, port, err := net.SplitHostPort(addr)
+    if err != nil || net.ParseIP(host) != nil {
+        return dialer.DialContext(ctx, network, addr)
+    }
+    ips, err := r.lookup(ctx, host)
+    if err != nil {
+        return nil, err
+    }
+    r.mu.Lock()
+    offset := r.rng.IntN(len(ips))
+    r.mu.Unlock()
+    var lastErr error
+    for i := range ips {
+        conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ips[(offset+i)%len(ips)].String(), port))
+        if err == nil {
+            return conn, nil
+        }
+        lastErr = err
+        if ctx.Err() != nil {
+            break
+        }
+    }
+    return nil, lastErr
+}
+
+// lookup daftar IP host sesuai mode cache
+func (r *dnsResolver) lookup(ctx context.Context, host string) ([]net.IP, error) {
+    r.mu.Lock()
+    h := r.hosts[host]
+    if h == nil {
+        h = &dnsHost{}
+        r.hosts[host] = h
+    }
+    r.mu.Unlock()
+    if ips := h.overridden(); ips != nil {
+        return ips, nil
+    }
+
+    if r.mode == dnsRequest {
+        // Tanpa cache, query paralel tidak perlu saling menunggu
+        ips, ttl, err := r.resolve(ctx, host)
+        h.mu.Lock()
+        defer h.mu.Unlock()
+        if err != nil {
+            return nil, err
+        }
+        r.update(h, ips, ttl)
+        return ips, nil
+    }
+
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    if h.ips != nil && (r.mode == dnsOnce || time.Now().Before(h.expires)) {
+        return h.ips, nil
+    }
+    ips, ttl, err := r.resolve(ctx, host)
+    if err != nil {
+        if h.ips != nil {
+            // Nameserver gagal sesaat: pakai hasil lama daripada gagal dial
+            return h.ips, nil
+        }
+        return nil, err
+    }
+    r.update(h, ips, ttl)
+    return ips, nil
+}
+
+func (h *dnsHost) overridden() []net.IP {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    return h.override
+}
+
+// update menyimpan hasil resolve dan menghitung perubahan IP. Dipanggil
+// dengan h.mu terkunci.
+func (r *dnsResolver) update(h *dnsHost, ips []net.IP, ttl time.Duration) {
+    if h.lookups > 0 {
+        r.reresolves.Add(1)
+        if !sameIPs(h.ips, ips) {
+            r.changes.Add(1)
+            h.changes++
+        }
+    }
+    h.lookups++
+    h.ips, h.ttl, h.expires = ips, ttl, time.Now().Add(ttl)
+}
+
+func (r *dnsResolver) resolve(ctx context.Context, host string) ([]net.IP, time.Duration, error) {
+    r.lookups.Add(1)
+    var ips []net.IP
+    var ttl time.Duration
+    var err error
+    if r.server == "" {
+        var addrs []net.IPAddr
+        addrs, err = net.DefaultResolver.LookupIPAddr(ctx, host)
+        for _, a := range addrs {
+            ips = append(ips, a.IP)
+        }
+    } else {
+        ips, ttl, err = queryNameserver(ctx, r.server, host)
+    }
+    if err == nil && len(ips) == 0 {
+        err = fmt.Errorf("resolve %s: tidak ada record A/AAAA", host)
+    }
+    if err != nil {
+        r.failures.Add(1)
+        return nil, 0, err
+    }
+    sort.Slice(ips, func(i, j int) bool { return ips[i].String() < ips[j].String() })
+    return ips, ttl, nil
+}
+
+// queryNameserver mengirim query A dan AAAA lewat UDP. TTL hasil adalah TTL
+// terkecil dari semua record jawaban, termasuk CNAME.
+func queryNameserver(ctx context.Context, server, host string) ([]net.IP, time.Duration, error) {
+    if !strings.HasSuffix(host, ".") {
+        host += "."
+    }
+    name, err := dnsmessage.NewName(host)
+    if err != nil {
+        return nil, 0, fmt.Errorf("nama host %q tidak valid: %w", host, err)
+    }
+    var ips []net.IP
+    ttl := time.Duration(-1)
+    for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+        answers, err := dnsExchange(ctx, server, name, qtype)
+        if err != nil {
+            return nil, 0, fmt.Errorf("resolve %s lewat %s: %w", host, server, err)
+        }
+        for _, answer := range answers {
+            if d := time.Duration(answer.Header.TTL) * time.Second; ttl < 0 || d < ttl {
+                ttl = d
+            }
+            switch body := answer.Body.(type) {
+            case *dnsmessage.AResource:
+                ips = append(ips, net.IP(body.A[:]))
+            case *dnsmessage.AAAAResource:
+                ips = append(ips, net.IP(body.AAAA[:]))
+            }
+        }
+    }
+    return ips, max(ttl, 0), nil
+}
+
+func dnsExchange(ctx context.Context, server string, name dnsmessage.Name, qtype dnsmessage.Type) ([]dnsmessage.Resource, error) {
+    id := uint16(rand.Uint32())
+    query := dnsmessage.Message{
+        Header:    dnsmessage.Header{ID: id, RecursionDesired: true},
+        Questions: []dnsmessage.Question{{Name: name, Type: qtype, Class: dnsmessage.ClassINET}},
+    }
+    packet, err := query.Pack()
+    if err != nil {
+        return nil, err
+    }
+    ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+    defer cancel()
+    var d net.Dialer
+    conn, err := d.DialContext(ctx, "udp", server)
+    if err != nil {
+        return nil, err
+    }
+    defer conn.Close()
+    if deadline, ok := ctx.Deadline(); ok {
+        conn.SetDeadline(deadline)
+    }
+    if _, err := conn.Write(packet); err != nil {
+        return nil, err
+    }
+    buf := make([]byte, 4096)
+    for {
+        n, err := conn.Read(buf)
+        if err != nil {
+            return nil, err
+        }
+        var reply dnsmessage.Message
+        if err := reply.Unpack(buf[:n]); err != nil || reply.Header.ID != id {
+            continue // Bukan jawaban untuk query ini
+        }
+        switch reply.Header.RCode {
+        case dnsmessage.RCodeSuccess:
+            return reply.Answers, nil
+        case dnsmessage.RCodeNameError:
+            return nil, fmt.Errorf("NXDOMAIN")
+        default:
+            return nil, fmt.Errorf("rcode %v", reply.Header.RCode)
+        }
+    }
+}
+
+func sameIPs(a, b []net.IP) bool {
+    return slices.EqualFunc(a, b, func(x, y net.IP) bool { return x.Equal(y) })
+}
+
+// DNSReport resolve nama host target selama run (-dns selain system)
+type DNSReport struct {
+    Mode          string           `json:"mode"`
+    Server        string           `json:"server,omitempty"`
+    Lookups       int64            `json:"lookups"`
+    Reresolutions int64            `json:"reresolutions"` // Lookup ulang untuk host yang sama
+    Changes       int64            `json:"ip_changes"`    // Lookup ulang yang mengubah daftar IP
+    Failures      int64            `json:"failures"`
+    Hosts         []DNSHostReport  `json:"hosts,omitempty"`
+    Failovers     []FailoverReport `json:"failovers,omitempty"`
+}
+
+// DNSHostReport hasil resolve terakhir satu host
+type DNSHostReport struct {
+    Host     string   `json:"host"`
+    IPs      []string `json:"ips"`
+    Override []string `json:"override,omitempty"` // IP hasil pengalihan failover
+    TTLSec   float64  `json:"ttl_s,omitempty"`
+    Lookups  int64    `json:"lookups"`
+    Changes  int64    `json:"ip_changes"`
+}
+
+func (r *dnsResolver) report() *DNSReport {
+    if r == nil {
+        return nil
+    }
+    report := &DNSReport{
+        Mode:          r.mode,
+        Server:        r.server,
+        Lookups:       r.lookups.Load(),
+        Reresolutions: r.reresolves.Load(),
+        Changes:       r.changes.Load(),
+        Failures:      r.failures.Load(),
+    }
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    for name, h := range r.hosts {
+        h.mu.Lock()
+        host := DNSHostReport{Host: name, TTLSec: h.ttl.Seconds(), Lookups: h.lookups, Changes: h.changes}
+        for _, ip := range h.ips {
+            host.IPs = append(host.IPs, ip.String())
+        }
+        for _, ip := range h.override {
+            host.Override = append(host.Override, ip.String())
+        }
+        h.mu.Unlock()
+        report.Hosts = append(report.Hosts, host)
+    }
+    sort.Slice(report.Hosts, func(i, j int) bool { return report.Hosts[i].Host < report.Hosts[j].Host })
+    report.Failovers = r.failoverReport()
+    return report
+}
+
+func printDNS(w io.Writer, d *DNSReport) {
+    if d == nil {
+        return
+    }
+    fmt.Fprintf(w, "  Lookup DNS:            %d (-dns %s), %d resolve ulang, %d perubahan IP, %d gagal\n",
+        d.Lookups, d.Mode, d.Reresolutions, d.Changes, d.Failures)
+    for _, h := range d.Hosts {
+        ttl := ""
+        if d.Mode == dnsTTL || d.Mode == dnsRequest {
+            ttl = fmt.Sprintf(", TTL %.0fs", h.TTLSec)
+        }
+        override := ""
+        if len(h.Override) > 0 {
+            override = fmt.Sprintf(", dialihkan ke %s", strings.Join(h.Override, ", "))
+        }
+        fmt.Fprintf(w, "    %s → %s (%d lookup%s%s)\n", h.Host, strings.Join(h.IPs, ", "), h.Lookups, ttl, override)
+    }
+}
@@ -0,0 +1,151 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "maps"
+    "sort"
+    "sync"
+    "time"
+)
+
+// Drainer diimplementasikan Requester yang bisa membiarkan koneksinya
+// terbuka setelah jadwal selesai untuk mengamati cara server menutupnya
+// (-drain)
+type Drainer interface {
+    Drain(timeout time.Duration) *DrainReport
+}
+
+// drainWatch penutupan koneksi selama drain
+type drainWatch struct {
+    start time.Time
+
+    mu      sync.Mutex
+    closes  []drainClose
+    goAways map[string]int64 // Error code GOAWAY -> jumlah
+}
+
+// drainClose satu koneksi yang tertutup selama drain
+type drainClose struct {
+    kind   string // closedFIN, closedRST atau closedClient
+    at     time.Duration
+    h2     bool
+    goAway bool // Server mengirim GOAWAY sebelum koneksi tertutup
+}
+
+func (w *drainWatch) closed(c *trackedConn, kind string) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    w.closes = append(w.closes, drainClose{
+        kind:   kind,
+        at:     time.Since(w.start),
+        h2:     c.h2,
+        goAway: c.lastGoAway.Load() != nil,
+    })
+}
+
+func (w *drainWatch) goAway(code string) {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    w.goAways[code]++
+}
+
+// drain berhenti mengirim request tapi membiarkan koneksi idle terbuka
+// sampai server menutup semuanya atau timeout habis. Koneksi yang tersisa
+// ditutup client saat requester ditutup.
+func (t *connTracker) drain(timeout time.Duration) *DrainReport {
+    w := &drainWatch{start: time.Now(), goAways: make(map[string]int64)}
+    t.draining.Store(w)
+    open := t.open()
+    fmt.Fprintf(t.out, "🚰 Drain: %d koneksi dibiarkan terbuka, menunggu server menutupnya (maks %v)\n", open, timeout)
+    deadline := w.start.Add(timeout)
+    for t.open() > 0 && time.Now().Before(deadline) {
+        time.Sleep(50 * time.Millisecond)
+    }
+    t.draining.Store(nil)
+    return w.report(timeout, open, t.open())
+}
+
+// DrainReport cara server menutup koneksi saat graceful shutdown
+type DrainReport struct {
+    TimeoutMs     float64          `json:"timeout_ms"`
+    Open          int64            `json:"open"` // Koneksi terbuka saat drain dimulai
+    ServerFIN     int64            `json:"server_fin"`
+    ServerRST     int64            `json:"server_rst"`
+    ClientClosed  int64            `json:"client_closed"` // Ditutup client, misalnya setelah GOAWAY
+    StillOpen     int64            `json:"still_open"`    // Masih terbuka saat drain habis
+    GoAways       map[string]int64 `json:"goaways,omitempty"`
+    H2NoGoAway    int64            `json:"h2_without_goaway"` // Koneksi HTTP/2 tertutup tanpa GOAWAY
+    FirstCloseMs  float64          `json:"first_close_ms"`
+    MedianCloseMs float64          `json:"median_close_ms"`
+    LastCloseMs   float64          `json:"last_close_ms"`
+    Graceful      bool             `json:"graceful"`
+}
+
+func (w *drainWatch) report(timeout time.Duration, open, stillOpen int64) *DrainReport {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    r := &DrainReport{
+        TimeoutMs: durationMs(timeout),
+        Open:      open,
+        StillOpen: stillOpen,
+        GoAways:   maps.Clone(w.goAways), // Callback goAway yang terlambat masih bisa menulis ke map aslinya
+    }
+    var times []time.Duration
+    for _, c := range w.closes {
+        switch c.kind {
+        case closedFIN:
+            r.ServerFIN++
+        case closedRST:
+            r.ServerRST++
+        default:
+            r.ClientClosed++
+        }
+        if c.h2 && !c.goAway {
+            r.H2NoGoAway++
+        }
+        times = append(times, c.at)
+    }
+    if len(times) > 0 {
+        sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+        r.FirstCloseMs = durationMs(times[0])
+        r.MedianCloseMs = durationMs(times[len(times)/2])
+        r.LastCloseMs = durationMs(times[len(times)-1])
+    }
+    // Graceful: semua koneksi ditutup tanpa RST sebelum drain habis, dan
+    // koneksi HTTP/2 diberi GOAWAY lebih dulu
+    r.Graceful = open > 0 && stillOpen == 0 && r.ServerRST == 0 && r.H2NoGoAway == 0
+    return r
+}
+
+func printDrain(w io.Writer, report *Report) {
+    d := report.Drain
+    if d == nil {
+        return
+    }
+    fmt.Fprintln(w, "\n🚰 Drain Koneksi:")
+    if d.Open == 0 {
+        fmt.Fprintln(w, "  Tidak ada koneksi terbuka saat drain dimulai (keep-alive mati?)")
+        return
+    }
+    fmt.Fprintf(w, "  Koneksi saat drain:    %d\n", d.Open)
+    fmt.Fprintf(w, "  Ditutup server (FIN):  %d\n", d.ServerFIN)
+    fmt.Fprintf(w, "  Direset server (RST):  %d\n", d.ServerRST)
+    fmt.Fprintf(w, "  Ditutup client:        %d\n", d.ClientClosed)
+    fmt.Fprintf(w, "  Masih terbuka:         %d (batas drain %.0f ms)\n", d.StillOpen, d.TimeoutMs)
+    for code, n := range d.GoAways {
+        fmt.Fprintf(w, "  %-22s %d\n", "GOAWAY "+code+":", n)
+    }
+    if d.H2NoGoAway > 0 {
+        fmt.Fprintf(w, "  ⚠️  %d koneksi HTTP/2 ditutup tanpa GOAWAY\n", d.H2NoGoAway)
+    }
+    if d.ServerFIN+d.ServerRST+d.ClientClosed > 0 {
+        fmt.Fprintf(w, "  Waktu tutup:           pertama %.0f ms, median %.0f ms, terakhir %.0f ms\n",
+            d.FirstCloseMs, d.MedianCloseMs, d.LastCloseMs)
+    }
+    if d.Graceful {
+        fmt.Fprintln(w, "  ✅ Graceful: semua koneksi ditutup rapi sebelum batas drain")
+    } else {
+        fmt.Fprintln(w, "  ❌ Tidak graceful: ada koneksi yang direset, ditutup tanpa GOAWAY, atau tidak ditutup server")
+    }
+}
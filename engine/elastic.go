@@ -0,0 +1,141 @@
+package engine
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "strings"
+    "time"
+)
+
+// intervalDoc dokumen Elasticsearch untuk metrik satu interval
+type intervalDoc struct {
+    Timestamp time.Time `json:"@timestamp"`
+    RunID     string    `json:"run_id"`
+    URL       string    `json:"url"`
+    Method    string    `json:"method"`
+    IntervalReport
+}
+
+// runDoc dokumen Elasticsearch untuk ringkasan satu run
+type runDoc struct {
+    Timestamp time.Time `json:"@timestamp"`
+    *Report
+}
+
+// indexToElastic mengirim ringkasan run ke index <prefix>-runs dan metrik
+// per interval ke <prefix>-intervals lewat bulk API Elasticsearch/OpenSearch,
+// paling banyak -export-batch dokumen per request. Setiap dokumen punya _id
+// tetap (run ID dan awal interval) sehingga halaman yang dikirim ulang
+// menimpa, bukan menggandakan, dokumen. Autentikasi memakai userinfo pada URL
+// atau env ELASTIC_API_KEY.
+func indexToElastic(config *Config, report *Report, intervals []IntervalReport) error {
+    endpoint, err := url.Parse(config.ElasticURL)
+    if err != nil {
+        return fmt.Errorf("URL elastic tidak valid: %w", err)
+    }
+    user := endpoint.User
+    endpoint.User = nil
+    target := endpoint.String()
+    endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + "/_bulk"
+
+    // Dokumen pertama ringkasan run, sisanya interval
+    writeDoc := func(enc *json.Encoder, i int) error {
+        index, id, doc := config.ElasticIndex+"-runs", report.RunID, interface{}(runDoc{Timestamp: report.StartTime, Report: report})
+        if i > 0 {
+            interval := intervals[i-1]
+            index = config.ElasticIndex + "-intervals"
+            id = fmt.Sprintf("%s-%d", report.RunID, interval.Start.UnixMilli())
+            doc = intervalDoc{
+                Timestamp:      interval.Start,
+                RunID:          report.RunID,
+                URL:            report.URL,
+                Method:         report.Method,
+                IntervalReport: interval,
+            }
+        }
+        action := map[string]map[string]string{"index": {"_index": index, "_id": id}}
+        if err := enc.Encode(action); err != nil {
+            return err
+        }
+        return enc.Encode(doc)
+    }
+
+    pager := newExportPager(config.out, "elastic", config.ExportBatch)
+    err = pager.run(len(intervals)+1, func(ctx context.Context, lo, hi int) error {
+        var body bytes.Buffer
+        enc := json.NewEncoder(&body)
+        for i := lo; i < hi; i++ {
+            if err := writeDoc(enc, i); err != nil {
+                return err
+            }
+        }
+        req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), &body)
+        if err != nil {
+            return err
+        }
+        req.Header.Set("Content-Type", "application/x-ndjson")
+        if user != nil {
+            password, _ := user.Password()
+            req.SetBasicAuth(user.Username(), password)
+        } else if apiKey := os.Getenv("ELASTIC_API_KEY"); apiKey != "" {
+            req.Header.Set("Authorization", "ApiKey "+apiKey)
+        }
+        return postBulk(req)
+    })
+    if err != nil {
+        return err
+    }
+    fmt.Fprintf(config.out, "🔎 %d dokumen diindex ke %s (%s)\n", len(intervals)+1, target, pager.summary())
+    return nil
+}
+
+// postBulk mengirim satu request bulk. Bulk API tetap 200 walau sebagian
+// dokumen gagal; dokumen yang ditolak karena cluster sibuk (429) membuat
+// seluruh halaman diulang.
+func postBulk(req *http.Request) error {
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    respBody, _ := io.ReadAll(resp.Body)
+    if resp.StatusCode/100 != 2 {
+        return newSinkError(resp, fmt.Sprintf("elastic merespon %s: %s", resp.Status, truncate(strings.TrimSpace(string(respBody)), 200)))
+    }
+
+    var bulk struct {
+        Errors bool `json:"errors"`
+        Items  []map[string]struct {
+            Status int             `json:"status"`
+            Error  json.RawMessage `json:"error"`
+        } `json:"items"`
+    }
+    if err := json.Unmarshal(respBody, &bulk); err != nil || !bulk.Errors {
+        return nil
+    }
+    failed, busy := 0, false
+    var first string
+    for _, item := range bulk.Items {
+        for _, result := range item {
+            if result.Status/100 == 2 {
+                continue
+            }
+            failed++
+            busy = busy || result.Status == http.StatusTooManyRequests
+            if first == "" {
+                first = string(result.Error)
+            }
+        }
+    }
+    msg := fmt.Sprintf("%d dokumen gagal diindex oleh elastic: %s", failed, truncate(first, 200))
+    if busy {
+        return &sinkError{status: http.StatusTooManyRequests, msg: msg}
+    }
+    return &sinkError{status: http.StatusBadRequest, msg: msg}
+}
@@ -0,0 +1,188 @@
+package engine
+
+import (
+    "bytes"
+    "crypto/rand"
+    "crypto/tls"
+    "encoding/base64"
+    "encoding/hex"
+    "fmt"
+    "mime"
+    "net"
+    "net/smtp"
+    "os"
+    "strings"
+    "time"
+)
+
+// emailNotifier mengirim report HTML sebagai lampiran email setelah run
+// selesai. Password SMTP dibaca dari env SMTP_PASSWORD.
+type emailNotifier struct {
+    to       []string
+    from     string
+    host     string // host:port
+    user     string
+    password string
+}
+
+func newEmailNotifier(config *Config) *emailNotifier {
+    var to []string
+    for _, addr := range strings.Split(config.EmailTo, ",") {
+        if addr = strings.TrimSpace(addr); addr != "" {
+            to = append(to, addr)
+        }
+    }
+    from := config.EmailFrom
+    if from == "" {
+        from = config.SMTPUser
+    }
+    return &emailNotifier{
+        to:       to,
+        from:     from,
+        host:     config.SMTPHost,
+        user:     config.SMTPUser,
+        password: os.Getenv("SMTP_PASSWORD"),
+    }
+}
+
+func (e *emailNotifier) RunStarted(runID string, config *Config, startTime time.Time) error {
+    return nil
+}
+
+func (e *emailNotifier) RunFinished(report *Report, breached []ThresholdResult) error {
+    if e.from == "" {
+        return fmt.Errorf("email: -email-from atau -smtp-user harus diisi")
+    }
+
+    html, err := report.HTML()
+    if err != nil {
+        return err
+    }
+
+    status := "SELESAI"
+    if len(report.Thresholds) > 0 {
+        status = "PASS"
+        if len(breached) > 0 {
+            status = "FAIL"
+        }
+    }
+    subject := fmt.Sprintf("[loadtest] %s %s %s - %d requests, %.1f req/s", status, report.Method, report.URL, report.TotalRequests, report.RPS)
+
+    var summary strings.Builder
+    fmt.Fprintf(&summary, "Hasil load test %s\n\n", report.RunID)
+    fmt.Fprintf(&summary, "URL:                %s %s\n", report.Method, report.URL)
+    fmt.Fprintf(&summary, "Jadwal:             %s\n", report.Schedule)
+    fmt.Fprintf(&summary, "Total requests:     %d\n", report.TotalRequests)
+    fmt.Fprintf(&summary, "Requests per detik: %.2f\n", report.RPS)
+    fmt.Fprintf(&summary, "Rata-rata latency:  %.2f ms\n", report.AvgLatencyMs)
+    fmt.Fprintf(&summary, "Error rate:         %.2f%%\n", report.ErrorRate)
+    for _, result := range report.Thresholds {
+        mark := "PASS"
+        if !result.Passed {
+            mark = "FAIL"
+        }
+        fmt.Fprintf(&summary, "Threshold %-20s %s (actual %s)\n", result.Threshold, mark, result.actual())
+    }
+    summary.WriteString("\nReport lengkap terlampir.\n")
+
+    msg, err := buildEmail(e.from, e.to, subject, summary.String(), "loadtest-"+report.RunID+".html", html)
+    if err != nil {
+        return err
+    }
+    return e.send(msg)
+}
+
+// buildEmail menyusun pesan MIME multipart berisi teks dan satu lampiran HTML
+func buildEmail(from string, to []string, subject, text, attachmentName string, attachment []byte) ([]byte, error) {
+    boundaryBytes := make([]byte, 12)
+    if _, err := rand.Read(boundaryBytes); err != nil {
+        return nil, err
+    }
+    boundary := "loadtest-" + hex.EncodeToString(boundaryBytes)
+
+    var buf bytes.Buffer
+    fmt.Fprintf(&buf, "From: %s\r\n", from)
+    fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+    fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+    fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+    buf.WriteString("MIME-Version: 1.0\r\n")
+    fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", boundary)
+
+    fmt.Fprintf(&buf, "--%s\r\n", boundary)
+    buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+    buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+    writeBase64Lines(&buf, []byte(text))
+
+    fmt.Fprintf(&buf, "--%s\r\n", boundary)
+    fmt.Fprintf(&buf, "Content-Type: text/html; charset=utf-8; name=%q\r\n", attachmentName)
+    fmt.Fprintf(&buf, "Content-Disposition: attachment; filename=%q\r\n", attachmentName)
+    buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+    writeBase64Lines(&buf, attachment)
+
+    fmt.Fprintf(&buf, "--%s--\r\n", boundary)
+    return buf.Bytes(), nil
+}
+
+// writeBase64Lines menulis data base64 dengan baris maksimal 76 karakter (RFC 2045)
+func writeBase64Lines(buf *bytes.Buffer, data []byte) {
+    encoded := base64.StdEncoding.EncodeToString(data)
+    for len(encoded) > 76 {
+        buf.WriteString(encoded[:76] + "\r\n")
+        encoded = encoded[76:]
+    }
+    buf.WriteString(encoded + "\r\n")
+}
+
+// send mengirim pesan lewat SMTP. Port 465 memakai TLS langsung, port lain
+// memakai STARTTLS jika server mendukung.
+func (e *emailNotifier) send(msg []byte) error {
+    host, port, err := net.SplitHostPort(e.host)
+    if err != nil {
+        return fmt.Errorf("email: -smtp-host harus berformat host:port: %w", err)
+    }
+
+    var auth smtp.Auth
+    if e.user != "" {
+        auth = smtp.PlainAuth("", e.user, e.password, host)
+    }
+
+    if port != "465" {
+        return smtp.SendMail(e.host, auth, e.from, e.to, msg)
+    }
+
+    conn, err := tls.Dial("tcp", e.host, &tls.Config{ServerName: host})
+    if err != nil {
+        return err
+    }
+    client, err := smtp.NewClient(conn, host)
+    if err != nil {
+        conn.Close()
+        return err
+    }
+    defer client.Close()
+
+    if auth != nil {
+        if err := client.Auth(auth); err != nil {
+            return err
+        }
+    }
+    if err := client.Mail(e.from); err != nil {
+        return err
+    }
+    for _, addr := range e.to {
+        if err := client.Rcpt(addr); err != nil {
+            return err
+        }
+    }
+    w, err := client.Data()
+    if err != nil {
+        return err
+    }
+    if _, err := w.Write(msg); err != nil {
+        return err
+    }
+    if err := w.Close(); err != nil {
+        return err
+    }
+    return client.Quit()
+}
@@ -0,0 +1,189 @@
+package engine
+
+import (
+    "encoding/json"
+    "fmt"
+    "io"
+    "sort"
+    "strings"
+    "sync"
+)
+
+// errorReasonLimit jumlah alasan error berbeda yang dilacak, sisanya digabung
+// ke satu baris agar kode dinamis (misalnya berisi ID) tidak menghabiskan
+// memori
+const errorReasonLimit = 500
+
+// errorReasonOther kode baris gabungan setelah errorReasonLimit tercapai
+const errorReasonOther = "(lainnya)"
+
+// Path kode dan pesan error yang dicoba berurutan jika -error-code-path /
+// -error-message-path kosong. Mencakup bentuk umum: {"error": {"code",
+// "message"}}, {"code", "message"}, {"errors": [...]}, problem+json RFC 7807
+// dan OAuth.
+var (
+    errorCodePaths    = []string{"error.code", "error_code", "errorCode", "code", "errors.0.code", "error.type", "type", "error"}
+    errorMessagePaths = []string{"error.message", "message", "errors.0.message", "detail", "title", "error_description", "error.reason", "msg", "error"}
+)
+
+// errorBodies (-error-bodies) membaca body JSON response 4xx/5xx dan
+// mengelompokkan alasan error yang dilaporkan server per status dan kode
+type errorBodies struct {
+    codePaths    []string
+    messagePaths []string
+
+    mu        sync.Mutex
+    responses int64
+    parsed    int64
+    reasons   map[errorReasonKey]*ErrorReason
+}
+
+type errorReasonKey struct {
+    status int
+    code   string
+}
+
+func newErrorBodies(config *Config) *errorBodies {
+    if !config.ErrorBodies {
+        return nil
+    }
+    b := &errorBodies{
+        codePaths:    errorCodePaths,
+        messagePaths: errorMessagePaths,
+        reasons:      make(map[errorReasonKey]*ErrorReason),
+    }
+    if config.ErrorCodePath != "" {
+        b.codePaths = []string{strings.TrimPrefix(config.ErrorCodePath, "$.")}
+    }
+    if config.ErrorMsgPath != "" {
+        b.messagePaths = []string{strings.TrimPrefix(config.ErrorMsgPath, "$.")}
+    }
+    return b
+}
+
+// observe membaca body response error. Response tanpa body JSON tetap
+// dihitung agar porsi yang tidak terbaca terlihat.
+func (b *errorBodies) observe(result Result) {
+    if b == nil || result.StatusCode < 400 {
+        return
+    }
+    code, message, ok := b.parse(result.ErrorBody)
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    b.responses++
+    if !ok {
+        return
+    }
+    b.parsed++
+    key := errorReasonKey{status: result.StatusCode, code: code}
+    reason := b.reasons[key]
+    if reason == nil {
+        if len(b.reasons) >= errorReasonLimit {
+            key.code, message = errorReasonOther, ""
+            reason = b.reasons[key]
+        }
+        if reason == nil {
+            reason = &ErrorReason{Status: key.status, Code: key.code, Message: message}
+            b.reasons[key] = reason
+        }
+    }
+    reason.Count++
+}
+
+// parse mengambil kode dan pesan error dari body JSON. Jika server tidak
+// mengirim kode, pesan dipakai sebagai kode agar tetap bisa dikelompokkan.
+func (b *errorBodies) parse(body string) (code, message string, ok bool) {
+    var doc any
+    if err := json.Unmarshal([]byte(body), &doc); err != nil {
+        return "", "", false
+    }
+    if _, isObject := doc.(map[string]any); !isObject {
+        return "", "", false
+    }
+    code = firstJSONValue(doc, b.codePaths)
+    message = firstJSONValue(doc, b.messagePaths)
+    if code == "" && message == "" {
+        return "", "", false
+    }
+    if code == "" || code == message {
+        code, message = truncate(message, 120), ""
+    }
+    return code, truncate(message, 200), true
+}
+
+// firstJSONValue nilai skalar pertama yang ada dari paths. Object dan array
+// dilewati, misalnya "error" yang berisi object {"code", "message"}.
+func firstJSONValue(doc any, paths []string) string {
+    for _, path := range paths {
+        v, err := jsonPath(doc, path)
+        if err != nil || v == "" || v == "null" || strings.HasPrefix(v, "{") || strings.HasPrefix(v, "[") {
+            continue
+        }
+        return v
+    }
+    return ""
+}
+
+// ErrorReason satu alasan error dari body response server
+type ErrorReason struct {
+    Status  int     `json:"status"`
+    Code    string  `json:"code"`
+    Message string  `json:"message,omitempty"` // Contoh pesan pertama
+    Count   int64   `json:"count"`
+    Pct     float64 `json:"pct"` // Persen dari response error yang terbaca
+}
+
+// ErrorBodyReport hasil -error-bodies
+type ErrorBodyReport struct {
+    Responses int64         `json:"responses"` // Response 4xx/5xx
+    Parsed    int64         `json:"parsed"`    // Body JSON dengan kode atau pesan error
+    Reasons   []ErrorReason `json:"reasons,omitempty"`
+}
+
+func (b *errorBodies) report() *ErrorBodyReport {
+    if b == nil {
+        return nil
+    }
+    b.mu.Lock()
+    defer b.mu.Unlock()
+    r := &ErrorBodyReport{Responses: b.responses, Parsed: b.parsed}
+    for _, reason := range b.reasons {
+        entry := *reason
+        entry.Pct = float64(entry.Count) / float64(b.parsed) * 100
+        r.Reasons = append(r.Reasons, entry)
+    }
+    sort.Slice(r.Reasons, func(i, j int) bool {
+        if r.Reasons[i].Count != r.Reasons[j].Count {
+            return r.Reasons[i].Count > r.Reasons[j].Count
+        }
+        return r.Reasons[i].Code < r.Reasons[j].Code
+    })
+    return r
+}
+
+func printErrorBodies(w io.Writer, report *Report) {
+    r := report.ErrorBodies
+    if r == nil || r.Responses == 0 {
+        return
+    }
+    fmt.Fprintf(w, "\n🧾 Alasan Error dari Server (%d dari %d response 4xx/5xx terbaca):\n", r.Parsed, r.Responses)
+    for i, reason := range r.Reasons {
+        if i == 10 {
+            fmt.Fprintf(w, "     ... %d alasan lain\n", len(r.Reasons)-i)
+            break
+        }
+        fmt.Fprintf(w, "  %3d %-32s %8d (%5.1f%%)", reason.Status, truncate(reason.Code, 32), reason.Count, reason.Pct)
+        if reason.Message != "" {
+            fmt.Fprintf(w, "  %s", truncate(reason.Message, 60))
+        }
+        fmt.Fprintln(w)
+    }
+}
+
+// TopErrorReasons alasan error terbanyak untuk tabel report
+func (r *Report) TopErrorReasons() []ErrorReason {
+    if r.ErrorBodies == nil {
+        return nil
+    }
+    return r.ErrorBodies.Reasons[:min(len(r.ErrorBodies.Reasons), 20)]
+}
@@ -0,0 +1,220 @@
+package engine
+
+import (
+    "bytes"
+    "context"
+    "crypto/tls"
+    "errors"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "strings"
+    "sync/atomic"
+    "syscall"
+    "time"
+)
+
+const (
+    errorSampleSize     = 512  // Byte body response gagal yang disimpan sebagai contoh
+    errorBodyMax        = 8192 // Byte body response gagal yang disimpan untuk dibaca -error-bodies
+    errorStreamBuffer   = 4096 // Event yang antre sebelum event baru dibuang
+    errorSamplesPerPost = 20   // Contoh event per kategori dalam satu kiriman, sisanya cukup dihitung
+)
+
+// errorCategory mengelompokkan request gagal, kosong jika request sukses
+func errorCategory(r Result) string {
+    if r.Challenge != "" {
+        return "waf_challenge"
+    }
+    if err := r.Err; err != nil {
+        var dnsErr *net.DNSError
+        var netErr net.Error
+        var certErr *tls.CertificateVerificationError
+        var assertErr *assertionError
+        var idemErr *idempotencyError
+        switch {
+        case errors.Is(err, errDataExhausted):
+            return ""
+        case errors.As(err, &assertErr):
+            return "assertion"
+        case errors.As(err, &idemErr):
+            return "idempotency"
+        case errors.As(err, &dnsErr):
+            return "dns"
+        case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+            return "timeout"
+        case errors.Is(err, syscall.ECONNREFUSED), strings.Contains(err.Error(), "connection refused"):
+            return "connection_refused"
+        case errors.As(err, &certErr), strings.Contains(err.Error(), "tls:"):
+            return "tls"
+        }
+        switch classifyConnError(err) {
+        case "rst":
+            return "connection_reset"
+        case "eof":
+            return "connection_closed"
+        }
+        return "other"
+    }
+    switch {
+    case r.StatusCode >= 500:
+        return "http_5xx"
+    case r.StatusCode >= 400:
+        return "http_4xx"
+    }
+    return ""
+}
+
+// drainBody membuang body response agar koneksi bisa di-reuse. Untuk
+// response gagal (status >= 400) sebagian awal body disimpan sebagai contoh.
+func drainBody(body io.Reader, status int) (int64, string) {
+    if status < 400 {
+        n, _ := io.Copy(io.Discard, body)
+        return n, ""
+    }
+    var sample bytes.Buffer
+    n, _ := io.Copy(&sample, io.LimitReader(body, errorBodyMax))
+    rest, _ := io.Copy(io.Discard, body)
+    return n + rest, sample.String()
+}
+
+// errorSample potongan awal body response gagal, cukup panjang agar body
+// JSON error bisa dibaca utuh oleh -error-bodies
+func errorSample(body []byte) string {
+    if len(body) > errorBodyMax {
+        body = body[:errorBodyMax]
+    }
+    return string(body)
+}
+
+// ErrorEvent satu request gagal yang dikirim ke -error-webhook
+type ErrorEvent struct {
+    Time       time.Time `json:"time"`
+    Category   string    `json:"category"`
+    StatusCode int       `json:"status_code,omitempty"`
+    Error      string    `json:"error,omitempty"`
+    LatencyMs  float64   `json:"latency_ms"`
+    Sample     string    `json:"sample,omitempty"` // Potongan body response
+}
+
+// errorBatch payload satu kiriman webhook
+type errorBatch struct {
+    RunID   string           `json:"run_id"`
+    URL     string           `json:"url"`
+    Counts  map[string]int64 `json:"counts"` // Jumlah event per kategori dalam batch ini
+    Events  []ErrorEvent     `json:"events"`
+    Dropped int64            `json:"dropped,omitempty"` // Event yang dibuang karena antrean penuh
+}
+
+// errorStream mengirim event error ke webhook selama test berjalan, dikumpulkan
+// per interval agar webhook tidak dibanjiri saat target sedang down
+type errorStream struct {
+    endpoint string
+    interval time.Duration
+    runID    string
+    url      string
+    scrub    bool // -scrub: tanpa contoh body dan query string URL
+    client   *http.Client
+    out      io.Writer
+
+    events  chan ErrorEvent
+    dropped atomic.Int64
+    sent    atomic.Int64
+    done    chan struct{}
+}
+
+func startErrorStream(config *Config, runID string) *errorStream {
+    s := &errorStream{
+        endpoint: config.ErrorWebhook,
+        interval: config.ErrorWebhookInterval,
+        runID:    runID,
+        url:      config.URL,
+        scrub:    config.Scrub,
+        client:   &http.Client{Timeout: 10 * time.Second},
+        out:      config.out,
+        events:   make(chan ErrorEvent, errorStreamBuffer),
+        done:     make(chan struct{}),
+    }
+    go s.loop()
+    return s
+}
+
+// add mencatat result jika gagal, tanpa pernah memblok worker
+func (s *errorStream) add(r Result) {
+    category := errorCategory(r)
+    if category == "" {
+        return
+    }
+    event := ErrorEvent{
+        Time:       r.Start,
+        Category:   category,
+        StatusCode: r.StatusCode,
+        LatencyMs:  durationMs(r.Duration),
+        Sample:     r.ErrorBody[:min(len(r.ErrorBody), errorSampleSize)],
+    }
+    if r.Err != nil {
+        event.Error = r.Err.Error()
+    }
+    if s.scrub {
+        event.Sample = ""
+        event.Error = scrubText(event.Error)
+    }
+    select {
+    case s.events <- event:
+    default:
+        s.dropped.Add(1)
+    }
+}
+
+func (s *errorStream) loop() {
+    defer close(s.done)
+    ticker := time.NewTicker(s.interval)
+    defer ticker.Stop()
+
+    url := s.url
+    if s.scrub {
+        url = scrubURL(url)
+    }
+    batch := errorBatch{RunID: s.runID, URL: url, Counts: make(map[string]int64)}
+    flush := func() {
+        batch.Dropped = s.dropped.Swap(0)
+        if len(batch.Counts) == 0 && batch.Dropped == 0 {
+            return
+        }
+        ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        if err := postJSON(ctx, s.client, s.endpoint, nil, batch); err != nil {
+            fmt.Fprintf(s.out, "⚠️  Error webhook: %v\n", err)
+        } else {
+            for _, n := range batch.Counts {
+                s.sent.Add(n)
+            }
+        }
+        cancel()
+        batch.Counts = make(map[string]int64)
+        batch.Events = nil
+    }
+
+    for {
+        select {
+        case event, ok := <-s.events:
+            if !ok {
+                flush()
+                return
+            }
+            batch.Counts[event.Category]++
+            if batch.Counts[event.Category] <= errorSamplesPerPost {
+                batch.Events = append(batch.Events, event)
+            }
+        case <-ticker.C:
+            flush()
+        }
+    }
+}
+
+// Close mengirim sisa event dan menunggu kiriman terakhir selesai
+func (s *errorStream) Close() {
+    close(s.events)
+    <-s.done
+    fmt.Fprintf(s.out, "📣 %d event error dikirim ke webhook\n", s.sent.Load())
+}
@@ -0,0 +1,159 @@
+package engine
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "strconv"
+    "time"
+)
+
+const (
+    exportRetries    = 5                // Percobaan ulang per halaman sebelum ekspor dianggap gagal
+    exportBackoff    = time.Second      // Jeda retry pertama, berlipat dua di setiap percobaan
+    exportBackoffMax = 30 * time.Second // Jeda retry terpanjang
+)
+
+// sinkError response sink eksternal yang bukan 2xx. 429 dan 5xx boleh
+// diulang; 413 membuat halaman dipecah menjadi lebih kecil.
+type sinkError struct {
+    status     int
+    retryAfter time.Duration // Dari header Retry-After, nol jika tidak ada
+    msg        string
+}
+
+func (e *sinkError) Error() string { return e.msg }
+
+func (e *sinkError) retryable() bool {
+    return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// newSinkError membuat sinkError dari response gagal
+func newSinkError(resp *http.Response, msg string) *sinkError {
+    e := &sinkError{status: resp.StatusCode, msg: msg}
+    if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs > 0 {
+        e.retryAfter = time.Duration(secs) * time.Second
+    } else if at, err := http.ParseTime(resp.Header.Get("Retry-After")); err == nil {
+        e.retryAfter = time.Until(at)
+    }
+    return e
+}
+
+// exportPager mengirim data hasil run ke sink eksternal per halaman. Halaman
+// berikutnya baru dikirim setelah halaman sebelumnya diterima, sehingga
+// sink yang lambat atau membalas 429 otomatis memperlambat pengiriman
+// (backpressure) dan run berjam-jam tidak dikirim dalam satu body raksasa.
+type exportPager struct {
+    sink     string // Nama sink untuk pesan, misalnya "elastic"
+    pageSize int
+    out      io.Writer
+
+    pages   int
+    retries int
+}
+
+func newExportPager(out io.Writer, sink string, pageSize int) *exportPager {
+    return &exportPager{sink: sink, pageSize: max(pageSize, 1), out: out}
+}
+
+// run memanggil send untuk item [lo, hi) sampai total item terkirim,
+// minimal satu halaman walau total nol. Halaman yang ditolak karena terlalu
+// besar (413) dipecah dua; error yang bisa diulang dicoba lagi dengan
+// backoff. send harus aman dikirim ulang.
+func (p *exportPager) run(total int, send func(ctx context.Context, lo, hi int) error) error {
+    size := p.pageSize
+    for lo := 0; ; {
+        hi := min(lo+size, total)
+        err := p.retry(func(ctx context.Context) error { return send(ctx, lo, hi) })
+        var sinkErr *sinkError
+        if errors.As(err, &sinkErr) && sinkErr.status == http.StatusRequestEntityTooLarge && hi-lo > 1 {
+            size = max((hi-lo)/2, 1)
+            fmt.Fprintf(p.out, "⚠️  %s: halaman terlalu besar, dikecilkan menjadi %d item\n", p.sink, size)
+            continue
+        }
+        if err != nil {
+            return fmt.Errorf("item %d-%d dari %d: %w", lo, hi, total, err)
+        }
+        p.pages++
+        if lo = hi; lo >= total {
+            return nil
+        }
+    }
+}
+
+// retry menjalankan satu pengiriman dengan backoff eksponensial. Error
+// jaringan dan sinkError yang retryable diulang; Retry-After dari sink
+// didahulukan dari jeda backoff.
+func (p *exportPager) retry(post func(ctx context.Context) error) error {
+    backoff := exportBackoff
+    for attempt := 1; ; attempt++ {
+        ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+        err := post(ctx)
+        cancel()
+        if err == nil {
+            return nil
+        }
+        var sinkErr *sinkError
+        if errors.As(err, &sinkErr) && !sinkErr.retryable() || attempt > exportRetries {
+            return err
+        }
+        wait := backoff
+        if sinkErr != nil && sinkErr.retryAfter > 0 {
+            wait = min(sinkErr.retryAfter, exportBackoffMax)
+        }
+        fmt.Fprintf(p.out, "⚠️  %s: %v, coba lagi dalam %v (%d/%d)\n", p.sink, err, wait, attempt, exportRetries)
+        time.Sleep(wait)
+        backoff = min(backoff*2, exportBackoffMax)
+        p.retries++
+    }
+}
+
+// summary ringkasan pengiriman untuk pesan sukses
+func (p *exportPager) summary() string {
+    if p.retries == 0 {
+        return fmt.Sprintf("%d halaman", p.pages)
+    }
+    return fmt.Sprintf("%d halaman, %d retry", p.pages, p.retries)
+}
+
+// resultPage payload satu kiriman -result-webhook. Penerima bisa
+// menyusun ulang run dari offset dan menyimpan ringkasan dari halaman
+// pertama; header Idempotency-Key sama saat halaman dikirim ulang.
+type resultPage struct {
+    RunID     string           `json:"run_id"`
+    URL       string           `json:"url"`
+    Offset    int              `json:"offset"`            // Index interval pertama di halaman ini
+    Total     int              `json:"total"`             // Jumlah interval seluruh run
+    Final     bool             `json:"final"`             // Halaman terakhir
+    Summary   *Report          `json:"summary,omitempty"` // Hanya di halaman pertama
+    Intervals []IntervalReport `json:"intervals"`
+}
+
+// sendResultWebhook mengirim ringkasan run dan metrik per interval ke
+// -result-webhook per halaman -export-batch interval
+func sendResultWebhook(config *Config, report *Report, intervals []IntervalReport) error {
+    client := &http.Client{Timeout: 30 * time.Second}
+    pager := newExportPager(config.out, "result webhook", config.ExportBatch)
+    err := pager.run(len(intervals), func(ctx context.Context, lo, hi int) error {
+        page := resultPage{
+            RunID:     report.RunID,
+            URL:       report.URL,
+            Offset:    lo,
+            Total:     len(intervals),
+            Final:     hi == len(intervals),
+            Intervals: intervals[lo:hi],
+        }
+        if lo == 0 {
+            page.Summary = report
+        }
+        headers := map[string]string{"Idempotency-Key": fmt.Sprintf("%s-%d", report.RunID, lo)}
+        return postJSON(ctx, client, config.ResultWebhook, headers, page)
+    })
+    if err != nil {
+        return err
+    }
+    fmt.Fprintf(config.out, "📤 Hasil run (%d interval) dikirim ke result webhook (%s)\n", len(intervals), pager.summary())
+    return nil
+}
@@ -0,0 +1,363 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "net"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+)
+
+// failoverMoved porsi request ke IP baru agar trafik dianggap sudah pindah
+const failoverMoved = 0.95
+
+// failoverBaseline rentang sebelum pengalihan untuk error rate acuan
+const failoverBaseline = 10 * time.Second
+
+// failoverTolerance kenaikan error rate (poin persen) di atas acuan yang
+// masih dianggap pulih
+const failoverTolerance = 1.0
+
+// DNSSwitcher diimplementasikan Requester yang resolve host target lewat
+// dnsResolver, sehingga IP target bisa dialihkan di tengah run
+type DNSSwitcher interface {
+    DNSResolver() *dnsResolver
+}
+
+// dnsSwitchSpec satu pengalihan terjadwal dari -dns-switch
+type dnsSwitchSpec struct {
+    at   time.Duration
+    host string
+    ips  []net.IP
+}
+
+// parseDNSSwitches membaca -dns-switch berformat 'durasi=[host@]IP[|IP],...',
+// misalnya '30s=10.0.0.2,90s=api.example.com@10.0.0.1|10.0.0.3'. Host default
+// adalah host dari -u.
+func parseDNSSwitches(spec, defaultHost string) ([]dnsSwitchSpec, error) {
+    var switches []dnsSwitchSpec
+    for _, entry := range strings.Split(spec, ",") {
+        entry = strings.TrimSpace(entry)
+        if entry == "" {
+            continue
+        }
+        at, target, ok := strings.Cut(entry, "=")
+        if !ok {
+            return nil, fmt.Errorf("%q bukan durasi=IP", entry)
+        }
+        d, err := time.ParseDuration(strings.TrimSpace(at))
+        if err != nil || d < 0 {
+            return nil, fmt.Errorf("durasi %q tidak valid", at)
+        }
+        host := defaultHost
+        if h, rest, ok := strings.Cut(target, "@"); ok {
+            host, target = strings.TrimSpace(h), rest
+        }
+        if host == "" {
+            return nil, fmt.Errorf("%q: host tidak diketahui, tulis host@IP", entry)
+        }
+        ips, err := parseSwitchIPs(strings.Split(target, "|"))
+        if err != nil {
+            return nil, fmt.Errorf("%q: %w", entry, err)
+        }
+        switches = append(switches, dnsSwitchSpec{at: d, host: host, ips: ips})
+    }
+    return switches, nil
+}
+
+func parseSwitchIPs(values []string) ([]net.IP, error) {
+    var ips []net.IP
+    for _, v := range values {
+        ip := net.ParseIP(strings.TrimSpace(v))
+        if ip == nil {
+            return nil, fmt.Errorf("IP %q tidak valid", v)
+        }
+        ips = append(ips, ip)
+    }
+    if len(ips) == 0 {
+        return nil, fmt.Errorf("daftar IP kosong")
+    }
+    return ips, nil
+}
+
+// targetHost nama host dari -u, kosong jika -u berupa IP atau tidak ada
+func targetHost(rawURL string) string {
+    u, err := url.Parse(rawURL)
+    if err != nil || net.ParseIP(u.Hostname()) != nil {
+        return ""
+    }
+    return u.Hostname()
+}
+
+// failoverTracker mencatat pengalihan IP target dan trafik per detik untuk
+// mengukur berapa cepat trafik pindah dan error rate pulih
+type failoverTracker struct {
+    schedule []dnsSwitchSpec
+
+    mu       sync.Mutex
+    start    time.Time
+    timers   []*time.Timer
+    switches []*dnsSwitch
+    buckets  []failoverBucket // Per detik sejak test dimulai
+}
+
+// dnsSwitch satu pengalihan yang sudah diterapkan
+type dnsSwitch struct {
+    at       time.Duration
+    host     string
+    from, to []net.IP
+    trigger  string
+    firstNew time.Duration // -1 sampai ada request ke IP baru
+}
+
+// failoverBucket request yang selesai dalam satu detik. onNew dan onOld
+// dihitung terhadap pengalihan terakhir saat request selesai.
+type failoverBucket struct {
+    requests, errors int64
+    onNew, onOld     int64
+}
+
+// startFailover menjalankan jadwal -dns-switch mulai dari start
+func (r *dnsResolver) startFailover(start time.Time) {
+    if r == nil || r.failover == nil {
+        return
+    }
+    f := r.failover
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.start = start
+    for _, s := range f.schedule {
+        f.timers = append(f.timers, time.AfterFunc(time.Until(start.Add(s.at)), func() {
+            r.switchTo(s.host, s.ips, "jadwal")
+        }))
+    }
+}
+
+// stopFailover membatalkan pengalihan terjadwal yang belum berjalan
+func (r *dnsResolver) stopFailover() {
+    if r == nil || r.failover == nil {
+        return
+    }
+    r.failover.mu.Lock()
+    defer r.failover.mu.Unlock()
+    for _, t := range r.failover.timers {
+        t.Stop()
+    }
+}
+
+// switchTo mengalihkan host ke ips mulai koneksi baru berikutnya. Koneksi
+// keep-alive yang sudah terbuka tetap ke IP lama sampai ditutup.
+func (r *dnsResolver) switchTo(host string, ips []net.IP, trigger string) {
+    r.mu.Lock()
+    h := r.hosts[host]
+    if h == nil {
+        h = &dnsHost{}
+        r.hosts[host] = h
+    }
+    r.mu.Unlock()
+
+    h.mu.Lock()
+    from := h.override
+    if from == nil {
+        from = h.ips
+    }
+    h.override = ips
+    h.mu.Unlock()
+
+    f := r.failover
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if f.start.IsZero() {
+        return
+    }
+    at := time.Since(f.start)
+    f.switches = append(f.switches, &dnsSwitch{at: at, host: host, from: from, to: ips, trigger: trigger, firstNew: -1})
+    fmt.Fprintf(r.out, "🔀 [%v] DNS %s dialihkan: %s → %s (%s)\n", at.Round(time.Second), host,
+        joinIPs(from), joinIPs(ips), trigger)
+}
+
+// observe mencatat satu request yang selesai. remote alamat server dari
+// httptrace GotConn, nil jika request tidak mendapat koneksi.
+func (r *dnsResolver) observe(remote net.Addr, result Result) {
+    if r == nil || r.failover == nil {
+        return
+    }
+    f := r.failover
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if f.start.IsZero() {
+        return
+    }
+    elapsed := time.Since(f.start)
+    sec := int(elapsed / time.Second)
+    for len(f.buckets) <= sec {
+        f.buckets = append(f.buckets, failoverBucket{})
+    }
+    b := &f.buckets[sec]
+    b.requests++
+    if result.Err != nil {
+        b.errors++
+    }
+    if len(f.switches) == 0 || remote == nil {
+        return
+    }
+    sw := f.switches[len(f.switches)-1]
+    ip := remoteIP(remote)
+    switch {
+    case containsIP(sw.to, ip):
+        b.onNew++
+        if sw.firstNew < 0 {
+            sw.firstNew = elapsed - sw.at
+        }
+    case containsIP(sw.from, ip):
+        b.onOld++
+    }
+}
+
+func remoteIP(addr net.Addr) net.IP {
+    switch a := addr.(type) {
+    case *net.TCPAddr:
+        return a.IP
+    case *net.UDPAddr:
+        return a.IP
+    }
+    host, _, err := net.SplitHostPort(addr.String())
+    if err != nil {
+        return nil
+    }
+    return net.ParseIP(host)
+}
+
+func containsIP(ips []net.IP, ip net.IP) bool {
+    for _, candidate := range ips {
+        if candidate.Equal(ip) {
+            return true
+        }
+    }
+    return false
+}
+
+func joinIPs(ips []net.IP) string {
+    if len(ips) == 0 {
+        return "(belum di-resolve)"
+    }
+    s := make([]string, len(ips))
+    for i, ip := range ips {
+        s[i] = ip.String()
+    }
+    return strings.Join(s, ", ")
+}
+
+// FailoverReport pemulihan setelah satu pengalihan IP target. Waktu dihitung
+// dari saat pengalihan dengan resolusi satu detik; field kosong berarti
+// belum tercapai sampai pengalihan berikutnya atau test selesai.
+type FailoverReport struct {
+    AtSec           float64  `json:"at_s"`
+    Host            string   `json:"host"`
+    From            []string `json:"from,omitempty"`
+    To              []string `json:"to"`
+    Trigger         string   `json:"trigger"`               // jadwal atau api
+    FirstNewSec     *float64 `json:"first_new_s,omitempty"` // Request pertama ke IP baru
+    MovedSec        *float64 `json:"moved_s,omitempty"`     // >=95% request ke IP baru
+    RecoverySec     *float64 `json:"recovery_s,omitempty"`  // Sudah pindah dan error rate kembali ke acuan
+    ErrorRateBefore float64  `json:"error_rate_before"`     // Persen, 10 detik sebelum pengalihan pertama
+    PeakErrorRate   float64  `json:"peak_error_rate"`       // Persen, detik terburuk setelah pengalihan
+    Requests        int64    `json:"requests"`              // Request sampai pengalihan berikutnya
+    Errors          int64    `json:"errors"`
+}
+
+// Elapsed teks detik untuk tabel report, "-" jika belum tercapai
+func (f FailoverReport) Elapsed(sec *float64) string {
+    if sec == nil {
+        return "-"
+    }
+    return fmt.Sprintf("%.1f", *sec)
+}
+
+func (r *dnsResolver) failoverReport() []FailoverReport {
+    if r == nil || r.failover == nil {
+        return nil
+    }
+    f := r.failover
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    var reports []FailoverReport
+    for i, sw := range f.switches {
+        end := len(f.buckets)
+        if i+1 < len(f.switches) {
+            // Detik tempat pengalihan berikutnya terjadi sudah bercampur
+            end = min(end, int(f.switches[i+1].at/time.Second))
+        }
+        reports = append(reports, f.analyze(sw, f.switches[0].at, end))
+    }
+    return reports
+}
+
+// analyze menghitung pemulihan satu pengalihan dari bucket detik
+// pengalihan sampai sebelum end. Error rate acuan diambil sebelum pengalihan
+// pertama (baseline), karena pengalihan balik setelah target gagal tidak
+// boleh diukur terhadap periode yang sedang gagal. Dipanggil dengan mu
+// terkunci.
+func (f *failoverTracker) analyze(sw *dnsSwitch, baseline time.Duration, end int) FailoverReport {
+    report := FailoverReport{AtSec: sw.at.Seconds(), Host: sw.host, Trigger: sw.trigger}
+    for _, ip := range sw.from {
+        report.From = append(report.From, ip.String())
+    }
+    for _, ip := range sw.to {
+        report.To = append(report.To, ip.String())
+    }
+    if sw.firstNew >= 0 {
+        report.FirstNewSec = ptrFloat(sw.firstNew.Seconds())
+    }
+
+    var before, beforeErrors int64
+    for sec := max(0, int((baseline-failoverBaseline)/time.Second)); sec < min(int(baseline/time.Second), len(f.buckets)); sec++ {
+        before += f.buckets[sec].requests
+        beforeErrors += f.buckets[sec].errors
+    }
+    if before > 0 {
+        report.ErrorRateBefore = float64(beforeErrors) / float64(before) * 100
+    }
+
+    moved := false
+    for sec := int(sw.at / time.Second); sec < end; sec++ {
+        b := f.buckets[sec]
+        report.Requests += b.requests
+        report.Errors += b.errors
+        if b.requests == 0 {
+            continue
+        }
+        errorRate := float64(b.errors) / float64(b.requests) * 100
+        report.PeakErrorRate = max(report.PeakErrorRate, errorRate)
+        // Detik dihitung sampai akhir bucket tempat kondisi tercapai
+        elapsed := (time.Duration(sec+1)*time.Second - sw.at).Seconds()
+        if !moved && b.onNew+b.onOld > 0 && float64(b.onNew)/float64(b.onNew+b.onOld) >= failoverMoved {
+            moved = true
+            report.MovedSec = ptrFloat(elapsed)
+        }
+        if moved && report.RecoverySec == nil && errorRate <= report.ErrorRateBefore+failoverTolerance {
+            report.RecoverySec = ptrFloat(elapsed)
+        }
+    }
+    return report
+}
+
+func ptrFloat(v float64) *float64 {
+    return &v
+}
+
+func printFailover(w io.Writer, failovers []FailoverReport) {
+    if len(failovers) == 0 {
+        return
+    }
+    fmt.Fprintln(w, "\n🔀 Failover DNS:")
+    for _, f := range failovers {
+        fmt.Fprintf(w, "  [%.0fs] %s → %s (%s)\n", f.AtSec, f.Host, strings.Join(f.To, ", "), f.Trigger)
+        fmt.Fprintf(w, "    Request pertama ke IP baru: %s s, trafik pindah (≥%.0f%%): %s s, pulih: %s s\n",
+            f.Elapsed(f.FirstNewSec), failoverMoved*100, f.Elapsed(f.MovedSec), f.Elapsed(f.RecoverySec))
+        fmt.Fprintf(w, "    Error rate: %.2f%% sebelum, puncak %.2f%% (%d dari %d request gagal)\n",
+            f.ErrorRateBefore, f.PeakErrorRate, f.Errors, f.Requests)
+    }
+}
@@ -0,0 +1,139 @@
+package engine
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "strings"
+    "time"
+)
+
+// grafanaNotifier menandai jendela waktu load test di dashboard Grafana
+// lewat Annotations API. Token dibaca dari env GRAFANA_TOKEN.
+type grafanaNotifier struct {
+    baseURL      string
+    token        string
+    dashboardUID string
+    tags         []string
+    client       *http.Client
+
+    annotationID int64
+}
+
+func newGrafanaNotifier(config *Config) *grafanaNotifier {
+    tags := []string{"loadtest"}
+    for _, tag := range strings.Split(config.GrafanaTags, ",") {
+        if tag = strings.TrimSpace(tag); tag != "" {
+            tags = append(tags, tag)
+        }
+    }
+    return &grafanaNotifier{
+        baseURL:      strings.TrimSuffix(config.GrafanaURL, "/"),
+        token:        os.Getenv("GRAFANA_TOKEN"),
+        dashboardUID: config.GrafanaDashboard,
+        tags:         tags,
+        client:       &http.Client{Timeout: 10 * time.Second},
+    }
+}
+
+type grafanaAnnotation struct {
+    DashboardUID string   `json:"dashboardUID,omitempty"`
+    Time         int64    `json:"time,omitempty"`
+    TimeEnd      int64    `json:"timeEnd,omitempty"`
+    Tags         []string `json:"tags,omitempty"`
+    Text         string   `json:"text,omitempty"`
+}
+
+func (g *grafanaNotifier) RunStarted(runID string, config *Config, startTime time.Time) error {
+    annotation := grafanaAnnotation{
+        DashboardUID: g.dashboardUID,
+        Time:         startTime.UnixMilli(),
+        Tags:         g.tags,
+        Text:         fmt.Sprintf("Load test %s dimulai: %s %s (c=%d)", runID, config.Method, config.URL, config.Concurrency),
+    }
+
+    var created struct {
+        ID int64 `json:"id"`
+    }
+    if err := g.call(http.MethodPost, "/api/annotations", annotation, &created); err != nil {
+        return err
+    }
+    g.annotationID = created.ID
+    return nil
+}
+
+func (g *grafanaNotifier) RunFinished(report *Report, breached []ThresholdResult) error {
+    endTime := report.StartTime.Add(time.Duration(report.TotalTimeMs * float64(time.Millisecond)))
+    text := fmt.Sprintf("Load test %s: %s %s, %d requests, %.2f req/s, avg %.1f ms, success %.1f%%",
+        report.RunID, report.Method, report.URL, report.TotalRequests, report.RPS, report.AvgLatencyMs, report.SuccessRate)
+
+    // Ubah anotasi awal menjadi region mulai-selesai; jika anotasi awal gagal
+    // dibuat, buat region baru
+    region := grafanaAnnotation{
+        DashboardUID: g.dashboardUID,
+        Time:         report.StartTime.UnixMilli(),
+        TimeEnd:      endTime.UnixMilli(),
+        Tags:         g.tags,
+        Text:         text,
+    }
+    var err error
+    if g.annotationID != 0 {
+        err = g.call(http.MethodPatch, fmt.Sprintf("/api/annotations/%d", g.annotationID), region, nil)
+    } else {
+        err = g.call(http.MethodPost, "/api/annotations", region, nil)
+    }
+    if err != nil {
+        return err
+    }
+
+    if len(breached) == 0 {
+        return nil
+    }
+    var failed []string
+    for _, result := range breached {
+        failed = append(failed, result.String())
+    }
+    breach := grafanaAnnotation{
+        DashboardUID: g.dashboardUID,
+        Time:         endTime.UnixMilli(),
+        Tags:         append(append([]string{}, g.tags...), "threshold-breach"),
+        Text:         fmt.Sprintf("Load test %s melanggar threshold: %s", report.RunID, strings.Join(failed, ", ")),
+    }
+    return g.call(http.MethodPost, "/api/annotations", breach, nil)
+}
+
+func (g *grafanaNotifier) call(method, path string, payload, out interface{}) error {
+    body, err := json.Marshal(payload)
+    if err != nil {
+        return err
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+    req, err := http.NewRequestWithContext(ctx, method, g.baseURL+path, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", "application/json")
+    if g.token != "" {
+        req.Header.Set("Authorization", "Bearer "+g.token)
+    }
+
+    resp, err := g.client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+    if resp.StatusCode/100 != 2 {
+        return fmt.Errorf("grafana merespon %s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+    }
+    if out != nil {
+        return json.Unmarshal(respBody, out)
+    }
+    return nil
+}
@@ -0,0 +1,161 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    mathrand "math/rand/v2"
+    "net/http"
+    "strconv"
+    "sync"
+
+    "golang.org/x/net/http2"
+)
+
+// Mode padding header -h2-header-mode
+const (
+    h2HeaderStatic = "static" // Nilai sama di setiap request, masuk tabel dinamis HPACK dan dikirim sebagai index
+    h2HeaderRandom = "random" // Nilai baru di setiap request, selalu literal dan terus menggeser isi tabel dinamis
+)
+
+// h2HeaderChunk panjang maksimal nilai satu header padding
+const h2HeaderChunk = 256
+
+const h2HeaderChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// h2HeaderPad (-h2-header-bytes) menambah header X-Loadtest-Pad-N ke setiap
+// request untuk menguji decoder HPACK dan batas ukuran header server
+type h2HeaderPad struct {
+    bytes  int
+    random bool
+    static []string // Nilai tetap untuk mode static
+    seed   int64
+}
+
+func newH2HeaderPad(config *Config) (*h2HeaderPad, error) {
+    if config.H2HdrBytes < 0 {
+        return nil, fmt.Errorf("-h2-header-bytes tidak boleh negatif")
+    }
+    switch config.H2HdrMode {
+    case h2HeaderStatic, h2HeaderRandom:
+    default:
+        return nil, fmt.Errorf("-h2-header-mode harus %s atau %s", h2HeaderStatic, h2HeaderRandom)
+    }
+    if config.H2HdrBytes == 0 {
+        return nil, nil
+    }
+    p := &h2HeaderPad{bytes: config.H2HdrBytes, random: config.H2HdrMode == h2HeaderRandom, seed: config.Seed}
+    if !p.random {
+        p.static = p.values(mathrand.New(mathrand.NewPCG(uint64(config.Seed), 0)))
+    }
+    return p, nil
+}
+
+// values nilai header padding, dipotong per h2HeaderChunk byte
+func (p *h2HeaderPad) values(rng *mathrand.Rand) []string {
+    var values []string
+    for left := p.bytes; left > 0; left -= h2HeaderChunk {
+        b := make([]byte, min(left, h2HeaderChunk))
+        for i := range b {
+            b[i] = h2HeaderChars[rng.IntN(len(h2HeaderChars))]
+        }
+        values = append(values, string(b))
+    }
+    return values
+}
+
+func (p *h2HeaderPad) apply(req *http.Request, requestNum int) {
+    if p == nil {
+        return
+    }
+    values := p.static
+    if p.random {
+        values = p.values(cacheRand(p.seed, requestNum))
+    }
+    for i, v := range values {
+        req.Header.Set("X-Loadtest-Pad-"+strconv.Itoa(i), v)
+    }
+}
+
+// newH2RoundTripper transport -http2 untuk satu VU (atau semua VU pada
+// -conn-pool shared). Dengan -h2-streams, request dibagi ke beberapa
+// koneksi agar setiap koneksi membawa paling banyak sejumlah stream itu.
+func newH2RoundTripper(config *Config, conns *connTracker, stats *h2Stats) idleTransport {
+    if config.H2Streams <= 0 {
+        return newH2Transport(config, conns, stats)
+    }
+    return &h2Lanes{
+        streams: config.H2Streams,
+        open:    func() *http2.Transport { return newH2Transport(config, conns, stats) },
+    }
+}
+
+// h2Lanes membatasi stream bersamaan per koneksi. Setiap lane punya
+// transport sendiri sehingga memakai satu koneksi selama server mengizinkan
+// stream sebanyak batasnya; lane baru dibuka saat semua lane penuh.
+type h2Lanes struct {
+    streams int
+    open    func() *http2.Transport
+
+    mu    sync.Mutex
+    lanes []*h2Lane
+}
+
+type h2Lane struct {
+    transport *http2.Transport
+    active    int
+}
+
+func (l *h2Lanes) acquire() *h2Lane {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    for _, lane := range l.lanes {
+        if lane.active < l.streams {
+            lane.active++
+            return lane
+        }
+    }
+    lane := &h2Lane{transport: l.open(), active: 1}
+    l.lanes = append(l.lanes, lane)
+    return lane
+}
+
+func (l *h2Lanes) release(lane *h2Lane) {
+    l.mu.Lock()
+    lane.active--
+    l.mu.Unlock()
+}
+
+// RoundTrip memegang slot stream sampai body response ditutup
+func (l *h2Lanes) RoundTrip(req *http.Request) (*http.Response, error) {
+    lane := l.acquire()
+    resp, err := lane.transport.RoundTrip(req)
+    if err != nil {
+        l.release(lane)
+        return nil, err
+    }
+    body := &laneBody{ReadCloser: resp.Body}
+    body.release = func() { l.release(lane) }
+    resp.Body = body
+    return resp, nil
+}
+
+func (l *h2Lanes) CloseIdleConnections() {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    for _, lane := range l.lanes {
+        lane.transport.CloseIdleConnections()
+    }
+}
+
+// laneBody body response yang melepas slot stream saat ditutup
+type laneBody struct {
+    io.ReadCloser
+    once    sync.Once
+    release func()
+}
+
+func (b *laneBody) Close() error {
+    err := b.ReadCloser.Close()
+    b.once.Do(b.release)
+    return err
+}
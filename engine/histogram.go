@@ -0,0 +1,96 @@
+package engine
+
+import (
+    "math/bits"
+    "sync/atomic"
+    "time"
+)
+
+const (
+    histSubBits    = 6
+    histSubBuckets = 1 << histSubBits // 64 sub-bucket per pangkat dua, presisi relatif ~1.5%
+    histExponents  = 36               // Cukup untuk nilai sampai ~2^42 mikrodetik
+    histBuckets    = (histExponents + 1) * histSubBuckets
+)
+
+// histogram distribusi nilai (latency dalam mikrodetik, ukuran dalam byte)
+// dengan bucket log-linear seperti HDR histogram. Lock-free, zero value
+// siap dipakai, dan dua histogram bisa digabung.
+type histogram struct {
+    counts [histBuckets]atomic.Int64
+    total  atomic.Int64
+}
+
+func histIndex(v int64) int {
+    if v < histSubBuckets {
+        if v < 0 {
+            return 0
+        }
+        return int(v)
+    }
+    shift := bits.Len64(uint64(v)) - histSubBits - 1
+    idx := (shift+1)*histSubBuckets + int(v>>uint(shift)) - histSubBuckets
+    if idx >= histBuckets {
+        return histBuckets - 1
+    }
+    return idx
+}
+
+// histValue nilai tengah bucket idx
+func histValue(idx int) int64 {
+    if idx < histSubBuckets {
+        return int64(idx)
+    }
+    shift := uint(idx/histSubBuckets - 1)
+    sub := int64(idx%histSubBuckets + histSubBuckets)
+    return sub<<shift + (int64(1)<<shift)/2
+}
+
+func (h *histogram) add(v int64) {
+    h.counts[histIndex(v)].Add(1)
+    h.total.Add(1)
+}
+
+// addDuration mencatat durasi dengan resolusi mikrodetik
+func (h *histogram) addDuration(d time.Duration) {
+    h.add(d.Microseconds())
+}
+
+func (h *histogram) count() int64 {
+    return h.total.Load()
+}
+
+// quantile nilai pada kuantil q (0..1), 0 jika histogram kosong
+func (h *histogram) quantile(q float64) int64 {
+    total := h.total.Load()
+    if total == 0 {
+        return 0
+    }
+    rank := int64(q*float64(total) + 0.5)
+    if rank < 1 {
+        rank = 1
+    }
+    var seen int64
+    for i := range h.counts {
+        seen += h.counts[i].Load()
+        if seen >= rank {
+            return histValue(i)
+        }
+    }
+    return histValue(histBuckets - 1)
+}
+
+// quantileDuration kuantil untuk histogram yang diisi lewat addDuration
+func (h *histogram) quantileDuration(q float64) time.Duration {
+    return time.Duration(h.quantile(q)) * time.Microsecond
+}
+
+// merge menambahkan semua nilai dari other
+func (h *histogram) merge(other *histogram) {
+    for i := range other.counts {
+        if n := other.counts[i].Load(); n > 0 {
+            h.counts[i].Add(n)
+        }
+    }
+    h.total.Add(other.total.Load())
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "net/http"
+    "os"
+    "os/exec"
+    "strings"
+    "time"
+)
+
+// hookPayload data yang dikirim ke hook -before-hook / -after-hook
+type hookPayload struct {
+    Event       string  `json:"event"` // "before" atau "after"
+    URL         string  `json:"url,omitempty"`
+    Scenario    string  `json:"scenario,omitempty"`
+    Run         int     `json:"run"` // Nomor run saat -repeat, mulai dari 1
+    Concurrency int     `json:"concurrency"`
+    Report      *Report `json:"report,omitempty"` // Hanya pada after, kosong jika run gagal
+    Error       string  `json:"error,omitempty"`
+}
+
+// runHook menjalankan hook run. Hook berupa URL http(s) di-POST dengan
+// payload JSON; selain itu dijalankan sebagai perintah shell dengan payload
+// di stdin serta env LOADTEST_EVENT dan LOADTEST_RUN.
+func runHook(hook string, timeout time.Duration, payload hookPayload) error {
+    if hook == "" {
+        return nil
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+
+    fmt.Printf("🪝 Menjalankan %s hook...\n", payload.Event)
+    if strings.HasPrefix(hook, "http://") || strings.HasPrefix(hook, "https://") {
+        return postJSON(ctx, http.DefaultClient, hook, nil, payload)
+    }
+
+    data, err := json.Marshal(payload)
+    if err != nil {
+        return err
+    }
+    cmd := exec.CommandContext(ctx, "sh", "-c", hook)
+    cmd.Stdin = bytes.NewReader(data)
+    cmd.Stdout = os.Stdout
+    cmd.Stderr = os.Stderr
+    cmd.Env = append(os.Environ(), "LOADTEST_EVENT="+payload.Event, fmt.Sprintf("LOADTEST_RUN=%d", payload.Run))
+    if err := cmd.Run(); err != nil {
+        return fmt.Errorf("hook %q: %w", hook, err)
+    }
+    return nil
+}
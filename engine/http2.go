@@ -0,0 +1,497 @@
+package engine
+
+import (
+    "context"
+    "crypto/tls"
+    "encoding/binary"
+    "fmt"
+    "io"
+    "maps"
+    "net"
+    "net/url"
+    "slices"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "golang.org/x/net/http2"
+)
+
+// h2FramePayload byte payload frame yang disimpan untuk dibaca. Frame
+// kontrol yang diamati (GOAWAY, RST_STREAM, SETTINGS, WINDOW_UPDATE) jauh
+// lebih kecil; debug data GOAWAY yang panjang cukup dipotong.
+const h2FramePayload = 512
+
+// newH2Transport transport HTTP/2 untuk -http2. https memakai ALPN h2,
+// http memakai h2c dengan prior knowledge. Koneksi tetap lewat connTracker
+// dan setiap frame yang lewat diamati oleh h2Stats.
+func newH2Transport(config *Config, conns *connTracker, stats *h2Stats) *http2.Transport {
+    plain := false
+    if u, err := url.Parse(config.URL); err == nil && u.Scheme == "http" {
+        plain = true
+    }
+    return &http2.Transport{
+        TLSClientConfig: &tls.Config{InsecureSkipVerify: true, NextProtos: []string{http2.NextProtoTLS}},
+        AllowHTTP:       plain,
+        IdleConnTimeout: idleConnTimeout(config),
+        DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+            conn, err := conns.DialContext(ctx, network, addr)
+            if err != nil {
+                return nil, err
+            }
+            if !plain {
+                tc := tls.Client(conn, cfg)
+                if err := tc.HandshakeContext(ctx); err != nil {
+                    conn.Close()
+                    return nil, err
+                }
+                if proto := tc.ConnectionState().NegotiatedProtocol; proto != http2.NextProtoTLS {
+                    tc.Close()
+                    return nil, fmt.Errorf("server tidak mendukung HTTP/2 (ALPN %q)", proto)
+                }
+                conn = tc
+            }
+            return stats.wrap(conn), nil
+        },
+    }
+}
+
+// h2Stats statistik frame HTTP/2 dari semua koneksi -http2
+type h2Stats struct {
+    goAways atomic.Int64
+
+    streamLimit int    // -h2-streams, 0 = batas dari server
+    headerPad   int    // -h2-header-bytes
+    headerMode  string // -h2-header-mode
+
+    mu             sync.Mutex
+    conns          []*h2Conn
+    codes          map[string]int64  // Error code GOAWAY
+    rstCodes       map[string]int64  // Error code RST_STREAM dari server
+    serverSettings map[string]uint32 // Nilai SETTINGS terakhir dari server
+    clientSettings map[string]uint32
+}
+
+func newH2Stats(config *Config) *h2Stats {
+    return &h2Stats{
+        streamLimit:    config.H2Streams,
+        headerPad:      config.H2HdrBytes,
+        headerMode:     config.H2HdrMode,
+        codes:          make(map[string]int64),
+        rstCodes:       make(map[string]int64),
+        serverSettings: make(map[string]uint32),
+        clientSettings: make(map[string]uint32),
+    }
+}
+
+// wrap memasang pengamat frame pada koneksi yang sudah melewati TLS
+func (s *h2Stats) wrap(conn net.Conn) net.Conn {
+    c := &h2Conn{
+        Conn:        conn,
+        stats:       s,
+        sendConn:    h2DefaultWindow,
+        sendInit:    h2DefaultWindow,
+        recvConn:    h2DefaultWindow,
+        recvInit:    h2DefaultWindow,
+        sendStreams: make(map[uint32]int64),
+        recvStreams: make(map[uint32]int64),
+    }
+    c.out.skip = len(http2.ClientPreface)
+    // Koneksi TLS membungkus trackedConn; h2c langsung trackedConn
+    raw := conn
+    if tc, ok := conn.(*tls.Conn); ok {
+        raw = tc.NetConn()
+    }
+    if tracked, ok := raw.(*trackedConn); ok {
+        c.tracked = tracked
+        tracked.h2 = true
+    }
+    s.mu.Lock()
+    s.conns = append(s.conns, c)
+    s.mu.Unlock()
+    return c
+}
+
+// h2DefaultWindow window flow control awal sebelum SETTINGS (RFC 9113 6.9.2)
+const h2DefaultWindow = 65535
+
+// h2Conn membaca frame HTTP/2 yang lewat di koneksi tanpa mengubahnya.
+// Window flow control kedua arah dihitung ulang dari frame DATA,
+// WINDOW_UPDATE dan SETTINGS untuk mendeteksi stall.
+type h2Conn struct {
+    net.Conn
+    stats   *h2Stats
+    tracked *trackedConn // nil jika koneksi tidak lewat connTracker
+    in, out h2FrameReader
+
+    // Diubah goroutine baca dan tulis transport, dilindungi mu
+    mu          sync.Mutex
+    streams     int64 // Stream yang dibuka client
+    active      int64
+    peak        int64 // Stream aktif terbanyak bersamaan
+    rstSent     int64
+    rstReceived int64
+    sendStalls  int64 // Window kirim client habis, client menunggu WINDOW_UPDATE
+    recvStalls  int64 // Window terima client habis, server menunggu WINDOW_UPDATE
+    headerSent  int64 // Byte header block HEADERS/CONTINUATION ke server
+    headerRecv  int64 // Byte header block dari server
+    sendConn    int64
+    sendInit    int64
+    recvConn    int64
+    recvInit    int64
+    sendStreams map[uint32]int64 // Window kirim per stream aktif
+    recvStreams map[uint32]int64 // Window terima per stream aktif
+}
+
+func (c *h2Conn) Read(b []byte) (int, error) {
+    n, err := c.Conn.Read(b)
+    c.in.feed(b[:n], func(f h2Frame) { c.frame(true, f) })
+    return n, err
+}
+
+// Write mencatat frame sebelum dikirim: response server yang cepat bisa
+// terbaca sebelum Write selesai, dan stream harus sudah tercatat terbuka
+// saat END_STREAM-nya datang. Koneksi yang gagal ditulis tetap ditutup
+// transport, jadi sisa frame yang tidak terkirim tidak berpengaruh.
+func (c *h2Conn) Write(b []byte) (int, error) {
+    c.out.feed(b, func(f h2Frame) { c.frame(false, f) })
+    return c.Conn.Write(b)
+}
+
+// frame dipanggil untuk setiap frame utuh. received true untuk frame dari
+// server.
+func (c *h2Conn) frame(received bool, f h2Frame) {
+    if received && f.typ == http2.FrameGoAway {
+        c.goAway(f)
+        return
+    }
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    switch f.typ {
+    case http2.FrameHeaders, http2.FrameContinuation:
+        // Panjang header block setelah kompresi HPACK
+        if received {
+            c.headerRecv += int64(f.length)
+        } else {
+            c.headerSent += int64(f.length)
+        }
+        if f.typ == http2.FrameContinuation {
+            return
+        }
+        if !received {
+            if _, open := c.sendStreams[f.stream]; !open {
+                c.streams++
+                c.active++
+                c.peak = max(c.peak, c.active)
+                c.sendStreams[f.stream] = c.sendInit
+                c.recvStreams[f.stream] = c.recvInit
+            }
+        } else if f.flags.Has(http2.FlagHeadersEndStream) {
+            c.endStream(f.stream)
+        }
+    case http2.FrameData:
+        // Window koneksi dan stream selalu dikurangi keduanya
+        if received {
+            connStall := windowExhausted(&c.recvConn, f.length)
+            if streamStall := c.consume(c.recvStreams, f.stream, f.length); connStall || streamStall {
+                c.recvStalls++
+            }
+            if f.flags.Has(http2.FlagDataEndStream) {
+                c.endStream(f.stream)
+            }
+        } else {
+            connStall := windowExhausted(&c.sendConn, f.length)
+            if streamStall := c.consume(c.sendStreams, f.stream, f.length); connStall || streamStall {
+                c.sendStalls++
+            }
+        }
+    case http2.FrameWindowUpdate:
+        if len(f.payload) < 4 {
+            return
+        }
+        inc := int64(binary.BigEndian.Uint32(f.payload) & (1<<31 - 1))
+        // WINDOW_UPDATE dari server menambah window kirim client, dan
+        // sebaliknya
+        conn, streams := &c.sendConn, c.sendStreams
+        if !received {
+            conn, streams = &c.recvConn, c.recvStreams
+        }
+        if f.stream == 0 {
+            *conn += inc
+        } else if w, open := streams[f.stream]; open {
+            streams[f.stream] = w + inc
+        }
+    case http2.FrameSettings:
+        if f.flags.Has(http2.FlagSettingsAck) {
+            return
+        }
+        // SETTINGS_INITIAL_WINDOW_SIZE server berlaku untuk window kirim
+        // client, milik client untuk window terima
+        init, streams, settings := &c.sendInit, c.sendStreams, c.stats.serverSettings
+        if !received {
+            init, streams, settings = &c.recvInit, c.recvStreams, c.stats.clientSettings
+        }
+        for i := 0; i+6 <= len(f.payload); i += 6 {
+            id := http2.SettingID(binary.BigEndian.Uint16(f.payload[i:]))
+            value := int64(binary.BigEndian.Uint32(f.payload[i+2:]))
+            c.stats.mu.Lock()
+            settings[id.String()] = uint32(value)
+            c.stats.mu.Unlock()
+            if id != http2.SettingInitialWindowSize {
+                continue
+            }
+            for id, w := range streams {
+                streams[id] = w + value - *init
+            }
+            *init = value
+        }
+    case http2.FrameRSTStream:
+        if received {
+            c.rstReceived++
+            if len(f.payload) >= 4 {
+                code := http2.ErrCode(binary.BigEndian.Uint32(f.payload)).String()
+                c.stats.mu.Lock()
+                c.stats.rstCodes[code]++
+                c.stats.mu.Unlock()
+            }
+        } else {
+            c.rstSent++
+        }
+        c.endStream(f.stream)
+    }
+}
+
+// consume mengurangi window stream, true jika window habis karenanya.
+// Dipanggil dengan mu terkunci.
+func (c *h2Conn) consume(streams map[uint32]int64, stream uint32, n int) bool {
+    w, open := streams[stream]
+    if !open {
+        return false
+    }
+    exhausted := windowExhausted(&w, n)
+    streams[stream] = w
+    return exhausted
+}
+
+// windowExhausted mengurangi window sebesar n, true jika window yang masih
+// tersisa menjadi habis
+func windowExhausted(window *int64, n int) bool {
+    before := *window
+    *window -= int64(n)
+    return before > 0 && *window <= 0
+}
+
+// endStream menutup stream. Dipanggil dengan mu terkunci.
+func (c *h2Conn) endStream(stream uint32) {
+    if _, open := c.sendStreams[stream]; !open {
+        return
+    }
+    delete(c.sendStreams, stream)
+    delete(c.recvStreams, stream)
+    c.active--
+}
+
+func (c *h2Conn) goAway(f h2Frame) {
+    if len(f.payload) < 8 {
+        return
+    }
+    code := http2.ErrCode(binary.BigEndian.Uint32(f.payload[4:8])).String()
+    c.stats.goAways.Add(1)
+    c.stats.mu.Lock()
+    c.stats.codes[code]++
+    c.stats.mu.Unlock()
+    if c.tracked != nil {
+        c.tracked.goAway(code)
+    }
+}
+
+// h2Frame header frame dan awal payload-nya
+type h2Frame struct {
+    typ     http2.FrameType
+    flags   http2.Flags
+    stream  uint32
+    length  int
+    payload []byte // Maksimal h2FramePayload byte pertama
+}
+
+// h2FrameReader memotong aliran byte satu arah menjadi frame. Read/Write
+// bisa memotong frame di mana saja, jadi state disimpan antar panggilan.
+type h2FrameReader struct {
+    skip      int // Byte yang dilewati, preface client di arah keluar
+    header    [9]byte
+    filled    int
+    remaining int
+    frame     h2Frame
+}
+
+func (r *h2FrameReader) feed(p []byte, emit func(h2Frame)) {
+    for len(p) > 0 {
+        if r.skip > 0 {
+            n := min(r.skip, len(p))
+            r.skip -= n
+            p = p[n:]
+            continue
+        }
+        if r.filled < len(r.header) {
+            n := copy(r.header[r.filled:], p)
+            r.filled += n
+            p = p[n:]
+            if r.filled < len(r.header) {
+                return
+            }
+            r.frame = h2Frame{
+                length: int(r.header[0])<<16 | int(r.header[1])<<8 | int(r.header[2]),
+                typ:    http2.FrameType(r.header[3]),
+                flags:  http2.Flags(r.header[4]),
+                stream: binary.BigEndian.Uint32(r.header[5:9]) & (1<<31 - 1),
+            }
+            r.remaining = r.frame.length
+            if r.remaining == 0 {
+                emit(r.frame)
+                r.filled = 0
+                continue
+            }
+        }
+        n := min(r.remaining, len(p))
+        if keep := min(r.frame.length, h2FramePayload) - len(r.frame.payload); keep > 0 && h2Control(r.frame.typ) {
+            r.frame.payload = append(r.frame.payload, p[:min(n, keep)]...)
+        }
+        r.remaining -= n
+        p = p[n:]
+        if r.remaining == 0 {
+            emit(r.frame)
+            r.filled = 0
+        }
+    }
+}
+
+// h2Control true untuk frame yang payload-nya dibaca
+func h2Control(typ http2.FrameType) bool {
+    switch typ {
+    case http2.FrameGoAway, http2.FrameRSTStream, http2.FrameSettings, http2.FrameWindowUpdate:
+        return true
+    }
+    return false
+}
+
+// HTTP2Report statistik frame koneksi -http2
+type HTTP2Report struct {
+    Connections    int64            `json:"connections"`
+    Streams        int64            `json:"streams"`
+    StreamsPerConn float64          `json:"streams_per_conn"`
+    MaxStreamsConn int64            `json:"max_streams_per_conn"`
+    PeakConcurrent int64            `json:"peak_concurrent_streams"` // Stream aktif bersamaan terbanyak di satu koneksi
+    RSTReceived    int64            `json:"rst_stream_received"`
+    RSTSent        int64            `json:"rst_stream_sent"`
+    RSTCodes       map[string]int64 `json:"rst_stream_codes,omitempty"`
+    GoAways        int64            `json:"goaways"`
+    GoAwayCodes    map[string]int64 `json:"goaway_codes,omitempty"`
+    SendFlowStalls int64            `json:"send_flow_stalls"` // Client menunggu WINDOW_UPDATE server
+    RecvFlowStalls int64            `json:"recv_flow_stalls"` // Server menunggu WINDOW_UPDATE client
+
+    StreamLimit     int               `json:"stream_limit,omitempty"`     // -h2-streams
+    HeaderPadBytes  int               `json:"header_pad_bytes,omitempty"` // -h2-header-bytes
+    HeaderPadMode   string            `json:"header_pad_mode,omitempty"`
+    HeaderBlockSent float64           `json:"header_block_sent_avg"` // Byte header block per request setelah HPACK
+    HeaderBlockRecv float64           `json:"header_block_recv_avg"` // Byte header block per response
+    ServerSettings  map[string]uint32 `json:"server_settings,omitempty"`
+    ClientSettings  map[string]uint32 `json:"client_settings,omitempty"`
+}
+
+func (s *h2Stats) report() *HTTP2Report {
+    if s == nil {
+        return nil
+    }
+    s.mu.Lock()
+    conns := slices.Clone(s.conns)
+    r := &HTTP2Report{
+        Connections: int64(len(conns)),
+        GoAways:     s.goAways.Load(),
+        GoAwayCodes: maps.Clone(s.codes),
+        RSTCodes:    maps.Clone(s.rstCodes),
+
+        StreamLimit:    s.streamLimit,
+        HeaderPadBytes: s.headerPad,
+        ServerSettings: maps.Clone(s.serverSettings),
+        ClientSettings: maps.Clone(s.clientSettings),
+    }
+    if s.headerPad > 0 {
+        r.HeaderPadMode = s.headerMode
+    }
+    s.mu.Unlock()
+    var headerSent, headerRecv int64
+    for _, c := range conns {
+        c.mu.Lock()
+        r.Streams += c.streams
+        r.MaxStreamsConn = max(r.MaxStreamsConn, c.streams)
+        r.PeakConcurrent = max(r.PeakConcurrent, c.peak)
+        r.RSTReceived += c.rstReceived
+        r.RSTSent += c.rstSent
+        r.SendFlowStalls += c.sendStalls
+        r.RecvFlowStalls += c.recvStalls
+        headerSent += c.headerSent
+        headerRecv += c.headerRecv
+        c.mu.Unlock()
+    }
+    if r.Connections > 0 {
+        r.StreamsPerConn = float64(r.Streams) / float64(r.Connections)
+    }
+    if r.Streams > 0 {
+        r.HeaderBlockSent = float64(headerSent) / float64(r.Streams)
+        r.HeaderBlockRecv = float64(headerRecv) / float64(r.Streams)
+    }
+    return r
+}
+
+func printHTTP2(w io.Writer, h *HTTP2Report) {
+    if h == nil {
+        return
+    }
+    fmt.Fprintf(w, "  Koneksi HTTP/2:        %d, %d stream (rata-rata %.1f, maks %d per koneksi)\n",
+        h.Connections, h.Streams, h.StreamsPerConn, h.MaxStreamsConn)
+    fmt.Fprintf(w, "  Stream bersamaan:      maks %d per koneksi\n", h.PeakConcurrent)
+    fmt.Fprintf(w, "  RST_STREAM:            %d dari server, %d dari client\n", h.RSTReceived, h.RSTSent)
+    for _, code := range slices.Sorted(maps.Keys(h.RSTCodes)) {
+        fmt.Fprintf(w, "    RST_STREAM %s: %d\n", code, h.RSTCodes[code])
+    }
+    fmt.Fprintf(w, "  GOAWAY diterima:       %d\n", h.GoAways)
+    for _, code := range slices.Sorted(maps.Keys(h.GoAwayCodes)) {
+        fmt.Fprintf(w, "    GOAWAY %s: %d\n", code, h.GoAwayCodes[code])
+    }
+    fmt.Fprintf(w, "  Flow control stall:    %d kirim (client menunggu), %d terima (server menunggu)\n",
+        h.SendFlowStalls, h.RecvFlowStalls)
+    fmt.Fprintf(w, "  Header block HPACK:    %.0f byte/request, %.0f byte/response\n", h.HeaderBlockSent, h.HeaderBlockRecv)
+    if h.HeaderPadBytes > 0 {
+        fmt.Fprintf(w, "    Padding header:      %d byte (%s)\n", h.HeaderPadBytes, h.HeaderPadMode)
+    }
+    if h.StreamLimit > 0 {
+        fmt.Fprintf(w, "  Batas stream client:   %d per koneksi\n", h.StreamLimit)
+    }
+    printH2Settings(w, "SETTINGS server:", h.ServerSettings)
+    printH2Settings(w, "SETTINGS client:", h.ClientSettings)
+    // Server yang membatasi stream lebih rendah dari -h2-streams membuat
+    // client membuka koneksi tambahan
+    if limit, ok := h.ServerSettings[http2.SettingMaxConcurrentStreams.String()]; ok && h.StreamLimit > int(limit) {
+        fmt.Fprintf(w, "  ⚠️  Server hanya mengizinkan %d stream bersamaan, kurang dari -h2-streams %d\n", limit, h.StreamLimit)
+    }
+}
+
+func printH2Settings(w io.Writer, label string, settings map[string]uint32) {
+    if len(settings) == 0 {
+        return
+    }
+    fmt.Fprintf(w, "  %-22s", label)
+    for _, name := range slices.Sorted(maps.Keys(settings)) {
+        fmt.Fprintf(w, " %s=%d", name, settings[name])
+    }
+    fmt.Fprintln(w)
+}
+
+// idleConnTimeout batas koneksi idle transport. Saat -drain, koneksi harus
+// tetap terbuka sepanjang drain agar yang menutupnya adalah server.
+func idleConnTimeout(config *Config) time.Duration {
+    if timeout := 90 * time.Second; config.Drain < timeout {
+        return timeout
+    }
+    return config.Drain + time.Minute
+}
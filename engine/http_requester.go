@@ -0,0 +1,275 @@
+package engine
+
+import (
+    "context"
+    "io"
+    "net"
+    "net/http"
+    "net/http/httptrace"
+    "time"
+)
+
+// httpRequester implementasi Requester untuk HTTP/HTTPS
+type httpRequester struct {
+    client   *http.Client
+    requests *requestBuilder
+    pool     *preconnectPool
+    conns    *connTracker
+
+    assertions []Assertion
+    streaming  bool         // Semua assertion bisa diperiksa tanpa membaca body utuh
+    cache      *cacheTester // Opsional, mode -cache-test
+    audit      *headerAudit
+    shadow     *shadowMirror       // Opsional, mode -shadow
+    limits     *urlLimiter         // Opsional, -url-limit
+    idem       *idempotencyChecker // Opsional, -idempotency
+    deadline   *deadlineStamper    // Opsional, -deadline-header
+    pad        *h2HeaderPad        // Opsional, -h2-header-bytes
+}
+
+func newHTTPRequester(config *Config) (Requester, error) {
+    requests, err := newRequestBuilder(config)
+    if err != nil {
+        return nil, err
+    }
+
+    assertions, err := newAssertions(config)
+    if err != nil {
+        return nil, err
+    }
+
+    h := &httpRequester{
+        client:     createHTTPClient(config),
+        requests:   requests,
+        conns:      newConnTracker(config.out),
+        assertions: assertions,
+        streaming:  streamable(assertions),
+        audit:      newHeaderAudit(),
+    }
+    if h.conns.dns, err = newDNSResolver(config); err != nil {
+        return nil, err
+    }
+    if h.shadow, err = newShadowMirror(config); err != nil {
+        return nil, err
+    }
+    if h.limits, err = newURLLimiter(config.URLLimits); err != nil {
+        return nil, err
+    }
+    if h.idem, err = newIdempotencyChecker(config); err != nil {
+        return nil, err
+    }
+    if h.deadline, err = newDeadlineStamper(config); err != nil {
+        return nil, err
+    }
+    if config.CacheTest {
+        mix, err := parseCacheMix(config.CacheMix)
+        if err != nil {
+            return nil, err
+        }
+        h.cache = newCacheTester(mix)
+    }
+    if config.HTTP2 {
+        h.conns.h2 = newH2Stats(config)
+        if h.pad, err = newH2HeaderPad(config); err != nil {
+            return nil, err
+        }
+        h.client.Transport = &transportPool{
+            base:  newH2RoundTripper(config, h.conns, h.conns.h2),
+            clone: func() idleTransport { return newH2RoundTripper(config, h.conns, h.conns.h2) },
+            perVU: config.ConnPool != connPoolShared,
+        }
+        return h, nil
+    }
+    transport := h.client.Transport.(*http.Transport)
+    transport.DialContext = h.conns.DialContext
+    if config.Preconnect {
+        h.pool = newPreconnectPool(h.conns.DialContext, transport.TLSClientConfig)
+        h.pool.install(transport)
+    }
+    // Dial hook dipasang sebelum transport di-clone per VU
+    h.client.Transport = newTransportPool(transport, config.ConnPool)
+    return h, nil
+}
+
+// Preconnect membuka n koneksi keep-alive (TCP+TLS) sebelum test dimulai
+func (h *httpRequester) Preconnect(ctx context.Context, n int) error {
+    if h.pool == nil {
+        return nil
+    }
+    return h.pool.warm(ctx, h.requests.URL(), n)
+}
+
+func (h *httpRequester) Do(ctx context.Context, requestNum int) (result Result) {
+    // Catat apakah request memakai koneksi reuse
+    var reused bool
+    var local, remote net.Addr
+    trace := &httptrace.ClientTrace{
+        GotConn: func(info httptrace.GotConnInfo) {
+            reused, local, remote = info.Reused, info.Conn.LocalAddr(), info.Conn.RemoteAddr()
+        },
+    }
+
+    req, err := h.requests.build(httptrace.WithClientTrace(ctx, trace), requestNum)
+    if err != nil {
+        return Result{Start: time.Now(), Err: err}
+    }
+    var cacheKind string
+    if h.cache != nil {
+        cacheKind = h.cache.prepare(req, cacheRand(h.requests.seed, requestNum))
+    }
+    h.pad.apply(req, requestNum)
+    release, err := h.limits.acquire(ctx, req)
+    if err != nil {
+        return Result{Start: time.Now(), Err: err}
+    }
+    defer release()
+    deadline := h.deadline.stamp(req, 0)
+    pair := h.shadow.mirror(req, requestNum)
+    defer func() {
+        h.conns.recordSource(local, remote, result)
+        h.deadline.observe(deadline, result)
+        pair.observe(result)
+    }()
+    dup := h.idem.start(ctx, h.client, req, requestNum)
+    defer func() { result = dup.verify(result) }()
+
+    start := time.Now()
+    resp, err := h.client.Do(req)
+    duration := time.Since(start)
+    h.conns.recordRequest(reused, err)
+    if err != nil {
+        return Result{Start: start, Duration: duration, Err: err}
+    }
+    defer resp.Body.Close()
+    resp.Body = pair.captureBody(resp.Body)
+    resp.Body = dup.captureBody(resp.Body)
+    watch := watchChallenge(resp)
+    defer func() { result = watch.check(result) }()
+
+    // Body hanya disimpan jika perlu diperiksa assertion, selain itu cukup
+    // di-drain untuk reuse connection
+    if len(h.assertions) > 0 && h.streaming {
+        check := newStreamCheck(h.assertions)
+        n, _ := io.Copy(io.Discard, io.TeeReader(resp.Body, check))
+        result := Result{Start: start, Duration: duration, StatusCode: resp.StatusCode, Bytes: n, Err: check.err(), RetryAfter: parseRetryAfter(resp)}
+        if result.Err != nil || resp.StatusCode >= 400 {
+            result.ErrorBody = string(check.head)
+        }
+        return h.finish(req, resp, cacheKind, result)
+    }
+    if len(h.assertions) > 0 {
+        body, err := io.ReadAll(resp.Body)
+        if err == nil {
+            err = checkAssertions(h.assertions, resp, body)
+        }
+        result := Result{Start: start, Duration: duration, StatusCode: resp.StatusCode, Bytes: int64(len(body)), Err: err, RetryAfter: parseRetryAfter(resp)}
+        if err != nil || resp.StatusCode >= 400 {
+            result.ErrorBody = errorSample(body)
+        }
+        return h.finish(req, resp, cacheKind, result)
+    }
+    n, sample := drainBody(resp.Body, resp.StatusCode)
+
+    return h.finish(req, resp, cacheKind, Result{
+        Start:      start,
+        Duration:   duration,
+        StatusCode: resp.StatusCode,
+        Bytes:      n,
+        ErrorBody:  sample,
+        RetryAfter: parseRetryAfter(resp),
+    })
+}
+
+// finish mencatat latency per path jika -prom-mix, -from-sitemap atau beberapa URL target aktif dan per jenis
+// request jika -cache-test aktif agar terlihat di tabel fase, lalu
+// memeriksa security header dan header caching response
+func (h *httpRequester) finish(req *http.Request, resp *http.Response, cacheKind string, result Result) Result {
+    h.audit.observe(resp)
+    if h.requests.mix != nil && !h.requests.mix.noPhases {
+        name := req.URL.Path
+        if h.requests.mix.multiHost {
+            name = req.URL.Host + name
+        }
+        result.Phases = map[string]time.Duration{req.Method + " " + name: result.Duration}
+    }
+    if h.cache != nil {
+        if result.Phases == nil {
+            result.Phases = make(map[string]time.Duration, 1)
+        }
+        result.Phases["cache "+cacheKind] = result.Duration
+        h.cache.observe(cacheKind, req, resp, result.Bytes)
+    }
+    return result
+}
+
+// CacheReport hasil -cache-test, nil jika mode itu tidak aktif
+func (h *httpRequester) CacheReport() *CacheReport {
+    if h.cache == nil {
+        return nil
+    }
+    return h.cache.report()
+}
+
+// SecurityReport audit security header response selama test
+func (h *httpRequester) SecurityReport() *SecurityReport {
+    return h.audit.report()
+}
+
+// ShadowReport perbandingan dengan host -shadow, nil jika mode itu tidak
+// aktif
+func (h *httpRequester) ShadowReport() *ShadowReport {
+    return h.shadow.report()
+}
+
+// IdempotencyReport hasil -idempotency, nil jika mode itu tidak aktif
+func (h *httpRequester) IdempotencyReport() *IdempotencyReport {
+    return h.idem.report()
+}
+
+// URLLimitReport antrean per pola -url-limit, nil jika tidak diisi
+func (h *httpRequester) URLLimitReport() []URLLimitReport {
+    return h.limits.report()
+}
+
+// CapturePackets merekam sampel koneksi ke PCAP
+func (h *httpRequester) CapturePackets(c *packetCapture) {
+    h.conns.capture = c
+}
+
+// Drain membiarkan koneksi idle terbuka sampai server menutupnya
+func (h *httpRequester) Drain(timeout time.Duration) *DrainReport {
+    return h.conns.drain(timeout)
+}
+
+// DeadlineReport response yang datang setelah deadline -deadline-header
+func (h *httpRequester) DeadlineReport() *DeadlineReport {
+    return h.deadline.report()
+}
+
+// DNSResolver resolver host target untuk pengalihan failover, nil pada
+// -dns system tanpa -dns-switch dan -control-addr
+func (h *httpRequester) DNSResolver() *dnsResolver {
+    return h.conns.dns
+}
+
+// ConnReport statistik koneksi, termasuk koneksi yang diputus server
+func (h *httpRequester) ConnReport() *ConnReport {
+    return h.conns.report()
+}
+
+func (h *httpRequester) OpenConns() int64 {
+    return h.conns.live.Load()
+}
+
+func (h *httpRequester) CloseIdleConnections() {
+    h.client.CloseIdleConnections()
+}
+
+func (h *httpRequester) Close() error {
+    if h.pool != nil {
+        h.pool.close()
+    }
+    h.shadow.close()
+    h.client.CloseIdleConnections()
+    return nil
+}
@@ -0,0 +1,272 @@
+package engine
+
+import (
+    "context"
+    "crypto/rand"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "sort"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// idempotencyChecker (-idempotency) mengirim setiap request dua kali dengan
+// idempotency key yang sama, duplikatnya dalam rentang acak 0..window
+// setelah request utama, lalu memeriksa kedua response konsisten: status
+// sama dan body sama setelah dinormalisasi seperti -shadow-diff. Response
+// yang tidak konsisten membuat request utama gagal.
+type idempotencyChecker struct {
+    window time.Duration
+    header string
+    seed   int64    // -seed, untuk jeda duplikat
+    ignore []string // Path JSON yang boleh berbeda, contoh $.request_id
+
+    pairs      atomic.Int64
+    consistent atomic.Int64
+    skipped    atomic.Int64 // Request utama gagal atau dibatalkan
+    dupErrors  atomic.Int64 // Duplikat gagal tanpa response
+
+    mu       sync.Mutex
+    statuses map[string]int64 // "201 → 409" -> jumlah
+    paths    map[string]*ShadowDiffPath
+}
+
+func newIdempotencyChecker(config *Config) (*idempotencyChecker, error) {
+    if config.Idempotency == 0 {
+        return nil, nil
+    }
+    if config.Idempotency < 0 {
+        return nil, fmt.Errorf("-idempotency harus > 0")
+    }
+    if config.IdemHeader == "" {
+        return nil, fmt.Errorf("-idempotency-header tidak boleh kosong")
+    }
+    return &idempotencyChecker{
+        window:   config.Idempotency,
+        header:   http.CanonicalHeaderKey(config.IdemHeader),
+        seed:     config.Seed,
+        ignore:   jsonPaths(config.IdemIgnore),
+        statuses: make(map[string]int64),
+        paths:    make(map[string]*ShadowDiffPath),
+    }, nil
+}
+
+// idempotencyPair satu request utama dan duplikatnya
+type idempotencyPair struct {
+    c       *idempotencyChecker
+    capture *bodyCapture // Body response utama
+    done    chan struct{}
+
+    // Diisi goroutine duplikat sebelum done ditutup
+    status int
+    body   []byte
+    err    error
+}
+
+// start memberi req idempotency key (kecuali sudah diisi, contoh lewat -H
+// dengan template) lalu menjadwalkan duplikatnya. ctx harus tanpa client
+// trace request utama karena duplikat berjalan bersamaan. Nil jika mode
+// tidak aktif atau body request tidak bisa disalin. Jeda duplikat dipilih
+// per requestNum agar berulang dengan -seed yang sama.
+func (c *idempotencyChecker) start(ctx context.Context, client *http.Client, req *http.Request, requestNum int) *idempotencyPair {
+    if c == nil {
+        return nil
+    }
+    if req.Header.Get(c.header) == "" {
+        req.Header.Set(c.header, rand.Text())
+    }
+    dup := req.Clone(ctx)
+    if req.GetBody != nil {
+        dup.Body, _ = req.GetBody()
+    } else if req.Body != nil && req.Body != http.NoBody {
+        c.skipped.Add(1)
+        return nil
+    }
+
+    p := &idempotencyPair{c: c, done: make(chan struct{})}
+    delay := time.Duration(cacheRand(c.seed, requestNum).Int64N(int64(c.window) + 1))
+    go func() {
+        defer close(p.done)
+        if !sleepUntil(ctx, time.Now().Add(delay)) {
+            p.err = ctx.Err()
+            return
+        }
+        resp, err := client.Do(dup)
+        if err != nil {
+            p.err = err
+            return
+        }
+        defer resp.Body.Close()
+        capture := &bodyCapture{ReadCloser: resp.Body}
+        if _, err := io.Copy(io.Discard, capture); err != nil {
+            p.err = err
+            return
+        }
+        p.status, p.body = resp.StatusCode, capture.body()
+    }()
+    return p
+}
+
+// captureBody membungkus body response utama agar bisa dibandingkan
+func (p *idempotencyPair) captureBody(body io.ReadCloser) io.ReadCloser {
+    if p == nil {
+        return body
+    }
+    p.capture = &bodyCapture{ReadCloser: body}
+    return p.capture
+}
+
+// idempotencyError response duplikat tidak sama dengan response pertama
+type idempotencyError struct {
+    detail string
+}
+
+func (e *idempotencyError) Error() string {
+    return "response duplikat dengan idempotency key sama berbeda: " + e.detail
+}
+
+// verify menunggu duplikat selesai lalu membandingkan kedua response,
+// dipanggil setelah body response utama selesai dibaca
+func (p *idempotencyPair) verify(result Result) Result {
+    if p == nil {
+        return result
+    }
+    <-p.done
+    c := p.c
+    switch {
+    case result.StatusCode == 0 || errors.Is(p.err, context.Canceled):
+        c.skipped.Add(1)
+        return result
+    case p.err != nil:
+        c.dupErrors.Add(1)
+        return result
+    }
+    c.pairs.Add(1)
+
+    if result.StatusCode != p.status {
+        key := fmt.Sprintf("%d → %d", result.StatusCode, p.status)
+        c.mu.Lock()
+        c.statuses[key]++
+        c.mu.Unlock()
+        return p.fail(result, "status "+key)
+    }
+    // Body > 1 MiB hanya dibandingkan status-nya
+    primary := p.capture.body()
+    if primary == nil || p.body == nil {
+        c.consistent.Add(1)
+        return result
+    }
+    diffs := diffPaths(primary, p.body, c.ignore)
+    if len(diffs) == 0 {
+        c.consistent.Add(1)
+        return result
+    }
+    c.mu.Lock()
+    for _, diff := range diffs {
+        path := c.paths[diff.path]
+        if path == nil {
+            path = &ShadowDiffPath{Path: diff.path, Example: diff.String()}
+            c.paths[diff.path] = path
+        }
+        path.Count++
+    }
+    c.mu.Unlock()
+    return p.fail(result, "body "+diffs[0].path+" "+diffs[0].String())
+}
+
+func (p *idempotencyPair) fail(result Result, detail string) Result {
+    if result.Err == nil {
+        result.Err = &idempotencyError{detail: detail}
+    }
+    return result
+}
+
+// IdempotencyReport hasil -idempotency
+type IdempotencyReport struct {
+    WindowMs        float64          `json:"window_ms"`
+    Header          string           `json:"header"`
+    Pairs           int64            `json:"pairs"` // Pasangan yang dibandingkan
+    Consistent      int64            `json:"consistent"`
+    Inconsistent    int64            `json:"inconsistent"`
+    InconsistentPct float64          `json:"inconsistent_pct"`
+    Skipped         int64            `json:"skipped"`          // Request utama gagal tanpa response
+    DupErrors       int64            `json:"duplicate_errors"` // Duplikat gagal tanpa response
+    StatusPairs     map[string]int64 `json:"status_pairs,omitempty"`
+    Paths           []ShadowDiffPath `json:"body_paths,omitempty"` // Path body yang berbeda, format sama dengan -shadow-diff
+}
+
+func (c *idempotencyChecker) report() *IdempotencyReport {
+    if c == nil {
+        return nil
+    }
+    r := &IdempotencyReport{
+        WindowMs:   durationMs(c.window),
+        Header:     c.header,
+        Pairs:      c.pairs.Load(),
+        Consistent: c.consistent.Load(),
+        Skipped:    c.skipped.Load(),
+        DupErrors:  c.dupErrors.Load(),
+    }
+    r.Inconsistent = r.Pairs - r.Consistent
+    if r.Pairs > 0 {
+        r.InconsistentPct = float64(r.Inconsistent) / float64(r.Pairs) * 100
+    }
+    c.mu.Lock()
+    if len(c.statuses) > 0 {
+        r.StatusPairs = make(map[string]int64, len(c.statuses))
+        for pair, n := range c.statuses {
+            r.StatusPairs[pair] = n
+        }
+    }
+    for _, p := range c.paths {
+        r.Paths = append(r.Paths, *p)
+    }
+    c.mu.Unlock()
+    sort.Slice(r.Paths, func(i, j int) bool {
+        if r.Paths[i].Count != r.Paths[j].Count {
+            return r.Paths[i].Count > r.Paths[j].Count
+        }
+        return r.Paths[i].Path < r.Paths[j].Path
+    })
+    return r
+}
+
+// IdempotencyReporter diimplementasikan Requester yang mendukung
+// -idempotency
+type IdempotencyReporter interface {
+    IdempotencyReport() *IdempotencyReport
+}
+
+func printIdempotency(w io.Writer, report *Report) {
+    r := report.Idempotency
+    if r == nil {
+        return
+    }
+    fmt.Fprintf(w, "\n🔂 Idempotency (duplikat dalam %.0f ms, header %s):\n", r.WindowMs, r.Header)
+    icon := "✅"
+    if r.Inconsistent > 0 {
+        icon = "❌"
+    }
+    fmt.Fprintf(w, "  %s Tidak konsisten: %d dari %d pasangan (%.2f%%)\n", icon, r.Inconsistent, r.Pairs, r.InconsistentPct)
+    if r.Skipped > 0 || r.DupErrors > 0 {
+        fmt.Fprintf(w, "  Dilewati: %d, duplikat gagal tanpa response: %d\n", r.Skipped, r.DupErrors)
+    }
+    pairs := make([]string, 0, len(r.StatusPairs))
+    for pair := range r.StatusPairs {
+        pairs = append(pairs, pair)
+    }
+    sort.Slice(pairs, func(i, j int) bool { return r.StatusPairs[pairs[i]] > r.StatusPairs[pairs[j]] })
+    for _, pair := range pairs {
+        fmt.Fprintf(w, "       status %-14s %d\n", pair, r.StatusPairs[pair])
+    }
+    for i, p := range r.Paths {
+        if i == 10 {
+            fmt.Fprintf(w, "       ... %d path lain\n", len(r.Paths)-i)
+            break
+        }
+        fmt.Fprintf(w, "       %-32s %6d  %s\n", truncate(p.Path, 32), p.Count, p.Example)
+    }
+}
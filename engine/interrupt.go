@@ -0,0 +1,46 @@
+package engine
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "os/signal"
+    "syscall"
+)
+
+// interruptSignals sinyal yang menghentikan test dengan rapi. Di Windows,
+// Ctrl+C dan Ctrl+Break sama-sama dikirim Go sebagai os.Interrupt, dan
+// jendela console yang ditutup sebagai SIGTERM.
+var interruptSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// interruptContext dibatalkan saat sinyal pertama agar jadwal berhenti dan
+// ringkasan serta report tetap ditulis. Sinyal kedua keluar paksa. stop
+// mengembalikan penanganan sinyal ke default, dipanggil setelah jadwal
+// selesai.
+func interruptContext(parent context.Context, interrupted func()) (ctx context.Context, stop func()) {
+    ctx, cancel := context.WithCancel(parent)
+    signals := make(chan os.Signal, 1)
+    signal.Notify(signals, interruptSignals...)
+    done := make(chan struct{})
+    go func() {
+        select {
+        case <-signals:
+        case <-done:
+            return
+        }
+        fmt.Println("\n⏹️  Test dihentikan, menunggu request yang berjalan selesai (tekan Ctrl+C sekali lagi untuk keluar paksa)")
+        interrupted()
+        cancel()
+        select {
+        case <-signals:
+            fmt.Println("⏹️  Keluar paksa")
+            os.Exit(130)
+        case <-done:
+        }
+    }()
+    return ctx, func() {
+        signal.Stop(signals)
+        close(done)
+        cancel()
+    }
+}
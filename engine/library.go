@@ -0,0 +1,169 @@
+// Package engine mesin load test loadtest. CLI memanggil Main; program Go
+// lain menjalankan test lewat Run dengan Config dari ParseArgs, lalu membaca
+// hasilnya dari Report.
+package engine
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "io"
+    "net/http"
+    "sync"
+)
+
+// BodyGenerator membuat body request untuk satu iterasi (nomor request,
+// mulai dari 0) pada VU (worker) tertentu, beserta Content-Type-nya (kosong
+// berarti header dari -H atau deteksi default dipakai). Error membuat
+// request itu dihitung gagal tanpa dikirim. Harus aman dipanggil bersamaan
+// dari banyak VU.
+type BodyGenerator func(iteration int, vu int) (io.Reader, string, error)
+
+// Option pengaturan Run yang tidak punya flag CLI
+type Option func(*Config)
+
+// WithBodyGenerator mengganti body request dengan hasil gen, untuk payload
+// yang terlalu rumit untuk template -d. Hanya untuk request HTTP tunggal,
+// tidak bisa dipakai bersama -d, -body-file, -param atau -scenario.
+func WithBodyGenerator(gen BodyGenerator) Option {
+    return func(config *Config) {
+        config.bodyGen = gen
+    }
+}
+
+// WithOutput menulis output terminal Run (ringkasan hasil, threshold,
+// peringatan selama test) ke w alih-alih stdout. Nil membuang output.
+// Penulisan ke w dari beberapa goroutine diserialisasi Run.
+func WithOutput(w io.Writer) Option {
+    return func(config *Config) {
+        if w == nil {
+            w = io.Discard
+        }
+        config.out = &syncWriter{w: w}
+    }
+}
+
+// syncWriter menyerialisasi Write karena output ditulis dari worker,
+// monitor dan agent secara bersamaan
+type syncWriter struct {
+    mu sync.Mutex
+    w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    return s.w.Write(p)
+}
+
+// ParseArgs membaca argumen dengan format yang sama seperti CLI, contoh
+// ParseArgs("-c", "20", "-duration", "30s", "https://api.staging.example.com")
+func ParseArgs(args ...string) (*Config, error) {
+    fs := flag.NewFlagSet("loadtest", flag.ContinueOnError)
+    fs.SetOutput(io.Discard)
+    return parseFlags(fs, args)
+}
+
+// Tahap Run yang gagal, lihat RunError
+const (
+    StageConfig = "config" // Flag atau Option tidak valid, test belum dimulai
+    StageSetup  = "setup"  // Menyiapkan requester, file output atau koneksi pendukung
+    StageRun    = "run"    // Test dihentikan sebelum jadwal selesai (ctx dibatalkan atau controller)
+    StageExport = "export" // Menulis report -out atau ekspor hasil
+)
+
+// RunError kegagalan Run beserta tahapnya. Pesan error sama dengan yang
+// ditampilkan CLI.
+type RunError struct {
+    Stage string
+    Err   error
+}
+
+func (e *RunError) Error() string { return e.Err.Error() }
+
+func (e *RunError) Unwrap() error { return e.Err }
+
+func stageError(stage string, err error) error {
+    return &RunError{Stage: stage, Err: err}
+}
+
+// ThresholdError test selesai tetapi ada threshold yang tidak terpenuhi
+// (exit code 2 di CLI). Report tetap lengkap.
+type ThresholdError struct {
+    Failed []ThresholdResult
+}
+
+func (e *ThresholdError) Error() string {
+    return fmt.Sprintf("%d threshold tidak terpenuhi", len(e.Failed))
+}
+
+// Run menjalankan satu load test seperti CLI tanpa -repeat: output terminal
+// ditulis ke stdout (atau ke WithOutput) dan report ke -out jika diisi. Run
+// tidak pernah memanggil os.Exit dan tidak menangani sinyal.
+//
+// Pembatalan ctx menghentikan jadwal seperti Ctrl+C: request yang berjalan
+// ditunggu, lalu Run mengembalikan report sebagian (Interrupted) bersama
+// RunError tahap StageRun. Error lain adalah *RunError dengan Report nil,
+// kecuali StageExport yang tetap mengembalikan report, atau *ThresholdError
+// dengan report lengkap.
+func Run(ctx context.Context, config *Config, opts ...Option) (*Report, error) {
+    for _, opt := range opts {
+        opt(config)
+    }
+    config.embedded = true
+    if err := checkLibraryConfig(config); err != nil {
+        return nil, stageError(StageConfig, err)
+    }
+    scheduler, thresholds, err := prepareRun(config)
+    if err != nil {
+        return nil, stageError(StageConfig, err)
+    }
+    if err := ctx.Err(); err != nil {
+        return nil, stageError(StageSetup, err)
+    }
+    report, breached, err := executeRun(ctx, config, scheduler, thresholds, newNotifiers(config))
+    switch {
+    case err != nil:
+        return report, err
+    case ctx.Err() != nil:
+        return report, stageError(StageRun, ctx.Err())
+    case len(breached) > 0:
+        return report, &ThresholdError{Failed: breached}
+    }
+    return report, nil
+}
+
+// checkLibraryConfig menolak flag yang hanya berlaku di CLI dan kombinasi
+// Option yang tidak didukung
+func checkLibraryConfig(config *Config) error {
+    if config.Repeat > 1 || config.BeforeHook != "" || config.AfterHook != "" || config.SSHTunnel != "" {
+        return fmt.Errorf("-repeat, -before-hook, -after-hook dan -ssh-tunnel hanya untuk CLI; panggil Run berulang atau siapkan sendiri di program")
+    }
+    if config.bodyGen != nil && (config.Body != "" || config.BodyFile != "" || len(config.Params) > 0 || config.Scenario != "" || config.TunnelBench) {
+        return fmt.Errorf("BodyGenerator tidak bisa dipakai bersama -d, -body-file, -param, -scenario atau -tunnel-bench")
+    }
+    return nil
+}
+
+// generateBody mengisi body req dari BodyGenerator. Reader dari
+// bytes/strings bisa dibaca ulang (GetBody) sehingga ikut -shadow dan
+// -idempotency.
+func generateBody(req *http.Request, gen BodyGenerator, requestNum, vu int) error {
+    body, contentType, err := gen(requestNum, vu)
+    if err != nil {
+        return fmt.Errorf("body generator: %w", err)
+    }
+    // NewRequest menentukan ContentLength dan GetBody dari jenis reader
+    generated, err := http.NewRequest(req.Method, req.URL.String(), body)
+    if err != nil {
+        return err
+    }
+    req.Body, req.GetBody, req.ContentLength = generated.Body, generated.GetBody, generated.ContentLength
+    if req.Body == nil {
+        req.Body = http.NoBody
+    }
+    if contentType != "" {
+        req.Header.Set("Content-Type", contentType)
+    }
+    return nil
+}
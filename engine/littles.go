@@ -0,0 +1,65 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+)
+
+// LittlesLawReport membandingkan concurrency efektif menurut Little's Law
+// (L = throughput × latency rata-rata) dengan jumlah worker yang dikonfigurasi
+type LittlesLawReport struct {
+    Expected    float64 `json:"expected_concurrency"`
+    Configured  int     `json:"configured_concurrency"`
+    Utilization float64 `json:"utilization"` // Persen waktu worker berada di dalam request
+    Warning     string  `json:"warning,omitempty"`
+}
+
+// Batas utilisasi yang dianggap janggal
+const (
+    closedLoopMinUtilization = 80.0 // Worker selalu punya job, jadi seharusnya mendekati 100%
+    openLoopMaxUtilization   = 95.0 // Di atas ini request terjadwal mulai mengantre di client
+)
+
+// littlesLaw menghitung sanity check Little's Law. Pada jadwal closed-loop
+// (-n / -duration) setiap worker langsung mengambil job berikutnya, sehingga
+// utilisasi rendah berarti waktu hilang di generator. Pada jadwal open-loop
+// (rate/stages/replay) utilisasi mendekati 100% berarti semua worker sibuk
+// dan request terjadwal menunggu di client, sehingga latency yang terukur
+// lebih rendah dari yang dialami pengguna.
+func littlesLaw(report *Report, scheduler Scheduler) *LittlesLawReport {
+    if report.TotalRequests == 0 || report.Concurrency <= 0 {
+        return nil
+    }
+    l := &LittlesLawReport{
+        Expected:   report.RPS * report.AvgLatencyMs / 1000,
+        Configured: report.Concurrency,
+    }
+    l.Utilization = l.Expected / float64(l.Configured) * 100
+
+    switch scheduler.(type) {
+    case *countScheduler, *durationScheduler:
+        // Dengan -pacing worker memang menunggu di antara iterasi
+        if l.Utilization < closedLoopMinUtilization && report.Pacing == nil {
+            l.Warning = fmt.Sprintf("generator kurang termanfaatkan: worker %.0f%% waktu di luar request (overhead client, CPU generator atau -c lebih besar dari jumlah request)", 100-l.Utilization)
+        }
+    default:
+        if l.Utilization >= openLoopMaxUtilization {
+            l.Warning = "semua worker sibuk: request terjadwal mengantre di client sehingga rate target bisa tidak tercapai, naikkan -c"
+        }
+    }
+    return l
+}
+
+func printLittlesLaw(w io.Writer, report *Report) {
+    l := report.LittlesLaw
+    if l == nil {
+        return
+    }
+    fmt.Fprintln(w, "\n🧮 Little's Law:")
+    fmt.Fprintf(w, "  %-23s %.1f (req/s × avg latency)\n", "Concurrency efektif:", l.Expected)
+    fmt.Fprintf(w, "  %-23s %d\n", "Concurrency (-c):", l.Configured)
+    fmt.Fprintf(w, "  %-23s %.1f%%\n", "Utilisasi worker:", l.Utilization)
+    if l.Warning != "" {
+        fmt.Fprintf(w, "  ⚠️  %s\n", l.Warning)
+    }
+}
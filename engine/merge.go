@@ -0,0 +1,300 @@
+package engine
+
+import (
+    "bytes"
+    "compress/gzip"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "io"
+    "os"
+    "slices"
+    "sort"
+    "strings"
+    "time"
+)
+
+// resultFormat penanda file hasil .bin. Layout histogram ikut ditulis
+// karena index bucket hanya bisa digabung dengan layout yang sama.
+var resultFormat = fmt.Sprintf("loadtest-result/1 hist %d/%d", histSubBits, histExponents)
+
+// ResultFile hasil run yang bisa digabung dengan `loadtest merge`: report
+// beserta isi histogram latency yang tidak ikut di report JSON. Disimpan
+// sebagai JSON terkompresi gzip.
+type ResultFile struct {
+    Format  string       `json:"format"`
+    Report  *Report      `json:"report"`
+    Latency []HistBucket `json:"latency_histogram"` // Hanya bucket berisi
+}
+
+// HistBucket jumlah sampel pada satu bucket histogram
+type HistBucket struct {
+    Index int   `json:"i"`
+    Count int64 `json:"n"`
+}
+
+// MergedRun satu run yang digabung ke report ini
+type MergedRun struct {
+    RunID         string  `json:"run_id"`
+    Host          string  `json:"generator_host"`
+    Agent         string  `json:"agent,omitempty"`
+    TotalRequests int64   `json:"total_requests"`
+    RPS           float64 `json:"requests_per_second"`
+    P95LatencyMs  float64 `json:"p95_latency_ms"`
+    ErrorRate     float64 `json:"error_rate"`
+}
+
+// Result file hasil .bin, butuh histogram latency dari run ini
+func (r *Report) Result() ([]byte, error) {
+    if r.latency == nil {
+        return nil, fmt.Errorf("histogram latency tidak tersedia untuk report ini")
+    }
+    result := ResultFile{Format: resultFormat, Report: r}
+    for i := range r.latency.counts {
+        if n := r.latency.counts[i].Load(); n > 0 {
+            result.Latency = append(result.Latency, HistBucket{Index: i, Count: n})
+        }
+    }
+    var buf bytes.Buffer
+    zw := gzip.NewWriter(&buf)
+    if err := json.NewEncoder(zw).Encode(result); err != nil {
+        return nil, err
+    }
+    if err := zw.Close(); err != nil {
+        return nil, err
+    }
+    return buf.Bytes(), nil
+}
+
+// loadResult membaca file hasil .bin dan mengisi ulang histogram latency
+func loadResult(path string) (*Report, error) {
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+    zr, err := gzip.NewReader(f)
+    if err != nil {
+        return nil, fmt.Errorf("%s bukan file hasil .bin (simpan dengan -out hasil.bin): %w", path, err)
+    }
+    var result ResultFile
+    if err := json.NewDecoder(io.LimitReader(zr, 1<<30)).Decode(&result); err != nil {
+        return nil, fmt.Errorf("%s bukan file hasil yang valid: %w", path, err)
+    }
+    if result.Format != resultFormat || result.Report == nil {
+        return nil, fmt.Errorf("%s: format %q tidak didukung versi ini (%s)", path, result.Format, resultFormat)
+    }
+    h := &histogram{}
+    for _, b := range result.Latency {
+        if b.Index < 0 || b.Index >= histBuckets {
+            return nil, fmt.Errorf("%s: bucket histogram %d di luar layout", path, b.Index)
+        }
+        h.counts[b.Index].Add(b.Count)
+        h.total.Add(b.Count)
+    }
+    result.Report.latency = h
+    return result.Report, nil
+}
+
+// runMerge menggabungkan hasil beberapa agent yang dijalankan terpisah
+// tanpa controller menjadi satu report
+func runMerge(args []string) int {
+    fs := flag.NewFlagSet("merge", flag.ExitOnError)
+    var out string
+    fs.StringVar(&out, "out", "", "Simpan report gabungan ke file (.json, .html, .md atau .bin)")
+    fs.StringVar(&out, "o", "", "Sama dengan -out")
+    includeQuarantined := fs.Bool("include-quarantined", false, "Ikut gabungkan hasil agent yang ditandai quarantined karena konfigurasinya berbeda")
+    fs.Usage = func() {
+        fmt.Fprintf(os.Stderr, "Usage: loadtest merge [options] agent1.bin agent2.bin ... -o gabungan.html\n\n")
+        fmt.Fprintf(os.Stderr, "File hasil .bin disimpan setiap agent dengan -out hasil.bin. Histogram latency\n")
+        fmt.Fprintf(os.Stderr, "digabung sehingga percentile gabungan tepat, bukan rata-rata percentile.\n\n")
+        fmt.Fprintf(os.Stderr, "Options:\n")
+        fs.PrintDefaults()
+    }
+    paths := parseInterspersed(fs, args)
+    if len(paths) < 2 {
+        fs.Usage()
+        return 1
+    }
+
+    var reports []*Report
+    for _, path := range paths {
+        report, err := loadResult(path)
+        if err != nil {
+            fmt.Printf("Error: %v\n", err)
+            return 1
+        }
+        if agent := report.Metadata.Agent; agent != nil && agent.Quarantined && !*includeQuarantined {
+            fmt.Printf("⚠️  %s dilewati: agent %s quarantined (%s)\n", path, agent.Agent, agent.Drift)
+            continue
+        }
+        reports = append(reports, report)
+    }
+    if len(reports) == 0 {
+        fmt.Println("Error: tidak ada hasil yang bisa digabung")
+        return 1
+    }
+
+    merged, err := mergeReports(reports)
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    printMerged(merged)
+    if out == "" {
+        return 0
+    }
+    config := &Config{Precision: 2, out: os.Stdout}
+    if merged.Brand, err = newReportBrand(config); err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    if merged.display, err = newNumberFormat(config); err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    if err := writeReport(merged, out); err != nil {
+        fmt.Printf("Error: menulis report: %v\n", err)
+        return 1
+    }
+    fmt.Printf("💾 Report gabungan disimpan ke %s\n", out)
+    return 0
+}
+
+// parseInterspersed mem-parse flag yang boleh berada di antara argumen
+// posisi, seperti `merge a.bin b.bin -o gabungan.html`
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+    var positional []string
+    for {
+        fs.Parse(args)
+        args = fs.Args()
+        for len(args) > 0 && (args[0] == "-" || !strings.HasPrefix(args[0], "-")) {
+            positional = append(positional, args[0])
+            args = args[1:]
+        }
+        if len(args) == 0 {
+            return positional
+        }
+    }
+}
+
+// mergeReports menggabungkan report beberapa agent. Counter dan status code
+// dijumlahkan, percentile dihitung dari histogram gabungan dan rate dari
+// rentang waktu semua run. Bagian lain report (stage, koneksi, fase) tidak
+// digabung.
+func mergeReports(reports []*Report) (*Report, error) {
+    first := reports[0]
+    for _, r := range reports[1:] {
+        if r.Method+" "+r.URL+" "+r.Scenario != first.Method+" "+first.URL+" "+first.Scenario {
+            return nil, fmt.Errorf("target %s berbeda dengan %s, hasil tidak bisa digabung", r.RunID, first.RunID)
+        }
+        for _, diff := range compareMetadata(first, r) {
+            if !strings.HasPrefix(diff, "generator host") && !strings.HasPrefix(diff, "CPU generator") &&
+                !strings.HasPrefix(diff, "concurrency") && !strings.HasPrefix(diff, "jadwal") {
+                fmt.Printf("⚠️  %s: %s\n", r.RunID, diff)
+            }
+        }
+    }
+
+    sort.Slice(reports, func(i, j int) bool { return reports[i].StartTime.Before(reports[j].StartTime) })
+    start, end := reports[0].StartTime, time.Time{}
+    merged := &Report{
+        SchemaVersion: reportSchemaVersion,
+        URL:           first.URL,
+        Scenario:      first.Scenario,
+        Method:        first.Method,
+        StartTime:     start,
+        StatusCodes:   make(map[int]int64),
+        Metadata:      first.Metadata,
+        latency:       &histogram{},
+    }
+    merged.RunID = "merge-" + start.UTC().Format("20060102T150405Z")
+    merged.Metadata.Agent, merged.Metadata.Clock = nil, nil
+
+    var hosts, schedules []string
+    var totalLatency float64
+    for _, r := range reports {
+        if finish := r.StartTime.Add(time.Duration(r.TotalTimeMs * float64(time.Millisecond))); finish.After(end) {
+            end = finish
+        }
+        merged.Concurrency += r.Concurrency
+        merged.TotalRequests += r.TotalRequests
+        merged.Successful += r.Successful
+        merged.Failed += r.Failed
+        merged.Retries += r.Retries
+        totalLatency += r.AvgLatencyMs * float64(r.TotalRequests)
+        if r.TotalRequests > 0 && (merged.MinLatencyMs == 0 || r.MinLatencyMs < merged.MinLatencyMs) {
+            merged.MinLatencyMs = r.MinLatencyMs
+        }
+        if r.TotalRequests > 0 {
+            merged.MaxLatencyMs = max(merged.MaxLatencyMs, r.MaxLatencyMs)
+        }
+        for code, n := range r.StatusCodes {
+            merged.StatusCodes[code] += n
+        }
+        for vendor, n := range r.Challenges {
+            if merged.Challenges == nil {
+                merged.Challenges = make(map[string]int64)
+            }
+            merged.Challenges[vendor] += n
+        }
+        merged.latency.merge(r.latency)
+        if !slices.Contains(hosts, r.Metadata.GeneratorHost) {
+            hosts = append(hosts, r.Metadata.GeneratorHost)
+        }
+        if !slices.Contains(schedules, r.Schedule) {
+            schedules = append(schedules, r.Schedule)
+        }
+        run := MergedRun{
+            RunID:         r.RunID,
+            Host:          r.Metadata.GeneratorHost,
+            TotalRequests: r.TotalRequests,
+            RPS:           r.RPS,
+            P95LatencyMs:  r.P95LatencyMs,
+            ErrorRate:     r.ErrorRate,
+        }
+        if r.Metadata.Agent != nil {
+            run.Agent = r.Metadata.Agent.Agent
+        }
+        merged.Merged = append(merged.Merged, run)
+    }
+    merged.Metadata.GeneratorHost = strings.Join(hosts, ",")
+    merged.Schedule = fmt.Sprintf("gabungan %d run: %s", len(reports), strings.Join(schedules, "; "))
+    window := end.Sub(start)
+    merged.TotalTimeMs = durationMs(window)
+
+    if merged.TotalRequests > 0 {
+        merged.RPS = float64(merged.TotalRequests) / window.Seconds()
+        merged.AvgLatencyMs = totalLatency / float64(merged.TotalRequests)
+        percentile := func(q float64) float64 {
+            return max(merged.MinLatencyMs, min(merged.MaxLatencyMs, durationMs(merged.latency.quantileDuration(q))))
+        }
+        merged.P50LatencyMs = percentile(0.50)
+        merged.P90LatencyMs = percentile(0.90)
+        merged.P95LatencyMs = percentile(0.95)
+        merged.P99LatencyMs = percentile(0.99)
+        merged.SuccessRate = float64(merged.Successful) / float64(merged.TotalRequests) * 100
+        errors := merged.Failed
+        for code, count := range merged.StatusCodes {
+            if code >= 400 {
+                errors += count
+            }
+        }
+        merged.ErrorRate = float64(errors) / float64(merged.TotalRequests) * 100
+    }
+    return merged, nil
+}
+
+func printMerged(r *Report) {
+    fmt.Println(strings.Repeat("=", 72))
+    fmt.Printf("📊 HASIL GABUNGAN %d RUN\n", len(r.Merged))
+    fmt.Println(strings.Repeat("=", 72))
+    fmt.Printf("  %-34s %10s %10s %10s %8s\n", "Run", "Requests", "Req/s", "p95 ms", "Error %")
+    for _, run := range r.Merged {
+        fmt.Printf("  %-34s %10d %10.1f %10.2f %8.2f\n", run.RunID, run.TotalRequests, run.RPS, run.P95LatencyMs, run.ErrorRate)
+    }
+    fmt.Printf("  %-34s %10d %10.1f %10.2f %8.2f\n", "Gabungan", r.TotalRequests, r.RPS, r.P95LatencyMs, r.ErrorRate)
+    fmt.Printf("\nRentang waktu:            %v\n", time.Duration(r.TotalTimeMs*float64(time.Millisecond)).Round(time.Millisecond))
+    fmt.Printf("Latency p50/p95/p99:      %.2f ms / %.2f ms / %.2f ms\n", r.P50LatencyMs, r.P95LatencyMs, r.P99LatencyMs)
+    fmt.Printf("Latency min/avg/max:      %.2f ms / %.2f ms / %.2f ms\n", r.MinLatencyMs, r.AvgLatencyMs, r.MaxLatencyMs)
+}
@@ -0,0 +1,181 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "math"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// Jenis metrik custom dari skenario
+const (
+    metricCounter = "counter" // Dijumlahkan, contoh jumlah order
+    metricGauge   = "gauge"   // Nilai terakhir beserta min/max, contoh stok
+    metricTrend   = "trend"   // Distribusi nilai, contoh jumlah item per pencarian
+)
+
+// trendScale presisi nilai trend di histogram (3 angka di belakang koma)
+const trendScale = 1000
+
+// metricSample satu nilai metrik custom dari satu step
+type metricSample struct {
+    name  string
+    kind  string
+    value float64
+}
+
+// compiledMetric definisi metrik pada step: "trend:{{.Vars.count}}"
+type compiledMetric struct {
+    kind  string
+    value *valueTemplate
+}
+
+func parseMetric(name, spec string) (compiledMetric, error) {
+    kind, raw, found := strings.Cut(spec, ":")
+    if !found || raw == "" {
+        return compiledMetric{}, fmt.Errorf("metrik %s %q tidak valid (format: jenis:nilai)", name, spec)
+    }
+    switch kind {
+    case metricCounter, metricGauge, metricTrend:
+    default:
+        return compiledMetric{}, fmt.Errorf("jenis metrik %q tidak dikenal (counter, gauge, trend)", kind)
+    }
+    value, err := parseValueTemplate("metric "+name, raw)
+    if err != nil {
+        return compiledMetric{}, err
+    }
+    return compiledMetric{kind: kind, value: value}, nil
+}
+
+func (m compiledMetric) sample(name string, data templateData) (metricSample, error) {
+    out, err := m.value.render(data)
+    if err != nil {
+        return metricSample{}, fmt.Errorf("metric %s: %w", name, err)
+    }
+    value, err := strconv.ParseFloat(strings.TrimSpace(out), 64)
+    if err != nil {
+        return metricSample{}, fmt.Errorf("metric %s: nilai %q bukan angka", name, out)
+    }
+    return metricSample{name: name, kind: m.kind, value: value}, nil
+}
+
+// customMetric agregat satu metrik custom
+type customMetric struct {
+    kind string
+
+    mu       sync.Mutex
+    count    int64
+    sum      float64
+    last     float64
+    min, max float64
+    values   histogram // Hanya trend, nilai dikali trendScale
+}
+
+// metricSet kumpulan metrik custom per nama. Zero value siap dipakai.
+type metricSet struct {
+    metrics sync.Map // string -> *customMetric
+}
+
+func (s *metricSet) add(sample metricSample) {
+    m, ok := s.metrics.Load(sample.name)
+    if !ok {
+        m, _ = s.metrics.LoadOrStore(sample.name, &customMetric{kind: sample.kind})
+    }
+    metric := m.(*customMetric)
+    metric.mu.Lock()
+    defer metric.mu.Unlock()
+    if metric.count == 0 || sample.value < metric.min {
+        metric.min = sample.value
+    }
+    if metric.count == 0 || sample.value > metric.max {
+        metric.max = sample.value
+    }
+    metric.count++
+    metric.sum += sample.value
+    metric.last = sample.value
+    if metric.kind == metricTrend {
+        metric.values.add(int64(math.Round(sample.value * trendScale)))
+    }
+}
+
+// CustomMetricReport ringkasan satu metrik custom. Field yang terisi
+// bergantung jenisnya: counter (total, rate), gauge (value, min, max),
+// trend (avg, min, p50, p95, p99, max).
+type CustomMetricReport struct {
+    Type  string   `json:"type"`
+    Count int64    `json:"count"`
+    Total *float64 `json:"total,omitempty"`
+    Rate  *float64 `json:"rate_per_second,omitempty"`
+    Value *float64 `json:"value,omitempty"`
+    Avg   *float64 `json:"avg,omitempty"`
+    Min   float64  `json:"min"`
+    P50   *float64 `json:"p50,omitempty"`
+    P95   *float64 `json:"p95,omitempty"`
+    P99   *float64 `json:"p99,omitempty"`
+    Max   float64  `json:"max"`
+}
+
+func (s *metricSet) report(totalTime time.Duration) map[string]CustomMetricReport {
+    reports := make(map[string]CustomMetricReport)
+    s.metrics.Range(func(key, value any) bool {
+        m := value.(*customMetric)
+        m.mu.Lock()
+        defer m.mu.Unlock()
+        r := CustomMetricReport{Type: m.kind, Count: m.count, Min: m.min, Max: m.max}
+        switch m.kind {
+        case metricCounter:
+            total, rate := m.sum, m.sum/totalTime.Seconds()
+            r.Total, r.Rate = &total, &rate
+        case metricGauge:
+            last := m.last
+            r.Value = &last
+        case metricTrend:
+            // Nilai bucket histogram hanya perkiraan, jangan keluar dari min/max
+            quantile := func(q float64) *float64 {
+                v := max(m.min, min(m.max, float64(m.values.quantile(q))/trendScale))
+                return &v
+            }
+            avg := m.sum / float64(m.count)
+            r.Avg, r.P50, r.P95, r.P99 = &avg, quantile(0.50), quantile(0.95), quantile(0.99)
+        }
+        reports[key.(string)] = r
+        return true
+    })
+    if len(reports) == 0 {
+        return nil
+    }
+    return reports
+}
+
+// Summary ringkasan satu baris untuk terminal dan report HTML
+func (r CustomMetricReport) Summary() string {
+    switch r.Type {
+    case metricCounter:
+        return fmt.Sprintf("total %g (%.2f/s)", *r.Total, *r.Rate)
+    case metricGauge:
+        return fmt.Sprintf("nilai %g (min %g, max %g)", *r.Value, r.Min, r.Max)
+    default:
+        return fmt.Sprintf("avg %.2f, min %.2f, p50 %.2f, p95 %.2f, p99 %.2f, max %.2f", *r.Avg, r.Min, *r.P50, *r.P95, *r.P99, r.Max)
+    }
+}
+
+func printCustomMetrics(w io.Writer, report *Report) {
+    if len(report.Metrics) == 0 {
+        return
+    }
+    names := make([]string, 0, len(report.Metrics))
+    for name := range report.Metrics {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    fmt.Fprintln(w, "\n📏 Metrik Custom:")
+    for _, name := range names {
+        m := report.Metrics[name]
+        fmt.Fprintf(w, "  %-20s %-8s %s\n", name, m.Type, m.Summary())
+    }
+}
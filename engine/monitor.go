@@ -0,0 +1,277 @@
+package engine
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "math"
+    "net"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+)
+
+// monitorEvalEvery jarak evaluasi SLO window bergulir pada -monitor
+const monitorEvalEvery = 10 * time.Second
+
+// sloMonitor (-monitor) mengirim beban rendah tanpa batas waktu di antara
+// load test penuh. Setiap monitorEvalEvery, hasil request dalam window
+// terakhir dievaluasi terhadap -thresholds dan metriknya tersedia untuk
+// Prometheus di -monitor-addr.
+type sloMonitor struct {
+    config     *Config
+    scheduler  Scheduler
+    thresholds []Threshold
+    window     time.Duration
+
+    server *http.Server
+    done   chan struct{}
+    wg     sync.WaitGroup
+
+    mu           sync.Mutex
+    began        time.Time
+    samples      []Result // Hasil yang selesai dalam window
+    total        int64    // Sejak monitor mulai
+    errors       int64    // Request gagal atau status >= 400 sejak monitor mulai
+    last         *Report  // Report window evaluasi terakhir
+    evaluations  int64
+    breaches     int64 // Berapa kali SLO berubah dari terpenuhi menjadi dilanggar
+    failing      bool
+    failingSince time.Time
+    failingTime  time.Duration
+}
+
+func newSLOMonitor(config *Config, scheduler Scheduler, thresholds []Threshold) *sloMonitor {
+    if !config.Monitor {
+        return nil
+    }
+    return &sloMonitor{
+        config:     config,
+        scheduler:  scheduler,
+        thresholds: thresholds,
+        window:     config.MonitorWindow,
+        done:       make(chan struct{}),
+    }
+}
+
+// start membuka endpoint Prometheus lalu mulai mengevaluasi window
+func (m *sloMonitor) start(startTime time.Time) error {
+    if m == nil {
+        return nil
+    }
+    m.began = startTime
+    if m.config.MonitorAddr != "" {
+        listener, err := net.Listen("tcp", m.config.MonitorAddr)
+        if err != nil {
+            return fmt.Errorf("-monitor-addr: %w", err)
+        }
+        mux := http.NewServeMux()
+        mux.HandleFunc("GET /metrics", m.serveMetrics)
+        m.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+        go func() {
+            if err := m.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+                fmt.Fprintf(m.config.out, "⚠️  Endpoint metrik monitor berhenti: %v\n", err)
+            }
+        }()
+        fmt.Fprintf(m.config.out, "📡 Metrik Prometheus: http://%s/metrics\n", listener.Addr())
+    }
+    fmt.Fprintf(m.config.out, "🩺 Monitor: SLO dievaluasi setiap %v pada window %v, tekan Ctrl+C untuk berhenti\n", monitorEvalEvery, m.window)
+    m.wg.Add(1)
+    go func() {
+        defer m.wg.Done()
+        ticker := time.NewTicker(monitorEvalEvery)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-m.done:
+                return
+            case now := <-ticker.C:
+                m.evaluate(now)
+            }
+        }
+    }()
+    return nil
+}
+
+// stop menghentikan evaluasi dan endpoint metrik
+func (m *sloMonitor) stop() {
+    if m == nil {
+        return
+    }
+    close(m.done)
+    m.wg.Wait()
+    if m.server != nil {
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        m.server.Shutdown(ctx)
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if m.failing {
+        m.failingTime += time.Since(m.failingSince)
+    }
+}
+
+// observe menyimpan ringkasan hasil request; body dan metrik lain tidak
+// dibutuhkan untuk evaluasi window
+func (m *sloMonitor) observe(result Result) {
+    if m == nil {
+        return
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.total++
+    if result.Err != nil || result.StatusCode >= 400 {
+        m.errors++
+    }
+    m.samples = append(m.samples, Result{
+        Start:      result.Start,
+        Duration:   result.Duration,
+        StatusCode: result.StatusCode,
+        Bytes:      result.Bytes,
+        Err:        result.Err,
+    })
+}
+
+// evaluate membangun report dari request dalam window lalu memeriksa SLO
+func (m *sloMonitor) evaluate(now time.Time) {
+    m.mu.Lock()
+    cutoff := now.Add(-m.window)
+    keep := 0
+    for keep < len(m.samples) && m.samples[keep].Start.Add(m.samples[keep].Duration).Before(cutoff) {
+        keep++
+    }
+    m.samples = append(m.samples[:0], m.samples[keep:]...)
+    samples := append([]Result(nil), m.samples...)
+    span := min(m.window, now.Sub(m.began))
+    m.mu.Unlock()
+
+    stats := &Stats{}
+    for i, result := range samples {
+        stats.Record(i, result)
+    }
+    report := buildReport(m.config, m.scheduler, stats, now.Add(-span), span)
+    breached := evaluateThresholds(m.thresholds, report)
+
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.last = report
+    m.evaluations++
+    line := fmt.Sprintf("[%s] %v terakhir: %d req, p95 %.1f ms, error %.2f%%",
+        now.Format("15:04:05"), span.Round(time.Second), report.TotalRequests, report.P95LatencyMs, report.ErrorRate)
+    switch {
+    case len(m.thresholds) == 0 || report.TotalRequests == 0:
+        fmt.Fprintf(m.config.out, "🩺 %s\n", line)
+    case len(breached) > 0:
+        var failed []string
+        for _, result := range breached {
+            failed = append(failed, result.String())
+        }
+        if !m.failing {
+            m.failing, m.failingSince = true, now
+            m.breaches++
+        }
+        fmt.Fprintf(m.config.out, "🚨 %s - SLO dilanggar: %s\n", line, strings.Join(failed, ", "))
+    case m.failing:
+        m.failing = false
+        m.failingTime += now.Sub(m.failingSince)
+        fmt.Fprintf(m.config.out, "✅ %s - SLO pulih setelah %v\n", line, now.Sub(m.failingSince).Round(time.Second))
+    default:
+        fmt.Fprintf(m.config.out, "🩺 %s - SLO terpenuhi\n", line)
+    }
+}
+
+// serveMetrics menulis metrik monitor dalam format teks Prometheus
+func (m *sloMonitor) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+    target := promLabelValue(firstNonEmpty(m.config.URL, m.config.Scenario))
+    metric := func(name, kind, help string) {
+        fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+    }
+    metric("loadtest_monitor_requests_total", "counter", "Request yang dikirim sejak monitor mulai")
+    fmt.Fprintf(w, "loadtest_monitor_requests_total{target=%s} %d\n", target, m.total)
+    metric("loadtest_monitor_errors_total", "counter", "Request gagal atau status >= 400 sejak monitor mulai")
+    fmt.Fprintf(w, "loadtest_monitor_errors_total{target=%s} %d\n", target, m.errors)
+    metric("loadtest_monitor_slo_breaches_total", "counter", "Berapa kali SLO berubah menjadi dilanggar")
+    fmt.Fprintf(w, "loadtest_monitor_slo_breaches_total{target=%s} %d\n", target, m.breaches)
+    if m.last == nil {
+        return
+    }
+    r := m.last
+    metric("loadtest_monitor_window_requests", "gauge", "Request dalam window terakhir")
+    fmt.Fprintf(w, "loadtest_monitor_window_requests{target=%s} %d\n", target, r.TotalRequests)
+    metric("loadtest_monitor_window_error_rate_percent", "gauge", "Persen error dalam window terakhir")
+    fmt.Fprintf(w, "loadtest_monitor_window_error_rate_percent{target=%s} %g\n", target, r.ErrorRate)
+    metric("loadtest_monitor_window_latency_ms", "gauge", "Percentile latency dalam window terakhir")
+    for _, q := range []struct {
+        quantile string
+        value    float64
+    }{{"0.5", r.P50LatencyMs}, {"0.9", r.P90LatencyMs}, {"0.95", r.P95LatencyMs}, {"0.99", r.P99LatencyMs}} {
+        fmt.Fprintf(w, "loadtest_monitor_window_latency_ms{target=%s,quantile=%q} %g\n", target, q.quantile, q.value)
+    }
+    if len(r.Thresholds) == 0 {
+        return
+    }
+    ok := 1
+    if m.failing {
+        ok = 0
+    }
+    metric("loadtest_monitor_slo_ok", "gauge", "1 jika semua threshold terpenuhi pada window terakhir")
+    fmt.Fprintf(w, "loadtest_monitor_slo_ok{target=%s} %d\n", target, ok)
+    metric("loadtest_monitor_threshold_actual", "gauge", "Nilai metrik threshold pada window terakhir")
+    for _, result := range r.Thresholds {
+        actual := result.Actual
+        if result.NoData {
+            actual = math.NaN()
+        }
+        fmt.Fprintf(w, "loadtest_monitor_threshold_actual{target=%s,threshold=%s} %g\n", target, promLabelValue(result.Threshold), actual)
+    }
+}
+
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// promLabelValue nilai label Prometheus lengkap dengan tanda kutip
+func promLabelValue(value string) string {
+    return `"` + promLabelEscaper.Replace(value) + `"`
+}
+
+// MonitorReport ringkasan mode -monitor
+type MonitorReport struct {
+    WindowMs    float64 `json:"window_ms"`
+    Evaluations int64   `json:"evaluations"`
+    Breaches    int64   `json:"breaches"`   // Berapa kali SLO berubah menjadi dilanggar
+    FailingMs   float64 `json:"failing_ms"` // Total waktu SLO dilanggar
+    Failing     bool    `json:"failing"`    // SLO masih dilanggar saat monitor berhenti
+}
+
+func (m *sloMonitor) report() *MonitorReport {
+    if m == nil {
+        return nil
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return &MonitorReport{
+        WindowMs:    durationMs(m.window),
+        Evaluations: m.evaluations,
+        Breaches:    m.breaches,
+        FailingMs:   durationMs(m.failingTime),
+        Failing:     m.failing,
+    }
+}
+
+func printMonitor(w io.Writer, report *Report) {
+    m := report.Monitor
+    if m == nil {
+        return
+    }
+    fmt.Fprintf(w, "\n🩺 Monitor (window %v):\n", time.Duration(m.WindowMs*float64(time.Millisecond)))
+    fmt.Fprintf(w, "  Evaluasi SLO:          %d\n", m.Evaluations)
+    fmt.Fprintf(w, "  SLO dilanggar:         %d kali, total %v\n", m.Breaches, time.Duration(m.FailingMs*float64(time.Millisecond)).Round(time.Second))
+    if m.Failing {
+        fmt.Fprintln(w, "  🚨 SLO masih dilanggar saat monitor berhenti")
+    }
+}
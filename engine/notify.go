@@ -0,0 +1,58 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "time"
+)
+
+// Notifier menerima event siklus hidup run untuk integrasi eksternal
+// (anotasi dashboard, alerting, dst). Error dari notifier hanya ditampilkan
+// sebagai peringatan dan tidak menggagalkan test.
+type Notifier interface {
+    RunStarted(runID string, config *Config, startTime time.Time) error
+    RunFinished(report *Report, breached []ThresholdResult) error
+}
+
+func newNotifiers(config *Config) []Notifier {
+    var notifiers []Notifier
+    if config.GrafanaURL != "" {
+        notifiers = append(notifiers, newGrafanaNotifier(config))
+    }
+    if key := firstNonEmpty(config.PagerDutyKey, os.Getenv("PAGERDUTY_ROUTING_KEY")); key != "" {
+        notifiers = append(notifiers, newPagerDutyNotifier(key))
+    }
+    if key := firstNonEmpty(config.OpsgenieKey, os.Getenv("OPSGENIE_API_KEY")); key != "" {
+        notifiers = append(notifiers, newOpsgenieNotifier(key))
+    }
+    if config.EmailTo != "" {
+        notifiers = append(notifiers, newEmailNotifier(config))
+    }
+    return notifiers
+}
+
+func notifyRunStarted(notifiers []Notifier, runID string, config *Config, startTime time.Time) {
+    for _, n := range notifiers {
+        if err := n.RunStarted(runID, config, startTime); err != nil {
+            fmt.Fprintf(config.out, "⚠️  Notifikasi mulai gagal: %v\n", err)
+        }
+    }
+}
+
+func notifyRunFinished(w io.Writer, notifiers []Notifier, report *Report, breached []ThresholdResult) {
+    for _, n := range notifiers {
+        if err := n.RunFinished(report, breached); err != nil {
+            fmt.Fprintf(w, "⚠️  Notifikasi selesai gagal: %v\n", err)
+        }
+    }
+}
+
+func firstNonEmpty(values ...string) string {
+    for _, v := range values {
+        if v != "" {
+            return v
+        }
+    }
+    return ""
+}
@@ -0,0 +1,152 @@
+package engine
+
+import (
+    "fmt"
+    "math"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// numberLocales pemisah ribuan dan desimal per bahasa
+var numberLocales = map[string][2]string{
+    "en":  {",", "."},
+    "id":  {".", ","},
+    "de":  {".", ","},
+    "nl":  {".", ","},
+    "es":  {".", ","},
+    "it":  {".", ","},
+    "pt":  {".", ","},
+    "fr":  {" ", ","},
+    "raw": {"", "."}, // Tanpa pemisah ribuan, seperti output lama
+}
+
+// numberFormat format angka untuk output yang dibaca manusia (terminal,
+// report HTML/Markdown). JSON, CSV dan export lain tetap angka mentah.
+// Zero value sama dengan locale en presisi 2.
+type numberFormat struct {
+    group, decimal string
+    precision      int
+}
+
+// newNumberFormat memilih locale dari -locale, atau LC_ALL/LC_NUMERIC/LANG
+// jika kosong
+func newNumberFormat(config *Config) (numberFormat, error) {
+    if config.Precision < 0 || config.Precision > 6 {
+        return numberFormat{}, fmt.Errorf("-precision harus 0 sampai 6")
+    }
+    locale := config.Locale
+    if locale == "" {
+        locale = envLocale()
+    }
+    seps, ok := numberLocales[localeLanguage(locale)]
+    if !ok {
+        if config.Locale != "" {
+            return numberFormat{}, fmt.Errorf("locale %q tidak dikenal (en, id, de, nl, es, it, pt, fr, raw)", config.Locale)
+        }
+        seps = numberLocales["en"]
+    }
+    return numberFormat{group: seps[0], decimal: seps[1], precision: config.Precision}, nil
+}
+
+func envLocale() string {
+    for _, name := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+        if value := os.Getenv(name); value != "" {
+            return value
+        }
+    }
+    return "en"
+}
+
+// localeLanguage mengambil kode bahasa dari format seperti id_ID.UTF-8
+func localeLanguage(locale string) string {
+    lang := strings.ToLower(locale)
+    if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+        lang = lang[:i]
+    }
+    if lang == "c" || lang == "posix" {
+        return "en"
+    }
+    return lang
+}
+
+func (f numberFormat) orDefault() numberFormat {
+    if f.decimal == "" {
+        return numberFormat{group: ",", decimal: ".", precision: 2}
+    }
+    return f
+}
+
+// Int bilangan bulat dengan pemisah ribuan
+func (f numberFormat) Int(n int64) string {
+    f = f.orDefault()
+    digits := strconv.FormatInt(n, 10)
+    sign := ""
+    if n < 0 {
+        sign, digits = "-", digits[1:]
+    }
+    return sign + groupDigits(digits, f.group)
+}
+
+// Float bilangan desimal dengan presisi -precision
+func (f numberFormat) Float(v float64) string {
+    return f.FloatN(v, f.orDefault().precision)
+}
+
+// FloatN bilangan desimal dengan presisi tertentu, untuk kolom yang
+// presisinya tetap seperti persentase
+func (f numberFormat) FloatN(v float64, precision int) string {
+    f = f.orDefault()
+    if math.IsNaN(v) || math.IsInf(v, 0) {
+        return strconv.FormatFloat(v, 'f', -1, 64)
+    }
+    s := strconv.FormatFloat(math.Abs(v), 'f', precision, 64)
+    whole, frac, _ := strings.Cut(s, ".")
+    s = groupDigits(whole, f.group)
+    if frac != "" {
+        s += f.decimal + frac
+    }
+    if v < 0 && strings.Trim(s, "0"+f.group+f.decimal) != "" {
+        s = "-" + s
+    }
+    return s
+}
+
+// Duration durasi dalam ms di bawah satu detik, detik di bawah satu menit,
+// dan format Go (1m30s) untuk yang lebih lama
+func (f numberFormat) Duration(d time.Duration) string {
+    switch {
+    case d >= time.Minute:
+        return d.Round(time.Second).String()
+    case d >= time.Second:
+        return f.Float(d.Seconds()) + " s"
+    default:
+        return f.Float(durationMs(d)) + " ms"
+    }
+}
+
+func groupDigits(digits, sep string) string {
+    if sep == "" || len(digits) <= 3 {
+        return digits
+    }
+    var sb strings.Builder
+    head := len(digits) % 3
+    if head > 0 {
+        sb.WriteString(digits[:head])
+    }
+    for i := head; i < len(digits); i += 3 {
+        if sb.Len() > 0 {
+            sb.WriteString(sep)
+        }
+        sb.WriteString(digits[i : i+3])
+    }
+    return sb.String()
+}
+
+// Int, Float dan FloatN dipakai template report HTML/Markdown,
+// contoh {{$.Int .TotalRequests}} atau {{$.Float .P99LatencyMs}}
+
+func (r *Report) Int(n int64) string                     { return r.display.Int(n) }
+func (r *Report) Float(v float64) string                 { return r.display.Float(v) }
+func (r *Report) FloatN(v float64, precision int) string { return r.display.FloatN(v, precision) }
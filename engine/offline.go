@@ -0,0 +1,142 @@
+package engine
+
+import (
+    "bytes"
+    "compress/gzip"
+    "encoding/base64"
+    "fmt"
+    "html"
+    "html/template"
+    "io"
+    "mime"
+    "net/http"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+    "time"
+)
+
+// Report HTML bawaan tidak memuat aset dari luar (CSS inline, font sistem,
+// grafik SVG inline) agar bisa dibuka di jaringan air-gapped. File ini
+// menyematkan sisa aset yang berasal dari luar: logo URL dan raw samples.
+
+// externalAsset referensi ke aset di luar file pada template HTML custom:
+// src/href absolut, url() di CSS dan @import
+var externalAsset = regexp.MustCompile(`(?i)(?:\b(?:src|href)\s*=\s*["']?|url\(\s*["']?|@import\s+["'])((?:https?:)?//[^"'\s)>]+)`)
+
+// checkOfflineTemplate memperingatkan jika template HTML custom memuat
+// JS/CSS/font dari CDN, karena report tidak akan tampil benar tanpa internet
+func checkOfflineTemplate(w io.Writer, path string, data []byte) {
+    matches := externalAsset.FindAllSubmatch(data, -1)
+    if len(matches) == 0 {
+        return
+    }
+    fmt.Fprintf(w, "⚠️  Template %s memuat %d aset dari luar (contoh %s); report butuh internet untuk tampil lengkap\n", path, len(matches), matches[0][1])
+}
+
+// fetchLogo mengunduh logo URL agar disematkan sebagai data URI. Jika
+// gagal, URL tetap dipakai dan logo hanya tampil saat ada internet.
+func fetchLogo(w io.Writer, url string) string {
+    data, contentType, err := download(url)
+    if err != nil {
+        fmt.Fprintf(w, "⚠️  Logo %s tidak bisa diunduh (%v), report memakai URL dan butuh internet untuk menampilkannya\n", url, err)
+        return url
+    }
+    return dataURI(data, contentType)
+}
+
+func download(url string) ([]byte, string, error) {
+    client := &http.Client{Timeout: 10 * time.Second}
+    resp, err := client.Get(url)
+    if err != nil {
+        return nil, "", err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return nil, "", fmt.Errorf("status %s", resp.Status)
+    }
+    data, err := io.ReadAll(io.LimitReader(resp.Body, 5<<20))
+    return data, resp.Header.Get("Content-Type"), err
+}
+
+// dataURI menyandikan gambar sebagai data URI, content type dideteksi dari
+// isi jika kosong
+func dataURI(data []byte, contentType string) string {
+    if contentType == "" {
+        contentType = http.DetectContentType(data)
+    }
+    return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data)
+}
+
+// logoDataURI membaca logo dari file lokal sebagai data URI
+func logoDataURI(path string) (string, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return "", fmt.Errorf("membaca logo: %w", err)
+    }
+    return dataURI(data, mime.TypeByExtension(filepath.Ext(path))), nil
+}
+
+// isHTMLPath apakah report di path ditulis sebagai HTML
+func isHTMLPath(path string) bool {
+    ext := strings.ToLower(filepath.Ext(path))
+    return ext == ".html" || ext == ".htm"
+}
+
+// embeddedRaw raw samples CSV yang disematkan ke report HTML (-report-embed-raw)
+type embeddedRaw struct {
+    file    string // Nama file -raw, dipakai saat CSV diunduh dari report
+    samples int64
+    data    string // CSV, gzip lalu base64
+}
+
+// embedRawSamples membaca file -raw yang sudah ditutup untuk disematkan
+func embedRawSamples(path string, samples int64) (*embeddedRaw, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var buf bytes.Buffer
+    zw := gzip.NewWriter(&buf)
+    if _, err := zw.Write(data); err != nil {
+        return nil, err
+    }
+    if err := zw.Close(); err != nil {
+        return nil, err
+    }
+    return &embeddedRaw{
+        file:    filepath.Base(path),
+        samples: samples,
+        data:    base64.StdEncoding.EncodeToString(buf.Bytes()),
+    }, nil
+}
+
+// RawSamples bagian report HTML berisi raw samples yang disematkan beserta
+// tombol unduh CSV. Data ada di elemen <script id="loadtest-raw"> (gzip,
+// base64) sehingga juga bisa diambil tanpa browser.
+func (r *Report) RawSamples() template.HTML {
+    raw := r.rawSamples
+    if raw == nil {
+        return ""
+    }
+    file := html.EscapeString(raw.file)
+    return template.HTML(fmt.Sprintf(`<h2>Raw Samples</h2>
+<p>%s sampel dari %s disematkan di report ini (%s KB, gzip). <a href="#" id="loadtest-raw-download">Unduh CSV</a></p>
+<script type="application/gzip" id="loadtest-raw" data-file="%s">
+%s
+</script>
+<script>
+document.getElementById("loadtest-raw-download").addEventListener("click", async function (e) {
+  e.preventDefault();
+  var el = document.getElementById("loadtest-raw");
+  var bytes = Uint8Array.from(atob(el.textContent.trim()), function (c) { return c.charCodeAt(0); });
+  var csv = await new Response(new Blob([bytes]).stream().pipeThrough(new DecompressionStream("gzip"))).blob();
+  var a = document.createElement("a");
+  a.href = URL.createObjectURL(new Blob([csv], {type: "text/csv"}));
+  a.download = el.dataset.file;
+  a.click();
+});
+</script>
+`, r.Int(raw.samples), file, r.Int(int64(len(raw.data)*3/4/1024)), file, raw.data))
+}
@@ -0,0 +1,101 @@
+package engine
+
+import (
+    "bytes"
+    "fmt"
+    "net/url"
+    "os"
+    "path/filepath"
+    "regexp"
+    "strings"
+    "text/template"
+    "time"
+)
+
+// outputPathData nilai yang bisa dipakai di path output (-out, -raw,
+// -capacity, -pcap), contoh: results/{{.Name}}-{{.Timestamp}}.json
+type outputPathData struct {
+    Name      string // -name, atau nama file skenario / host target
+    Timestamp string // Waktu run dimulai (lokal), 20060102-150405
+    Date      string // 2006-01-02
+    Run       int    // Nomor run -repeat, 1 tanpa -repeat
+}
+
+// unsafePathChars karakter yang diganti saat nama dipakai di path
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// outputName nama test untuk path output. Tanpa -name dipakai nama file
+// skenario atau host target.
+func outputName(config *Config) string {
+    name := config.Name
+    if name == "" && config.Scenario != "" {
+        name = strings.TrimSuffix(filepath.Base(config.Scenario), filepath.Ext(config.Scenario))
+    }
+    if name == "" {
+        if u, err := url.Parse(config.URL); err == nil && u.Host != "" {
+            name = u.Hostname()
+        }
+    }
+    name = strings.Trim(unsafePathChars.ReplaceAllString(name, "-"), "-")
+    if name == "" {
+        return "loadtest"
+    }
+    return name
+}
+
+// outputPaths path output Config yang boleh berisi template
+func (config *Config) outputPaths() []*string {
+    return []*string{&config.OutFile, &config.RawFile, &config.CapacityFile, &config.PcapFile}
+}
+
+// validateOutputPaths memeriksa template path output sebelum test dimulai
+func validateOutputPaths(config *Config) error {
+    for _, path := range config.outputPaths() {
+        if _, err := expandOutputPath(*path, outputPathData{}); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// withOutputPaths mengembalikan salinan config dengan template path output
+// diisi waktu mulai run, lalu membuat folder tujuannya. Semua file satu run
+// memakai timestamp yang sama.
+func (config *Config) withOutputPaths(start time.Time) (*Config, error) {
+    runConfig := *config
+    data := outputPathData{
+        Name:      outputName(config),
+        Timestamp: start.Format("20060102-150405"),
+        Date:      start.Format("2006-01-02"),
+        Run:       max(config.RepeatRun, 1),
+    }
+    for _, path := range runConfig.outputPaths() {
+        if *path == "" {
+            continue
+        }
+        expanded, err := expandOutputPath(*path, data)
+        if err != nil {
+            return nil, err
+        }
+        if err := os.MkdirAll(filepath.Dir(expanded), 0755); err != nil {
+            return nil, fmt.Errorf("membuat folder output: %w", err)
+        }
+        *path = expanded
+    }
+    return &runConfig, nil
+}
+
+func expandOutputPath(path string, data outputPathData) (string, error) {
+    if !strings.Contains(path, "{{") {
+        return path, nil
+    }
+    tmpl, err := template.New("path").Option("missingkey=error").Parse(path)
+    if err != nil {
+        return "", fmt.Errorf("template path output %q: %w", path, err)
+    }
+    var buf bytes.Buffer
+    if err := tmpl.Execute(&buf, data); err != nil {
+        return "", fmt.Errorf("template path output %q: %w", path, err)
+    }
+    return buf.String(), nil
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "sync/atomic"
+    "time"
+)
+
+// pacingValue flag -pacing: jumlah iterasi per satuan waktu per VU
+// (contoh 6/min) atau langsung interval start-to-start (contoh 10s)
+type pacingValue time.Duration
+
+func (p *pacingValue) String() string {
+    if p == nil || *p == 0 {
+        return ""
+    }
+    return time.Duration(*p).String()
+}
+
+func (p *pacingValue) Set(value string) error {
+    interval, err := parsePacing(value)
+    if err != nil {
+        return err
+    }
+    *p = pacingValue(interval)
+    return nil
+}
+
+// parsePacing mengubah '6/min' atau '10s' menjadi interval antar awal iterasi
+func parsePacing(value string) (time.Duration, error) {
+    countStr, unit, found := strings.Cut(strings.TrimSpace(value), "/")
+    if !found {
+        interval, err := time.ParseDuration(value)
+        if err != nil || interval <= 0 {
+            return 0, fmt.Errorf("pacing %q tidak valid (format: 6/min atau interval seperti 10s)", value)
+        }
+        return interval, nil
+    }
+
+    count, err := strconv.ParseFloat(countStr, 64)
+    if err != nil || count <= 0 {
+        return 0, fmt.Errorf("jumlah iterasi pacing %q tidak valid", countStr)
+    }
+    var per time.Duration
+    switch unit {
+    case "s", "sec", "detik":
+        per = time.Second
+    case "m", "min", "menit":
+        per = time.Minute
+    case "h", "hour", "jam":
+        per = time.Hour
+    default:
+        return 0, fmt.Errorf("satuan pacing %q tidak valid (s, min atau h)", unit)
+    }
+    return time.Duration(float64(per) / count), nil
+}
+
+// pacer menjaga jarak start-to-start iterasi satu VU. Berbeda dengan think
+// time, waktu tunggu dikurangi durasi iterasi sebelumnya; iterasi yang lebih
+// lama dari interval membuat iterasi berikutnya langsung dimulai.
+type pacer struct {
+    interval time.Duration
+    next     time.Time
+    missed   *atomic.Int64
+    primed   bool
+}
+
+// newPacer membuat pacer untuk worker id. Awal iterasi pertama disebar
+// merata dalam satu interval agar semua VU tidak mengirim bersamaan.
+func newPacer(interval time.Duration, id, workers int, missed *atomic.Int64) *pacer {
+    p := &pacer{interval: interval, missed: missed}
+    if interval > 0 && workers > 0 {
+        p.next = time.Now().Add(interval * time.Duration(id) / time.Duration(workers))
+    }
+    return p
+}
+
+// wait menunggu jadwal iterasi berikutnya, false jika ctx dibatalkan
+func (p *pacer) wait(ctx context.Context) bool {
+    if p.interval == 0 {
+        return true
+    }
+    if now := time.Now(); now.After(p.next) {
+        if p.primed {
+            p.missed.Add(1)
+        }
+        p.next = now
+    }
+    if !sleepUntil(ctx, p.next) {
+        return false
+    }
+    p.primed = true
+    p.next = p.next.Add(p.interval)
+    return true
+}
+
+// PacingReport ringkasan pacing per VU
+type PacingReport struct {
+    IntervalMs float64 `json:"interval_ms"`
+    Iterations int64   `json:"iterations"`
+    Missed     int64   `json:"missed"` // Iterasi yang mulai terlambat karena iterasi sebelumnya melebihi interval
+    Warning    string  `json:"warning,omitempty"`
+}
+
+func pacingReport(interval time.Duration, iterations, missed int64) *PacingReport {
+    if interval == 0 {
+        return nil
+    }
+    r := &PacingReport{IntervalMs: durationMs(interval), Iterations: iterations, Missed: missed}
+    if missed > 0 {
+        r.Warning = fmt.Sprintf("%d iterasi lebih lama dari interval pacing, rate per VU tidak tercapai", missed)
+    }
+    return r
+}
+
+func printPacing(w io.Writer, report *Report) {
+    p := report.Pacing
+    if p == nil {
+        return
+    }
+    fmt.Fprintln(w, "\n⏱️  Pacing:")
+    fmt.Fprintf(w, "  %-23s %.0f ms (%.2f iterasi/menit per VU)\n", "Interval:", p.IntervalMs, 60000/p.IntervalMs)
+    fmt.Fprintf(w, "  %-23s %d\n", "Iterasi terlambat:", p.Missed)
+    if p.Warning != "" {
+        fmt.Fprintf(w, "  ⚠️  %s\n", p.Warning)
+    }
+}
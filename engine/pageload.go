@@ -0,0 +1,391 @@
+package engine
+
+import (
+    "bytes"
+    "context"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "sort"
+    "strings"
+    "sync"
+    "sync/atomic"
+    "time"
+
+    "golang.org/x/net/html"
+)
+
+const (
+    pageMaxHTML   = 5 << 20 // Body HTML yang diparse, sisanya hanya di-drain
+    pageMaxAssets = 200     // Aset per halaman yang diambil
+)
+
+// pageRequester mensimulasikan page load (-page): mengambil HTML, mencari
+// CSS, JS, gambar dan aset lain yang direferensikan, lalu mengambilnya
+// dengan -page-concurrency request bersamaan per VU seperti browser.
+// Latency yang dicatat adalah waktu page load sintetis: HTML ditambah
+// waktu sampai aset terakhir selesai. Aset tidak di-cache antar iterasi.
+type pageRequester struct {
+    *httpRequester
+    concurrency int
+    external    bool       // Ambil juga aset dari host lain
+    guard       *hostGuard // Memeriksa host aset eksternal
+    stats       pageStats
+}
+
+func newPageRequester(config *Config) (Requester, error) {
+    if config.PageParallel < 1 {
+        return nil, fmt.Errorf("-page-concurrency harus >= 1")
+    }
+    requester, err := newHTTPRequester(config)
+    if err != nil {
+        return nil, err
+    }
+    return &pageRequester{
+        httpRequester: requester.(*httpRequester),
+        concurrency:   config.PageParallel,
+        external:      config.PageExternal,
+        guard:         newHostGuard(config),
+    }, nil
+}
+
+// pageAsset satu aset yang direferensikan halaman
+type pageAsset struct {
+    url  string
+    kind string // css, js, img, font, other
+}
+
+func (p *pageRequester) Do(ctx context.Context, requestNum int) Result {
+    req, err := p.requests.build(ctx, requestNum)
+    if err != nil {
+        return Result{Start: time.Now(), Err: err}
+    }
+    release, err := p.limits.acquire(ctx, req)
+    if err != nil {
+        return Result{Start: time.Now(), Err: err}
+    }
+    start := time.Now()
+    resp, err := p.client.Do(req)
+    if err != nil {
+        release()
+        return Result{Start: start, Duration: time.Since(start), Err: err}
+    }
+    body, readErr := io.ReadAll(io.LimitReader(resp.Body, pageMaxHTML))
+    rest, _ := io.Copy(io.Discard, resp.Body)
+    resp.Body.Close()
+    release()
+    htmlTime := time.Since(start)
+    p.audit.observe(resp)
+
+    result := Result{
+        Start:      start,
+        StatusCode: resp.StatusCode,
+        Bytes:      int64(len(body)) + rest,
+        Err:        readErr,
+        RetryAfter: parseRetryAfter(resp),
+        Phases:     map[string]time.Duration{"html": htmlTime},
+    }
+    if result.Err == nil && len(p.assertions) > 0 {
+        result.Err = checkAssertions(p.assertions, resp, body)
+    }
+    if result.Err != nil || resp.StatusCode >= 400 {
+        result.Duration = htmlTime
+        result.ErrorBody = errorSample(body)
+        return result
+    }
+
+    assets := parseAssets(resp.Request.URL, body, p.external, p.guard)
+    assetStart := time.Now()
+    assetBytes := p.fetchAssets(ctx, assets, req)
+    assetTime := time.Since(assetStart)
+    result.Duration = time.Since(start)
+    result.Bytes += assetBytes
+    if len(assets) > 0 {
+        result.Phases["aset"] = assetTime
+    }
+    p.stats.pages.Add(1)
+    p.stats.assets.Add(int64(len(assets)))
+    p.stats.bytes.Add(result.Bytes)
+    return result
+}
+
+// fetchAssets mengambil aset dengan p.concurrency worker, mengembalikan
+// total byte yang diterima
+func (p *pageRequester) fetchAssets(ctx context.Context, assets []pageAsset, page *http.Request) int64 {
+    var total atomic.Int64
+    next := make(chan pageAsset)
+    var wg sync.WaitGroup
+    for range min(p.concurrency, len(assets)) {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for asset := range next {
+                total.Add(p.fetchAsset(ctx, asset, page))
+            }
+        }()
+    }
+    for _, asset := range assets {
+        next <- asset
+    }
+    close(next)
+    wg.Wait()
+    return total.Load()
+}
+
+func (p *pageRequester) fetchAsset(ctx context.Context, asset pageAsset, page *http.Request) int64 {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.url, nil)
+    if err != nil {
+        p.stats.record(asset, 0, 0, err)
+        return 0
+    }
+    // Header custom (-H, cookie, auth) hanya dikirim ke host yang sama agar
+    // token tidak bocor ke CDN pihak ketiga
+    if req.URL.Host != page.URL.Host {
+        req.Header.Set("User-Agent", page.Header.Get("User-Agent"))
+    } else {
+        for name, values := range page.Header {
+            if name != "Content-Type" && name != "Content-Length" {
+                req.Header[name] = values
+            }
+        }
+    }
+    release, err := p.limits.acquire(ctx, req)
+    if err != nil {
+        return 0
+    }
+    defer release()
+    start := time.Now()
+    resp, err := p.client.Do(req)
+    if err != nil {
+        p.stats.record(asset, 0, time.Since(start), err)
+        return 0
+    }
+    n, _ := io.Copy(io.Discard, resp.Body)
+    resp.Body.Close()
+    p.stats.record(asset, resp.StatusCode, time.Since(start), nil)
+    return n
+}
+
+// parseAssets mencari aset yang akan diambil browser saat memuat halaman.
+// URL relatif di-resolve terhadap URL akhir HTML (setelah redirect) atau
+// <base href>. Aset host lain dilewati kecuali external dan host-nya lolos
+// guard.
+func parseAssets(page *url.URL, body []byte, external bool, guard *hostGuard) []pageAsset {
+    base := page
+    seen := make(map[string]bool)
+    var assets []pageAsset
+    add := func(ref, kind string) {
+        ref = strings.TrimSpace(ref)
+        if ref == "" || strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "#") || len(assets) >= pageMaxAssets {
+            return
+        }
+        u, err := base.Parse(ref)
+        if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+            return
+        }
+        u.Fragment = ""
+        if u.Host != page.Host && (!external || !guard.allowed(u.Host)) {
+            return
+        }
+        if s := u.String(); !seen[s] {
+            seen[s] = true
+            assets = append(assets, pageAsset{url: s, kind: kind})
+        }
+    }
+
+    z := html.NewTokenizer(bytes.NewReader(body))
+    for {
+        tt := z.Next()
+        if tt == html.ErrorToken {
+            return assets
+        }
+        if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+            continue
+        }
+        name, hasAttr := z.TagName()
+        attrs := make(map[string]string)
+        for hasAttr {
+            var key, value []byte
+            key, value, hasAttr = z.TagAttr()
+            attrs[string(key)] = string(value)
+        }
+        switch string(name) {
+        case "base":
+            if u, err := page.Parse(attrs["href"]); err == nil && attrs["href"] != "" {
+                base = u
+            }
+        case "link":
+            rel := " " + strings.ToLower(attrs["rel"]) + " "
+            switch {
+            case strings.Contains(rel, " stylesheet "):
+                add(attrs["href"], "css")
+            case strings.Contains(rel, " icon "):
+                add(attrs["href"], "img")
+            case strings.Contains(rel, " modulepreload "):
+                add(attrs["href"], "js")
+            case strings.Contains(rel, " preload "):
+                add(attrs["href"], preloadKind(attrs["as"]))
+            }
+        case "script":
+            add(attrs["src"], "js")
+        case "img":
+            add(attrs["src"], "img")
+        case "source", "video", "audio":
+            add(attrs["src"], "other")
+            if string(name) == "video" {
+                add(attrs["poster"], "img")
+            }
+        }
+    }
+}
+
+func preloadKind(as string) string {
+    switch as {
+    case "style":
+        return "css"
+    case "script":
+        return "js"
+    case "image":
+        return "img"
+    case "font":
+        return "font"
+    }
+    return "other"
+}
+
+// pageStats agregat aset selama run
+type pageStats struct {
+    pages  atomic.Int64
+    assets atomic.Int64
+    bytes  atomic.Int64
+
+    mu       sync.Mutex
+    kinds    map[string]*assetKindStats
+    failures map[string]*PageAssetFailure
+}
+
+type assetKindStats struct {
+    requests, failed int64
+    latency          histogram
+}
+
+func (s *pageStats) record(asset pageAsset, status int, d time.Duration, err error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    if s.kinds == nil {
+        s.kinds = make(map[string]*assetKindStats)
+        s.failures = make(map[string]*PageAssetFailure)
+    }
+    k := s.kinds[asset.kind]
+    if k == nil {
+        k = &assetKindStats{}
+        s.kinds[asset.kind] = k
+    }
+    k.requests++
+    k.latency.addDuration(d)
+    if err == nil && status < 400 {
+        return
+    }
+    k.failed++
+    f := s.failures[asset.url]
+    if f == nil {
+        f = &PageAssetFailure{URL: asset.url, Status: status}
+        if err != nil {
+            f.Error = err.Error()
+        }
+        s.failures[asset.url] = f
+    }
+    f.Count++
+}
+
+// PageReport hasil simulasi page load (-page)
+type PageReport struct {
+    Pages     int64                     `json:"pages"` // Halaman yang HTML-nya berhasil diambil
+    AvgAssets float64                   `json:"avg_assets"`
+    AvgBytes  float64                   `json:"avg_bytes"` // HTML dan aset per halaman
+    Kinds     map[string]PageAssetStats `json:"assets"`
+    Failures  []PageAssetFailure        `json:"failures,omitempty"`
+}
+
+// PageAssetStats latency aset per jenis
+type PageAssetStats struct {
+    Requests int64   `json:"requests"`
+    Failed   int64   `json:"failed"`
+    P50Ms    float64 `json:"p50_ms"`
+    P95Ms    float64 `json:"p95_ms"`
+}
+
+// PageAssetFailure aset yang gagal diambil
+type PageAssetFailure struct {
+    URL    string `json:"url"`
+    Status int    `json:"status,omitempty"`
+    Error  string `json:"error,omitempty"`
+    Count  int64  `json:"count"`
+}
+
+// PageReport ringkasan aset halaman selama run
+func (p *pageRequester) PageReport() *PageReport {
+    s := &p.stats
+    r := &PageReport{Pages: s.pages.Load(), Kinds: make(map[string]PageAssetStats)}
+    if r.Pages > 0 {
+        r.AvgAssets = float64(s.assets.Load()) / float64(r.Pages)
+        r.AvgBytes = float64(s.bytes.Load()) / float64(r.Pages)
+    }
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    for kind, k := range s.kinds {
+        r.Kinds[kind] = PageAssetStats{
+            Requests: k.requests,
+            Failed:   k.failed,
+            P50Ms:    durationMs(k.latency.quantileDuration(0.50)),
+            P95Ms:    durationMs(k.latency.quantileDuration(0.95)),
+        }
+    }
+    for _, f := range s.failures {
+        r.Failures = append(r.Failures, *f)
+    }
+    sort.Slice(r.Failures, func(i, j int) bool {
+        if r.Failures[i].Count != r.Failures[j].Count {
+            return r.Failures[i].Count > r.Failures[j].Count
+        }
+        return r.Failures[i].URL < r.Failures[j].URL
+    })
+    if len(r.Failures) > 10 {
+        r.Failures = r.Failures[:10]
+    }
+    return r
+}
+
+// PageReporter diimplementasikan Requester yang menjalankan -page
+type PageReporter interface {
+    PageReport() *PageReport
+}
+
+func printPage(w io.Writer, report *Report) {
+    p := report.Page
+    if p == nil {
+        return
+    }
+    fmt.Fprintln(w, "\n🌐 Page Load:")
+    fmt.Fprintf(w, "  Halaman dimuat: %d, rata-rata %.1f aset dan %.0f KB per halaman\n", p.Pages, p.AvgAssets, p.AvgBytes/1024)
+    if len(p.Kinds) > 0 {
+        fmt.Fprintf(w, "  %-8s %9s %7s %9s %9s\n", "Aset", "Requests", "Gagal", "p50 ms", "p95 ms")
+        kinds := make([]string, 0, len(p.Kinds))
+        for kind := range p.Kinds {
+            kinds = append(kinds, kind)
+        }
+        sort.Strings(kinds)
+        for _, kind := range kinds {
+            k := p.Kinds[kind]
+            fmt.Fprintf(w, "  %-8s %9d %7d %9.2f %9.2f\n", kind, k.Requests, k.Failed, k.P50Ms, k.P95Ms)
+        }
+    }
+    for _, f := range p.Failures {
+        reason := fmt.Sprintf("status %d", f.Status)
+        if f.Error != "" {
+            reason = truncate(f.Error, 50)
+        }
+        fmt.Fprintf(w, "  ⚠️  %s gagal %d kali (%s)\n", truncate(f.URL, 70), f.Count, reason)
+    }
+}
@@ -0,0 +1,62 @@
+package engine
+
+import (
+    "errors"
+    "fmt"
+    "net"
+    "syscall"
+)
+
+// afPacketSource membaca paket IP dari semua interface lewat AF_PACKET
+// (SOCK_DGRAM, tanpa header link layer). Butuh root atau CAP_NET_RAW.
+type afPacketSource struct {
+    fd       int
+    buf      []byte
+    loopback map[int]bool // ifindex loopback, paket keluar terlihat dua kali di sana
+}
+
+func openPacketSource() (packetSource, error) {
+    const ethPAll = 0x0003
+    proto := int(ethPAll>>8 | ethPAll<<8&0xff00) // htons
+    fd, err := syscall.Socket(syscall.AF_PACKET, syscall.SOCK_DGRAM, proto)
+    if err != nil {
+        if errors.Is(err, syscall.EPERM) || errors.Is(err, syscall.EACCES) {
+            return nil, fmt.Errorf("capture PCAP butuh root atau CAP_NET_RAW: %w", err)
+        }
+        return nil, fmt.Errorf("membuka socket AF_PACKET: %w", err)
+    }
+    // Timeout agar loop bisa berhenti saat capture ditutup
+    tv := syscall.Timeval{Usec: 200000}
+    if err := syscall.SetsockoptTimeval(fd, syscall.SOL_SOCKET, syscall.SO_RCVTIMEO, &tv); err != nil {
+        syscall.Close(fd)
+        return nil, err
+    }
+
+    s := &afPacketSource{fd: fd, buf: make([]byte, pcapSnapLen), loopback: make(map[int]bool)}
+    if ifaces, err := net.Interfaces(); err == nil {
+        for _, iface := range ifaces {
+            if iface.Flags&net.FlagLoopback != 0 {
+                s.loopback[iface.Index] = true
+            }
+        }
+    }
+    return s, nil
+}
+
+func (s *afPacketSource) read() ([]byte, error) {
+    n, from, err := syscall.Recvfrom(s.fd, s.buf, 0)
+    if err != nil {
+        if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) {
+            return nil, nil
+        }
+        return nil, err
+    }
+    if ll, ok := from.(*syscall.SockaddrLinklayer); ok && ll.Pkttype == syscall.PACKET_OUTGOING && s.loopback[ll.Ifindex] {
+        return nil, nil
+    }
+    return s.buf[:n], nil
+}
+
+func (s *afPacketSource) close() error {
+    return syscall.Close(s.fd)
+}
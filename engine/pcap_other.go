@@ -0,0 +1,9 @@
+//go:build !linux
+
+package engine
+
+import "fmt"
+
+func openPacketSource() (packetSource, error) {
+    return nil, fmt.Errorf("capture PCAP hanya didukung di Linux")
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "sort"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// phaseStats agregat durasi satu fase request (contoh: connect, tls)
+type phaseStats struct {
+    count atomic.Int64
+    total atomic.Int64
+    min   atomic.Int64
+    max   atomic.Int64
+}
+
+func (p *phaseStats) add(d time.Duration) {
+    p.count.Add(1)
+    p.total.Add(int64(d))
+    storeMin(&p.min, d)
+    storeMax(&p.max, d)
+}
+
+// phaseSet kumpulan phaseStats per nama fase. Zero value siap dipakai.
+type phaseSet struct {
+    phases sync.Map // string -> *phaseStats
+}
+
+func (s *phaseSet) add(name string, d time.Duration) {
+    p, ok := s.phases.Load(name)
+    if !ok {
+        p, _ = s.phases.LoadOrStore(name, &phaseStats{})
+    }
+    p.(*phaseStats).add(d)
+}
+
+// PhaseReport ringkasan durasi satu fase request
+type PhaseReport struct {
+    Count int64   `json:"count"`
+    AvgMs float64 `json:"avg_ms"`
+    MinMs float64 `json:"min_ms"`
+    MaxMs float64 `json:"max_ms"`
+}
+
+func (s *phaseSet) report() map[string]PhaseReport {
+    reports := make(map[string]PhaseReport)
+    s.phases.Range(func(key, value interface{}) bool {
+        p := value.(*phaseStats)
+        count := p.count.Load()
+        if count == 0 {
+            return true
+        }
+        reports[key.(string)] = PhaseReport{
+            Count: count,
+            AvgMs: durationMs(time.Duration(p.total.Load() / count)),
+            MinMs: durationMs(time.Duration(p.min.Load())),
+            MaxMs: durationMs(time.Duration(p.max.Load())),
+        }
+        return true
+    })
+    if len(reports) == 0 {
+        return nil
+    }
+    return reports
+}
+
+func printPhases(w io.Writer, report *Report) {
+    if len(report.Phases) == 0 {
+        return
+    }
+    names := make([]string, 0, len(report.Phases))
+    for name := range report.Phases {
+        names = append(names, name)
+    }
+    sort.Slice(names, func(i, j int) bool {
+        return phaseOrder(names[i]) < phaseOrder(names[j]) ||
+            (phaseOrder(names[i]) == phaseOrder(names[j]) && names[i] < names[j])
+    })
+
+    fmt.Fprintln(w, "\n⏱️  Fase Request:")
+    fmt.Fprintf(w, "  %-18s %8s %10s %10s %10s\n", "Fase", "Jumlah", "Avg (ms)", "Min (ms)", "Max (ms)")
+    for _, name := range names {
+        p := report.Phases[name]
+        fmt.Fprintf(w, "  %-18s %8d %10.2f %10.2f %10.2f\n", name, p.Count, p.AvgMs, p.MinMs, p.MaxMs)
+    }
+}
+
+// phaseOrder urutan tampil fase yang dikenal, sesuai urutan terjadinya
+func phaseOrder(name string) int {
+    for i, known := range []string{"proxy_connect", "connect_tunnel", "tls", "request"} {
+        if name == known {
+            return i
+        }
+    }
+    return 100
+}
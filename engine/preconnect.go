@@ -0,0 +1,152 @@
+package engine
+
+import (
+    "context"
+    "crypto/tls"
+    "fmt"
+    "net"
+    "net/http"
+    "net/url"
+    "sync"
+)
+
+// preconnectPool menyimpan koneksi (TCP+TLS) yang dibuka sebelum jendela
+// pengukuran. Transport mengambil koneksi dari pool ini lebih dulu sebelum
+// melakukan dial baru.
+type preconnectPool struct {
+    dial      dialFunc
+    tlsConfig *tls.Config
+
+    mu    sync.Mutex
+    conns map[string][]net.Conn // key: network + addr
+}
+
+func newPreconnectPool(dial dialFunc, tlsConfig *tls.Config) *preconnectPool {
+    return &preconnectPool{
+        dial:      dial,
+        tlsConfig: tlsConfig,
+        conns:     make(map[string][]net.Conn),
+    }
+}
+
+// install memasang pool ke transport
+func (p *preconnectPool) install(transport *http.Transport) {
+    transport.DialContext = p.dialContext
+    transport.DialTLSContext = p.dialTLSContext
+}
+
+func (p *preconnectPool) take(key string) net.Conn {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    conns := p.conns[key]
+    if len(conns) == 0 {
+        return nil
+    }
+    conn := conns[len(conns)-1]
+    p.conns[key] = conns[:len(conns)-1]
+    return conn
+}
+
+func (p *preconnectPool) put(key string, conn net.Conn) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    p.conns[key] = append(p.conns[key], conn)
+}
+
+func (p *preconnectPool) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+    if conn := p.take("tcp " + addr); conn != nil {
+        return conn, nil
+    }
+    return p.dial(ctx, network, addr)
+}
+
+func (p *preconnectPool) dialTLSContext(ctx context.Context, network, addr string) (net.Conn, error) {
+    if conn := p.take("tls " + addr); conn != nil {
+        return conn, nil
+    }
+    return p.dialTLS(ctx, network, addr)
+}
+
+func (p *preconnectPool) dialTLS(ctx context.Context, network, addr string) (net.Conn, error) {
+    host, _, err := net.SplitHostPort(addr)
+    if err != nil {
+        return nil, err
+    }
+    cfg := p.tlsConfig.Clone()
+    if cfg.ServerName == "" {
+        cfg.ServerName = host
+    }
+
+    rawConn, err := p.dial(ctx, network, addr)
+    if err != nil {
+        return nil, err
+    }
+    conn := tls.Client(rawConn, cfg)
+    if err := conn.HandshakeContext(ctx); err != nil {
+        rawConn.Close()
+        return nil, err
+    }
+    return conn, nil
+}
+
+// warm membuka n koneksi ke target secara paralel dan menyimpannya di pool
+func (p *preconnectPool) warm(ctx context.Context, target *url.URL, n int) error {
+    addr := target.Host
+    if target.Port() == "" {
+        port := "80"
+        if target.Scheme == "https" {
+            port = "443"
+        }
+        addr = net.JoinHostPort(target.Hostname(), port)
+    }
+
+    var wg sync.WaitGroup
+    errs := make(chan error, n)
+    for i := 0; i < n; i++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            var conn net.Conn
+            var err error
+            key := "tcp " + addr
+            if target.Scheme == "https" {
+                key = "tls " + addr
+                conn, err = p.dialTLS(ctx, "tcp", addr)
+            } else {
+                conn, err = p.dial(ctx, "tcp", addr)
+            }
+            if err != nil {
+                errs <- err
+                return
+            }
+            p.put(key, conn)
+        }()
+    }
+    wg.Wait()
+    close(errs)
+
+    failed := 0
+    var firstErr error
+    for err := range errs {
+        if firstErr == nil {
+            firstErr = err
+        }
+        failed++
+    }
+    if failed > 0 {
+        return fmt.Errorf("%d dari %d koneksi gagal dibuka: %w", failed, n, firstErr)
+    }
+    return nil
+}
+
+// close menutup koneksi yang tidak sempat terpakai
+func (p *preconnectPool) close() {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+    for key, conns := range p.conns {
+        for _, conn := range conns {
+            conn.Close()
+        }
+        delete(p.conns, key)
+    }
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "os"
+    "runtime"
+    "strings"
+)
+
+const (
+    preflightReservedFDs = 64 // File descriptor untuk stdin/out, file output, DNS, dll.
+    timeWaitSeconds      = 60 // Perkiraan lama TIME_WAIT (Linux 60s, macOS ~30s, Windows default 120s)
+)
+
+// checkPreflight memeriksa batas sistem operasi yang sering membuat load
+// test gagal dengan error yang membingungkan (too many open files, cannot
+// assign requested address) dan mengembalikan saran sesuai platform
+func checkPreflight(config *Config) []string {
+    var advice []string
+
+    need := uint64(config.Concurrency)
+    if config.PageLoad {
+        need *= uint64(max(config.PageParallel, 1))
+    }
+    need += preflightReservedFDs
+    if limit, ok := openFileLimit(); ok && limit < need {
+        advice = append(advice, fmt.Sprintf("Batas file descriptor %d, test butuh sekitar %d koneksi. %s", limit, need, fdAdvice()))
+    }
+
+    // Tanpa keep-alive setiap request membuka port lokal baru yang tertahan
+    // di TIME_WAIT setelah ditutup
+    if !config.KeepAlive && config.Rate > 0 {
+        ports := ephemeralPorts()
+        demand := int(config.Rate * timeWaitSeconds)
+        if config.Duration == 0 && !config.Monitor {
+            demand = min(demand, config.NumRequests)
+        }
+        if demand > ports {
+            advice = append(advice, fmt.Sprintf("Tanpa keep-alive, -rate %.0f menahan ~%d port di TIME_WAIT, port ephemeral hanya %d. %s", config.Rate, demand, ports, portAdvice()))
+        }
+    }
+    return advice
+}
+
+// printPreflight menampilkan saran preflight, tidak ada output jika aman
+func printPreflight(w io.Writer, advice []string) {
+    if len(advice) == 0 {
+        return
+    }
+    fmt.Fprintln(w, "🧰 Pemeriksaan sistem:")
+    for _, a := range advice {
+        fmt.Fprintf(w, "   ⚠️  %s\n", a)
+    }
+    fmt.Fprintln(w)
+}
+
+func fdAdvice() string {
+    switch runtime.GOOS {
+    case "darwin":
+        return "Naikkan dengan `ulimit -n 65536`; batas sistem lihat `launchctl limit maxfiles` dan `sysctl kern.maxfilesperproc`."
+    case "windows":
+        return "Windows tidak membatasi socket per proses; kurangi -c jika muncul error buffer space."
+    default:
+        return "Naikkan dengan `ulimit -n 65536`, LimitNOFILE di unit systemd, atau /etc/security/limits.conf."
+    }
+}
+
+func portAdvice() string {
+    switch runtime.GOOS {
+    case "darwin":
+        return "Aktifkan -k atau perlebar range dengan `sudo sysctl -w net.inet.ip.portrange.first=16384`."
+    case "windows":
+        return "Aktifkan -k atau perlebar range dengan `netsh int ipv4 set dynamicport tcp start=10000 num=55535` dan kecilkan TcpTimedWaitDelay di registry."
+    default:
+        return "Aktifkan -k, perlebar `sysctl net.ipv4.ip_local_port_range` atau aktifkan `sysctl net.ipv4.tcp_tw_reuse=1`."
+    }
+}
+
+// ephemeralPorts jumlah port lokal yang bisa dipakai koneksi keluar.
+// macOS dan Windows memakai range default IANA 49152-65535.
+func ephemeralPorts() int {
+    if runtime.GOOS == "linux" {
+        if data, err := os.ReadFile("/proc/sys/net/ipv4/ip_local_port_range"); err == nil {
+            var lo, hi int
+            if _, err := fmt.Sscan(strings.TrimSpace(string(data)), &lo, &hi); err == nil && hi > lo {
+                return hi - lo + 1
+            }
+        }
+    }
+    return 65535 - 49152 + 1
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package engine
+
+import "syscall"
+
+// openFileLimit batas soft file descriptor proses ini (ulimit -n)
+func openFileLimit() (uint64, bool) {
+    var limit syscall.Rlimit
+    if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+        return 0, false
+    }
+    return uint64(limit.Cur), true
+}
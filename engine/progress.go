@@ -0,0 +1,116 @@
+package engine
+
+import (
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// progressInterval jarak antar Snapshot untuk OnProgress
+const progressInterval = time.Second
+
+// Snapshot ringkasan hasil sejak test mulai, dikirim ke callback OnProgress
+type Snapshot struct {
+    Elapsed     time.Duration
+    Total       int   // Jumlah request sesuai jadwal, 0 jika ditentukan durasi
+    Requests    int64 // Request selesai, sukses dan gagal
+    Successful  int64
+    Failed      int64
+    RPS         float64 // Rata-rata sejak mulai
+    IntervalRPS float64 // Sejak Snapshot sebelumnya
+    Mean        time.Duration
+    P50         time.Duration
+    P95         time.Duration
+    P99         time.Duration
+    Max         time.Duration
+    StatusCodes map[int]int64 // 0 = error koneksi/timeout
+    Final       bool          // Snapshot terakhir setelah jadwal selesai
+}
+
+// OnProgress memanggil fn setiap detik selama test berjalan dan sekali lagi
+// setelah jadwal selesai (Final), agar program yang menjalankan Run bisa
+// menampilkan progres tanpa membaca output terminal. fn dipanggil dari satu
+// goroutine secara berurutan; fn yang lambat menunda Snapshot berikutnya,
+// bukan request.
+func OnProgress(fn func(Snapshot)) Option {
+    return func(config *Config) {
+        config.onProgress = fn
+    }
+}
+
+// progressReporter goroutine pengirim Snapshot, nil jika OnProgress tidak dipakai
+type progressReporter struct {
+    fn    func(Snapshot)
+    stats *Stats
+    total int
+    start time.Time
+    done  chan struct{}
+    wg    sync.WaitGroup
+
+    last     int64 // Requests pada Snapshot sebelumnya
+    lastTime time.Time
+}
+
+func startProgress(fn func(Snapshot), stats *Stats, total int, start time.Time) *progressReporter {
+    if fn == nil {
+        return nil
+    }
+    p := &progressReporter{fn: fn, stats: stats, total: total, start: start, done: make(chan struct{}), lastTime: start}
+    p.wg.Add(1)
+    go func() {
+        defer p.wg.Done()
+        ticker := time.NewTicker(progressInterval)
+        defer ticker.Stop()
+        for {
+            select {
+            case now := <-ticker.C:
+                p.fn(p.snapshot(now, false))
+            case <-p.done:
+                p.fn(p.snapshot(time.Now(), true))
+                return
+            }
+        }
+    }()
+    return p
+}
+
+// stop mengirim Snapshot terakhir dan menunggu callback selesai
+func (p *progressReporter) stop() {
+    if p == nil {
+        return
+    }
+    close(p.done)
+    p.wg.Wait()
+}
+
+func (p *progressReporter) snapshot(now time.Time, final bool) Snapshot {
+    stats := p.stats
+    s := Snapshot{
+        Elapsed:     now.Sub(p.start),
+        Total:       p.total,
+        Requests:    stats.TotalRequests.Load(),
+        Successful:  stats.SuccessfulRequests.Load(),
+        Failed:      stats.FailedRequests.Load(),
+        P50:         stats.latency.quantileDuration(0.50),
+        P95:         stats.latency.quantileDuration(0.95),
+        P99:         stats.latency.quantileDuration(0.99),
+        Max:         time.Duration(stats.MaxDuration.Load()),
+        StatusCodes: make(map[int]int64),
+        Final:       final,
+    }
+    if s.Requests > 0 {
+        s.Mean = time.Duration(stats.TotalDuration.Load() / s.Requests)
+    }
+    if s.Elapsed > 0 {
+        s.RPS = float64(s.Requests) / s.Elapsed.Seconds()
+    }
+    if interval := now.Sub(p.lastTime); interval > 0 {
+        s.IntervalRPS = float64(s.Requests-p.last) / interval.Seconds()
+    }
+    p.last, p.lastTime = s.Requests, now
+    stats.StatusCodes.Range(func(key, value interface{}) bool {
+        s.StatusCodes[key.(int)] = value.(*atomic.Int64).Load()
+        return true
+    })
+    return s
+}
@@ -0,0 +1,164 @@
+package engine
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    mathrand "math/rand/v2"
+    "net/http"
+    "net/url"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// defaultPromMixQuery rate request per path dari metrik HTTP standar
+// (client_golang, Spring Actuator, dll), label path diatur -prom-mix-label
+const defaultPromMixQuery = "sum by (%s, method) (rate(http_requests_total[5m]))"
+
+// mixEntry satu jenis request di mix beserta porsinya
+type mixEntry struct {
+    method string // Kosong berarti pakai -m
+    path   string
+    query  string // Kosong berarti pakai query URL target
+    weight float64
+    target *url.URL // Multi-target: URL lengkap yang menggantikan URL target
+}
+
+func (e mixEntry) name(fallback string) string {
+    method := e.method
+    if method == "" {
+        method = fallback
+    }
+    if e.target != nil {
+        return method + " " + e.target.String()
+    }
+    if e.query != "" {
+        return method + " " + e.path + "?" + e.query
+    }
+    return method + " " + e.path
+}
+
+// requestMix memilih request secara acak sesuai bobot
+type requestMix struct {
+    entries    []mixEntry
+    cumulative []float64
+    noPhases   bool // Latency per path tidak dicatat di tabel fase (terlalu banyak path)
+    multiHost  bool // Entry ke host berbeda, tabel fase dipisah per host
+}
+
+func newRequestMix(entries []mixEntry) *requestMix {
+    sort.SliceStable(entries, func(i, j int) bool { return entries[i].weight > entries[j].weight })
+    m := &requestMix{entries: entries, cumulative: make([]float64, len(entries))}
+    total := 0.0
+    for i, e := range entries {
+        total += e.weight
+        m.cumulative[i] = total
+    }
+    return m
+}
+
+// mixRand sumber acak pemilihan entry mix, dengan salt sendiri agar
+// pilihan endpoint tidak berkorelasi dengan nilai template request itu
+func mixRand(seed int64, requestNum int) *mathrand.Rand {
+    if seed == 0 {
+        return mathrand.New(mathrand.NewPCG(mathrand.Uint64(), mathrand.Uint64()))
+    }
+    return mathrand.New(mathrand.NewPCG(uint64(seed)^0x6d6978, uint64(requestNum)))
+}
+
+func (m *requestMix) pick(rng *mathrand.Rand) mixEntry {
+    target := rng.Float64() * m.cumulative[len(m.cumulative)-1]
+    i := sort.SearchFloat64s(m.cumulative, target)
+    return m.entries[min(i, len(m.entries)-1)]
+}
+
+// loadPromMix menjalankan query Prometheus dan mengubah rate per path
+// menjadi mix request. Path yang masih berupa template route (/users/{id},
+// /users/:id) dilewati karena nilai parameternya tidak diketahui.
+func loadPromMix(config *Config) (*requestMix, error) {
+    query := config.PromMixQuery
+    if query == "" {
+        query = fmt.Sprintf(defaultPromMixQuery, config.PromMixLabel)
+    }
+    endpoint := strings.TrimSuffix(config.PromMix, "/") + "/api/v1/query?" + url.Values{"query": {query}}.Encode()
+
+    ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+    defer cancel()
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+    if err != nil {
+        return nil, err
+    }
+    if token := os.Getenv("PROMETHEUS_TOKEN"); token != "" {
+        req.Header.Set("Authorization", "Bearer "+token)
+    }
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("query prometheus: %w", err)
+    }
+    defer resp.Body.Close()
+    data, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, err
+    }
+
+    var result struct {
+        Status string `json:"status"`
+        Error  string `json:"error"`
+        Data   struct {
+            ResultType string `json:"resultType"`
+            Result     []struct {
+                Metric map[string]string `json:"metric"`
+                Value  [2]any            `json:"value"`
+            } `json:"result"`
+        } `json:"data"`
+    }
+    if err := json.Unmarshal(data, &result); err != nil {
+        return nil, fmt.Errorf("response prometheus tidak valid (%s): %w", resp.Status, err)
+    }
+    if result.Status != "success" {
+        return nil, fmt.Errorf("query prometheus gagal: %s", result.Error)
+    }
+    if result.Data.ResultType != "vector" {
+        return nil, fmt.Errorf("query prometheus harus menghasilkan instant vector, bukan %s", result.Data.ResultType)
+    }
+
+    var entries []mixEntry
+    var skipped []string
+    for _, sample := range result.Data.Result {
+        path := sample.Metric[config.PromMixLabel]
+        raw, _ := sample.Value[1].(string)
+        rate, err := strconv.ParseFloat(raw, 64)
+        if path == "" || err != nil || rate <= 0 {
+            continue
+        }
+        if !strings.HasPrefix(path, "/") || strings.ContainsAny(path, "{:<*") {
+            skipped = append(skipped, path)
+            continue
+        }
+        entries = append(entries, mixEntry{method: strings.ToUpper(sample.Metric["method"]), path: path, weight: rate})
+    }
+    if len(skipped) > 0 {
+        fmt.Fprintf(config.out, "⚠️  %d path dari prometheus dilewati karena berupa template route: %s\n", len(skipped), strings.Join(skipped, ", "))
+    }
+    if len(entries) == 0 {
+        return nil, fmt.Errorf("query prometheus tidak menghasilkan path dengan label %q dan rate > 0", config.PromMixLabel)
+    }
+    return newRequestMix(entries), nil
+}
+
+// printMix menampilkan porsi tiap request di mix, maksimal 10 teratas
+func printMix(w io.Writer, m *requestMix, method, source string) {
+    total := m.cumulative[len(m.cumulative)-1]
+    fmt.Fprintf(w, "🧮 Mix request dari %s (%d path):\n", source, len(m.entries))
+    for i, e := range m.entries {
+        if i == 10 {
+            fmt.Fprintf(w, "   ... %d path lainnya\n", len(m.entries)-i)
+            break
+        }
+        fmt.Fprintf(w, "   %5.1f%%  %s\n", e.weight/total*100, e.name(method))
+    }
+}
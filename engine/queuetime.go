@@ -0,0 +1,84 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "sync/atomic"
+    "time"
+)
+
+// queueLate waktu antre di generator yang dianggap terlambat. Di bawah ini
+// selisihnya masih sebatas jitter scheduler Go.
+const queueLate = 10 * time.Millisecond
+
+// queueStats waktu job menunggu di generator: dari jatuh tempo menurut
+// jadwal open-loop (-rate, -stages, -burst, -wave, -replay) sampai worker
+// mulai mengirim request. Waktu ini tidak termasuk latency server. Zero
+// value siap dipakai.
+type queueStats struct {
+    wait histogram
+    max  atomic.Int64
+    late atomic.Int64 // Job yang menunggu >= queueLate
+}
+
+func (q *queueStats) add(d time.Duration) {
+    q.wait.addDuration(d)
+    if d >= queueLate {
+        q.late.Add(1)
+    }
+    for {
+        current := q.max.Load()
+        if int64(d) <= current || q.max.CompareAndSwap(current, int64(d)) {
+            return
+        }
+    }
+}
+
+// QueueReport waktu antre job di generator sebelum request dikirim
+type QueueReport struct {
+    Jobs    int64   `json:"jobs"`
+    P50Ms   float64 `json:"p50_ms"`
+    P95Ms   float64 `json:"p95_ms"`
+    P99Ms   float64 `json:"p99_ms"`
+    MaxMs   float64 `json:"max_ms"`
+    Late    int64   `json:"late_jobs"` // Job yang menunggu >= 10 ms
+    LatePct float64 `json:"late_pct"`
+    Warning string  `json:"warning,omitempty"`
+}
+
+// report nil untuk jadwal closed-loop, karena job selalu siap dan waktu
+// menunggu worker kosong memang bagian dari model beban
+func (q *queueStats) report(latencyP95Ms float64) *QueueReport {
+    jobs := q.wait.count()
+    if jobs == 0 {
+        return nil
+    }
+    maxMs := durationMs(time.Duration(q.max.Load()))
+    r := &QueueReport{
+        Jobs:  jobs,
+        P50Ms: min(maxMs, durationMs(q.wait.quantileDuration(0.50))),
+        P95Ms: min(maxMs, durationMs(q.wait.quantileDuration(0.95))),
+        P99Ms: min(maxMs, durationMs(q.wait.quantileDuration(0.99))),
+        MaxMs: maxMs,
+        Late:  q.late.Load(),
+    }
+    r.LatePct = float64(r.Late) / float64(jobs) * 100
+    if r.P95Ms >= durationMs(queueLate) && r.P95Ms >= latencyP95Ms*0.1 {
+        r.Warning = fmt.Sprintf("generator ikut menjadi sumber delay: p95 antre %.1f ms (%.0f%% dari p95 latency server), naikkan -c atau periksa CPU generator", r.P95Ms, r.P95Ms/max(latencyP95Ms, 0.001)*100)
+    }
+    return r
+}
+
+func printQueue(w io.Writer, report *Report) {
+    q := report.Queue
+    if q == nil {
+        return
+    }
+    fmt.Fprintln(w, "\n⏳ Antre di Generator (jadwal sampai request dikirim, di luar latency):")
+    fmt.Fprintf(w, "  p50 / p95 / p99:       %.2f / %.2f / %.2f ms\n", q.P50Ms, q.P95Ms, q.P99Ms)
+    fmt.Fprintf(w, "  Maksimum:              %.2f ms\n", q.MaxMs)
+    fmt.Fprintf(w, "  Terlambat >= %v:     %d dari %d job (%.2f%%)\n", queueLate, q.Late, q.Jobs, q.LatePct)
+    if q.Warning != "" {
+        fmt.Fprintf(w, "  ⚠️  %s\n", q.Warning)
+    }
+}
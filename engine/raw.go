@@ -0,0 +1,203 @@
+package engine
+
+import (
+    "container/heap"
+    "encoding/csv"
+    "fmt"
+    "os"
+    "sort"
+    "strconv"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// Nilai kolom kept: alasan baris raw sample disimpan
+const (
+    rawKeptAll     = "all"     // Tanpa -sample-raw, semua request ditulis
+    rawKeptSample  = "sample"  // Terpilih acak oleh -sample-raw
+    rawKeptError   = "error"   // Request gagal atau status 5xx selalu ditulis
+    rawKeptSlowest = "slowest" // Termasuk -raw-keep-slowest request paling lambat
+)
+
+// sampleWriter menulis hasil setiap request (raw samples) ke file CSV.
+// Selain waktu dinding, setiap sampel punya mono_ns (jam monotonic sejak
+// metadata.clock.mono_epoch) dan corrected_timestamp (jika -ntp atau
+// -clock-sync berhasil) agar
+// hasil beberapa agent bisa disejajarkan meski jamnya tidak sinkron.
+//
+// Dengan -sample-raw hanya sebagian request sukses yang ditulis; request
+// gagal dan status 5xx selalu ditulis dan request paling lambat (-raw-keep-slowest) ditulis
+// di akhir file saat Close, agar run panjang tetap punya detail yang berguna
+// dengan ukuran file terbatas.
+type sampleWriter struct {
+    mu    sync.Mutex
+    file  *os.File
+    csv   *csv.Writer
+    clock *runClock
+    scrub bool    // -scrub: URL di pesan error tanpa query string
+    rate  float64 // -sample-raw, 0 berarti semua request ditulis
+    seed  int64   // -seed, untuk memilih sampel -sample-raw
+
+    slowest rawHeap // Request sukses paling lambat, min-heap berdasarkan latency
+    keep    int     // -raw-keep-slowest
+
+    // Hanya 1 dari every sampel yang ditulis, dinaikkan -max-memory saat
+    // memori generator mendekati batas
+    every atomic.Int64
+    seen  atomic.Int64
+
+    total   atomic.Int64
+    written atomic.Int64
+}
+
+func newSampleWriter(config *Config, clock *runClock) (*sampleWriter, error) {
+    file, err := os.Create(config.RawFile)
+    if err != nil {
+        return nil, err
+    }
+    w := &sampleWriter{file: file, csv: csv.NewWriter(file), clock: clock, scrub: config.Scrub, seed: config.Seed}
+    if config.RawSample > 0 && config.RawSample < 1 {
+        w.rate, w.keep = config.RawSample, config.RawSlowest
+    }
+    w.every.Store(1)
+    if err := w.csv.Write([]string{"timestamp", "latency_ms", "status", "bytes", "error", "mono_ns", "corrected_timestamp", "kept"}); err != nil {
+        file.Close()
+        return nil, err
+    }
+    return w, nil
+}
+
+func (w *sampleWriter) Write(requestNum int, result Result) {
+    w.total.Add(1)
+    kept := rawKeptAll
+    switch {
+    case (result.Err != nil || result.StatusCode >= 500) && w.rate > 0:
+        kept = rawKeptError
+    case w.rate > 0:
+        kept = rawKeptSample
+        if cacheRand(w.seed, requestNum).Float64() >= w.rate {
+            kept = ""
+        }
+    }
+    if kept != rawKeptError && kept != "" {
+        if every := w.every.Load(); every > 1 && w.seen.Add(1)%every != 0 {
+            kept = ""
+        }
+    }
+    candidate := kept != rawKeptError && w.keep > 0
+    if kept == "" && !candidate {
+        return
+    }
+
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    if kept == "" && !w.slowest.accepts(result.Duration, w.keep) {
+        return
+    }
+    record := w.record(result, kept)
+    if candidate {
+        // Request yang sudah ditulis sebagai sampel tetap ikut dihitung agar
+        // Close tidak menulisnya dua kali
+        w.slowest.offer(rawRow{latency: result.Duration, start: result.Start, record: record, written: kept != ""}, w.keep)
+    }
+    if kept != "" {
+        _ = w.csv.Write(record)
+        w.written.Add(1)
+    }
+}
+
+func (w *sampleWriter) record(result Result, kept string) []string {
+    errStr := ""
+    if result.Err != nil {
+        errStr = result.Err.Error()
+        if w.scrub {
+            errStr = scrubText(errStr)
+        }
+    }
+    record := []string{
+        result.Start.UTC().Format(time.RFC3339Nano),
+        strconv.FormatFloat(durationMs(result.Duration), 'f', 3, 64),
+        strconv.Itoa(result.StatusCode),
+        strconv.FormatInt(result.Bytes, 10),
+        errStr,
+        strconv.FormatInt(int64(w.clock.mono(result.Start)), 10),
+        "",
+        kept,
+    }
+    if corrected := w.clock.corrected(result.Start); !corrected.IsZero() {
+        record[6] = corrected.UTC().Format(time.RFC3339Nano)
+    }
+    return record
+}
+
+// summary ringkasan retensi untuk ditampilkan setelah file ditutup, kosong
+// tanpa -sample-raw
+func (w *sampleWriter) summary() string {
+    if w.rate == 0 {
+        return ""
+    }
+    return fmt.Sprintf("%d dari %d request (sampel %s, semua error/5xx, %d paling lambat)",
+        w.written.Load(), w.total.Load(), strconv.FormatFloat(w.rate*100, 'g', -1, 64)+"%", w.keep)
+}
+
+func (w *sampleWriter) Close() error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    // Request paling lambat yang belum ditulis, urut waktu
+    rows := w.slowest
+    sort.Slice(rows, func(i, j int) bool { return rows[i].start.Before(rows[j].start) })
+    for _, row := range rows {
+        if !row.written {
+            row.record[7] = rawKeptSlowest
+            _ = w.csv.Write(row.record)
+            w.written.Add(1)
+        }
+    }
+    w.slowest = nil
+    w.csv.Flush()
+    if err := w.csv.Error(); err != nil {
+        w.file.Close()
+        return err
+    }
+    return w.file.Close()
+}
+
+// rawRow satu kandidat request paling lambat
+type rawRow struct {
+    latency time.Duration
+    start   time.Time
+    record  []string
+    written bool // Sudah ditulis sebagai sampel
+}
+
+// rawHeap min-heap rawRow berdasarkan latency, akar adalah kandidat
+// tercepat yang pertama dibuang
+type rawHeap []rawRow
+
+func (h rawHeap) Len() int           { return len(h) }
+func (h rawHeap) Less(i, j int) bool { return h[i].latency < h[j].latency }
+func (h rawHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *rawHeap) Push(x any)        { *h = append(*h, x.(rawRow)) }
+func (h *rawHeap) Pop() any {
+    old := *h
+    row := old[len(old)-1]
+    *h = old[:len(old)-1]
+    return row
+}
+
+// accepts true jika latency termasuk n paling lambat sejauh ini
+func (h rawHeap) accepts(latency time.Duration, n int) bool {
+    return len(h) < n || latency > h[0].latency
+}
+
+// offer menyimpan row jika termasuk n paling lambat sejauh ini
+func (h *rawHeap) offer(row rawRow, n int) {
+    switch {
+    case h.Len() < n:
+        heap.Push(h, row)
+    case h.accepts(row.latency, n):
+        (*h)[0] = row
+        heap.Fix(h, 0)
+    }
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "path/filepath"
+    "strings"
+)
+
+// forRepeat mengembalikan salinan config untuk run ke-i dari -repeat, dengan
+// nama file output diberi nomor run agar tidak saling menimpa
+func (config *Config) forRepeat(i int) *Config {
+    runConfig := *config
+    runConfig.RepeatRun = i
+    runConfig.OutFile = numberedPath(config.OutFile, i)
+    runConfig.RawFile = numberedPath(config.RawFile, i)
+    runConfig.CapacityFile = numberedPath(config.CapacityFile, i)
+    runConfig.PcapFile = numberedPath(config.PcapFile, i)
+    return &runConfig
+}
+
+// numberedPath menyisipkan nomor sebelum ekstensi: report.json -> report-2.json
+func numberedPath(path string, i int) string {
+    // Template yang memakai {{.Run}} sudah berbeda per run
+    if path == "" || strings.Contains(path, ".Run") {
+        return path
+    }
+    ext := filepath.Ext(path)
+    return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), i, ext)
+}
+
+// printRepeatSummary menampilkan hasil tiap run beserta rata-rata, standar
+// deviasi dan koefisien variasi antar run
+func printRepeatSummary(w io.Writer, reports []*Report) {
+    fmt.Fprintln(w, "\n"+strings.Repeat("=", 60))
+    fmt.Fprintf(w, "🔁 RINGKASAN %d RUN\n", len(reports))
+    fmt.Fprintln(w, strings.Repeat("=", 60))
+
+    f := reports[0].display
+    fmt.Fprintf(w, "%-8s %12s %12s %12s %10s\n", "Run", "Req/detik", "Avg (ms)", "Max (ms)", "Error %")
+    for i, r := range reports {
+        fmt.Fprintf(w, "%-8d %12s %12s %12s %10s\n", i+1, f.Float(r.RPS), f.Float(r.AvgLatencyMs), f.Float(r.MaxLatencyMs), f.FloatN(r.ErrorRate, 2))
+    }
+    fmt.Fprintln(w, strings.Repeat("-", 60))
+
+    metrics := []struct {
+        name   string
+        metric func(r *Report) float64
+    }{
+        {"Requests per detik", func(r *Report) float64 { return r.RPS }},
+        {"Avg latency (ms)", func(r *Report) float64 { return r.AvgLatencyMs }},
+        {"Max latency (ms)", func(r *Report) float64 { return r.MaxLatencyMs }},
+        {"Error rate (%)", func(r *Report) float64 { return r.ErrorRate }},
+    }
+    fmt.Fprintf(w, "%-20s %12s %12s %12s\n", "Metrik", "Rata-rata", "Std dev", "CV")
+    for _, m := range metrics {
+        mean, sd := meanStd(reportValues(reports, m.metric))
+        cv := 0.0
+        if mean != 0 {
+            cv = sd / mean * 100
+        }
+        fmt.Fprintf(w, "%-20s %12s %12s %11s%%\n", m.name, f.Float(mean), f.Float(sd), f.FloatN(cv, 1))
+    }
+    fmt.Fprintln(w, strings.Repeat("=", 60))
+}
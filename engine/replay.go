@@ -0,0 +1,97 @@
+package engine
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// speedValue flag.Value untuk -speed, menerima '2x', '0.5x' atau '2'
+type speedValue float64
+
+func (v *speedValue) String() string {
+    if v == nil {
+        return ""
+    }
+    return strconv.FormatFloat(float64(*v), 'g', -1, 64) + "x"
+}
+
+func (v *speedValue) Set(value string) error {
+    speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(value)), "x"), 64)
+    if err != nil || speed <= 0 {
+        return fmt.Errorf("speed %q tidak valid (contoh: 2x, 0.5x)", value)
+    }
+    *v = speedValue(speed)
+    return nil
+}
+
+// scaleOffsets menyesuaikan jarak antar request dengan -speed: 2x membuat
+// jarak menjadi setengahnya, 0.5x dua kali lipat
+func scaleOffsets(offsets []time.Duration, speed float64) {
+    if speed == 1 {
+        return
+    }
+    for i, offset := range offsets {
+        offsets[i] = time.Duration(float64(offset) / speed)
+    }
+}
+
+// accessLogLayout format waktu Common/Combined Log Format (nginx, Apache)
+const accessLogLayout = "02/Jan/2006:15:04:05 -0700"
+
+// parseAccessLogTime mengambil timestamp '[10/Oct/2024:13:55:36 +0700]'
+// dari baris access log
+func parseAccessLogTime(line string) (time.Time, bool) {
+    open := strings.IndexByte(line, '[')
+    if open < 0 {
+        return time.Time{}, false
+    }
+    end := strings.IndexByte(line[open:], ']')
+    if end < 0 {
+        return time.Time{}, false
+    }
+    ts, err := time.Parse(accessLogLayout, line[open+1:open+end])
+    return ts, err == nil
+}
+
+// loadHAROffsets membaca startedDateTime semua entry di file HAR
+func loadHAROffsets(path string) ([]time.Duration, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var har struct {
+        Log struct {
+            Entries []struct {
+                StartedDateTime time.Time `json:"startedDateTime"`
+            } `json:"entries"`
+        } `json:"log"`
+    }
+    if err := json.Unmarshal(data, &har); err != nil {
+        return nil, fmt.Errorf("%s bukan file HAR yang valid: %w", path, err)
+    }
+    times := make([]time.Time, len(har.Log.Entries))
+    for i, entry := range har.Log.Entries {
+        times[i] = entry.StartedDateTime
+    }
+    if len(times) == 0 {
+        return nil, fmt.Errorf("%s: tidak ada entry", path)
+    }
+    return offsetsFrom(times), nil
+}
+
+// offsetsFrom mengurutkan timestamp lalu mengubahnya menjadi offset dari
+// timestamp pertama. HAR dan access log tidak selalu urut karena entry
+// ditulis saat request selesai.
+func offsetsFrom(times []time.Time) []time.Duration {
+    sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+    offsets := make([]time.Duration, len(times))
+    for i, ts := range times {
+        offsets[i] = ts.Sub(times[0])
+    }
+    return offsets
+}
@@ -0,0 +1,499 @@
+package engine
+
+import (
+    "encoding/json"
+    "fmt"
+    "html/template"
+    "net/http"
+    "os"
+    "path/filepath"
+    "runtime"
+    "sort"
+    "strings"
+    "sync/atomic"
+    "time"
+)
+
+// reportSchemaVersion versi skema report JSON. Dinaikkan hanya saat field
+// yang sudah ada diubah arti atau tipenya, atau dihapus; field baru
+// selalu opsional dan tidak mengubah versi.
+const reportSchemaVersion = 1
+
+// Report ringkasan hasil load test untuk diekspor ke file (JSON/HTML)
+type Report struct {
+    SchemaVersion int           `json:"schema_version"`
+    RunID         string        `json:"run_id"`
+    Name          string        `json:"name,omitempty"`        // -name
+    Interrupted   bool          `json:"interrupted,omitempty"` // Dihentikan Ctrl+C/SIGTERM sebelum jadwal selesai
+    URL           string        `json:"url"`
+    Targets       []string      `json:"targets,omitempty"` // Semua URL pada test multi-target
+    Scenario      string        `json:"scenario,omitempty"`
+    Method        string        `json:"method"`
+    Concurrency   int           `json:"concurrency"`
+    Schedule      string        `json:"schedule"`
+    StartTime     time.Time     `json:"start_time"`
+    TotalTimeMs   float64       `json:"total_time_ms"`
+    TotalRequests int64         `json:"total_requests"`
+    Successful    int64         `json:"successful_requests"`
+    Failed        int64         `json:"failed_requests"`
+    RPS           float64       `json:"requests_per_second"`
+    AvgLatencyMs  float64       `json:"avg_latency_ms"`
+    MinLatencyMs  float64       `json:"min_latency_ms"`
+    MaxLatencyMs  float64       `json:"max_latency_ms"`
+    P50LatencyMs  float64       `json:"p50_latency_ms"`
+    P90LatencyMs  float64       `json:"p90_latency_ms"`
+    P95LatencyMs  float64       `json:"p95_latency_ms"`
+    P99LatencyMs  float64       `json:"p99_latency_ms"`
+    SuccessRate   float64       `json:"success_rate"`
+    ErrorRate     float64       `json:"error_rate"` // Persen request gagal atau berstatus >= 400
+    StatusCodes   map[int]int64 `json:"status_codes"`
+    Retries       int64         `json:"retries,omitempty"`  // Retry step skenario
+    Scrubbed      bool          `json:"scrubbed,omitempty"` // Dibersihkan dengan -scrub
+
+    Challenges   map[string]int64              `json:"waf_challenges,omitempty"` // Response challenge WAF/bot detection per vendor
+    Thresholds   []ThresholdResult             `json:"thresholds,omitempty"`
+    Trend        *TrendReport                  `json:"trend,omitempty"`
+    Stages       []StageReport                 `json:"stages,omitempty"`
+    CapacityKnee *CapacityPoint                `json:"capacity_knee,omitempty"`
+    LittlesLaw   *LittlesLawReport             `json:"littles_law,omitempty"`
+    Queue        *QueueReport                  `json:"generator_queue,omitempty"`
+    Pacing       *PacingReport                 `json:"pacing,omitempty"`
+    Wave         *WaveReport                   `json:"wave,omitempty"`
+    BodySize     *BodySizeReport               `json:"body_size,omitempty"`
+    Connections  *ConnReport                   `json:"connections,omitempty"`
+    Cache        *CacheReport                  `json:"cache,omitempty"`
+    Security     *SecurityReport               `json:"security_headers,omitempty"`
+    Shadow       *ShadowReport                 `json:"shadow,omitempty"`
+    Page         *PageReport                   `json:"page,omitempty"`
+    URLLimits    []URLLimitReport              `json:"url_limits,omitempty"`
+    Idempotency  *IdempotencyReport            `json:"idempotency,omitempty"`
+    Deadline     *DeadlineReport               `json:"deadline,omitempty"`
+    ErrorBodies  *ErrorBodyReport              `json:"error_bodies,omitempty"`
+    Drain        *DrainReport                  `json:"drain,omitempty"`
+    Monitor      *MonitorReport                `json:"monitor,omitempty"`
+    Budget       *BudgetReport                 `json:"resource_budget,omitempty"`
+    Phases       map[string]PhaseReport        `json:"phases,omitempty"`
+    Workflows    map[string]WorkflowReport     `json:"workflows,omitempty"`
+    Consistency  map[string]ConsistencyReport  `json:"read_after_write,omitempty"`
+    Metrics      map[string]CustomMetricReport `json:"metrics,omitempty"`
+    Merged       []MergedRun                   `json:"merged_runs,omitempty"` // Report gabungan dari loadtest merge
+    Metadata     RunMetadata                   `json:"metadata"`
+
+    Brand   *ReportBrand `json:"-"` // Tampilan report HTML/Markdown
+    display numberFormat // Format angka report HTML/Markdown dan ringkasan terminal
+    latency *histogram   // Untuk file hasil .bin yang bisa digabung
+
+    rawSamples *embeddedRaw // -report-embed-raw
+}
+
+// RunMetadata konfigurasi dan lingkungan generator saat run, dipakai untuk
+// memastikan run yang dibandingkan memang setara
+type RunMetadata struct {
+    GeneratorHost string     `json:"generator_host"`
+    OS            string     `json:"os"`
+    Arch          string     `json:"arch"`
+    NumCPU        int        `json:"num_cpu"`
+    GoVersion     string     `json:"go_version"`
+    TimeoutSec    int        `json:"timeout_s"`
+    KeepAlive     bool       `json:"keep_alive"`
+    ConnPool      string     `json:"conn_pool,omitempty"`
+    BodySHA256    string     `json:"body_sha256,omitempty"`
+    Seed          int64      `json:"seed,omitempty"`
+    HeaderNames   []string   `json:"header_names,omitempty"` // Nilai header tidak disimpan karena bisa berisi token
+    Clock         *ClockInfo `json:"clock,omitempty"`
+    Agent         *AgentInfo `json:"agent,omitempty"` // Run terdistribusi dengan -run-group
+}
+
+func newRunMetadata(config *Config) RunMetadata {
+    host, _ := os.Hostname()
+    meta := RunMetadata{
+        GeneratorHost: host,
+        OS:            runtime.GOOS,
+        Arch:          runtime.GOARCH,
+        NumCPU:        runtime.NumCPU(),
+        GoVersion:     runtime.Version(),
+        TimeoutSec:    config.Timeout,
+        KeepAlive:     config.KeepAlive,
+        ConnPool:      config.ConnPool,
+        Seed:          config.Seed,
+    }
+    if config.Body != "" {
+        meta.BodySHA256 = sha256Hex([]byte(config.Body))
+    }
+    for _, header := range config.Headers {
+        if name, _, found := strings.Cut(header, ":"); found {
+            meta.HeaderNames = append(meta.HeaderNames, http.CanonicalHeaderKey(strings.TrimSpace(name)))
+        }
+    }
+    sort.Strings(meta.HeaderNames)
+    return meta
+}
+
+func durationMs(d time.Duration) float64 {
+    return float64(d) / float64(time.Millisecond)
+}
+
+// buildReport menyusun Report dari statistik yang sudah terkumpul
+func buildReport(config *Config, scheduler Scheduler, stats *Stats, startTime time.Time, totalTime time.Duration) *Report {
+    report := &Report{
+        SchemaVersion: reportSchemaVersion,
+        RunID:         newRunID(startTime),
+        Name:          config.Name,
+        URL:           config.URL,
+        Targets:       config.Targets,
+        Scenario:      config.Scenario,
+        Method:        config.Method,
+        Concurrency:   config.Concurrency,
+        Schedule:      scheduler.String(),
+        StartTime:     startTime,
+        TotalTimeMs:   durationMs(totalTime),
+        TotalRequests: stats.TotalRequests.Load(),
+        Successful:    stats.SuccessfulRequests.Load(),
+        Failed:        stats.FailedRequests.Load(),
+        StatusCodes:   make(map[int]int64),
+        Metadata:      newRunMetadata(config),
+        Phases:        stats.phases.report(),
+        Workflows:     stats.flows.report(),
+        Consistency:   stats.reads.report(),
+        Metrics:       stats.metrics.report(totalTime),
+        Retries:       stats.retries.Load(),
+        BodySize:      stats.sizes.report(),
+        Challenges:    stats.challenges.report(),
+        Budget:        stats.budget.report(),
+        ErrorBodies:   stats.bodies.report(),
+        Interrupted:   stats.interrupted.Load(),
+        latency:       &stats.latency,
+    }
+    if stats.segments != nil {
+        report.Stages = stats.segments.report()
+        curve := capacityCurve(report.Stages)
+        if knee := kneeIndex(curve); knee >= 0 {
+            report.CapacityKnee = &curve[knee]
+        }
+    }
+
+    stats.StatusCodes.Range(func(key, value interface{}) bool {
+        report.StatusCodes[key.(int)] = value.(*atomic.Int64).Load()
+        return true
+    })
+
+    if report.TotalRequests > 0 {
+        report.RPS = float64(report.TotalRequests) / totalTime.Seconds()
+        report.AvgLatencyMs = durationMs(time.Duration(stats.TotalDuration.Load() / report.TotalRequests))
+        report.MinLatencyMs = durationMs(time.Duration(stats.MinDuration.Load()))
+        report.MaxLatencyMs = durationMs(time.Duration(stats.MaxDuration.Load()))
+        // Nilai bucket histogram hanya perkiraan, jangan sampai keluar dari min/max
+        percentile := func(q float64) float64 {
+            return max(report.MinLatencyMs, min(report.MaxLatencyMs, durationMs(stats.latency.quantileDuration(q))))
+        }
+        report.P50LatencyMs = percentile(0.50)
+        report.P90LatencyMs = percentile(0.90)
+        report.P95LatencyMs = percentile(0.95)
+        report.P99LatencyMs = percentile(0.99)
+        report.SuccessRate = float64(report.Successful) / float64(report.TotalRequests) * 100
+
+        errors := report.Failed
+        for code, count := range report.StatusCodes {
+            if code >= 400 {
+                errors += count
+            }
+        }
+        report.ErrorRate = float64(errors) / float64(report.TotalRequests) * 100
+    }
+    if wave, ok := scheduler.(*waveScheduler); ok {
+        report.Wave = wave.report(&stats.timeline, startTime)
+    }
+    report.Pacing = pacingReport(config.Pacing, report.TotalRequests, stats.pacingMissed.Load())
+    report.LittlesLaw = littlesLaw(report, scheduler)
+    report.Queue = stats.queue.report(report.P95LatencyMs)
+    return report
+}
+
+// exportResults menulis report dan raw samples ke file lalu mengirimnya ke
+// sink eksternal yang dikonfigurasi
+func exportResults(config *Config, report *Report, stats *Stats) error {
+    if stats.raw != nil {
+        if err := stats.raw.Close(); err != nil {
+            return fmt.Errorf("menulis raw samples: %w", err)
+        }
+        if summary := stats.raw.summary(); summary != "" {
+            fmt.Fprintf(config.out, "💾 Raw samples %s disimpan ke %s\n", summary, config.RawFile)
+        }
+        if config.ReportEmbedRaw {
+            raw, err := embedRawSamples(config.RawFile, stats.raw.written.Load())
+            if err != nil {
+                return fmt.Errorf("menyematkan raw samples: %w", err)
+            }
+            report.rawSamples = raw
+        }
+    }
+
+    if config.OutFile != "" {
+        if err := writeReport(report, config.OutFile); err != nil {
+            return fmt.Errorf("menulis report: %w", err)
+        }
+        fmt.Fprintf(config.out, "💾 Report disimpan ke %s\n", config.OutFile)
+    }
+
+    if config.CapacityFile != "" {
+        curve := capacityCurve(report.Stages)
+        if err := writeCapacity(curve, kneeIndex(curve), config.CapacityFile); err != nil {
+            return fmt.Errorf("menulis kurva kapasitas: %w", err)
+        }
+        fmt.Fprintf(config.out, "💾 Kurva kapasitas disimpan ke %s\n", config.CapacityFile)
+    }
+
+    // Interval digeser offset jam agar dashboard gabungan beberapa agent
+    // sejajar
+    adjustedIntervals := func(length time.Duration) []IntervalReport {
+        intervals := stats.timeline.intervals(length)
+        for i := range intervals {
+            intervals[i].Start = stats.clock.adjust(intervals[i].Start)
+        }
+        return intervals
+    }
+
+    if config.ElasticURL != "" {
+        if err := indexToElastic(config, report, adjustedIntervals(config.ElasticInterval)); err != nil {
+            return fmt.Errorf("indexing ke elastic: %w", err)
+        }
+    }
+
+    if config.ResultWebhook != "" {
+        if err := sendResultWebhook(config, report, adjustedIntervals(config.ResultInterval)); err != nil {
+            return fmt.Errorf("mengirim ke result webhook: %w", err)
+        }
+    }
+
+    if config.Upload != "" {
+        // Report JSON selalu ikut diunggah agar hasil bisa diolah mesin
+        files := make(map[string][]byte)
+        data, err := report.JSON()
+        if err != nil {
+            return err
+        }
+        files["report.json"] = data
+        for _, path := range []string{config.OutFile, config.RawFile, config.CapacityFile} {
+            if path == "" {
+                continue
+            }
+            data, err := os.ReadFile(path)
+            if err != nil {
+                return err
+            }
+            files[filepath.Base(path)] = data
+        }
+        if err := uploadResults(config.out, config.Upload, report.RunID, files); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// newRunID membuat ID run dari hostname dan waktu mulai, dipakai sebagai
+// nama folder upload dan penanda dokumen di sink eksternal
+func newRunID(startTime time.Time) string {
+    host, _ := os.Hostname()
+    if host == "" {
+        host = "unknown"
+    }
+    return fmt.Sprintf("%s-%s", host, startTime.UTC().Format("20060102T150405Z"))
+}
+
+// loadReport membaca report JSON yang disimpan dengan -out
+func loadReport(path string) (*Report, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var report Report
+    if err := json.Unmarshal(data, &report); err != nil {
+        return nil, fmt.Errorf("%s bukan report JSON yang valid: %w", path, err)
+    }
+    if report.SchemaVersion > reportSchemaVersion {
+        fmt.Printf("⚠️  %s memakai skema report versi %d, versi ini hanya mengenal sampai %d; sebagian field mungkin terbaca salah\n", path, report.SchemaVersion, reportSchemaVersion)
+    }
+    return &report, nil
+}
+
+// writeReport menulis report ke path, format ditentukan dari ekstensi file
+func writeReport(report *Report, path string) error {
+    var data []byte
+    var err error
+    switch strings.ToLower(filepath.Ext(path)) {
+    case ".json":
+        data, err = report.JSON()
+    case ".html", ".htm":
+        data, err = report.HTML()
+    case ".md", ".markdown":
+        data, err = report.Markdown()
+    case ".yaml", ".yml":
+        data, err = report.YAML()
+    case ".bin":
+        data, err = report.Result()
+    default:
+        return fmt.Errorf("format report %q tidak dikenal (gunakan .json, .yaml, .html, .md atau .bin)", filepath.Ext(path))
+    }
+    if err != nil {
+        return err
+    }
+    return os.WriteFile(path, data, 0644)
+}
+
+func (r *Report) JSON() ([]byte, error) {
+    return json.MarshalIndent(r, "", "  ")
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.ReportTitle}} - {{.URL}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 4px 12px; text-align: left; }
+th { background: #f0f0f0; }
+.logo { max-height: 64px; }
+footer { color: #666; border-top: 1px solid #ccc; padding-top: 1em; }
+</style>
+</head>
+<body>
+{{if .ReportLogo}}<img class="logo" src="{{.ReportLogo}}" alt="logo">
+{{end}}<h1>📈 {{.ReportTitle}}</h1>
+<table>
+<tr><th>URL</th><td>{{.URL}}</td></tr>
+<tr><th>Method</th><td>{{.Method}}</td></tr>
+<tr><th>Jadwal</th><td>{{.Schedule}}</td></tr>
+<tr><th>Concurrency</th><td>{{.Concurrency}}</td></tr>
+<tr><th>Mulai</th><td>{{.StartTime.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+<tr><th>Total waktu</th><td>{{$.FloatN .TotalTimeMs 0}} ms</td></tr>
+<tr><th>Total requests</th><td>{{$.Int .TotalRequests}}</td></tr>
+<tr><th>Requests sukses</th><td>{{$.Int .Successful}}</td></tr>
+<tr><th>Requests gagal</th><td>{{$.Int .Failed}}</td></tr>
+<tr><th>Requests per detik</th><td>{{$.Float .RPS}}</td></tr>
+{{if .TotalRequests}}<tr><th>Rata-rata latency</th><td>{{$.Float .AvgLatencyMs}} ms</td></tr>
+<tr><th>Latency terendah</th><td>{{$.Float .MinLatencyMs}} ms</td></tr>
+<tr><th>Latency tertinggi</th><td>{{$.Float .MaxLatencyMs}} ms</td></tr>
+<tr><th>Latency p50 / p95 / p99</th><td>{{$.Float .P50LatencyMs}} / {{$.Float .P95LatencyMs}} / {{$.Float .P99LatencyMs}} ms</td></tr>
+<tr><th>Success rate</th><td>{{$.FloatN .SuccessRate 1}}%</td></tr>
+{{else}}<tr><th>Latency</th><td>- (tidak ada request yang selesai)</td></tr>
+{{end}}{{with .BodySize}}<tr><th>Ukuran response (min / p50 / p99 / max)</th><td>{{$.Int .Min}} / {{$.Int .P50}} / {{$.Int .P99}} / {{$.Int .Max}} bytes{{if .Warning}}<br>⚠️ {{.Warning}}{{end}}</td></tr>
+{{end}}{{with .LittlesLaw}}<tr><th>Concurrency efektif (Little's Law)</th><td>{{$.FloatN .Expected 1}} dari {{.Configured}} worker ({{$.FloatN .Utilization 1}}%){{if .Warning}}<br>⚠️ {{.Warning}}{{end}}</td></tr>
+{{end}}{{with .Queue}}<tr><th>Antre di generator p50 / p95 / p99</th><td>{{$.Float .P50Ms}} / {{$.Float .P95Ms}} / {{$.Float .P99Ms}} ms ({{$.Float .LatePct}}% job ≥ 10 ms){{if .Warning}}<br>⚠️ {{.Warning}}{{end}}</td></tr>
+{{end}}</table>
+<h2>Status Codes</h2>
+<table>
+<tr><th>Code</th><th>Requests</th></tr>
+{{range .SortedStatusCodes}}<tr><td>{{.Code}}</td><td>{{$.Int .Count}}</td></tr>
+{{else}}<tr><td colspan="2">Tidak ada response{{if .Failed}}: semua request gagal sebelum server membalas{{end}}</td></tr>
+{{end}}</table>
+{{if .Challenges}}<h2>Diblokir WAF/Bot Detection</h2>
+<p>⚠️ Request berikut ditolak sebelum sampai ke aplikasi; latency dan error rate tidak mencerminkan performa aplikasi.</p>
+<table>
+<tr><th>Vendor</th><th>Requests</th></tr>
+{{range $vendor, $n := .Challenges}}<tr><td>{{$vendor}}</td><td>{{$.Int $n}}</td></tr>
+{{end}}</table>
+{{end}}{{with .ErrorBodies}}{{if .Reasons}}<h2>Alasan Error dari Server</h2>
+<p>{{$.Int .Parsed}} dari {{$.Int .Responses}} response 4xx/5xx berisi kode atau pesan error JSON.</p>
+<table>
+<tr><th>Status</th><th>Kode</th><th>Requests</th><th>%</th><th>Contoh pesan</th></tr>
+{{range $.TopErrorReasons}}<tr><td>{{.Status}}</td><td>{{.Code}}</td><td>{{$.Int .Count}}</td><td>{{$.FloatN .Pct 1}}</td><td>{{.Message}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}{{with .Budget}}{{if .Events}}<h2>Budget Resource Generator</h2>
+<p>⚠️ Generator menurunkan beban karena mendekati batas resource; hasil setelah degradasi tidak setara dengan beban yang direncanakan.</p>
+<table>
+<tr><th>Detik</th><th>Penyebab</th><th>Tindakan</th></tr>
+{{range .Events}}<tr><td>{{$.FloatN .AtSec 0}}</td><td>{{.Reason}}</td><td>{{.Action}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}{{with .Metadata.Agent}}{{if .Pauses}}<h2>Kesehatan Agent</h2>
+<p>⚠️ Controller menjeda beban agent {{.Agent}} karena generator tidak sehat; {{$.Int .Shed}} job open-loop dibuang selama jeda.</p>
+<table>
+<tr><th>Detik</th><th>Lama (detik)</th><th>Penyebab</th></tr>
+{{range .Pauses}}<tr><td>{{$.FloatN .AtSec 0}}</td><td>{{$.FloatN .Sec 0}}</td><td>{{.Reason}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}{{if .Merged}}<h2>Run Digabung</h2>
+<p>Percentile dihitung dari histogram latency gabungan semua run; requests per detik dari rentang waktu semua run.</p>
+<table>
+<tr><th>Run</th><th>Generator</th><th>Requests</th><th>Req/s</th><th>p95 ms</th><th>Error %</th></tr>
+{{range .Merged}}<tr><td>{{.RunID}}</td><td>{{.Host}}</td><td>{{$.Int .TotalRequests}}</td><td>{{$.FloatN .RPS 1}}</td><td>{{$.Float .P95LatencyMs}}</td><td>{{$.Float .ErrorRate}}</td></tr>
+{{end}}</table>
+{{end}}{{with .Trend}}<h2>Tren Historis</h2>
+<p>Dibandingkan dengan {{len .Baseline}} run sebelumnya berprofil sama, batas {{.Sigma}}σ.</p>
+<table>
+<tr><th>Metrik</th><th>Run ini</th><th>Rata-rata</th><th>Std dev</th><th>σ</th><th>Hasil</th></tr>
+{{range .Metrics}}<tr><td>{{.Metric}}</td><td>{{$.Float .Value}}</td><td>{{$.Float .Mean}}</td><td>{{$.Float .StdDev}}</td><td>{{$.FloatN .Z 1}}</td><td>{{if .Anomaly}}❌{{else}}✅{{end}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Stages}}<h2>Hasil per Stage</h2>
+<table>
+<tr><th>Stage</th><th>Target</th><th>Requests</th><th>Req/s</th><th>Avg ms</th><th>p50 ms</th><th>p95 ms</th><th>p99 ms</th><th>Error %</th></tr>
+{{range .Stages}}<tr><td>{{.Name}}</td><td>{{.Target}}</td><td>{{$.Int .Requests}}</td><td>{{$.FloatN .RPS 1}}</td><td>{{$.FloatN .AvgMs 1}}</td><td>{{$.FloatN .P50Ms 1}}</td><td>{{$.FloatN .P95Ms 1}}</td><td>{{$.FloatN .P99Ms 1}}</td><td>{{$.Float .ErrorRate}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Workflows}}<h2>Latency Workflow (submit sampai selesai)</h2>
+<table>
+<tr><th>Workflow</th><th>Selesai</th><th>Gagal</th><th>Avg ms</th><th>p50 ms</th><th>p95 ms</th><th>p99 ms</th><th>Max ms</th></tr>
+{{range $name, $w := .Workflows}}<tr><td>{{$w.From}} → {{$name}}</td><td>{{$.Int $w.Completed}}</td><td>{{$.Int $w.Failed}}</td><td>{{$.FloatN $w.AvgMs 1}}</td><td>{{$.FloatN $w.P50Ms 1}}</td><td>{{$.FloatN $w.P95Ms 1}}</td><td>{{$.FloatN $w.P99Ms 1}}</td><td>{{$.FloatN $w.MaxMs 1}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Consistency}}<h2>Read-after-write</h2>
+<table>
+<tr><th>Tulis → baca</th><th>Baca</th><th>Basi %</th><th>Tidak pernah konsisten</th><th>Lag p50 ms</th><th>Lag p95 ms</th><th>Lag p99 ms</th><th>Lag max ms</th></tr>
+{{range $name, $c := .Consistency}}<tr><td>{{$c.From}} → {{$name}}</td><td>{{$.Int $c.Reads}}</td><td>{{$.Float $c.StalePct}}</td><td>{{$.Int $c.Diverged}}</td><td>{{$.FloatN $c.LagP50Ms 1}}</td><td>{{$.FloatN $c.LagP95Ms 1}}</td><td>{{$.FloatN $c.LagP99Ms 1}}</td><td>{{$.FloatN $c.LagMaxMs 1}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Metrics}}<h2>Metrik Custom</h2>
+<table>
+<tr><th>Metrik</th><th>Jenis</th><th>Jumlah sampel</th><th>Ringkasan</th></tr>
+{{range $name, $m := .Metrics}}<tr><td>{{$name}}</td><td>{{$m.Type}}</td><td>{{$.Int $m.Count}}</td><td>{{$m.Summary}}</td></tr>
+{{end}}</table>
+{{end}}{{with .Security}}<h2>Audit Security Header</h2>
+<table>
+<tr><th>Header</th><th>Status</th><th>Ada</th><th>Hilang</th><th>Hilang di 5xx/429</th><th>Tidak valid</th><th>Keterangan</th></tr>
+{{range .Headers}}<tr><td>{{.Header}}</td><td>{{if eq .Status "pass"}}✅ PASS{{else}}⚠️ WARN{{end}}</td><td>{{$.FloatN .PresentPct 1}}%</td><td>{{$.Int .Missing}}</td><td>{{$.Int .MissingOnError}}</td><td>{{$.Int .Invalid}}</td><td>{{.Note}}</td></tr>
+{{end}}</table>
+{{end}}{{with .Shadow}}<h2>Shadow Traffic</h2>
+<p>{{.URL}}: {{$.Int .Mirrored}} request disalin, {{$.Int .Dropped}} dilewati, status berbeda {{$.Int .Mismatches}} ({{$.Float .MismatchPct}}%)</p>
+<table>
+<tr><th></th><th>Requests</th><th>Error %</th><th>p50 ms</th><th>p95 ms</th><th>p99 ms</th></tr>
+<tr><td>Utama</td><td>{{$.Int .Primary.Requests}}</td><td>{{$.Float .Primary.ErrorRate}}</td><td>{{$.FloatN .Primary.P50Ms 1}}</td><td>{{$.FloatN .Primary.P95Ms 1}}</td><td>{{$.FloatN .Primary.P99Ms 1}}</td></tr>
+<tr><td>Shadow</td><td>{{$.Int .Shadow.Requests}}</td><td>{{$.Float .Shadow.ErrorRate}}</td><td>{{$.FloatN .Shadow.P50Ms 1}}</td><td>{{$.FloatN .Shadow.P95Ms 1}}</td><td>{{$.FloatN .Shadow.P99Ms 1}}</td></tr>
+</table>
+{{with .Diff}}<p>Body berbeda: {{$.Int .Different}} dari {{$.Int .Compared}} yang dibandingkan ({{$.Float .DifferentPct}}%)</p>
+{{if .Paths}}<table>
+<tr><th>Path</th><th>Response</th><th>Contoh (utama → shadow)</th></tr>
+{{range .Paths}}<tr><td>{{.Path}}</td><td>{{$.Int .Count}}</td><td>{{.Example}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}{{end}}{{with .Wave}}<h2>Wave: Rate Target vs Tercapai</h2>
+<p>{{.Shape}}, deviasi rata-rata {{$.FloatN .AvgDeviation 1}}%{{if .Warning}}<br>⚠️ {{.Warning}}{{end}}</p>
+{{$.WaveChart}}
+{{end}}{{.RawSamples}}{{if .ReportFooter}}<footer>{{.ReportFooter}}</footer>
+{{end}}</body>
+</html>
+`))
+
+// HTML report dalam format HTML, memakai template custom jika ada
+func (r *Report) HTML() ([]byte, error) {
+    tmpl := htmlReportTemplate
+    if r.Brand != nil && r.Brand.html != nil {
+        tmpl = r.Brand.html
+    }
+    var sb strings.Builder
+    if err := tmpl.Execute(&sb, r); err != nil {
+        return nil, err
+    }
+    return []byte(sb.String()), nil
+}
+
+// StatusCount pasangan status code dan jumlahnya
+type StatusCount struct {
+    Code  int
+    Count int64
+}
+
+// SortedStatusCodes mengembalikan status codes urut dari kode terkecil
+func (r *Report) SortedStatusCodes() []StatusCount {
+    counts := make([]StatusCount, 0, len(r.StatusCodes))
+    for code, count := range r.StatusCodes {
+        counts = append(counts, StatusCount{Code: code, Count: count})
+    }
+    sort.Slice(counts, func(i, j int) bool { return counts[i].Code < counts[j].Code })
+    return counts
+}
@@ -0,0 +1,219 @@
+package engine
+
+import (
+    "bytes"
+    "errors"
+    "flag"
+    "os"
+    "path/filepath"
+    "strconv"
+    "sync"
+    "testing"
+    "time"
+)
+
+var update = flag.Bool("update", false, "Tulis ulang file golden di testdata")
+
+// goldenStart waktu mulai tetap agar timeline dan run_id tidak berubah
+var goldenStart = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// goldenCases hasil request sintetis untuk kasus tepi report, berurutan agar
+// -update dan kegagalan selalu muncul dengan urutan yang sama
+var goldenCases = []struct {
+    name    string
+    results []Result
+}{
+    {"empty", nil},
+    {"all_failed", []Result{
+        {Start: goldenStart, Duration: 3 * time.Millisecond, Err: errors.New("dial tcp 10.0.0.1:80: connect: connection refused")},
+        {Start: goldenStart.Add(400 * time.Millisecond), Duration: 2 * time.Millisecond, Err: errors.New("dial tcp 10.0.0.1:80: connect: connection refused")},
+        {Start: goldenStart.Add(1200 * time.Millisecond), Duration: time.Second, Err: errors.New("context deadline exceeded")},
+    }},
+    {"normal", []Result{
+        {Start: goldenStart, Duration: 12 * time.Millisecond, StatusCode: 200, Bytes: 512},
+        {Start: goldenStart.Add(100 * time.Millisecond), Duration: 15 * time.Millisecond, StatusCode: 200, Bytes: 512},
+        {Start: goldenStart.Add(300 * time.Millisecond), Duration: 18 * time.Millisecond, StatusCode: 200, Bytes: 640},
+        {Start: goldenStart.Add(600 * time.Millisecond), Duration: 21 * time.Millisecond, StatusCode: 201, Bytes: 128},
+        {Start: goldenStart.Add(900 * time.Millisecond), Duration: 25 * time.Millisecond, StatusCode: 200, Bytes: 512},
+        {Start: goldenStart.Add(1100 * time.Millisecond), Duration: 30 * time.Millisecond, StatusCode: 404, Bytes: 64},
+        {Start: goldenStart.Add(1400 * time.Millisecond), Duration: 90 * time.Millisecond, StatusCode: 503, Bytes: 32},
+        {Start: goldenStart.Add(1700 * time.Millisecond), Duration: 14 * time.Millisecond, StatusCode: 200, Bytes: 512},
+    }},
+}
+
+// TestReportGolden membandingkan ringkasan terminal, report JSON, YAML dan
+// HTML serta hasil threshold dengan file golden, termasuk run tanpa request dan
+// run yang semua request-nya gagal. Perbarui dengan go test -update.
+func TestReportGolden(t *testing.T) {
+    for _, tc := range goldenCases {
+        t.Run(tc.name, func(t *testing.T) {
+            text, report := renderGolden(t, tc.results)
+            data, err := report.JSON()
+            if err != nil {
+                t.Fatal(err)
+            }
+            page, err := report.HTML()
+            if err != nil {
+                t.Fatal(err)
+            }
+            checkGolden(t, tc.name+".txt", text)
+            checkGolden(t, tc.name+".json", data)
+            checkGolden(t, tc.name+".html", page)
+            if tc.name == "empty" {
+                return
+            }
+            doc, err := report.YAML()
+            if err != nil {
+                t.Fatal(err)
+            }
+            checkGolden(t, tc.name+".yaml", doc)
+        })
+    }
+}
+
+// TestThresholdsNoData threshold tidak bisa dinilai tanpa request selesai:
+// hasilnya NoData dan dianggap gagal, bukan lulus karena semua nilai 0
+func TestThresholdsNoData(t *testing.T) {
+    _, report := renderGolden(t, nil)
+    if len(report.Thresholds) == 0 {
+        t.Fatal("threshold tidak dievaluasi")
+    }
+    for _, result := range report.Thresholds {
+        if !result.NoData || result.Passed {
+            t.Errorf("%s: NoData=%v Passed=%v, ingin NoData dan gagal", result.Threshold, result.NoData, result.Passed)
+        }
+    }
+
+    _, report = renderGolden(t, goldenCases[1].results)
+    for _, result := range report.Thresholds {
+        if result.NoData {
+            t.Errorf("%s: NoData pada run dengan request gagal", result.Threshold)
+        }
+    }
+}
+
+// renderGolden membangun report dari results seperti executeRun dengan
+// metadata generator yang tetap, dan mengembalikan output terminalnya
+func renderGolden(t *testing.T, results []Result) ([]byte, *Report) {
+    t.Helper()
+    config, err := ParseArgs("-n", strconv.Itoa(max(len(results), 1)), "-c", "2", "-locale", "en",
+        "-thresholds", "p99<500ms,error_rate<1%,rps>1", "http://api.test/health")
+    if err != nil {
+        t.Fatal(err)
+    }
+    scheduler, thresholds, err := prepareRun(config)
+    if err != nil {
+        t.Fatal(err)
+    }
+    display, err := newNumberFormat(config)
+    if err != nil {
+        t.Fatal(err)
+    }
+    brand, err := newReportBrand(config)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    stats := &Stats{}
+    for i, result := range results {
+        stats.Record(i, result)
+    }
+    totalTime := 2 * time.Second
+    var text bytes.Buffer
+    printResults(&text, stats, totalTime, config, display)
+    report := buildReport(config, scheduler, stats, goldenStart, totalTime)
+    report.RunID = "golden-20260102T030405Z"
+    report.Metadata = RunMetadata{GeneratorHost: "golden", OS: "linux", Arch: "amd64", NumCPU: 4, GoVersion: "go1.24", TimeoutSec: config.Timeout}
+    report.Brand = brand
+    report.display = display
+    evaluateThresholds(thresholds, report)
+    printThresholds(&text, report)
+    return text.Bytes(), report
+}
+
+func checkGolden(t *testing.T, name string, got []byte) {
+    t.Helper()
+    path := filepath.Join("testdata", name)
+    if *update {
+        if err := os.WriteFile(path, got, 0644); err != nil {
+            t.Fatal(err)
+        }
+        return
+    }
+    want, err := os.ReadFile(path)
+    if err != nil {
+        t.Fatalf("%v (buat dengan go test -update)", err)
+    }
+    if !bytes.Equal(got, want) {
+        t.Errorf("%s berbeda dari golden (perbarui dengan go test -update jika perubahan disengaja)\n--- didapat ---\n%s", path, got)
+    }
+}
+
+// TestRecordStatusCodesConcurrent hitungan status code tidak boleh hilang
+// saat banyak worker mencatat bersamaan
+func TestRecordStatusCodesConcurrent(t *testing.T) {
+    const workers, perWorker = 8, 500
+    stats := &Stats{}
+    var wg sync.WaitGroup
+    for w := 0; w < workers; w++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := 0; i < perWorker; i++ {
+                stats.Record(w*perWorker+i, Result{Start: goldenStart, Duration: time.Millisecond, StatusCode: 200 + i%2})
+            }
+        }()
+    }
+    wg.Wait()
+
+    config, err := ParseArgs("-n", "1", "http://api.test/health")
+    if err != nil {
+        t.Fatal(err)
+    }
+    report := buildReport(config, &countScheduler{count: workers * perWorker}, stats, goldenStart, time.Second)
+    if got := report.StatusCodes[200] + report.StatusCodes[201]; got != workers*perWorker {
+        t.Errorf("status code tercatat %d, ingin %d", got, workers*perWorker)
+    }
+}
+
+// TestJSONToYAML scalar dan key yang dibaca lain oleh parser YAML jika
+// ditulis tanpa quote, serta map dan list kosong
+func TestJSONToYAML(t *testing.T) {
+    tests := []struct {
+        name string
+        json string
+        want string
+    }{
+        {"bool-like strings", `{"a":"yes","b":"no","c":"on","d":"null","e":"~","f":"true"}`,
+            "a: \"yes\"\nb: \"no\"\nc: \"on\"\nd: \"null\"\ne: \"~\"\nf: \"true\"\n"},
+        {"number-like strings", `{"a":"1e3","b":"0x1F","c":"012","d":"1_000",".inf":".nan"}`,
+            "a: \"1e3\"\nb: \"0x1F\"\nc: \"012\"\nd: \"1_000\"\n\".inf\": \".nan\"\n"},
+        {"indicator characters", `{"a":"-x","b":": x","c":"#x","d":"a: b","e":"&x","f":"*x","g":"!x","h":"[1]"}`,
+            "a: \"-x\"\nb: \": x\"\nc: \"#x\"\nd: \"a: b\"\ne: \"&x\"\nf: \"*x\"\ng: \"!x\"\nh: \"[1]\"\n"},
+        {"multi-line and control", `{"err":"dial tcp: refused\nretry\tlater\r\u0000","empty":""}`,
+            "err: \"dial tcp: refused\\nretry\\tlater\\r\\x00\"\nempty: \"\"\n"},
+        {"unicode", `{"a":"latensi 5µs → ok","b":"\u2028"}`,
+            "a: \"latensi 5µs → ok\"\nb: \"\\u2028\"\n"},
+        {"tricky keys", `{"yes":1,"No":2,"-x":3,"200":4,"a:b":5,"":6,"a b":7,"plain_key.v-1":8}`,
+            "\"yes\": 1\n\"No\": 2\n\"-x\": 3\n\"200\": 4\n\"a:b\": 5\n\"\": 6\n\"a b\": 7\nplain_key.v-1: 8\n"},
+        {"numbers", `{"a":12,"b":-1.5,"c":1e-07,"d":1e+21,"e":2E5,"f":0,"g":true,"h":null}`,
+            "a: 12\nb: -1.5\nc: 1.0e-07\nd: 1.0e+21\ne: 2.0E+5\nf: 0\ng: true\nh: null\n"},
+        {"empty containers", `{"m":{},"l":[],"nested":{"m":{},"l":[[],{}]}}`,
+            "m: {}\nl: []\nnested:\n  m: {}\n  l:\n    - []\n    - {}\n"},
+        {"list of objects", `{"items":[{"a":1,"b":{"c":"x"}},{"d":[1,2]},"s"]}`,
+            "items:\n  - a: 1\n    b:\n      c: \"x\"\n  - d:\n      - 1\n      - 2\n  - \"s\"\n"},
+        {"nested lists", `[[1,[2]],[]]`,
+            "-\n  - 1\n  -\n    - 2\n- []\n"},
+    }
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            got, err := jsonToYAML([]byte(tc.json))
+            if err != nil {
+                t.Fatal(err)
+            }
+            if string(got) != tc.want {
+                t.Errorf("jsonToYAML(%s)\ndidapat:\n%s\ningin:\n%s", tc.json, got, tc.want)
+            }
+        })
+    }
+}
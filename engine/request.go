@@ -0,0 +1,183 @@
+package engine
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "strings"
+)
+
+// formParam satu parameter dari -param key=value (value) atau
+// -param-file key=path (feed)
+type formParam struct {
+    name  string
+    value *valueTemplate
+    feed  *paramFeed
+}
+
+func (p formParam) render(data templateData, worker int) (string, error) {
+    if p.feed != nil {
+        return p.feed.pick(data.N, worker)
+    }
+    return p.value.render(data)
+}
+
+// requestBuilder membuat request untuk setiap nomor request dari base
+// request, ditambah parameter -param yang di-render per request
+type requestBuilder struct {
+    base   *http.Request
+    params []formParam
+    seed   int64
+    mix    *requestMix   // Opsional, path dan method per request dari -prom-mix, -from-sitemap atau beberapa URL target
+    gen    BodyGenerator // Opsional, body per request dari WithBodyGenerator
+    // inQuery true jika parameter dikirim di query string (GET/HEAD),
+    // selain itu sebagai body application/x-www-form-urlencoded
+    inQuery bool
+}
+
+func newRequestBuilder(config *Config) (*requestBuilder, error) {
+    base, err := createBaseRequest(config)
+    if err != nil {
+        return nil, err
+    }
+    b := &requestBuilder{base: base, seed: config.Seed, gen: config.bodyGen}
+    if config.PromMix != "" {
+        if b.mix, err = loadPromMix(config); err != nil {
+            return nil, err
+        }
+        printMix(config.out, b.mix, base.Method, "prometheus")
+    } else if config.Sitemap != "" {
+        if b.mix, err = loadSitemap(config, base.URL); err != nil {
+            return nil, err
+        }
+        printMix(config.out, b.mix, base.Method, "sitemap")
+    } else if len(config.Targets) > 1 {
+        if b.mix, err = targetMix(config.Targets); err != nil {
+            return nil, err
+        }
+        printMix(config.out, b.mix, base.Method, "argumen URL")
+    }
+
+    for _, param := range config.Params {
+        name, value, found := strings.Cut(param, "=")
+        if !found || name == "" {
+            return nil, fmt.Errorf("param %q tidak valid (format: key=value)", param)
+        }
+        tmpl, err := parseValueTemplate("param "+name, value)
+        if err != nil {
+            return nil, err
+        }
+        b.params = append(b.params, formParam{name: name, value: tmpl})
+    }
+    agent, err := parseAgentShard(config.AgentShard)
+    if err != nil {
+        return nil, err
+    }
+    for _, param := range config.ParamFiles {
+        name, path, found := strings.Cut(param, "=")
+        if !found || name == "" || path == "" {
+            return nil, fmt.Errorf("param file %q tidak valid (format: key=path)", param)
+        }
+        feed, err := loadParamFeed(path, config.ParamFileMode, config.Concurrency, agent, config.Seed)
+        if err != nil {
+            return nil, err
+        }
+        b.params = append(b.params, formParam{name: name, feed: feed})
+    }
+    if len(b.params) == 0 {
+        return b, nil
+    }
+
+    b.inQuery = base.Method == http.MethodGet || base.Method == http.MethodHead
+    if !b.inQuery {
+        if config.Body != "" {
+            return nil, fmt.Errorf("-param tidak bisa dipakai bersamaan dengan -d atau -body-file untuk method %s", base.Method)
+        }
+        if config.ContentType == "" {
+            base.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+        }
+    }
+    return b, nil
+}
+
+// targetMix membagi request sama rata ke beberapa URL target yang diberikan
+// sebagai argumen. Latency per target tampil di tabel fase.
+func targetMix(targets []string) (*requestMix, error) {
+    var entries []mixEntry
+    for _, raw := range targets {
+        u, err := url.Parse(raw)
+        if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            return nil, fmt.Errorf("URL target %q tidak valid, multi-target hanya untuk URL http(s)", raw)
+        }
+        entries = append(entries, mixEntry{path: u.Path, query: u.RawQuery, weight: 1, target: u})
+    }
+    mix := newRequestMix(entries)
+    mix.multiHost = true
+    return mix, nil
+}
+
+// URL target request, dipakai untuk preconnect dan tunnel
+func (b *requestBuilder) URL() *url.URL {
+    return b.base.URL
+}
+
+// build membuat request ke-requestNum. Clone berbagi Body dengan base, jadi
+// body dibuat ulang lewat GetBody agar setiap request (termasuk yang
+// berjalan bersamaan) mengirim body lengkap.
+func (b *requestBuilder) build(ctx context.Context, requestNum int) (*http.Request, error) {
+    req := b.base.Clone(ctx)
+    if b.base.GetBody != nil {
+        req.Body, _ = b.base.GetBody()
+    }
+    if b.mix != nil {
+        entry := b.mix.pick(mixRand(b.seed, requestNum))
+        if entry.target != nil {
+            target := *entry.target
+            req.URL = &target
+            // Header Host dari -H tetap dipakai untuk semua target
+            if req.Host == b.base.URL.Host {
+                req.Host = ""
+            }
+        }
+        req.URL.Path, req.URL.RawPath = entry.path, ""
+        if entry.query != "" {
+            req.URL.RawQuery = entry.query
+        }
+        if entry.method != "" {
+            req.Method = entry.method
+        }
+    }
+    if b.gen != nil {
+        if err := generateBody(req, b.gen, requestNum, workerID(ctx)); err != nil {
+            return nil, err
+        }
+    }
+    if len(b.params) == 0 {
+        return req, nil
+    }
+
+    data := newTemplateData(requestNum, b.seed)
+    values := url.Values{}
+    if b.inQuery {
+        values = req.URL.Query()
+    }
+    for _, p := range b.params {
+        value, err := p.render(data, workerID(ctx))
+        if err != nil {
+            return nil, fmt.Errorf("param %s: %w", p.name, err)
+        }
+        values.Add(p.name, value)
+    }
+
+    encoded := values.Encode()
+    if b.inQuery {
+        req.URL.RawQuery = encoded
+        return req, nil
+    }
+    req.ContentLength = int64(len(encoded))
+    req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(encoded)), nil }
+    req.Body, _ = req.GetBody()
+    return req, nil
+}
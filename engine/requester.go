@@ -0,0 +1,102 @@
+package engine
+
+import (
+    "context"
+    "fmt"
+    "net/url"
+    "strings"
+    "time"
+)
+
+// Result hasil eksekusi satu request oleh Requester
+type Result struct {
+    Start      time.Time
+    Duration   time.Duration
+    StatusCode int
+    Bytes      int64
+    Err        error
+    ErrorBody  string        // Potongan body response gagal, untuk -error-webhook dan -error-bodies
+    RetryAfter time.Duration // Retry-After pada response 429/503, dipakai -courtesy
+    Retries    int           // Jumlah retry step skenario di dalam request ini
+    Challenge  string        // Vendor WAF/bot detection jika response adalah halaman challenge
+
+    // Phases durasi tiap fase request (opsional), diagregasi per nama
+    Phases map[string]time.Duration
+    // Workflows latency workflow async pada skenario dengan until (opsional)
+    Workflows []workflowSample
+    // Consistency hasil step read-after-write skenario (opsional)
+    Consistency []consistencySample
+    // Metrics nilai metrik custom dari step skenario (opsional)
+    Metrics []metricSample
+}
+
+// Requester mengeksekusi satu request terhadap target. Scheduler, stats dan
+// reporting hanya mengenal interface ini sehingga protokol baru (gRPC,
+// WebSocket, TCP, dst) cukup menambah implementasi tanpa menyentuh worker.
+// Implementasi harus aman dipakai bersamaan oleh banyak worker.
+type Requester interface {
+    Do(ctx context.Context, requestNum int) Result
+    Close() error
+}
+
+// Preconnector diimplementasikan Requester yang bisa membuka koneksi lebih
+// dulu sehingga waktu setup koneksi tidak ikut terukur
+type Preconnector interface {
+    Preconnect(ctx context.Context, n int) error
+}
+
+// ConnReporter diimplementasikan Requester yang melacak statistik koneksi
+type ConnReporter interface {
+    ConnReport() *ConnReport
+}
+
+// ConnCounter diimplementasikan Requester yang menghitung koneksi yang
+// sedang terbuka, dipakai -max-open-conns. Hitungan per Requester sehingga
+// beberapa Run bersamaan tidak saling membatasi.
+type ConnCounter interface {
+    OpenConns() int64
+}
+
+// requesterFactory membuat Requester dari config
+type requesterFactory func(config *Config) (Requester, error)
+
+// requesterFactories memetakan URL scheme ke factory Requester-nya
+var requesterFactories = map[string]requesterFactory{
+    "http":  newHTTPRequester,
+    "https": newHTTPRequester,
+}
+
+// newRequester membuat Requester untuk config, dibungkus courtesyRequester
+// jika -courtesy aktif
+func newRequester(config *Config) (Requester, error) {
+    requester, err := newTargetRequester(config)
+    if err != nil || !config.Courtesy {
+        return requester, err
+    }
+    return newCourtesyRequester(requester, config.CourtesyRPS, config.out), nil
+}
+
+// newTargetRequester memilih Requester sesuai skenario, mode tunnel atau
+// URL scheme
+func newTargetRequester(config *Config) (Requester, error) {
+    if config.Scenario != "" {
+        return newScenarioRequester(config)
+    }
+    if config.TunnelBench {
+        return newTunnelRequester(config)
+    }
+    if config.PageLoad {
+        return newPageRequester(config)
+    }
+
+    u, err := url.Parse(config.URL)
+    if err != nil {
+        return nil, fmt.Errorf("URL tidak valid: %w", err)
+    }
+
+    factory, ok := requesterFactories[strings.ToLower(u.Scheme)]
+    if !ok {
+        return nil, fmt.Errorf("protokol %q tidak didukung", u.Scheme)
+    }
+    return factory(config)
+}
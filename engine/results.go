@@ -0,0 +1,225 @@
+package engine
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Accessor bertipe untuk program yang membaca Report (hasil Run atau
+// loadReport). Field JSON tetap dalam milidetik agar skema tidak berubah.
+
+// Latencies ringkasan latency request
+type Latencies struct {
+    Min  time.Duration
+    Mean time.Duration
+    P50  time.Duration
+    P90  time.Duration
+    P95  time.Duration
+    P99  time.Duration
+    Max  time.Duration
+}
+
+// ErrorClasses jumlah error per kelas. Failed tidak punya status code,
+// sehingga ketiganya tidak saling tumpang tindih.
+type ErrorClasses struct {
+    Failed int64 // Request gagal tanpa response yang valid: koneksi, timeout atau assertion
+    Client int64 // Status 4xx
+    Server int64 // Status 5xx
+}
+
+// Total semua error, sama dengan dasar ErrorRate
+func (e ErrorClasses) Total() int64 {
+    return e.Failed + e.Client + e.Server
+}
+
+// Latencies latency request sebagai time.Duration
+func (r *Report) Latencies() Latencies {
+    return Latencies{
+        Min:  msDuration(r.MinLatencyMs),
+        Mean: msDuration(r.AvgLatencyMs),
+        P50:  msDuration(r.P50LatencyMs),
+        P90:  msDuration(r.P90LatencyMs),
+        P95:  msDuration(r.P95LatencyMs),
+        P99:  msDuration(r.P99LatencyMs),
+        Max:  msDuration(r.MaxLatencyMs),
+    }
+}
+
+// Throughput request selesai per detik selama test
+func (r *Report) Throughput() float64 {
+    return r.RPS
+}
+
+// Errors jumlah error per kelas
+func (r *Report) Errors() ErrorClasses {
+    e := ErrorClasses{Failed: r.Failed}
+    for code, count := range r.StatusCodes {
+        switch {
+        case code >= 500:
+            e.Server += count
+        case code >= 400:
+            e.Client += count
+        }
+    }
+    return e
+}
+
+func msDuration(ms float64) time.Duration {
+    return time.Duration(ms * float64(time.Millisecond))
+}
+
+// YAML report dalam YAML dengan field dan urutan yang sama seperti JSON,
+// termasuk schema_version
+func (r *Report) YAML() ([]byte, error) {
+    data, err := json.Marshal(r)
+    if err != nil {
+        return nil, err
+    }
+    return jsonToYAML(data)
+}
+
+// yamlNode nilai JSON dengan urutan key objek dipertahankan
+type yamlNode struct {
+    kind   json.Delim // '{', '[' atau 0 untuk scalar
+    keys   []string
+    items  []*yamlNode
+    scalar string // Sudah dalam bentuk YAML
+}
+
+// jsonToYAML mengubah dokumen JSON menjadi YAML block style. String ditulis
+// double-quoted (escape JSON juga valid di YAML), angka, bool dan null apa
+// adanya.
+func jsonToYAML(data []byte) ([]byte, error) {
+    dec := json.NewDecoder(bytes.NewReader(data))
+    dec.UseNumber()
+    root, err := readYAMLNode(dec)
+    if err != nil {
+        return nil, err
+    }
+    var buf bytes.Buffer
+    writeYAMLNode(&buf, root, 0)
+    return buf.Bytes(), nil
+}
+
+func readYAMLNode(dec *json.Decoder) (*yamlNode, error) {
+    tok, err := dec.Token()
+    if err != nil {
+        return nil, err
+    }
+    switch v := tok.(type) {
+    case json.Delim:
+        node := &yamlNode{kind: v}
+        for dec.More() {
+            if v == '{' {
+                key, err := dec.Token()
+                if err != nil {
+                    return nil, err
+                }
+                node.keys = append(node.keys, key.(string))
+            }
+            item, err := readYAMLNode(dec)
+            if err != nil {
+                return nil, err
+            }
+            node.items = append(node.items, item)
+        }
+        if _, err := dec.Token(); err != nil {
+            return nil, err
+        }
+        return node, nil
+    case string:
+        return &yamlNode{scalar: strconv.Quote(v)}, nil
+    case json.Number:
+        return &yamlNode{scalar: yamlNumber(v.String())}, nil
+    case bool:
+        return &yamlNode{scalar: strconv.FormatBool(v)}, nil
+    case nil:
+        return &yamlNode{scalar: "null"}, nil
+    }
+    return nil, fmt.Errorf("token JSON %v tidak dikenal", tok)
+}
+
+// yamlNumber menulis eksponen dengan titik dan tanda (1e-07 menjadi
+// 1.0e-07) karena parser YAML 1.1 membaca 1e-07 sebagai string
+func yamlNumber(n string) string {
+    i := strings.IndexAny(n, "eE")
+    if i < 0 {
+        return n
+    }
+    mantissa, exp := n[:i], n[i+1:]
+    if !strings.Contains(mantissa, ".") {
+        mantissa += ".0"
+    }
+    if exp != "" && exp[0] != '-' && exp[0] != '+' {
+        exp = "+" + exp
+    }
+    return mantissa + n[i:i+1] + exp
+}
+
+// yamlPlainKey key yang aman ditulis tanpa quote (bukan angka, bool atau null)
+var yamlPlainKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+func yamlKey(key string) string {
+    switch strings.ToLower(key) {
+    case "true", "false", "null", "yes", "no", "on", "off", "y", "n":
+        return strconv.Quote(key)
+    }
+    if yamlPlainKey.MatchString(key) {
+        return key
+    }
+    return strconv.Quote(key)
+}
+
+// inline true untuk nilai yang ditulis di baris yang sama dengan key atau "-"
+func (n *yamlNode) inline() bool {
+    return n.kind == 0 || len(n.items) == 0
+}
+
+func (n *yamlNode) inlineValue() string {
+    switch {
+    case n.kind == '{':
+        return "{}"
+    case n.kind == '[':
+        return "[]"
+    }
+    return n.scalar
+}
+
+func writeYAMLNode(w io.Writer, n *yamlNode, indent int) {
+    pad := strings.Repeat("  ", indent)
+    if n.inline() {
+        fmt.Fprintf(w, "%s%s\n", pad, n.inlineValue())
+        return
+    }
+    for i, item := range n.items {
+        prefix := pad + "- "
+        if n.kind == '{' {
+            prefix = pad + yamlKey(n.keys[i]) + ":"
+            if item.inline() {
+                fmt.Fprintf(w, "%s %s\n", prefix, item.inlineValue())
+            } else {
+                fmt.Fprintf(w, "%s\n", prefix)
+                writeYAMLNode(w, item, indent+1)
+            }
+            continue
+        }
+        switch {
+        case item.inline():
+            fmt.Fprintf(w, "%s%s\n", prefix, item.inlineValue())
+        case item.kind == '{':
+            // Key pertama objek di baris "-", sisanya sejajar dengannya
+            var b bytes.Buffer
+            writeYAMLNode(&b, item, indent+1)
+            fmt.Fprintf(w, "%s%s", prefix, strings.TrimPrefix(b.String(), pad+"  "))
+        default:
+            fmt.Fprintf(w, "%s\n", strings.TrimRight(prefix, " "))
+            writeYAMLNode(w, item, indent+1)
+        }
+    }
+}
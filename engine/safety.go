@@ -0,0 +1,227 @@
+package engine
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net"
+    "net/url"
+    "os"
+    "path"
+    "strings"
+    "sync"
+    "time"
+)
+
+// nonProductionLabels label DNS yang menandakan environment non-production
+var nonProductionLabels = map[string]bool{
+    "dev": true, "develop": true, "development": true, "test": true, "testing": true,
+    "stg": true, "stage": true, "staging": true, "qa": true, "uat": true, "sandbox": true,
+    "perf": true, "loadtest": true, "preprod": true, "local": true, "internal": true,
+}
+
+// reservedSuffixes domain yang dicadangkan untuk test/dokumentasi (RFC 2606,
+// RFC 6761) atau hanya berlaku di jaringan lokal
+var reservedSuffixes = []string{
+    ".test", ".example", ".invalid", ".localhost", ".local", ".internal", ".lan", ".home.arpa",
+    "example.com", "example.net", "example.org",
+}
+
+// checkTargetSafety memastikan test hanya diarahkan ke host yang memang
+// dimaksud. Dengan -allow-hosts semua host target harus cocok dengan daftar
+// itu. Tanpa -allow-hosts, host yang terlihat seperti production (domain
+// publik tanpa penanda non-production dan resolve ke IP publik) ditolak
+// kecuali -i-know-what-im-doing.
+func checkTargetSafety(config *Config) error {
+    hosts, err := jobHosts(config)
+    if err != nil {
+        return err
+    }
+    if config.AllowHosts != "" {
+        patterns := strings.Split(config.AllowHosts, ",")
+        for _, host := range hosts {
+            if !hostAllowed(patterns, host) {
+                return fmt.Errorf("target %s tidak ada di -allow-hosts", host)
+            }
+        }
+        return nil
+    }
+    if config.IKnowWhatImDoing {
+        return nil
+    }
+    for _, host := range hosts {
+        if !safeHost(host) {
+            return fmt.Errorf("target %s terlihat seperti production atau situs eksternal; tambahkan ke -allow-hosts atau pakai -i-know-what-im-doing jika memang disengaja", host)
+        }
+    }
+    return nil
+}
+
+// safeHost true untuk host lokal/privat, domain yang dicadangkan, host
+// dengan label non-production (contoh api.staging.example.id) atau host
+// yang semua alamatnya privat
+func safeHost(host string) bool {
+    name, _, err := net.SplitHostPort(host)
+    if err != nil {
+        name = host
+    }
+    name = strings.ToLower(strings.TrimSuffix(strings.Trim(name, "[]"), "."))
+    if name == "localhost" {
+        return true
+    }
+    if ip := net.ParseIP(name); ip != nil {
+        return privateIP(ip)
+    }
+    for _, suffix := range reservedSuffixes {
+        if name == strings.TrimPrefix(suffix, ".") || strings.HasSuffix(name, suffix) {
+            return true
+        }
+    }
+    for _, label := range strings.FieldsFunc(name, func(r rune) bool { return r == '.' || r == '-' }) {
+        if nonProductionLabels[label] {
+            return true
+        }
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+    defer cancel()
+    addrs, err := net.DefaultResolver.LookupIPAddr(ctx, name)
+    if err != nil || len(addrs) == 0 {
+        // Host yang tidak bisa di-resolve tidak akan menerima beban
+        return true
+    }
+    for _, addr := range addrs {
+        if !privateIP(addr.IP) {
+            return false
+        }
+    }
+    return true
+}
+
+func privateIP(ip net.IP) bool {
+    return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified()
+}
+
+// jobHosts semua host yang akan ditarget job: URL utama, semua URL target,
+// -shadow, -from-sitemap dan URL absolut di skenario. Host berisi template
+// ditolak karena tidak bisa diperiksa. Host yang baru diketahui saat test
+// berjalan diperiksa hostGuard.
+func jobHosts(config *Config) ([]string, error) {
+    var hosts []string
+    add := func(raw string) error {
+        u, err := url.Parse(raw)
+        if err != nil {
+            return fmt.Errorf("URL %q tidak valid: %w", raw, err)
+        }
+        if strings.Contains(u.Host, "{{") {
+            return fmt.Errorf("host berisi template (%s) tidak bisa diperiksa allowlist", raw)
+        }
+        hosts = append(hosts, u.Host)
+        return nil
+    }
+
+    if config.URL != "" {
+        if err := add(config.URL); err != nil {
+            return nil, err
+        }
+    }
+    for _, target := range config.Targets {
+        if err := add(target); err != nil {
+            return nil, err
+        }
+    }
+    for _, extra := range []string{config.Shadow, config.Sitemap} {
+        if extra == "" {
+            continue
+        }
+        if err := add(extra); err != nil {
+            return nil, err
+        }
+    }
+    if config.Scenario != "" {
+        data, err := os.ReadFile(config.Scenario)
+        if err != nil {
+            return nil, err
+        }
+        var sc Scenario
+        if err := json.Unmarshal(data, &sc); err != nil {
+            return nil, fmt.Errorf("%s bukan skenario JSON yang valid: %w", config.Scenario, err)
+        }
+        for _, block := range [][]Step{sc.Setup, sc.VUSetup, sc.Steps, sc.VUTeardown, sc.Teardown} {
+            for _, step := range block {
+                if strings.HasPrefix(step.URL, "{{") {
+                    return nil, fmt.Errorf("URL step %q berisi template dan tidak bisa diperiksa allowlist", step.URL)
+                }
+                if strings.Contains(step.URL, "://") {
+                    if err := add(step.URL); err != nil {
+                        return nil, err
+                    }
+                }
+            }
+        }
+    }
+    return hosts, nil
+}
+
+// hostGuard memeriksa host yang baru diketahui saat test berjalan (aset
+// -page-external, sitemap dari sitemap index atau robots.txt) dengan aturan
+// yang sama seperti checkTargetSafety. Host yang ditolak dilewati dengan
+// warning sekali per host.
+type hostGuard struct {
+    patterns []string // -allow-hosts, kosong jika tidak diatur
+    unsafeOK bool     // -i-know-what-im-doing tanpa -allow-hosts
+    checked  sync.Map // host -> bool
+    out      io.Writer
+}
+
+func newHostGuard(config *Config) *hostGuard {
+    g := &hostGuard{unsafeOK: config.IKnowWhatImDoing, out: config.out}
+    if config.AllowHosts != "" {
+        g.patterns = strings.Split(config.AllowHosts, ",")
+    }
+    return g
+}
+
+func (g *hostGuard) allowed(host string) bool {
+    if ok, found := g.checked.Load(host); found {
+        return ok.(bool)
+    }
+    var ok bool
+    switch {
+    case len(g.patterns) > 0:
+        ok = hostAllowed(g.patterns, host)
+    case g.unsafeOK:
+        ok = true
+    default:
+        ok = safeHost(host)
+    }
+    if _, loaded := g.checked.LoadOrStore(host, ok); !loaded && !ok {
+        fmt.Fprintf(g.out, "⚠️  Host %s dilewati: tidak ada di -allow-hosts atau terlihat seperti production\n", host)
+    }
+    return ok
+}
+
+// hostAllowed mencocokkan host (boleh dengan port) dengan daftar pola.
+// Pola tanpa port berlaku untuk semua port; "*" cocok dengan semua host.
+func hostAllowed(patterns []string, host string) bool {
+    name, port, err := net.SplitHostPort(host)
+    if err != nil {
+        name, port = host, ""
+    }
+    name = strings.ToLower(strings.Trim(name, "[]"))
+    for _, pattern := range patterns {
+        pattern = strings.ToLower(strings.TrimSpace(pattern))
+        patternName, patternPort, err := net.SplitHostPort(pattern)
+        if err != nil {
+            patternName, patternPort = pattern, ""
+        }
+        if ok, _ := path.Match(patternPort, port); patternPort != "" && !ok {
+            continue
+        }
+        if ok, _ := path.Match(strings.Trim(patternName, "[]"), name); ok {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,463 @@
+package engine
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "math"
+    "net/http"
+    "os"
+    "regexp"
+    "slices"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Scenario alur request dari file JSON (-scenario). Steps adalah satu
+// iterasi yang diukur; setup/teardown tidak ikut diukur dan berjalan sekali
+// untuk seluruh run (Setup, Teardown) atau sekali per virtual user/worker
+// (VUSetup, VUTeardown), contoh login sebelum loop dan hapus data setelahnya.
+type Scenario struct {
+    Setup      []Step `json:"setup"`
+    VUSetup    []Step `json:"vu_setup"`
+    Steps      []Step `json:"steps"`
+    VUTeardown []Step `json:"vu_teardown"`
+    Teardown   []Step `json:"teardown"`
+}
+
+// Step satu request dalam skenario. URL, header dan body boleh berisi
+// template; variabel hasil extract tersedia sebagai {{.Vars.nama}}.
+type Step struct {
+    Name    string            `json:"name"`
+    Method  string            `json:"method"`
+    URL     string            `json:"url"`
+    Headers map[string]string `json:"headers"`
+    Body    string            `json:"body"`
+    // Extract menyimpan nilai dari response ke variabel: "json:data.token",
+    // "header:X-Request-Id" atau "regex:id=(\\d+)"
+    Extract map[string]string `json:"extract"`
+    // Metrics mencatat metrik custom setelah step berhasil, format
+    // "jenis:nilai" dengan jenis counter, gauge atau trend, contoh
+    // {"items_per_search": "trend:{{.Vars.count}}"}
+    Metrics map[string]string `json:"metrics"`
+
+    // If kondisi sebelum step dikirim, step dilewati jika false. Until
+    // kondisi setelah step, step diulang (polling) sampai true. Kondisi
+    // adalah template yang menghasilkan true/false, contoh
+    // 'eq .Vars.status "ready"' atau 'eq .Status 202'.
+    If    string `json:"if"`
+    Until string `json:"until"`
+    Poll  Poll   `json:"poll"`
+
+    // Consistency kondisi read-after-write: step dibaca ulang sesuai Poll
+    // sampai kondisi true, seperti Until. Baca pertama yang belum memenuhi
+    // kondisi dihitung basi (stale) dan lag sampai hasil tulis (step
+    // poll.from) terlihat dilaporkan, contoh 'eq .Vars.saved .Vars.value'.
+    Consistency string `json:"consistency"`
+
+    // Timeout batas waktu step ini, menggantikan -t (contoh "2s", "5m")
+    Timeout string `json:"timeout"`
+    Retry   Retry  `json:"retry"`
+
+    // Parallel nama grup. Step berurutan dengan grup yang sama dikirim
+    // bersamaan oleh VU, seperti browser mengambil aset halaman. Durasi
+    // gabungan grup (awal step pertama sampai step terakhir selesai)
+    // dicatat sebagai fase dengan nama grup.
+    Parallel string `json:"parallel"`
+}
+
+// Retry kebijakan pengulangan step yang gagal karena error jaringan,
+// timeout atau status tertentu
+type Retry struct {
+    Max     int    `json:"max"`     // Jumlah pengulangan maksimal, 0 = tanpa retry
+    Backoff string `json:"backoff"` // Jeda sebelum retry pertama, berlipat dua setiap retry (default 100ms)
+    On      []int  `json:"on"`      // Status yang di-retry (default 502, 503, 504)
+}
+
+// Poll jadwal pengulangan step dengan Until
+type Poll struct {
+    MaxAttempts int     `json:"max_attempts"` // Default 10
+    Interval    string  `json:"interval"`     // Jeda awal antar percobaan, default 1s
+    Backoff     float64 `json:"backoff"`      // Pengali jeda setiap percobaan, default 1
+    MaxInterval string  `json:"max_interval"` // Batas jeda setelah backoff
+    // From nama step submit untuk latency workflow, default step sebelumnya
+    From string `json:"from"`
+}
+
+// compiledStep Step yang template dan extractor-nya sudah di-parse
+type compiledStep struct {
+    name    string
+    method  string
+    url     *valueTemplate
+    headers map[string]*valueTemplate
+    body    *valueTemplate
+    extract map[string]extractor
+    metrics map[string]compiledMetric
+
+    cond, until *valueTemplate // nil jika tidak diisi
+    consistency *valueTemplate // nil jika bukan step read-after-write
+    poll        compiledPoll
+    timeout     time.Duration // 0 berarti pakai -t
+    retry       compiledRetry
+    parallel    string // Grup paralel, kosong jika step dijalankan berurutan
+}
+
+type compiledRetry struct {
+    max     int
+    backoff time.Duration
+    on      map[int]bool
+}
+
+// retryable true jika hasil percobaan layak diulang. Request yang dibatalkan
+// karena test selesai tidak diulang.
+func (r compiledRetry) retryable(ctx context.Context, result Result) bool {
+    if ctx.Err() != nil {
+        return false
+    }
+    if result.Err != nil {
+        return result.StatusCode == 0
+    }
+    return r.on[result.StatusCode]
+}
+
+type compiledPoll struct {
+    from        string
+    maxAttempts int
+    interval    time.Duration
+    backoff     float64
+    maxInterval time.Duration
+}
+
+// delay jeda sebelum percobaan ke-attempt berikutnya (attempt mulai dari 1)
+func (p compiledPoll) delay(attempt int) time.Duration {
+    d := time.Duration(float64(p.interval) * math.Pow(p.backoff, float64(attempt-1)))
+    if p.maxInterval > 0 && d > p.maxInterval {
+        return p.maxInterval
+    }
+    return d
+}
+
+// compiledScenario Scenario yang siap dieksekusi
+type compiledScenario struct {
+    setup, vuSetup, steps, vuTeardown, teardown []compiledStep
+}
+
+func loadScenario(path string) (*compiledScenario, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var sc Scenario
+    if err := json.Unmarshal(data, &sc); err != nil {
+        return nil, fmt.Errorf("%s bukan skenario JSON yang valid: %w", path, err)
+    }
+    if len(sc.Steps) == 0 {
+        return nil, fmt.Errorf("%s: skenario tidak memiliki steps", path)
+    }
+
+    compiled := &compiledScenario{}
+    metricKinds := make(map[string]string)
+    for _, block := range []struct {
+        name  string
+        steps []Step
+        dest  *[]compiledStep
+    }{
+        {"setup", sc.Setup, &compiled.setup},
+        {"vu_setup", sc.VUSetup, &compiled.vuSetup},
+        {"steps", sc.Steps, &compiled.steps},
+        {"vu_teardown", sc.VUTeardown, &compiled.vuTeardown},
+        {"teardown", sc.Teardown, &compiled.teardown},
+    } {
+        seen := make(map[string]bool)
+        for i, step := range block.steps {
+            cs, err := compileStep(step, fmt.Sprintf("%s-%d", block.name, i+1))
+            if err != nil {
+                return nil, fmt.Errorf("%s: %s[%d]: %w", path, block.name, i, err)
+            }
+            if seen[cs.name] {
+                return nil, fmt.Errorf("%s: %s: nama step %q dipakai lebih dari sekali", path, block.name, cs.name)
+            }
+            if cs.until != nil || cs.consistency != nil {
+                if cs.poll.from == "" && i > 0 {
+                    cs.poll.from = (*block.dest)[i-1].name
+                }
+                if cs.poll.from != "" && !seen[cs.poll.from] {
+                    return nil, fmt.Errorf("%s: %s[%d]: poll.from %q harus nama step sebelumnya", path, block.name, i, cs.poll.from)
+                }
+            }
+            seen[cs.name] = true
+            if err := checkParallel(block.name, *block.dest, cs); err != nil {
+                return nil, fmt.Errorf("%s: %s[%d]: %w", path, block.name, i, err)
+            }
+            for name, metric := range cs.metrics {
+                if kind, ok := metricKinds[name]; ok && kind != metric.kind {
+                    return nil, fmt.Errorf("%s: metrik %q dipakai sebagai %s dan %s", path, name, kind, metric.kind)
+                }
+                metricKinds[name] = metric.kind
+            }
+            *block.dest = append(*block.dest, cs)
+        }
+    }
+    for _, step := range compiled.steps {
+        if step.parallel != "" && slices.ContainsFunc(compiled.steps, func(s compiledStep) bool { return s.name == step.parallel }) {
+            return nil, fmt.Errorf("%s: grup parallel %q sama dengan nama step", path, step.parallel)
+        }
+    }
+    return compiled, nil
+}
+
+// checkParallel memeriksa step dengan grup parallel. Step dalam grup berjalan
+// bersamaan, jadi tidak boleh mengubah variabel VU (extract) atau polling,
+// dan anggota grup harus berurutan.
+func checkParallel(block string, previous []compiledStep, cs compiledStep) error {
+    if cs.parallel == "" {
+        return nil
+    }
+    switch {
+    case block != "steps":
+        return fmt.Errorf("parallel hanya untuk steps")
+    case len(cs.extract) > 0:
+        return fmt.Errorf("step parallel tidak boleh memakai extract")
+    case cs.until != nil, cs.consistency != nil:
+        return fmt.Errorf("step parallel tidak boleh memakai until atau consistency")
+    }
+    if n := len(previous); n > 0 && previous[n-1].parallel == cs.parallel {
+        return nil
+    }
+    if slices.ContainsFunc(previous, func(s compiledStep) bool { return s.parallel == cs.parallel }) {
+        return fmt.Errorf("step grup parallel %q harus berurutan", cs.parallel)
+    }
+    return nil
+}
+
+func compileStep(step Step, defaultName string) (compiledStep, error) {
+    cs := compiledStep{
+        name:     step.Name,
+        method:   strings.ToUpper(step.Method),
+        parallel: step.Parallel,
+        headers:  make(map[string]*valueTemplate),
+        extract:  make(map[string]extractor),
+        metrics:  make(map[string]compiledMetric),
+    }
+    if cs.name == "" {
+        cs.name = defaultName
+    }
+    if cs.method == "" {
+        cs.method = http.MethodGet
+    }
+    if step.URL == "" {
+        return cs, fmt.Errorf("url harus diisi")
+    }
+
+    var err error
+    if cs.url, err = parseValueTemplate(cs.name+" url", step.URL); err != nil {
+        return cs, err
+    }
+    if cs.body, err = parseValueTemplate(cs.name+" body", step.Body); err != nil {
+        return cs, err
+    }
+    for name, value := range step.Headers {
+        if cs.headers[name], err = parseValueTemplate(cs.name+" header "+name, value); err != nil {
+            return cs, err
+        }
+    }
+    for name, spec := range step.Extract {
+        if cs.extract[name], err = parseExtractor(spec); err != nil {
+            return cs, fmt.Errorf("extract %s: %w", name, err)
+        }
+    }
+    for name, spec := range step.Metrics {
+        if cs.metrics[name], err = parseMetric(name, spec); err != nil {
+            return cs, err
+        }
+    }
+    if step.Timeout != "" {
+        if cs.timeout, err = time.ParseDuration(step.Timeout); err != nil || cs.timeout <= 0 {
+            return cs, fmt.Errorf("timeout %q tidak valid", step.Timeout)
+        }
+    }
+    if cs.retry, err = compileRetry(step.Retry); err != nil {
+        return cs, err
+    }
+    if step.If != "" {
+        if cs.cond, err = parseCondition(cs.name+" if", step.If); err != nil {
+            return cs, err
+        }
+    }
+    if step.Until != "" {
+        if cs.until, err = parseCondition(cs.name+" until", step.Until); err != nil {
+            return cs, err
+        }
+        if cs.poll, err = compilePoll(step.Poll); err != nil {
+            return cs, err
+        }
+    }
+    if step.Consistency != "" {
+        if cs.until != nil {
+            return cs, fmt.Errorf("until dan consistency tidak bisa dipakai bersamaan")
+        }
+        if cs.consistency, err = parseCondition(cs.name+" consistency", step.Consistency); err != nil {
+            return cs, err
+        }
+        // Lag replikasi biasanya milidetik, jadi default polling lebih rapat
+        poll := step.Poll
+        if poll.Interval == "" {
+            poll.Interval = "50ms"
+        }
+        if poll.MaxAttempts == 0 {
+            poll.MaxAttempts = 100
+        }
+        if cs.poll, err = compilePoll(poll); err != nil {
+            return cs, err
+        }
+    }
+    return cs, nil
+}
+
+func compileRetry(r Retry) (compiledRetry, error) {
+    cr := compiledRetry{max: r.Max, backoff: 100 * time.Millisecond, on: make(map[int]bool)}
+    if r.Max < 0 {
+        return cr, fmt.Errorf("retry: max tidak boleh negatif")
+    }
+    if r.Backoff != "" {
+        var err error
+        if cr.backoff, err = time.ParseDuration(r.Backoff); err != nil || cr.backoff < 0 {
+            return cr, fmt.Errorf("retry: backoff %q tidak valid", r.Backoff)
+        }
+    }
+    on := r.On
+    if len(on) == 0 {
+        on = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+    }
+    for _, code := range on {
+        cr.on[code] = true
+    }
+    return cr, nil
+}
+
+func compilePoll(p Poll) (compiledPoll, error) {
+    cp := compiledPoll{from: p.From, maxAttempts: p.MaxAttempts, interval: time.Second, backoff: p.Backoff}
+    if cp.maxAttempts == 0 {
+        cp.maxAttempts = 10
+    }
+    if cp.backoff == 0 {
+        cp.backoff = 1
+    }
+    if cp.maxAttempts < 1 || cp.backoff < 1 {
+        return cp, fmt.Errorf("poll: max_attempts dan backoff minimal 1")
+    }
+    var err error
+    if p.Interval != "" {
+        if cp.interval, err = time.ParseDuration(p.Interval); err != nil {
+            return cp, fmt.Errorf("poll: interval %q tidak valid", p.Interval)
+        }
+    }
+    if p.MaxInterval != "" {
+        if cp.maxInterval, err = time.ParseDuration(p.MaxInterval); err != nil {
+            return cp, fmt.Errorf("poll: max_interval %q tidak valid", p.MaxInterval)
+        }
+    }
+    return cp, nil
+}
+
+// parseCondition menerima kondisi dengan atau tanpa {{ }}
+func parseCondition(name, raw string) (*valueTemplate, error) {
+    if !strings.Contains(raw, "{{") {
+        raw = "{{" + raw + "}}"
+    }
+    return parseValueTemplate(name, raw)
+}
+
+// evalCondition me-render kondisi yang harus menghasilkan true atau false
+func evalCondition(cond *valueTemplate, data templateData) (bool, error) {
+    out, err := cond.render(data)
+    if err != nil {
+        return false, err
+    }
+    ok, err := strconv.ParseBool(strings.TrimSpace(out))
+    if err != nil {
+        return false, fmt.Errorf("kondisi %q menghasilkan %q, bukan true/false", cond.raw, out)
+    }
+    return ok, nil
+}
+
+// extractor mengambil satu nilai dari response
+type extractor func(resp *http.Response, body []byte) (string, error)
+
+func parseExtractor(spec string) (extractor, error) {
+    kind, arg, found := strings.Cut(spec, ":")
+    if !found || arg == "" {
+        return nil, fmt.Errorf("extract %q tidak valid (json:path, header:Nama atau regex:pola)", spec)
+    }
+    switch kind {
+    case "header":
+        return func(resp *http.Response, body []byte) (string, error) {
+            value := resp.Header.Get(arg)
+            if value == "" {
+                return "", fmt.Errorf("header %s tidak ada", arg)
+            }
+            return value, nil
+        }, nil
+    case "json":
+        return func(resp *http.Response, body []byte) (string, error) {
+            var doc any
+            if err := json.Unmarshal(body, &doc); err != nil {
+                return "", fmt.Errorf("body bukan JSON: %w", err)
+            }
+            return jsonPath(doc, arg)
+        }, nil
+    case "regex":
+        re, err := regexp.Compile(arg)
+        if err != nil {
+            return nil, fmt.Errorf("regex %q tidak valid: %w", arg, err)
+        }
+        return func(resp *http.Response, body []byte) (string, error) {
+            m := re.FindSubmatch(body)
+            switch {
+            case m == nil:
+                return "", fmt.Errorf("regex %s tidak cocok", arg)
+            case len(m) > 1:
+                return string(m[1]), nil
+            default:
+                return string(m[0]), nil
+            }
+        }, nil
+    default:
+        return nil, fmt.Errorf("jenis extract %q tidak dikenal (json, header, regex)", kind)
+    }
+}
+
+// jsonPath mengambil nilai dengan path titik, contoh "data.items.0.id".
+// Segmen "#" menghasilkan panjang array, contoh "data.items.#". Nilai
+// non-string dikembalikan dalam bentuk JSON-nya.
+func jsonPath(doc any, path string) (string, error) {
+    current := doc
+    for _, key := range strings.Split(path, ".") {
+        if v, ok := current.([]any); ok && key == "#" {
+            current = float64(len(v))
+            continue
+        }
+        switch v := current.(type) {
+        case map[string]any:
+            next, ok := v[key]
+            if !ok {
+                return "", fmt.Errorf("field %q tidak ada di %s", key, path)
+            }
+            current = next
+        case []any:
+            i, err := strconv.Atoi(key)
+            if err != nil || i < 0 || i >= len(v) {
+                return "", fmt.Errorf("index %q tidak valid di %s", key, path)
+            }
+            current = v[i]
+        default:
+            return "", fmt.Errorf("%s: %q bukan object atau array", path, key)
+        }
+    }
+    if s, ok := current.(string); ok {
+        return s, nil
+    }
+    data, err := json.Marshal(current)
+    return string(data), err
+}
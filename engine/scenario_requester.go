@@ -0,0 +1,594 @@
+package engine
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "net/http/cookiejar"
+    "net/http/httptrace"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+)
+
+// scenarioRequester menjalankan satu iterasi skenario per job. Setiap worker
+// adalah satu virtual user (VU) dengan cookie jar dan variabel sendiri.
+// Durasi tiap step dicatat sebagai fase dengan nama step.
+type scenarioRequester struct {
+    config    *Config
+    scenario  *compiledScenario
+    transport *http.Transport
+    pool      *transportPool
+    conns     *connTracker
+    audit     *headerAudit
+    shadow    *shadowMirror    // Opsional, mode -shadow
+    limits    *urlLimiter      // Opsional, -url-limit
+    deadline  *deadlineStamper // Opsional, -deadline-header
+    base      *url.URL         // Untuk URL step yang relatif, nil jika -u kosong
+    globals   map[string]string
+
+    vus sync.Map // int -> *vuState
+}
+
+// vuState state satu virtual user, hanya dipakai oleh satu worker
+type vuState struct {
+    id     int
+    client *http.Client
+    vars   map[string]string
+    status int  // Status code step terakhir, untuk kondisi if/until
+    ready  bool // vu_setup sudah berhasil
+}
+
+func newScenarioRequester(config *Config) (Requester, error) {
+    scenario, err := loadScenario(config.Scenario)
+    if err != nil {
+        return nil, err
+    }
+
+    client := createHTTPClient(config)
+    s := &scenarioRequester{
+        config:    config,
+        scenario:  scenario,
+        transport: client.Transport.(*http.Transport),
+        conns:     newConnTracker(config.out),
+        audit:     newHeaderAudit(),
+        globals:   make(map[string]string),
+    }
+    s.transport.DialContext = s.conns.DialContext
+    if s.conns.dns, err = newDNSResolver(config); err != nil {
+        return nil, err
+    }
+    if s.shadow, err = newShadowMirror(config); err != nil {
+        return nil, err
+    }
+    if s.limits, err = newURLLimiter(config.URLLimits); err != nil {
+        return nil, err
+    }
+    if s.deadline, err = newDeadlineStamper(config); err != nil {
+        return nil, err
+    }
+    s.pool = newTransportPool(s.transport, config.ConnPool)
+    if config.URL != "" {
+        if s.base, err = url.Parse(config.URL); err != nil {
+            return nil, fmt.Errorf("URL tidak valid: %w", err)
+        }
+    }
+
+    if len(scenario.setup) > 0 {
+        fmt.Fprintln(config.out, "🔧 Menjalankan setup skenario...")
+        vu := s.newVU(-1)
+        if err := s.runSteps(context.Background(), vu, scenario.setup, 0); err != nil {
+            return nil, fmt.Errorf("setup skenario gagal: %w", err)
+        }
+        s.globals = vu.vars
+    }
+    return s, nil
+}
+
+func (s *scenarioRequester) newVU(id int) *vuState {
+    jar, _ := cookiejar.New(nil)
+    vu := &vuState{
+        id: id,
+        client: &http.Client{
+            Transport: s.pool.get(id),
+            Timeout:   time.Duration(s.config.Timeout) * time.Second,
+            Jar:       jar,
+        },
+        vars: make(map[string]string, len(s.globals)),
+    }
+    for name, value := range s.globals {
+        vu.vars[name] = value
+    }
+    return vu
+}
+
+func (s *scenarioRequester) vu(id int) *vuState {
+    if vu, ok := s.vus.Load(id); ok {
+        return vu.(*vuState)
+    }
+    vu, _ := s.vus.LoadOrStore(id, s.newVU(id))
+    return vu.(*vuState)
+}
+
+func (s *scenarioRequester) Do(ctx context.Context, requestNum int) Result {
+    vu := s.vu(workerID(ctx))
+    if !vu.ready {
+        if err := s.runSteps(ctx, vu, s.scenario.vuSetup, requestNum); err != nil {
+            return Result{Start: time.Now(), Err: fmt.Errorf("vu_setup: %w", err)}
+        }
+        vu.ready = true
+    }
+
+    vu.status = 0
+    result := Result{Phases: make(map[string]time.Duration, len(s.scenario.steps))}
+    started := make(map[string]time.Time, len(s.scenario.steps))
+    finished := make(map[string]time.Time, len(s.scenario.steps))
+    // add menggabungkan hasil satu step ke hasil iterasi, false jika
+    // iterasi harus berhenti
+    add := func(step compiledStep, r Result) bool {
+        started[step.name] = r.Start
+        finished[step.name] = r.Start.Add(r.Duration)
+        result.Metrics = append(result.Metrics, r.Metrics...)
+        if step.until != nil {
+            result.Workflows = append(result.Workflows, newWorkflowSample(step, started, r))
+        }
+        for _, sample := range r.Consistency {
+            if written, ok := finished[sample.from]; ok && sample.from != step.name {
+                sample.lag = sample.at.Sub(written)
+            }
+            result.Consistency = append(result.Consistency, sample)
+        }
+        if result.Start.IsZero() || r.Start.Before(result.Start) {
+            result.Start = r.Start
+        }
+        if step.parallel == "" {
+            result.Duration += r.Duration
+        }
+        result.Bytes += r.Bytes
+        result.Retries += r.Retries
+        result.StatusCode = r.StatusCode
+        result.ErrorBody = r.ErrorBody
+        result.RetryAfter = r.RetryAfter
+        result.Phases[step.name] = r.Duration
+        if r.Err != nil {
+            result.Err = fmt.Errorf("step %s: %w", step.name, r.Err)
+            return false
+        }
+        // Step berikutnya biasanya bergantung pada step ini
+        return r.StatusCode < 400
+    }
+
+    steps := s.scenario.steps
+    for i := 0; i < len(steps); i++ {
+        if steps[i].parallel == "" {
+            r, ran := s.runStep(ctx, vu, steps[i], requestNum)
+            if ran && !add(steps[i], r) {
+                break
+            }
+            continue
+        }
+
+        group := steps[i:parallelGroupEnd(steps, i)]
+        i += len(group) - 1
+        results, ran, elapsed := s.runGroup(ctx, vu, group, requestNum)
+        if elapsed == 0 {
+            continue
+        }
+        result.Duration += elapsed
+        result.Phases[group[0].parallel] = elapsed
+        failed := -1
+        for j, step := range group {
+            if ran[j] && !add(step, results[j]) && failed < 0 {
+                failed = j
+            }
+        }
+        if failed >= 0 {
+            // Semua hasil grup dicatat, yang dilaporkan step gagal pertama
+            r := results[failed]
+            result.StatusCode, result.ErrorBody, result.RetryAfter = r.StatusCode, r.ErrorBody, r.RetryAfter
+            result.Err = nil
+            if r.Err != nil {
+                result.Err = fmt.Errorf("step %s: %w", group[failed].name, r.Err)
+            }
+            vu.status = r.StatusCode
+            break
+        }
+    }
+    if result.Start.IsZero() {
+        result.Start = time.Now()
+    }
+    return result
+}
+
+// parallelGroupEnd index setelah step terakhir grup parallel yang dimulai
+// di steps[start]
+func parallelGroupEnd(steps []compiledStep, start int) int {
+    end := start + 1
+    for end < len(steps) && steps[end].parallel == steps[start].parallel {
+        end++
+    }
+    return end
+}
+
+// runGroup mengirim step grup parallel bersamaan. elapsed waktu dari awal
+// step pertama sampai step terakhir selesai (latency "halaman"), 0 jika
+// semua step dilewati kondisi if.
+func (s *scenarioRequester) runGroup(ctx context.Context, vu *vuState, group []compiledStep, requestNum int) (results []Result, ran []bool, elapsed time.Duration) {
+    results, ran = make([]Result, len(group)), make([]bool, len(group))
+    var wg sync.WaitGroup
+    for j, step := range group {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            results[j], ran[j] = s.runStep(ctx, vu, step, requestNum)
+        }()
+    }
+    wg.Wait()
+
+    var first, last time.Time
+    for j, r := range results {
+        if !ran[j] {
+            continue
+        }
+        if first.IsZero() || r.Start.Before(first) {
+            first = r.Start
+        }
+        if end := r.Start.Add(r.Duration); end.After(last) {
+            last = end
+        }
+        if r.StatusCode != 0 {
+            vu.status = r.StatusCode
+        }
+    }
+    if first.IsZero() {
+        return results, ran, 0
+    }
+    return results, ran, last.Sub(first)
+}
+
+// runSteps menjalankan step setup/teardown; status >= 400 dianggap gagal
+func (s *scenarioRequester) runSteps(ctx context.Context, vu *vuState, steps []compiledStep, requestNum int) error {
+    for _, step := range steps {
+        r, _ := s.runStep(ctx, vu, step, requestNum)
+        if r.Err != nil {
+            return fmt.Errorf("step %s: %w", step.name, r.Err)
+        }
+        if r.StatusCode >= 400 {
+            return fmt.Errorf("step %s: status %d", step.name, r.StatusCode)
+        }
+    }
+    return nil
+}
+
+// newWorkflowSample latency dari awal step submit (poll.from) sampai polling
+// selesai. Tanpa step submit, dihitung dari percobaan pertama polling.
+func newWorkflowSample(step compiledStep, started map[string]time.Time, r Result) workflowSample {
+    from, ok := started[step.poll.from]
+    if !ok {
+        from = r.Start
+    }
+    name := step.poll.from
+    if name == "" {
+        name = step.name
+    }
+    return workflowSample{
+        name:      step.name,
+        from:      name,
+        duration:  time.Since(from),
+        completed: r.Err == nil && r.StatusCode < 400,
+    }
+}
+
+// runStep menjalankan step beserta kondisi if dan polling until. ran false
+// jika step dilewati karena kondisi if. Durasi hasil polling adalah total
+// durasi semua percobaan, tanpa jeda antar percobaan.
+func (s *scenarioRequester) runStep(ctx context.Context, vu *vuState, step compiledStep, requestNum int) (result Result, ran bool) {
+    if step.cond != nil {
+        ok, err := evalCondition(step.cond, s.templateData(vu, requestNum))
+        if err != nil {
+            return Result{Start: time.Now(), Err: fmt.Errorf("if: %w", err)}, true
+        }
+        if !ok {
+            return Result{}, false
+        }
+    }
+
+    result = s.attemptStep(ctx, vu, step, requestNum)
+    check, label := step.until, "until"
+    if step.consistency != nil {
+        check, label = step.consistency, "consistency"
+    }
+    if check == nil {
+        return s.recordMetrics(vu, step, requestNum, result), true
+    }
+    reads, converged := 0, false
+    for attempt := 1; result.Err == nil && result.StatusCode < 400; attempt++ {
+        done, err := evalCondition(check, s.templateData(vu, requestNum))
+        if err != nil {
+            result.Err = fmt.Errorf("%s: %w", label, err)
+            break
+        }
+        reads = attempt
+        if done {
+            converged = true
+            break
+        }
+        if attempt == step.poll.maxAttempts {
+            result.Err = fmt.Errorf("kondisi %s tidak terpenuhi setelah %d percobaan", label, attempt)
+            break
+        }
+        if !sleepUntil(ctx, time.Now().Add(step.poll.delay(attempt))) {
+            result.Err = ctx.Err()
+            break
+        }
+        r := s.attemptStep(ctx, vu, step, requestNum)
+        result.Retries += r.Retries
+        result.Duration += r.Duration
+        result.Bytes += r.Bytes
+        result.StatusCode, result.ErrorBody, result.RetryAfter, result.Err = r.StatusCode, r.ErrorBody, r.RetryAfter, r.Err
+    }
+    // Iterasi yang gagal sebelum kondisi sempat diperiksa tidak dihitung
+    if step.consistency != nil && reads > 0 {
+        result.Consistency = []consistencySample{{name: step.name, from: step.poll.from, reads: reads, converged: converged, at: time.Now()}}
+    }
+    return s.recordMetrics(vu, step, requestNum, result), true
+}
+
+// recordMetrics menghitung metrik custom step yang berhasil
+func (s *scenarioRequester) recordMetrics(vu *vuState, step compiledStep, requestNum int, result Result) Result {
+    if len(step.metrics) == 0 || result.Err != nil || result.StatusCode >= 400 {
+        return result
+    }
+    data := s.templateData(vu, requestNum)
+    for name, metric := range step.metrics {
+        sample, err := metric.sample(name, data)
+        if err != nil {
+            result.Err = err
+            return result
+        }
+        result.Metrics = append(result.Metrics, sample)
+    }
+    return result
+}
+
+// attemptStep mengirim step dan mengulanginya sesuai kebijakan retry step.
+// Durasi adalah total durasi semua percobaan, tanpa jeda backoff.
+func (s *scenarioRequester) attemptStep(ctx context.Context, vu *vuState, step compiledStep, requestNum int) Result {
+    result := s.execStep(ctx, vu, step, requestNum)
+    backoff := step.retry.backoff
+    for result.Retries < step.retry.max && step.retry.retryable(ctx, result) {
+        if !sleepUntil(ctx, time.Now().Add(backoff)) {
+            break
+        }
+        backoff *= 2
+        r := s.execStep(ctx, vu, step, requestNum)
+        r.Start = result.Start
+        r.Duration += result.Duration
+        r.Retries = result.Retries + 1
+        result = r
+    }
+    return result
+}
+
+// execStep mengirim satu step. Durasi diukur sampai header response
+// diterima, sama seperti request biasa.
+func (s *scenarioRequester) execStep(ctx context.Context, vu *vuState, step compiledStep, requestNum int) (result Result) {
+    var reused bool
+    var local, remote net.Addr
+    trace := &httptrace.ClientTrace{
+        GotConn: func(info httptrace.GotConnInfo) {
+            reused, local, remote = info.Reused, info.Conn.LocalAddr(), info.Conn.RemoteAddr()
+        },
+    }
+    req, err := s.buildStep(httptrace.WithClientTrace(ctx, trace), vu, step, requestNum)
+    if err != nil {
+        return Result{Start: time.Now(), Err: err}
+    }
+
+    client := vu.client
+    if step.timeout > 0 {
+        c := *vu.client
+        c.Timeout = step.timeout
+        client = &c
+    }
+    release, err := s.limits.acquire(ctx, req)
+    if err != nil {
+        return Result{Start: time.Now(), Err: err}
+    }
+    defer release()
+    deadline := s.deadline.stamp(req, step.timeout)
+    pair := s.shadow.mirror(req, requestNum)
+    defer func() {
+        s.conns.recordSource(local, remote, result)
+        s.deadline.observe(deadline, result)
+        pair.observe(result)
+    }()
+
+    start := time.Now()
+    resp, err := client.Do(req)
+    duration := time.Since(start)
+    s.conns.recordRequest(reused, err)
+    if err != nil {
+        return Result{Start: start, Duration: duration, Err: err}
+    }
+    defer resp.Body.Close()
+    resp.Body = pair.captureBody(resp.Body)
+    watch := watchChallenge(resp)
+    defer func() { result = watch.check(result) }()
+    if step.parallel == "" {
+        // Status step parallel diset setelah seluruh grup selesai
+        vu.status = resp.StatusCode
+    }
+    s.audit.observe(resp)
+
+    result = Result{Start: start, Duration: duration, StatusCode: resp.StatusCode, RetryAfter: parseRetryAfter(resp)}
+    if len(step.extract) == 0 {
+        result.Bytes, result.ErrorBody = drainBody(resp.Body, resp.StatusCode)
+        return result
+    }
+
+    body, err := io.ReadAll(resp.Body)
+    result.Bytes = int64(len(body))
+    if err != nil {
+        result.Err = err
+        return result
+    }
+    if resp.StatusCode >= 400 {
+        result.ErrorBody = errorSample(body)
+        return result
+    }
+    for name, extract := range step.extract {
+        value, err := extract(resp, body)
+        if err != nil {
+            result.Err = fmt.Errorf("extract %s: %w", name, err)
+            return result
+        }
+        vu.vars[name] = value
+    }
+    return result
+}
+
+// templateData data template untuk step VU ini
+func (s *scenarioRequester) templateData(vu *vuState, requestNum int) templateData {
+    data := newTemplateData(requestNum, s.config.Seed)
+    data.VU = vu.id
+    data.Vars = vu.vars
+    data.Status = vu.status
+    return data
+}
+
+func (s *scenarioRequester) buildStep(ctx context.Context, vu *vuState, step compiledStep, requestNum int) (*http.Request, error) {
+    data := s.templateData(vu, requestNum)
+
+    rawURL, err := step.url.render(data)
+    if err != nil {
+        return nil, err
+    }
+    u, err := url.Parse(rawURL)
+    if err != nil {
+        return nil, fmt.Errorf("URL %q tidak valid: %w", rawURL, err)
+    }
+    if s.base != nil {
+        u = s.base.ResolveReference(u)
+    }
+    if !u.IsAbs() {
+        return nil, fmt.Errorf("URL relatif %q membutuhkan base URL (-u)", rawURL)
+    }
+
+    body, err := step.body.render(data)
+    if err != nil {
+        return nil, err
+    }
+    var bodyReader io.Reader
+    if body != "" {
+        bodyReader = strings.NewReader(body)
+    }
+    req, err := http.NewRequestWithContext(ctx, step.method, u.String(), bodyReader)
+    if err != nil {
+        return nil, err
+    }
+
+    req.Header.Set("User-Agent", userAgent(s.config))
+    req.Header.Set("Accept", "*/*")
+    if body != "" {
+        req.Header.Set("Content-Type", detectContentType(body))
+    }
+    setCustomHeaders(req, s.config)
+    for name, tmpl := range step.headers {
+        value, err := tmpl.render(data)
+        if err != nil {
+            return nil, err
+        }
+        req.Header.Set(name, value)
+    }
+    return req, nil
+}
+
+// CapturePackets merekam sampel koneksi VU ke PCAP
+func (s *scenarioRequester) CapturePackets(c *packetCapture) {
+    s.conns.capture = c
+}
+
+// Drain membiarkan koneksi idle semua VU terbuka sampai server menutupnya
+func (s *scenarioRequester) Drain(timeout time.Duration) *DrainReport {
+    return s.conns.drain(timeout)
+}
+
+// DeadlineReport response step yang datang setelah deadline -deadline-header
+func (s *scenarioRequester) DeadlineReport() *DeadlineReport {
+    return s.deadline.report()
+}
+
+// DNSResolver resolver host target seluruh VU untuk pengalihan failover
+func (s *scenarioRequester) DNSResolver() *dnsResolver {
+    return s.conns.dns
+}
+
+// ConnReport statistik koneksi seluruh VU
+func (s *scenarioRequester) ConnReport() *ConnReport {
+    return s.conns.report()
+}
+
+func (s *scenarioRequester) OpenConns() int64 {
+    return s.conns.live.Load()
+}
+
+// SecurityReport audit security header response semua step
+func (s *scenarioRequester) SecurityReport() *SecurityReport {
+    return s.audit.report()
+}
+
+// URLLimitReport antrean per pola -url-limit untuk semua step
+func (s *scenarioRequester) URLLimitReport() []URLLimitReport {
+    return s.limits.report()
+}
+
+// ShadowReport perbandingan dengan host -shadow untuk semua step
+func (s *scenarioRequester) ShadowReport() *ShadowReport {
+    return s.shadow.report()
+}
+
+// Close menjalankan vu_teardown untuk setiap VU yang sudah setup, lalu
+// teardown global
+func (s *scenarioRequester) Close() error {
+    if len(s.scenario.vuTeardown) > 0 {
+        var wg sync.WaitGroup
+        var failed sync.Map
+        s.vus.Range(func(_, value any) bool {
+            vu := value.(*vuState)
+            if !vu.ready {
+                return true
+            }
+            wg.Add(1)
+            go func() {
+                defer wg.Done()
+                if err := s.runSteps(context.Background(), vu, s.scenario.vuTeardown, 0); err != nil {
+                    failed.Store(vu.id, err)
+                }
+            }()
+            return true
+        })
+        wg.Wait()
+        failed.Range(func(id, err any) bool {
+            fmt.Fprintf(s.config.out, "⚠️  vu_teardown VU %d gagal: %v\n", id, err)
+            return true
+        })
+    }
+
+    if len(s.scenario.teardown) > 0 {
+        fmt.Fprintln(s.config.out, "🧹 Menjalankan teardown skenario...")
+        if err := s.runSteps(context.Background(), s.newVU(-1), s.scenario.teardown, 0); err != nil {
+            fmt.Fprintf(s.config.out, "⚠️  Teardown skenario gagal: %v\n", err)
+        }
+    }
+    s.pool.CloseIdleConnections()
+    s.shadow.close()
+    return nil
+}
+
+func (s *scenarioRequester) CloseIdleConnections() {
+    s.pool.CloseIdleConnections()
+}
@@ -0,0 +1,510 @@
+package engine
+
+import (
+    "bufio"
+    "context"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Scheduler menentukan kapan dan berapa banyak job dikirim ke worker.
+// Worker hanya membaca channel jobs, jadi model beban baru cukup menambah
+// implementasi Scheduler.
+type Scheduler interface {
+    // Run mengirim job ke jobs sampai jadwal selesai atau ctx dibatalkan.
+    // Run tidak menutup channel jobs.
+    Run(ctx context.Context, jobs chan<- job)
+    // Total jumlah request yang direncanakan, 0 jika tidak diketahui di awal
+    Total() int
+    String() string
+}
+
+// job satu request yang dijadwalkan untuk worker
+type job struct {
+    num int
+    // Waktu job jatuh tempo menurut jadwal open-loop, untuk mengukur waktu
+    // antre di generator. Zero pada jadwal closed-loop.
+    due time.Time
+}
+
+// Stage satu tahap pada ramping schedule: rate bergerak linear dari rate
+// tahap sebelumnya ke Target selama Duration
+type Stage struct {
+    Duration time.Duration
+    Target   float64
+}
+
+func newScheduler(config *Config) (Scheduler, error) {
+    switch {
+    case config.GroupRate > 0:
+        if config.ReplayFile != "" || config.Wave != "" || config.Burst != "" || config.Stages != "" || config.Rate > 0 {
+            return nil, fmt.Errorf("-group-rate tidak bisa dipakai bersama -rate, -stages, -burst, -wave atau -replay")
+        }
+        if config.Duration <= 0 {
+            return nil, fmt.Errorf("-group-rate membutuhkan -duration")
+        }
+        return &groupRateScheduler{groupRate: config.GroupRate, balance: config.Balance, duration: config.Duration}, nil
+    case config.ReplayFile != "":
+        if config.Stages != "" || config.Rate > 0 || config.Wave != "" || config.Burst != "" {
+            return nil, fmt.Errorf("-replay tidak bisa dipakai bersama -rate, -stages, -burst atau -wave")
+        }
+        offsets, err := loadReplayOffsets(config.ReplayFile)
+        if err != nil {
+            return nil, err
+        }
+        scaleOffsets(offsets, config.ReplaySpeed)
+        return &replayScheduler{offsets: offsets, speed: config.ReplaySpeed}, nil
+    case config.Wave != "":
+        if config.Stages != "" || config.Rate > 0 || config.Burst != "" {
+            return nil, fmt.Errorf("-wave tidak bisa dipakai bersama -rate, -stages atau -burst")
+        }
+        if config.Duration <= 0 {
+            return nil, fmt.Errorf("-wave membutuhkan -duration")
+        }
+        wave, err := parseWave(config.Wave)
+        if err != nil {
+            return nil, err
+        }
+        return &waveScheduler{wave: wave, duration: config.Duration}, nil
+    case config.Burst != "":
+        if config.Stages != "" || config.Rate > 0 {
+            return nil, fmt.Errorf("-burst tidak bisa dipakai bersama -rate atau -stages")
+        }
+        size, interval, err := parseBurst(config.Burst)
+        if err != nil {
+            return nil, err
+        }
+        return &burstScheduler{size: size, interval: interval, count: config.NumRequests, duration: config.Duration}, nil
+    case config.Stages != "":
+        stages, err := parseStages(config.Stages)
+        if err != nil {
+            return nil, err
+        }
+        return &rampScheduler{stages: stages}, nil
+    case config.Monitor:
+        rate := config.Rate
+        if rate <= 0 {
+            rate = 1
+        }
+        return &rateScheduler{rate: rate, count: -1}, nil
+    case config.Rate > 0:
+        return &rateScheduler{rate: config.Rate, count: config.NumRequests, duration: config.Duration}, nil
+    case config.Duration > 0:
+        return &durationScheduler{duration: config.Duration}, nil
+    default:
+        if config.NumRequests <= 0 {
+            return nil, fmt.Errorf("jumlah request harus lebih dari 0")
+        }
+        return &countScheduler{count: config.NumRequests}, nil
+    }
+}
+
+// send mengirim satu job, false jika ctx sudah dibatalkan
+func send(ctx context.Context, jobs chan<- job, requestNum int, due time.Time) bool {
+    select {
+    case jobs <- job{num: requestNum, due: due}:
+        return true
+    case <-ctx.Done():
+        return false
+    }
+}
+
+// sleepUntil menunggu sampai waktu t, false jika ctx dibatalkan lebih dulu
+func sleepUntil(ctx context.Context, t time.Time) bool {
+    wait := time.Until(t)
+    if wait <= 0 {
+        return ctx.Err() == nil
+    }
+    timer := time.NewTimer(wait)
+    defer timer.Stop()
+    select {
+    case <-timer.C:
+        return true
+    case <-ctx.Done():
+        return false
+    }
+}
+
+// countScheduler mengirim sejumlah request secepat worker sanggup
+type countScheduler struct {
+    count int
+}
+
+func (s *countScheduler) Run(ctx context.Context, jobs chan<- job) {
+    for i := 0; i < s.count; i++ {
+        if !send(ctx, jobs, i, time.Time{}) {
+            return
+        }
+    }
+}
+
+func (s *countScheduler) Total() int     { return s.count }
+func (s *countScheduler) String() string { return fmt.Sprintf("%d requests", s.count) }
+
+// durationScheduler mengirim request secepat worker sanggup selama durasi tertentu
+type durationScheduler struct {
+    duration time.Duration
+}
+
+func (s *durationScheduler) Run(ctx context.Context, jobs chan<- job) {
+    ctx, cancel := context.WithTimeout(ctx, s.duration)
+    defer cancel()
+
+    for i := 0; ; i++ {
+        if !send(ctx, jobs, i, time.Time{}) {
+            return
+        }
+    }
+}
+
+func (s *durationScheduler) Total() int     { return 0 }
+func (s *durationScheduler) String() string { return fmt.Sprintf("selama %v", s.duration) }
+
+// rateScheduler mengirim request dengan rate konstan, dibatasi jumlah
+// request atau durasi (durasi diutamakan jika diisi)
+type rateScheduler struct {
+    rate     float64
+    count    int // Negatif untuk tanpa batas (-monitor)
+    duration time.Duration
+}
+
+func (s *rateScheduler) Run(ctx context.Context, jobs chan<- job) {
+    if s.duration > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, s.duration)
+        defer cancel()
+    }
+
+    interval := time.Duration(float64(time.Second) / s.rate)
+    start := time.Now()
+    for i := 0; s.duration > 0 || s.count < 0 || i < s.count; i++ {
+        if s.duration > 0 && time.Duration(i)*interval >= s.duration {
+            return
+        }
+        due := start.Add(time.Duration(i) * interval)
+        if !sleepUntil(ctx, due) {
+            return
+        }
+        if !send(ctx, jobs, i, due) {
+            return
+        }
+    }
+}
+
+func (s *rateScheduler) Total() int {
+    if s.count < 0 {
+        return 0
+    }
+    if s.duration > 0 {
+        return int(s.rate * s.duration.Seconds())
+    }
+    return s.count
+}
+
+func (s *rateScheduler) String() string {
+    if s.duration > 0 {
+        return fmt.Sprintf("%.1f req/s selama %v", s.rate, s.duration)
+    }
+    if s.count < 0 {
+        return fmt.Sprintf("%.1f req/s tanpa batas (monitor)", s.rate)
+    }
+    return fmt.Sprintf("%.1f req/s, %d requests", s.rate, s.count)
+}
+
+// rampScheduler mengubah rate secara bertahap mengikuti daftar stage
+type rampScheduler struct {
+    stages []Stage
+}
+
+// expectedAt menghitung jumlah request kumulatif yang seharusnya sudah
+// dikirim pada waktu elapsed (integral rate), ok=false jika semua stage selesai
+func (s *rampScheduler) expectedAt(elapsed time.Duration) (expected float64, ok bool) {
+    from := 0.0
+    for _, stage := range s.stages {
+        if elapsed < stage.Duration {
+            progress := float64(elapsed) / float64(stage.Duration)
+            rate := from + (stage.Target-from)*progress
+            return expected + (from+rate)/2*elapsed.Seconds(), true
+        }
+        expected += (from + stage.Target) / 2 * stage.Duration.Seconds()
+        elapsed -= stage.Duration
+        from = stage.Target
+    }
+    return expected, false
+}
+
+func (s *rampScheduler) Run(ctx context.Context, jobs chan<- job) {
+    // Resolusi pengecekan rate; request yang jatuh tempo dalam satu tick
+    // dikirim sekaligus
+    const tick = 5 * time.Millisecond
+
+    start := time.Now()
+    sent := 0
+    for {
+        now := time.Now()
+        expected, ok := s.expectedAt(now.Sub(start))
+        for ; sent < int(expected); sent++ {
+            if !send(ctx, jobs, sent, now) {
+                return
+            }
+        }
+        if !ok {
+            return
+        }
+        if !sleepUntil(ctx, time.Now().Add(tick)) {
+            return
+        }
+    }
+}
+
+func (s *rampScheduler) Total() int {
+    var total time.Duration
+    for _, stage := range s.stages {
+        total += stage.Duration
+    }
+    expected, _ := s.expectedAt(total)
+    return int(expected)
+}
+
+func (s *rampScheduler) String() string {
+    parts := make([]string, len(s.stages))
+    for i, stage := range s.stages {
+        parts[i] = fmt.Sprintf("%v→%.0f req/s", stage.Duration, stage.Target)
+    }
+    return "ramping " + strings.Join(parts, ", ")
+}
+
+// Segments satu segmen per stage untuk laporan per stage
+func (s *rampScheduler) Segments() []Segment {
+    var segments []Segment
+    var offset time.Duration
+    from := 0.0
+    for i, stage := range s.stages {
+        target := fmt.Sprintf("%.0f req/s", stage.Target)
+        if from != stage.Target {
+            target = fmt.Sprintf("%.0f→%.0f req/s", from, stage.Target)
+        }
+        segments = append(segments, Segment{
+            Name:   fmt.Sprintf("%d", i+1),
+            Target: target,
+            Start:  offset,
+            End:    offset + stage.Duration,
+        })
+        offset += stage.Duration
+        from = stage.Target
+    }
+    return segments
+}
+
+// rampHoldStages menerjemahkan -ramp-up, -hold dan -ramp-down ke format
+// -stages: naik dari 0 ke -rate, bertahan, lalu turun ke 0
+func rampHoldStages(config *Config) (string, error) {
+    switch {
+    case config.Stages != "" || config.Burst != "" || config.Wave != "" || config.ReplayFile != "" || config.GroupRate > 0:
+        return "", fmt.Errorf("-ramp-up/-hold/-ramp-down tidak bisa dipakai bersama -stages, -burst, -wave, -replay atau -group-rate")
+    case config.Duration > 0:
+        return "", fmt.Errorf("-ramp-up/-hold/-ramp-down tidak bisa dipakai bersama -duration; lama test = ramp-up + hold + ramp-down")
+    case config.Rate <= 0:
+        return "", fmt.Errorf("-ramp-up/-hold/-ramp-down butuh -rate sebagai target")
+    case config.RampUp <= 0 || config.Hold <= 0 || config.RampDown < 0:
+        return "", fmt.Errorf("-ramp-up dan -hold harus lebih dari 0, -ramp-down tidak boleh negatif")
+    }
+    stages := fmt.Sprintf("%v:%g,%v:%g", config.RampUp, config.Rate, config.Hold, config.Rate)
+    if config.RampDown > 0 {
+        stages += fmt.Sprintf(",%v:0", config.RampDown)
+    }
+    return stages, nil
+}
+
+// parseStages membaca format 'durasi:rate,durasi:rate', contoh '30s:100,1m:500,30s:0'
+func parseStages(value string) ([]Stage, error) {
+    var stages []Stage
+    for _, part := range strings.Split(value, ",") {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            continue
+        }
+        durStr, rateStr, found := strings.Cut(part, ":")
+        if !found {
+            return nil, fmt.Errorf("stage %q tidak valid (format: durasi:rate)", part)
+        }
+        duration, err := time.ParseDuration(durStr)
+        if err != nil || duration <= 0 {
+            return nil, fmt.Errorf("durasi stage %q tidak valid", durStr)
+        }
+        target, err := strconv.ParseFloat(rateStr, 64)
+        if err != nil || target < 0 {
+            return nil, fmt.Errorf("rate stage %q tidak valid", rateStr)
+        }
+        stages = append(stages, Stage{Duration: duration, Target: target})
+    }
+    if len(stages) == 0 {
+        return nil, fmt.Errorf("stages kosong")
+    }
+    return stages, nil
+}
+
+// burstScheduler mengirim size request sekaligus setiap interval lalu diam
+// sampai burst berikutnya, seperti traffic dari cron atau batch job. Dibatasi
+// jumlah request atau durasi (durasi diutamakan jika diisi).
+type burstScheduler struct {
+    size     int
+    interval time.Duration
+    count    int
+    duration time.Duration
+}
+
+func (s *burstScheduler) Run(ctx context.Context, jobs chan<- job) {
+    total := s.Total()
+    start := time.Now()
+    for sent, burst := 0, 0; sent < total; burst++ {
+        due := start.Add(time.Duration(burst) * s.interval)
+        if !sleepUntil(ctx, due) {
+            return
+        }
+        // Request yang belum terambil worker mengantre seperti di client nyata
+        for i := 0; i < s.size && sent < total; i, sent = i+1, sent+1 {
+            if !send(ctx, jobs, sent, due) {
+                return
+            }
+        }
+    }
+}
+
+func (s *burstScheduler) Total() int {
+    if s.duration > 0 {
+        bursts := int((s.duration + s.interval - 1) / s.interval)
+        return bursts * s.size
+    }
+    return s.count
+}
+
+func (s *burstScheduler) String() string {
+    if s.duration > 0 {
+        return fmt.Sprintf("burst %d request setiap %v selama %v", s.size, s.interval, s.duration)
+    }
+    return fmt.Sprintf("burst %d request setiap %v, %d requests", s.size, s.interval, s.count)
+}
+
+// parseBurst membaca format 'jumlah@interval', contoh '100@10s'
+func parseBurst(value string) (int, time.Duration, error) {
+    sizeStr, intervalStr, found := strings.Cut(value, "@")
+    if !found {
+        return 0, 0, fmt.Errorf("burst %q tidak valid (format: jumlah@interval)", value)
+    }
+    size, err := strconv.Atoi(strings.TrimSpace(sizeStr))
+    if err != nil || size <= 0 {
+        return 0, 0, fmt.Errorf("jumlah burst %q tidak valid", sizeStr)
+    }
+    interval, err := time.ParseDuration(strings.TrimSpace(intervalStr))
+    if err != nil || interval <= 0 {
+        return 0, 0, fmt.Errorf("interval burst %q tidak valid", intervalStr)
+    }
+    return size, interval, nil
+}
+
+// replayScheduler mengirim request mengikuti jarak waktu hasil rekaman
+type replayScheduler struct {
+    offsets []time.Duration
+    speed   float64
+}
+
+func (s *replayScheduler) Run(ctx context.Context, jobs chan<- job) {
+    start := time.Now()
+    for i, offset := range s.offsets {
+        due := start.Add(offset)
+        if !sleepUntil(ctx, due) {
+            return
+        }
+        if !send(ctx, jobs, i, due) {
+            return
+        }
+    }
+}
+
+func (s *replayScheduler) Total() int { return len(s.offsets) }
+
+func (s *replayScheduler) String() string {
+    span := time.Duration(0)
+    if len(s.offsets) > 0 {
+        span = s.offsets[len(s.offsets)-1]
+    }
+    if s.speed != 1 {
+        return fmt.Sprintf("replay %d requests selama %v (%gx)", len(s.offsets), span, s.speed)
+    }
+    return fmt.Sprintf("replay %d requests selama %v", len(s.offsets), span)
+}
+
+// loadReplayOffsets membaca file berisi satu timestamp per baris (unix
+// seconds dengan pecahan atau RFC3339), file HAR, atau access log dengan
+// format Common/Combined Log, lalu mengubahnya menjadi offset dari
+// timestamp pertama
+func loadReplayOffsets(path string) ([]time.Duration, error) {
+    if strings.EqualFold(filepath.Ext(path), ".har") {
+        return loadHAROffsets(path)
+    }
+    f, err := os.Open(path)
+    if err != nil {
+        return nil, err
+    }
+    defer f.Close()
+
+    var offsets []time.Duration
+    var first time.Time
+    var logTimes []time.Time
+    scanner := bufio.NewScanner(f)
+    for lineNum := 1; scanner.Scan(); lineNum++ {
+        line := strings.TrimSpace(scanner.Text())
+        if line == "" || strings.HasPrefix(line, "#") {
+            continue
+        }
+        if ts, ok := parseAccessLogTime(line); ok {
+            logTimes = append(logTimes, ts)
+            continue
+        }
+        if len(logTimes) > 0 {
+            return nil, fmt.Errorf("%s:%d: bukan baris access log", path, lineNum)
+        }
+
+        ts, err := parseTimestamp(line)
+        if err != nil {
+            return nil, fmt.Errorf("%s:%d: %v", path, lineNum, err)
+        }
+        if first.IsZero() {
+            first = ts
+        }
+        offset := ts.Sub(first)
+        if offset < 0 {
+            return nil, fmt.Errorf("%s:%d: timestamp tidak berurutan", path, lineNum)
+        }
+        offsets = append(offsets, offset)
+    }
+    if err := scanner.Err(); err != nil {
+        return nil, err
+    }
+    if len(logTimes) > 0 {
+        if len(offsets) > 0 {
+            return nil, fmt.Errorf("%s: campuran timestamp dan baris access log", path)
+        }
+        return offsetsFrom(logTimes), nil
+    }
+    if len(offsets) == 0 {
+        return nil, fmt.Errorf("%s: tidak ada timestamp", path)
+    }
+    return offsets, nil
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+    if secs, err := strconv.ParseFloat(value, 64); err == nil {
+        return time.Unix(0, int64(secs*float64(time.Second))), nil
+    }
+    ts, err := time.Parse(time.RFC3339Nano, value)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("timestamp %q tidak valid", value)
+    }
+    return ts, nil
+}
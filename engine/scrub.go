@@ -0,0 +1,87 @@
+package engine
+
+import (
+    "net/url"
+    "regexp"
+    "strings"
+)
+
+// urlPattern URL di dalam teks bebas seperti pesan error
+var urlPattern = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'<>]+`)
+
+// sensitiveHeaderPattern nama header yang menandakan kredensial; dengan
+// -scrub namanya pun tidak ikut diekspor
+var sensitiveHeaderPattern = regexp.MustCompile(`(?i)auth|cookie|token|key|secret|session|signature`)
+
+// scrubURL membuang userinfo, query string dan fragment dari URL
+func scrubURL(raw string) string {
+    u, err := url.Parse(raw)
+    if err != nil || u.Host == "" {
+        return raw
+    }
+    u.User = nil
+    u.RawQuery = ""
+    u.ForceQuery = false
+    u.Fragment = ""
+    u.RawFragment = ""
+    return u.String()
+}
+
+// scrubText membersihkan setiap URL di dalam teks
+func scrubText(s string) string {
+    if !strings.Contains(s, "://") {
+        return s
+    }
+    return urlPattern.ReplaceAllStringFunc(s, scrubURL)
+}
+
+// scrubReport menghapus data yang bisa berisi token atau PII dari report
+// sebelum ditampilkan dan diekspor (-scrub): query string dan userinfo URL,
+// nama header autentikasi, dan potongan body/nilai yang tertangkap
+func scrubReport(r *Report) {
+    r.Scrubbed = true
+    r.URL = scrubURL(r.URL)
+
+    headers := r.Metadata.HeaderNames[:0]
+    for _, name := range r.Metadata.HeaderNames {
+        if !sensitiveHeaderPattern.MatchString(name) {
+            headers = append(headers, name)
+        }
+    }
+    r.Metadata.HeaderNames = headers
+    if r.Metadata.Clock != nil {
+        r.Metadata.Clock.SyncURL = scrubURL(r.Metadata.Clock.SyncURL)
+    }
+
+    if r.Cache != nil {
+        for i := range r.Cache.Checks {
+            r.Cache.Checks[i].Example = scrubText(r.Cache.Checks[i].Example)
+        }
+    }
+    if r.Page != nil {
+        for i := range r.Page.Failures {
+            r.Page.Failures[i].URL = scrubURL(r.Page.Failures[i].URL)
+            r.Page.Failures[i].Error = scrubText(r.Page.Failures[i].Error)
+        }
+    }
+    if r.Shadow != nil {
+        r.Shadow.URL = scrubURL(r.Shadow.URL)
+        if r.Shadow.Diff != nil {
+            // Contoh nilai berasal dari body response
+            for i := range r.Shadow.Diff.Paths {
+                r.Shadow.Diff.Paths[i].Example = ""
+            }
+        }
+    }
+    if r.Idempotency != nil {
+        for i := range r.Idempotency.Paths {
+            r.Idempotency.Paths[i].Example = ""
+        }
+    }
+    if r.Security != nil {
+        // Nilai header (contoh report-uri CSP) bisa membawa token
+        for i := range r.Security.Headers {
+            r.Security.Headers[i].Values = nil
+        }
+    }
+}
@@ -0,0 +1,249 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "net/http"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+)
+
+// minHSTSMaxAge max-age HSTS minimal yang dianggap memadai (180 hari)
+const minHSTSMaxAge = 180 * 24 * 60 * 60
+
+// maxAuditValues batas jumlah nilai berbeda yang disimpan per header
+const maxAuditValues = 8
+
+// securityHeaders header yang diaudit, urut sesuai tampilan. check
+// mengembalikan masalah pada nilai header, "" jika nilainya memadai.
+var securityHeaders = []struct {
+    name      string
+    httpsOnly bool
+    check     func(value string) string
+}{
+    {"Strict-Transport-Security", true, checkHSTS},
+    {"Content-Security-Policy", false, checkCSP},
+    {"X-Content-Type-Options", false, func(value string) string {
+        if !strings.EqualFold(strings.TrimSpace(value), "nosniff") {
+            return "harus nosniff"
+        }
+        return ""
+    }},
+    {"X-Frame-Options", false, func(value string) string {
+        switch strings.ToUpper(strings.TrimSpace(value)) {
+        case "DENY", "SAMEORIGIN":
+            return ""
+        }
+        return "harus DENY atau SAMEORIGIN"
+    }},
+    {"Referrer-Policy", false, func(value string) string {
+        if strings.Contains(strings.ToLower(value), "unsafe-url") {
+            return "unsafe-url membocorkan URL lengkap"
+        }
+        return ""
+    }},
+}
+
+func checkHSTS(value string) string {
+    for _, directive := range strings.Split(value, ";") {
+        name, raw, _ := strings.Cut(strings.TrimSpace(directive), "=")
+        if !strings.EqualFold(name, "max-age") {
+            continue
+        }
+        seconds, err := strconv.ParseInt(strings.Trim(raw, `"`), 10, 64)
+        if err != nil {
+            return "max-age tidak valid"
+        }
+        if seconds < minHSTSMaxAge {
+            return "max-age kurang dari 180 hari"
+        }
+        return ""
+    }
+    return "tanpa max-age"
+}
+
+func checkCSP(value string) string {
+    if strings.Contains(value, "'unsafe-eval'") {
+        return "mengizinkan 'unsafe-eval'"
+    }
+    return ""
+}
+
+type headerAuditStats struct {
+    checked, missing, missingOnError, invalid int64
+    problem                                   string
+    values                                    map[string]int64
+}
+
+// headerAudit mencatat ada/tidaknya security header di setiap response
+// selama test, untuk menangkap header yang hilang saat middleware kewalahan
+type headerAudit struct {
+    mu      sync.Mutex
+    headers map[string]*headerAuditStats
+}
+
+func newHeaderAudit() *headerAudit {
+    a := &headerAudit{headers: make(map[string]*headerAuditStats, len(securityHeaders))}
+    for _, h := range securityHeaders {
+        a.headers[h.name] = &headerAuditStats{values: make(map[string]int64)}
+    }
+    return a
+}
+
+// observe memeriksa header satu response. Response 304 dan 1xx dilewati
+// karena memang boleh tanpa header tersebut.
+func (a *headerAudit) observe(resp *http.Response) {
+    if resp.StatusCode < 200 || resp.StatusCode == http.StatusNotModified {
+        return
+    }
+    https := resp.TLS != nil
+    failed := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    for _, h := range securityHeaders {
+        if h.httpsOnly && !https {
+            continue
+        }
+        s := a.headers[h.name]
+        s.checked++
+        value := resp.Header.Get(h.name)
+        if value == "" {
+            s.missing++
+            if failed {
+                s.missingOnError++
+            }
+            continue
+        }
+        if problem := h.check(value); problem != "" {
+            s.invalid++
+            if s.problem == "" {
+                s.problem = problem
+            }
+        }
+        if _, ok := s.values[value]; ok || len(s.values) < maxAuditValues {
+            s.values[value]++
+        }
+    }
+}
+
+// SecurityHeaderCheck hasil audit satu security header
+type SecurityHeaderCheck struct {
+    Header         string           `json:"header"`
+    Status         string           `json:"status"` // pass atau warn
+    Checked        int64            `json:"checked"`
+    Missing        int64            `json:"missing"`
+    MissingOnError int64            `json:"missing_on_error"` // Hilang pada response 5xx/429
+    Invalid        int64            `json:"invalid"`
+    Values         map[string]int64 `json:"values,omitempty"`
+    Note           string           `json:"note,omitempty"`
+}
+
+// SecurityReport ringkasan audit security header selama test
+type SecurityReport struct {
+    Headers  []SecurityHeaderCheck `json:"headers"`
+    Warnings int                   `json:"warnings"`
+}
+
+func (a *headerAudit) report() *SecurityReport {
+    a.mu.Lock()
+    defer a.mu.Unlock()
+
+    r := &SecurityReport{}
+    for _, h := range securityHeaders {
+        s := a.headers[h.name]
+        if s.checked == 0 {
+            continue
+        }
+        check := SecurityHeaderCheck{
+            Header:         h.name,
+            Status:         "pass",
+            Checked:        s.checked,
+            Missing:        s.missing,
+            MissingOnError: s.missingOnError,
+            Invalid:        s.invalid,
+        }
+        if len(s.values) > 0 {
+            check.Values = make(map[string]int64, len(s.values))
+            for value, n := range s.values {
+                check.Values[value] = n
+            }
+        }
+        var notes []string
+        switch {
+        case s.missing == s.checked:
+            notes = append(notes, "tidak pernah dikirim")
+        case s.missing > 0 && s.missingOnError > 0:
+            notes = append(notes, fmt.Sprintf("hilang pada %d dari %d response (%d di response 5xx/429), kemungkinan di-drop middleware saat overload", s.missing, s.checked, s.missingOnError))
+        case s.missing > 0:
+            notes = append(notes, fmt.Sprintf("hilang pada %d dari %d response", s.missing, s.checked))
+        }
+        if s.invalid > 0 {
+            notes = append(notes, fmt.Sprintf("%d nilai tidak memadai: %s", s.invalid, s.problem))
+        }
+        if len(s.values) > 1 {
+            notes = append(notes, fmt.Sprintf("%d nilai berbeda", len(s.values)))
+        }
+        check.Note = strings.Join(notes, "; ")
+        if s.missing > 0 || s.invalid > 0 {
+            check.Status = "warn"
+            r.Warnings++
+        }
+        r.Headers = append(r.Headers, check)
+    }
+    if len(r.Headers) == 0 {
+        return nil
+    }
+    return r
+}
+
+// commonValue nilai header yang paling sering muncul
+func (c SecurityHeaderCheck) commonValue() string {
+    values := make([]string, 0, len(c.Values))
+    for value := range c.Values {
+        values = append(values, value)
+    }
+    sort.Slice(values, func(i, j int) bool {
+        if c.Values[values[i]] != c.Values[values[j]] {
+            return c.Values[values[i]] > c.Values[values[j]]
+        }
+        return values[i] < values[j]
+    })
+    if len(values) == 0 {
+        return "-"
+    }
+    return values[0]
+}
+
+// PresentPct persen response yang menyertakan header
+func (c SecurityHeaderCheck) PresentPct() float64 {
+    return float64(c.Checked-c.Missing) / float64(c.Checked) * 100
+}
+
+// SecurityReporter diimplementasikan Requester yang mengaudit security
+// header response
+type SecurityReporter interface {
+    SecurityReport() *SecurityReport
+}
+
+func printSecurityHeaders(w io.Writer, report *Report) {
+    s := report.Security
+    if s == nil {
+        return
+    }
+    fmt.Fprintln(w, "\n🛡️  Audit Security Header:")
+    fmt.Fprintf(w, "     %-27s %7s  %-6s %s\n", "Header", "Ada", "Status", "Keterangan")
+    for _, h := range s.Headers {
+        icon := "✅"
+        if h.Status == "warn" {
+            icon = "⚠️ "
+        }
+        note := h.Note
+        if note == "" {
+            note = truncate(h.commonValue(), 60)
+        }
+        fmt.Fprintf(w, "  %s %-27s %6.1f%%  %-6s %s\n", icon, h.Header, h.PresentPct(), strings.ToUpper(h.Status), note)
+    }
+}
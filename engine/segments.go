@@ -0,0 +1,113 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "sync/atomic"
+    "time"
+)
+
+// Segment rentang waktu dalam run yang dilaporkan terpisah, contoh satu
+// stage pada ramping schedule
+type Segment struct {
+    Name   string
+    Target string        // Deskripsi target beban, contoh "100→500 req/s"
+    Start  time.Duration // Offset dari awal run
+    End    time.Duration
+}
+
+// Segmenter diimplementasikan Scheduler yang membagi run menjadi beberapa segmen
+type Segmenter interface {
+    Segments() []Segment
+}
+
+type segmentStats struct {
+    Segment
+    requests      atomic.Int64
+    errors        atomic.Int64 // Gagal atau status >= 400
+    totalDuration atomic.Int64
+    latency       histogram
+}
+
+// segmentSet mengelompokkan hasil request ke segmen berdasarkan waktu mulai request
+type segmentSet struct {
+    start    time.Time
+    segments []*segmentStats
+}
+
+func newSegmentSet(start time.Time, segments []Segment) *segmentSet {
+    set := &segmentSet{start: start}
+    for _, seg := range segments {
+        set.segments = append(set.segments, &segmentStats{Segment: seg})
+    }
+    return set
+}
+
+func (s *segmentSet) add(result Result) {
+    offset := result.Start.Sub(s.start)
+    for _, seg := range s.segments {
+        if offset >= seg.Start && offset < seg.End {
+            seg.requests.Add(1)
+            if result.Err != nil || result.StatusCode >= 400 {
+                seg.errors.Add(1)
+            }
+            seg.totalDuration.Add(int64(result.Duration))
+            seg.latency.addDuration(result.Duration)
+            return
+        }
+    }
+}
+
+// StageReport statistik satu segmen/stage
+type StageReport struct {
+    Name      string  `json:"name"`
+    Target    string  `json:"target"`
+    StartS    float64 `json:"start_s"`
+    DurationS float64 `json:"duration_s"`
+    Requests  int64   `json:"requests"`
+    RPS       float64 `json:"requests_per_second"`
+    AvgMs     float64 `json:"avg_latency_ms"`
+    P50Ms     float64 `json:"p50_latency_ms"`
+    P95Ms     float64 `json:"p95_latency_ms"`
+    P99Ms     float64 `json:"p99_latency_ms"`
+    ErrorRate float64 `json:"error_rate"`
+}
+
+func (s *segmentSet) report() []StageReport {
+    var reports []StageReport
+    for _, seg := range s.segments {
+        duration := seg.End - seg.Start
+        r := StageReport{
+            Name:      seg.Name,
+            Target:    seg.Target,
+            StartS:    seg.Start.Seconds(),
+            DurationS: duration.Seconds(),
+            Requests:  seg.requests.Load(),
+        }
+        if r.Requests > 0 {
+            r.RPS = float64(r.Requests) / duration.Seconds()
+            r.AvgMs = durationMs(time.Duration(seg.totalDuration.Load() / r.Requests))
+            r.P50Ms = durationMs(seg.latency.quantileDuration(0.50))
+            r.P95Ms = durationMs(seg.latency.quantileDuration(0.95))
+            r.P99Ms = durationMs(seg.latency.quantileDuration(0.99))
+            r.ErrorRate = float64(seg.errors.Load()) / float64(r.Requests) * 100
+        }
+        reports = append(reports, r)
+    }
+    return reports
+}
+
+func printStages(w io.Writer, report *Report) {
+    if len(report.Stages) == 0 {
+        return
+    }
+    fmt.Fprintln(w, "\n📶 Hasil per Stage:")
+    fmt.Fprintf(w, "  %-9s %-18s %9s %9s %9s %9s %9s %9s %8s\n",
+        "Stage", "Target", "Requests", "Req/s", "Avg ms", "p50 ms", "p95 ms", "p99 ms", "Error %")
+    f := report.display
+    for _, s := range report.Stages {
+        fmt.Fprintf(w, "  %-9s %-18s %9s %9s %9s %9s %9s %9s %8s\n",
+            s.Name, s.Target, f.Int(s.Requests), f.FloatN(s.RPS, 1), f.FloatN(s.AvgMs, 1), f.FloatN(s.P50Ms, 1),
+            f.FloatN(s.P95Ms, 1), f.FloatN(s.P99Ms, 1), f.FloatN(s.ErrorRate, 2))
+    }
+}
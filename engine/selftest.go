@@ -0,0 +1,150 @@
+package engine
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "math"
+    mathrand "math/rand/v2"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "strings"
+    "sync/atomic"
+    "time"
+)
+
+// selftestTarget server HTTP in-process dengan latency dan error buatan
+type selftestTarget struct {
+    latency   time.Duration
+    jitter    time.Duration
+    errorRate float64 // 0..1
+    body      []byte
+    received  atomic.Int64
+}
+
+func (t *selftestTarget) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    t.received.Add(1)
+    delay := t.latency
+    if t.jitter > 0 {
+        delay += time.Duration(mathrand.Int64N(int64(2*t.jitter))) - t.jitter
+    }
+    if delay > 0 {
+        time.Sleep(delay)
+    }
+    if t.errorRate > 0 && mathrand.Float64() < t.errorRate {
+        http.Error(w, "selftest: error buatan", http.StatusInternalServerError)
+        return
+    }
+    w.Header().Set("Content-Type", "application/octet-stream")
+    w.Write(t.body)
+}
+
+// runSelftest menjalankan generator terhadap server in-process untuk
+// memvalidasi instalasi dan mengukur overhead tool tanpa target eksternal.
+// Argumen setelah "--" diteruskan sebagai flag load test biasa.
+func runSelftest(args []string) int {
+    fs := flag.NewFlagSet("selftest", flag.ExitOnError)
+    target := &selftestTarget{}
+    fs.DurationVar(&target.latency, "latency", 0, "Latency buatan server per request")
+    fs.DurationVar(&target.jitter, "jitter", 0, "Variasi acak latency (+/-)")
+    errorPct := fs.Float64("error-rate", 0, "Persen response 500 buatan")
+    size := fs.Int("size", 100, "Ukuran body response (byte)")
+    fs.Usage = func() {
+        fmt.Fprintf(os.Stderr, "Usage: loadtest selftest [options] [-- flag load test]\n\n")
+        fmt.Fprintf(os.Stderr, "Menjalankan load test ke server HTTP in-process untuk memvalidasi instalasi\n")
+        fmt.Fprintf(os.Stderr, "dan mengukur overhead generator. Tanpa flag load test: -n 10000 -c 50.\n\n")
+        fmt.Fprintf(os.Stderr, "Options:\n")
+        fs.PrintDefaults()
+        fmt.Fprintf(os.Stderr, "\nContoh:\n")
+        fmt.Fprintf(os.Stderr, "  loadtest selftest\n")
+        fmt.Fprintf(os.Stderr, "  loadtest selftest -latency 20ms -jitter 5ms -error-rate 1 -- -c 100 -duration 30s\n")
+    }
+    fs.Parse(args)
+    if *errorPct < 0 || *errorPct > 100 || *size < 0 || target.jitter > target.latency {
+        fmt.Println("Error: -error-rate harus 0-100, -size >= 0 dan -jitter tidak melebihi -latency")
+        return 1
+    }
+    target.errorRate = *errorPct / 100
+    target.body = []byte(strings.Repeat("x", *size))
+
+    loadArgs := fs.Args()
+    if len(loadArgs) == 0 {
+        loadArgs = []string{"-n", "10000", "-c", "50"}
+    }
+    config, err := parseFlags(flag.NewFlagSet("loadtest", flag.ExitOnError), loadArgs)
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    if config.Scenario != "" || config.URL != "" {
+        fmt.Println("Error: selftest memakai server in-process, jangan isi URL atau -scenario")
+        return 1
+    }
+
+    server := httptest.NewServer(target)
+    defer server.Close()
+    config.URL = server.URL
+    if err := loadBody(config); err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    scheduler, err := newScheduler(config)
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    thresholds, err := parseThresholds(config.Thresholds)
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+
+    fmt.Printf("🧪 Selftest: server in-process %s (latency %v ± %v, error %.1f%%, body %d byte)\n", server.URL, target.latency, target.jitter, *errorPct, *size)
+    fmt.Printf("   Jadwal: %s, concurrency %d\n\n", scheduler, config.Concurrency)
+    report, _, err := executeRun(context.Background(), config, scheduler, thresholds, nil)
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    if !printSelftest(report, target, *errorPct) {
+        return 1
+    }
+    return 0
+}
+
+// printSelftest membandingkan hasil dengan perilaku server yang diketahui.
+// False jika ada yang tidak sesuai (instalasi atau generator bermasalah).
+func printSelftest(r *Report, target *selftestTarget, errorPct float64) bool {
+    ok := true
+    fmt.Println("\n🧪 Hasil Selftest:")
+
+    received := target.received.Load()
+    if received == r.TotalRequests && r.TotalRequests > 0 {
+        fmt.Printf("  ✅ Request terkirim dan diterima server: %d\n", received)
+    } else {
+        fmt.Printf("  ❌ Request tercatat %d, diterima server %d\n", r.TotalRequests, received)
+        ok = false
+    }
+
+    // Error rate boleh menyimpang dalam 3 standar deviasi binomial
+    p := errorPct / 100
+    tolerance := 3*math.Sqrt(p*(1-p)/float64(max(r.TotalRequests, 1)))*100 + 0.1
+    if math.Abs(r.ErrorRate-errorPct) <= tolerance {
+        fmt.Printf("  ✅ Error rate %.2f%% (diatur %.2f%%)\n", r.ErrorRate, errorPct)
+    } else {
+        fmt.Printf("  ❌ Error rate %.2f%%, diharapkan %.2f%% ± %.2f\n", r.ErrorRate, errorPct, tolerance)
+        ok = false
+    }
+
+    // Selisih latency terukur dengan latency buatan server adalah overhead
+    // generator, HTTP stack dan loopback
+    latencyMs := durationMs(target.latency)
+    fmt.Printf("  ⏱️  Overhead per request: p50 %.3f ms, p99 %.3f ms (latency terukur dikurangi %v buatan server)\n",
+        math.Max(r.P50LatencyMs-latencyMs, 0), math.Max(r.P99LatencyMs-latencyMs, 0), target.latency)
+    fmt.Printf("  🚀 Throughput: %.0f req/s\n", r.RPS)
+    if ok {
+        fmt.Println("  ✅ Instalasi berfungsi")
+    }
+    return ok
+}
@@ -0,0 +1,480 @@
+package engine
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "net/http"
+    "os"
+    "os/exec"
+    "os/signal"
+    "path/filepath"
+    "sort"
+    "sync"
+    "syscall"
+    "time"
+)
+
+// Status job di server
+const (
+    jobQueued    = "queued"
+    jobRunning   = "running"
+    jobDone      = "done"   // Selesai dengan exit code 0
+    jobFailed    = "failed" // Error atau threshold gagal, lihat exit_code dan log
+    jobCancelled = "cancelled"
+)
+
+// serverAllowedFlags flag yang boleh dikirim lewat API, flag lain ditolak
+// sehingga flag baru tertutup sampai diputuskan aman. Yang tidak ada di sini:
+// flag yang membaca atau menulis file di server (-body-file, -scenario,
+// -replay, -trend, -out, ...), menjalankan perintah (hook, SSH), membuka
+// listener (-control-addr, -monitor-addr), memakai kredensial atau layanan
+// server (-upload, email, alert, webhook, Grafana, Elasticsearch, Prometheus) dan
+// mengarahkan koneksi ke host selain target (-proxy, -dns-server,
+// -dns-switch, -run-group, -ntp). Report ditulis sendiri oleh server ke
+// direktori job.
+var serverAllowedFlags = map[string]bool{
+    // Target dan request
+    "u": true, "H": true, "d": true, "m": true, "t": true, "k": true,
+    "content-type": true, "param": true, "soap-action": true, "http2": true,
+    "h2-streams": true, "h2-header-bytes": true, "h2-header-mode": true,
+    "url-limit": true, "deadline-header": true, "deadline-format": true,
+    "deadline-budget": true, "idempotency": true, "idempotency-header": true,
+    "idempotency-ignore": true, "cache-test": true, "cache-mix": true,
+    "page": true, "page-concurrency": true, "page-external": true,
+    "from-sitemap": true, "sitemap-match": true, "shadow": true,
+    "shadow-diff": true, "shadow-diff-ignore": true, "shadow-max": true,
+    "dns": true, "conn-pool": true, "max-open-conns": true, "preconnect": true,
+    "drain": true, "courtesy": true, "courtesy-rps": true, "contact": true,
+    "allow-hosts": true, "i-know-what-im-doing": true,
+    // Jadwal
+    "n": true, "c": true, "duration": true, "rate": true, "stages": true,
+    "ramp-up": true, "ramp-down": true, "hold": true, "cooldown": true,
+    "burst": true, "wave": true, "group-rate": true, "balance": true,
+    "pacing": true, "monitor": true, "monitor-window": true, "repeat": true,
+    "seed": true, "max-memory": true,
+    // Validasi dan report
+    "assert-contains": true, "assert-prefix": true, "assert-xpath": true,
+    "thresholds": true, "error-bodies": true, "error-code-path": true,
+    "error-message-path": true, "name": true, "locale": true,
+    "precision": true, "scrub": true, "report-title": true,
+    "report-footer": true,
+}
+
+// serverJobEnv variabel environment yang diteruskan ke proses job. Sisanya,
+// termasuk kredensial server (AWS_*, SMTP_PASSWORD, token API), tidak
+// diteruskan agar tidak bisa dibaca template {{env}} di argumen job.
+var serverJobEnv = []string{"PATH", "HOME", "TMPDIR", "TZ", "LANG", "LC_ALL", "LC_NUMERIC", "SSL_CERT_FILE", "SSL_CERT_DIR", "SYSTEMROOT"}
+
+// Job satu definisi test yang dikirim ke server. Args sama persis dengan
+// argumen CLI loadtest, contoh ["-c", "50", "-duration", "1m", "https://api.example.com"].
+type Job struct {
+    ID       string     `json:"id"`
+    Name     string     `json:"name,omitempty"`
+    Owner    string     `json:"owner,omitempty"` // Nama token pengirim
+    Args     []string   `json:"args"`
+    Status   string     `json:"status"`
+    ExitCode *int       `json:"exit_code,omitempty"`
+    Error    string     `json:"error,omitempty"`
+    Created  time.Time  `json:"created"`
+    Started  *time.Time `json:"started,omitempty"`
+    Finished *time.Time `json:"finished,omitempty"`
+
+    cancel     context.CancelFunc
+    allowHosts string // Dipaksakan ke proses job sebagai -allow-hosts
+}
+
+// serverConfig batas sumber daya server
+type serverConfig struct {
+    listen         string
+    dataDir        string
+    parallel       int
+    maxQueue       int
+    maxConcurrency int
+    maxDuration    time.Duration
+    tokens         string
+    allowHosts     string
+}
+
+// jobServer menyimpan antrean job dan menjalankan setiap job sebagai proses
+// loadtest terpisah, sehingga output dan crash satu job tidak mengganggu
+// job lain
+type jobServer struct {
+    config serverConfig
+    binary string
+    tokens []apiToken // nil jika API terbuka tanpa token
+
+    agents *agentRegistry // Agent run terdistribusi (-run-group)
+
+    mu    sync.Mutex
+    jobs  map[string]*Job
+    queue chan *Job
+    seq   int
+}
+
+func runServer(args []string) int {
+    fs := flag.NewFlagSet("server", flag.ExitOnError)
+    var config serverConfig
+    fs.StringVar(&config.listen, "listen", ":8080", "Alamat HTTP API")
+    fs.StringVar(&config.dataDir, "data", "loadtest-jobs", "Direktori penyimpanan job (definisi, log dan report)")
+    fs.IntVar(&config.parallel, "parallel", 1, "Jumlah job yang boleh berjalan bersamaan")
+    fs.IntVar(&config.maxQueue, "max-queue", 100, "Jumlah maksimal job yang mengantre")
+    fs.IntVar(&config.maxConcurrency, "max-concurrency", 1000, "Nilai -c maksimal per job")
+    fs.DurationVar(&config.maxDuration, "max-duration", time.Hour, "Job yang berjalan lebih lama dari ini dihentikan")
+    fs.StringVar(&config.allowHosts, "allow-hosts", "", "Pola host yang boleh ditarget semua job, dipisah koma (token dengan allow_hosts harus lolos keduanya)")
+    fs.StringVar(&config.tokens, "tokens", "", "File JSON berisi API token beserta batas per token (allow_hosts, max_rate, max_concurrency)")
+    fs.Usage = func() {
+        fmt.Fprintf(os.Stderr, "Usage: loadtest server [options]\n\n")
+        fmt.Fprintf(os.Stderr, "Menjalankan loadtest sebagai service: job dikirim lewat HTTP API, diantrekan\n")
+        fmt.Fprintf(os.Stderr, "lalu dijalankan sesuai batas -parallel.\n\n")
+        fmt.Fprintf(os.Stderr, "Options:\n")
+        fs.PrintDefaults()
+    }
+    fs.Parse(args)
+
+    if config.parallel < 1 {
+        fmt.Println("Error: -parallel minimal 1")
+        return 1
+    }
+    binary, err := os.Executable()
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    if err := os.MkdirAll(config.dataDir, 0o755); err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+
+    s := &jobServer{
+        config: config,
+        binary: binary,
+        agents: newAgentRegistry(),
+        jobs:   make(map[string]*Job),
+        queue:  make(chan *Job, config.maxQueue),
+    }
+    if config.tokens != "" {
+        tokens, err := loadTokens(config.tokens)
+        if err != nil {
+            fmt.Printf("Error: %v\n", err)
+            return 1
+        }
+        s.tokens = tokens
+    } else {
+        fmt.Println("⚠️  Tanpa -tokens: API terbuka untuk siapa saja yang bisa mengakses alamat server")
+    }
+    if err := s.load(); err != nil {
+        fmt.Printf("Error: memuat job lama: %v\n", err)
+        return 1
+    }
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+    var workers sync.WaitGroup
+    for i := 0; i < config.parallel; i++ {
+        workers.Add(1)
+        go func() {
+            defer workers.Done()
+            s.work(ctx)
+        }()
+    }
+
+    srv := &http.Server{Addr: config.listen, Handler: s.routes()}
+    go func() {
+        <-ctx.Done()
+        shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+        defer cancel()
+        srv.Shutdown(shutdownCtx)
+    }()
+
+    fmt.Printf("🛰️  Server loadtest di %s (parallel %d, data %s)\n", config.listen, config.parallel, config.dataDir)
+    if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    // Job yang sedang berjalan ikut dihentikan lewat ctx
+    workers.Wait()
+    return 0
+}
+
+func (s *jobServer) routes() http.Handler {
+    mux := http.NewServeMux()
+    mux.HandleFunc("POST /jobs", s.handleSubmit)
+    mux.HandleFunc("GET /jobs", s.handleList)
+    mux.HandleFunc("GET /jobs/{id}", s.handleGet)
+    mux.HandleFunc("DELETE /jobs/{id}", s.handleCancel)
+    mux.HandleFunc("GET /jobs/{id}/report", s.handleFile("report.json", "application/json"))
+    mux.HandleFunc("GET /jobs/{id}/log", s.handleFile("output.log", "text/plain; charset=utf-8"))
+    mux.HandleFunc("GET /agents", s.agents.handleList)
+    mux.HandleFunc("PUT /agents/{group}", s.agents.handleDefine)
+    mux.HandleFunc("POST /agents/{group}/{action}", s.agents.handle)
+
+    // /time dipakai agent untuk -clock-sync, tanpa token karena hanya
+    // berisi jam server
+    root := http.NewServeMux()
+    root.HandleFunc("GET /time", handleTime)
+    root.Handle("/", s.authenticate(mux))
+    return root
+}
+
+func handleTime(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, ServerTime{UnixNano: time.Now().UnixNano()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    enc := json.NewEncoder(w)
+    enc.SetIndent("", "  ")
+    enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+    writeJSON(w, status, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
+
+func (s *jobServer) handleSubmit(w http.ResponseWriter, r *http.Request) {
+    var job Job
+    if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&job); err != nil {
+        writeError(w, http.StatusBadRequest, "definisi job tidak valid: %v", err)
+        return
+    }
+    token := tokenFrom(r)
+    allowHosts, err := s.checkJob(job.Args, token)
+    if err != nil {
+        writeError(w, http.StatusBadRequest, "%v", err)
+        return
+    }
+
+    s.mu.Lock()
+    s.seq++
+    job.ID = fmt.Sprintf("%s-%d", time.Now().UTC().Format("20060102-150405"), s.seq)
+    job.Owner = token.Name
+    job.allowHosts = allowHosts
+    job.Status = jobQueued
+    job.Created = time.Now().UTC()
+    job.ExitCode, job.Error, job.Started, job.Finished = nil, "", nil, nil
+    select {
+    case s.queue <- &job:
+        s.jobs[job.ID] = &job
+    default:
+        s.mu.Unlock()
+        writeError(w, http.StatusServiceUnavailable, "antrean penuh (%d job)", s.config.maxQueue)
+        return
+    }
+    snapshot := job
+    s.mu.Unlock()
+
+    s.save(&snapshot)
+    writeJSON(w, http.StatusCreated, snapshot)
+}
+
+// snapshot salinan job jika ada dan boleh diakses token
+func (s *jobServer) snapshot(id string, token *apiToken) (Job, bool) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    job, ok := s.jobs[id]
+    if !ok || !token.canAccess(job) {
+        return Job{}, false
+    }
+    return *job, true
+}
+
+func (s *jobServer) handleList(w http.ResponseWriter, r *http.Request) {
+    token := tokenFrom(r)
+    s.mu.Lock()
+    jobs := make([]Job, 0, len(s.jobs))
+    for _, job := range s.jobs {
+        if token.canAccess(job) {
+            jobs = append(jobs, *job)
+        }
+    }
+    s.mu.Unlock()
+    sort.Slice(jobs, func(i, j int) bool { return jobs[i].Created.Before(jobs[j].Created) })
+    writeJSON(w, http.StatusOK, jobs)
+}
+
+func (s *jobServer) handleGet(w http.ResponseWriter, r *http.Request) {
+    job, ok := s.snapshot(r.PathValue("id"), tokenFrom(r))
+    if !ok {
+        writeError(w, http.StatusNotFound, "job tidak ditemukan")
+        return
+    }
+    writeJSON(w, http.StatusOK, job)
+}
+
+func (s *jobServer) handleCancel(w http.ResponseWriter, r *http.Request) {
+    s.mu.Lock()
+    job, ok := s.jobs[r.PathValue("id")]
+    if !ok || !tokenFrom(r).canAccess(job) {
+        s.mu.Unlock()
+        writeError(w, http.StatusNotFound, "job tidak ditemukan")
+        return
+    }
+    switch job.Status {
+    case jobQueued:
+        // Job dilewati saat diambil dari antrean
+        job.Status = jobCancelled
+        now := time.Now().UTC()
+        job.Finished = &now
+    case jobRunning:
+        job.cancel()
+    }
+    snapshot := *job
+    s.mu.Unlock()
+
+    s.save(&snapshot)
+    writeJSON(w, http.StatusOK, snapshot)
+}
+
+func (s *jobServer) handleFile(name, contentType string) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        job, ok := s.snapshot(r.PathValue("id"), tokenFrom(r))
+        if !ok {
+            writeError(w, http.StatusNotFound, "job tidak ditemukan")
+            return
+        }
+        data, err := os.ReadFile(filepath.Join(s.config.dataDir, job.ID, name))
+        if err != nil {
+            writeError(w, http.StatusNotFound, "%s belum tersedia (status %s)", name, job.Status)
+            return
+        }
+        w.Header().Set("Content-Type", contentType)
+        w.Write(data)
+    }
+}
+
+// work mengambil job dari antrean sampai server berhenti
+func (s *jobServer) work(ctx context.Context) {
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case job := <-s.queue:
+            s.run(ctx, job)
+        }
+    }
+}
+
+func (s *jobServer) run(ctx context.Context, job *Job) {
+    ctx, cancel := context.WithTimeout(ctx, s.config.maxDuration)
+    defer cancel()
+
+    s.mu.Lock()
+    if job.Status != jobQueued {
+        s.mu.Unlock()
+        return
+    }
+    now := time.Now().UTC()
+    job.Status = jobRunning
+    job.Started = &now
+    job.cancel = cancel
+    snapshot := *job
+    s.mu.Unlock()
+    s.save(&snapshot)
+
+    dir := filepath.Join(s.config.dataDir, job.ID)
+    args := job.Args
+    // Flag tambahan diletakkan di depan agar tidak dianggap argumen posisi
+    if job.allowHosts != "" {
+        args = append([]string{"-allow-hosts", job.allowHosts}, args...)
+    }
+    err := s.exec(ctx, dir, args)
+
+    s.mu.Lock()
+    finished := time.Now().UTC()
+    job.Finished = &finished
+    var exitErr *exec.ExitError
+    switch {
+    case err == nil:
+        job.Status = jobDone
+        code := 0
+        job.ExitCode = &code
+    case errors.Is(ctx.Err(), context.DeadlineExceeded):
+        job.Status = jobFailed
+        job.Error = fmt.Sprintf("dihentikan setelah -max-duration %v", s.config.maxDuration)
+    case ctx.Err() != nil:
+        job.Status = jobCancelled
+    case errors.As(err, &exitErr):
+        job.Status = jobFailed
+        code := exitErr.ExitCode()
+        job.ExitCode = &code
+    default:
+        job.Status = jobFailed
+        job.Error = err.Error()
+    }
+    snapshot = *job
+    s.mu.Unlock()
+    s.save(&snapshot)
+    fmt.Printf("📋 Job %s %s\n", job.ID, job.Status)
+}
+
+// exec menjalankan satu job sebagai proses loadtest dengan output ke
+// direktori job
+func (s *jobServer) exec(ctx context.Context, dir string, args []string) error {
+    if err := os.MkdirAll(dir, 0o755); err != nil {
+        return err
+    }
+    logFile, err := os.Create(filepath.Join(dir, "output.log"))
+    if err != nil {
+        return err
+    }
+    defer logFile.Close()
+
+    // -out harus di depan agar tidak dianggap argumen posisi setelah URL
+    cmdArgs := append([]string{"-out", filepath.Join(dir, "report.json")}, args...)
+    cmd := exec.CommandContext(ctx, s.binary, cmdArgs...)
+    cmd.Env = []string{}
+    for _, name := range serverJobEnv {
+        if value, ok := os.LookupEnv(name); ok {
+            cmd.Env = append(cmd.Env, name+"="+value)
+        }
+    }
+    cmd.Stdout = logFile
+    cmd.Stderr = logFile
+    // SIGINT lebih dulu agar job sempat menjalankan teardown
+    cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+    cmd.WaitDelay = 30 * time.Second
+    return cmd.Run()
+}
+
+// save menulis status job ke job.json agar tetap tersedia setelah restart
+func (s *jobServer) save(job *Job) {
+    dir := filepath.Join(s.config.dataDir, job.ID)
+    if err := os.MkdirAll(dir, 0o755); err != nil {
+        fmt.Printf("⚠️  Menyimpan job %s: %v\n", job.ID, err)
+        return
+    }
+    data, _ := json.MarshalIndent(job, "", "  ")
+    if err := os.WriteFile(filepath.Join(dir, "job.json"), data, 0o644); err != nil {
+        fmt.Printf("⚠️  Menyimpan job %s: %v\n", job.ID, err)
+    }
+}
+
+// load membaca job dari run server sebelumnya. Job yang belum selesai saat
+// server mati ditandai gagal, bukan dijalankan ulang.
+func (s *jobServer) load() error {
+    paths, err := filepath.Glob(filepath.Join(s.config.dataDir, "*", "job.json"))
+    if err != nil {
+        return err
+    }
+    for _, path := range paths {
+        data, err := os.ReadFile(path)
+        if err != nil {
+            return err
+        }
+        var job Job
+        if err := json.Unmarshal(data, &job); err != nil {
+            return fmt.Errorf("%s: %w", path, err)
+        }
+        if job.Status == jobQueued || job.Status == jobRunning {
+            job.Status = jobFailed
+            job.Error = "server berhenti sebelum job selesai"
+            s.save(&job)
+        }
+        s.jobs[job.ID] = &job
+    }
+    return nil
+}
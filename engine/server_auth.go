@@ -0,0 +1,185 @@
+package engine
+
+import (
+    "context"
+    "crypto/subtle"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+    "strings"
+)
+
+// apiToken satu tenant di mode server. Batas kosong atau nol berarti tidak
+// dibatasi selain oleh batas server.
+type apiToken struct {
+    Name           string   `json:"name"`
+    Token          string   `json:"token"`
+    Admin          bool     `json:"admin"`       // Bisa melihat dan membatalkan job semua tenant
+    AllowHosts     []string `json:"allow_hosts"` // Pola host target, contoh "*.staging.example.com" atau "api.test:8080"
+    MaxRate        float64  `json:"max_rate"`    // Rate maksimal (req/s) per job
+    MaxConcurrency int      `json:"max_concurrency"`
+}
+
+// tokenFile format file -tokens
+type tokenFile struct {
+    Tokens []apiToken `json:"tokens"`
+}
+
+func loadTokens(path string) ([]apiToken, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    var file tokenFile
+    if err := json.Unmarshal(data, &file); err != nil {
+        return nil, fmt.Errorf("%s bukan file token yang valid: %w", path, err)
+    }
+    seen := make(map[string]bool)
+    for i, t := range file.Tokens {
+        if t.Name == "" || t.Token == "" {
+            return nil, fmt.Errorf("%s: token ke-%d membutuhkan name dan token", path, i+1)
+        }
+        if seen[t.Name] {
+            return nil, fmt.Errorf("%s: nama token %q dipakai lebih dari sekali", path, t.Name)
+        }
+        seen[t.Name] = true
+    }
+    if len(file.Tokens) == 0 {
+        return nil, fmt.Errorf("%s: tidak ada token", path)
+    }
+    return file.Tokens, nil
+}
+
+type apiTokenKey struct{}
+
+// openAccess dipakai jika server berjalan tanpa -tokens
+var openAccess = &apiToken{Admin: true}
+
+// authenticate mencocokkan header Authorization: Bearer <token>
+func (s *jobServer) authenticate(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        token := openAccess
+        if s.tokens != nil {
+            token = nil
+            given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+            for i := range s.tokens {
+                if ok && subtle.ConstantTimeCompare([]byte(given), []byte(s.tokens[i].Token)) == 1 {
+                    token = &s.tokens[i]
+                }
+            }
+            if token == nil {
+                w.Header().Set("WWW-Authenticate", "Bearer")
+                writeError(w, http.StatusUnauthorized, "token tidak valid")
+                return
+            }
+        }
+        next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiTokenKey{}, token)))
+    })
+}
+
+func tokenFrom(r *http.Request) *apiToken {
+    return r.Context().Value(apiTokenKey{}).(*apiToken)
+}
+
+// canAccess true jika token boleh melihat atau membatalkan job
+func (t *apiToken) canAccess(job *Job) bool {
+    return t.Admin || job.Owner == t.Name
+}
+
+// checkJob memeriksa argumen job terhadap batas server dan batas token, lalu
+// mengembalikan -allow-hosts yang dipaksakan ke proses job (kosong jika tidak ada)
+func (s *jobServer) checkJob(args []string, token *apiToken) (string, error) {
+    if len(args) == 0 {
+        return "", fmt.Errorf("args kosong")
+    }
+    allowHosts := strings.Join(token.AllowHosts, ",")
+    if allowHosts == "" {
+        allowHosts = s.config.allowHosts
+    }
+    for _, arg := range args {
+        if !strings.HasPrefix(arg, "-") {
+            continue
+        }
+        name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+        switch {
+        case !serverAllowedFlags[name]:
+            return "", fmt.Errorf("flag -%s tidak diizinkan di server", name)
+        case name == "allow-hosts" && allowHosts != "":
+            return "", fmt.Errorf("-allow-hosts diatur oleh server")
+        case name == "i-know-what-im-doing" && !token.Admin:
+            return "", fmt.Errorf("-i-know-what-im-doing hanya untuk token admin")
+        }
+    }
+
+    fs := flag.NewFlagSet("job", flag.ContinueOnError)
+    fs.SetOutput(io.Discard)
+    config, err := parseFlags(fs, args)
+    if err != nil {
+        return "", fmt.Errorf("args tidak valid: %w", err)
+    }
+    if config.URL == "" && config.Scenario == "" {
+        return "", fmt.Errorf("URL target kosong")
+    }
+
+    if config.Concurrency < 1 {
+        return "", fmt.Errorf("-c minimal 1")
+    }
+    if config.Concurrency > s.config.maxConcurrency {
+        return "", fmt.Errorf("-c %d melebihi batas server %d", config.Concurrency, s.config.maxConcurrency)
+    }
+    if token.MaxConcurrency > 0 && config.Concurrency > token.MaxConcurrency {
+        return "", fmt.Errorf("-c %d melebihi batas token %s (%d)", config.Concurrency, token.Name, token.MaxConcurrency)
+    }
+    if token.MaxRate > 0 {
+        if err := checkMaxRate(config, token.MaxRate); err != nil {
+            return "", fmt.Errorf("token %s: %w", token.Name, err)
+        }
+    }
+    if allowHosts == "" {
+        return "", nil
+    }
+    hosts, err := jobHosts(config)
+    if err != nil {
+        return "", err
+    }
+    for _, host := range hosts {
+        if len(token.AllowHosts) > 0 && !hostAllowed(token.AllowHosts, host) {
+            return "", fmt.Errorf("token %s tidak diizinkan menarget %s", token.Name, host)
+        }
+        if s.config.allowHosts != "" && !hostAllowed(strings.Split(s.config.allowHosts, ","), host) {
+            return "", fmt.Errorf("target %s tidak ada di -allow-hosts server", host)
+        }
+    }
+    return allowHosts, nil
+}
+
+// checkMaxRate memastikan jadwal job punya rate yang diketahui dan tidak
+// melebihi max. Jadwal closed-loop (-n / -duration tanpa -rate) dan
+// -replay (rate ditentukan isi file) tidak punya batas rate sehingga ditolak.
+func checkMaxRate(config *Config, max float64) error {
+    switch {
+    case config.ReplayFile != "":
+        return fmt.Errorf("dibatasi %.0f req/s, -replay tidak bisa dipakai karena rate-nya mengikuti file", max)
+    case config.Stages != "":
+        stages, err := parseStages(config.Stages)
+        if err != nil {
+            return err
+        }
+        for _, stage := range stages {
+            if stage.Target > max {
+                return fmt.Errorf("stage %.0f req/s melebihi batas %.0f req/s", stage.Target, max)
+            }
+        }
+        return nil
+    case config.Rate > 0:
+        if config.Rate > max {
+            return fmt.Errorf("-rate %.0f melebihi batas %.0f req/s", config.Rate, max)
+        }
+        return nil
+    default:
+        return fmt.Errorf("dibatasi %.0f req/s, job harus memakai -rate atau -stages", max)
+    }
+}
@@ -0,0 +1,329 @@
+package engine
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "sort"
+    "strconv"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// shadowMirror mengirim salinan setiap request ke host shadow (-shadow)
+// tanpa menunggu hasilnya, lalu membandingkan status dan latency dengan
+// target utama. Request shadow dibatasi -shadow-max in-flight; jika penuh
+// salinan dilewati agar shadow yang lambat tidak memperlambat test utama.
+type shadowMirror struct {
+    target *url.URL
+    client *http.Client
+    slots  chan struct{}
+    seed   int64 // -seed, untuk sampling -shadow-diff
+    wg     sync.WaitGroup
+
+    mirrored atomic.Int64
+    dropped  atomic.Int64 // Slot penuh atau body tidak bisa disalin
+    primary  shadowStats
+    shadow   shadowStats
+
+    mu         sync.Mutex
+    mismatches map[string]int64 // "200 → 500" -> jumlah
+    diff       *shadowDiffer    // Opsional, -shadow-diff
+}
+
+// shadowStats distribusi hasil satu sisi, hanya untuk request yang disalin
+// agar kedua sisi dibandingkan pada sampel yang sama
+type shadowStats struct {
+    latency  histogram
+    errors   atomic.Int64
+    mu       sync.Mutex
+    statuses map[int]int64 // 0 = error koneksi/timeout
+}
+
+func (s *shadowStats) record(status int, result Result) {
+    s.latency.addDuration(result.Duration)
+    if result.Err != nil || status >= 400 || status == 0 {
+        s.errors.Add(1)
+    }
+    s.mu.Lock()
+    s.statuses[status]++
+    s.mu.Unlock()
+}
+
+// shadowPair hasil pasangan primary/shadow; sisi yang selesai terakhir
+// membandingkan keduanya
+type shadowPair struct {
+    m       *shadowMirror
+    diff    bool         // Body pasangan ini ikut dibandingkan
+    capture *bodyCapture // Body response utama jika diff
+
+    mu       sync.Mutex
+    done     int
+    statuses [2]int    // Utama, shadow
+    bodies   [2][]byte // Utama, shadow
+}
+
+func newShadowMirror(config *Config) (*shadowMirror, error) {
+    if config.Shadow == "" {
+        return nil, nil
+    }
+    target, err := url.Parse(config.Shadow)
+    if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+        return nil, fmt.Errorf("-shadow %q harus URL http(s) dengan host", config.Shadow)
+    }
+    if config.ShadowMax < 1 {
+        return nil, fmt.Errorf("-shadow-max harus >= 1")
+    }
+    diff, err := newShadowDiffer(config)
+    if err != nil {
+        return nil, err
+    }
+    return &shadowMirror{
+        target:     target,
+        client:     createHTTPClient(config),
+        slots:      make(chan struct{}, config.ShadowMax),
+        seed:       config.Seed,
+        primary:    shadowStats{statuses: make(map[int]int64)},
+        shadow:     shadowStats{statuses: make(map[int]int64)},
+        mismatches: make(map[string]int64),
+        diff:       diff,
+    }, nil
+}
+
+// mirror menyalin req ke host shadow, harus dipanggil sebelum req dikirim
+// karena body disalin lewat GetBody. Nil jika mirror tidak aktif atau
+// salinan dilewati. Sampel -shadow-diff dipilih per requestNum agar
+// berulang dengan -seed yang sama.
+func (m *shadowMirror) mirror(req *http.Request, requestNum int) *shadowPair {
+    if m == nil {
+        return nil
+    }
+    // Context terpisah: request shadow tidak ikut batal saat test selesai
+    clone := req.Clone(context.Background())
+    clone.URL.Scheme, clone.URL.Host = m.target.Scheme, m.target.Host
+    clone.Host = ""
+    if req.GetBody != nil {
+        clone.Body, _ = req.GetBody()
+    } else if req.Body != nil && req.Body != http.NoBody {
+        m.dropped.Add(1)
+        return nil
+    }
+    select {
+    case m.slots <- struct{}{}:
+    default:
+        m.dropped.Add(1)
+        return nil
+    }
+
+    m.mirrored.Add(1)
+    pair := &shadowPair{m: m, diff: m.diff != nil && cacheRand(m.seed, requestNum).Float64() < m.diff.rate}
+    m.wg.Add(1)
+    go func() {
+        defer m.wg.Done()
+        defer func() { <-m.slots }()
+        result, body := doShadow(m.client, clone, pair.diff)
+        m.shadow.record(result.StatusCode, result)
+        pair.finish(1, result.StatusCode, body)
+    }()
+    return pair
+}
+
+// doShadow mengirim salinan request, body response disimpan jika withBody
+func doShadow(client *http.Client, req *http.Request, withBody bool) (Result, []byte) {
+    start := time.Now()
+    resp, err := client.Do(req)
+    if err != nil {
+        return Result{Start: start, Duration: time.Since(start), Err: err}, nil
+    }
+    defer resp.Body.Close()
+    var capture *bodyCapture
+    if withBody {
+        capture = &bodyCapture{ReadCloser: resp.Body}
+        resp.Body = capture
+    }
+    n, _ := drainBody(resp.Body, resp.StatusCode)
+    return Result{Start: start, Duration: time.Since(start), StatusCode: resp.StatusCode, Bytes: n}, capture.body()
+}
+
+// captureBody membungkus body response utama agar isinya bisa dibandingkan
+// dengan shadow. Body dikembalikan apa adanya jika pasangan ini tidak
+// di-diff.
+func (p *shadowPair) captureBody(body io.ReadCloser) io.ReadCloser {
+    if p == nil || !p.diff {
+        return body
+    }
+    p.capture = &bodyCapture{ReadCloser: body}
+    return p.capture
+}
+
+// observe mencatat hasil request utama untuk pasangan ini, dipanggil
+// setelah body selesai dibaca
+func (p *shadowPair) observe(result Result) {
+    if p == nil {
+        return
+    }
+    p.m.primary.record(result.StatusCode, result)
+    p.finish(0, result.StatusCode, p.capture.body())
+}
+
+func (p *shadowPair) finish(side, status int, body []byte) {
+    p.mu.Lock()
+    p.statuses[side], p.bodies[side] = status, body
+    p.done++
+    complete := p.done == 2
+    p.mu.Unlock()
+    if !complete {
+        return
+    }
+    if p.statuses[0] != p.statuses[1] {
+        key := shadowStatusName(p.statuses[0]) + " → " + shadowStatusName(p.statuses[1])
+        p.m.mu.Lock()
+        p.m.mismatches[key]++
+        p.m.mu.Unlock()
+        if p.diff {
+            p.m.diff.skipped.Add(1)
+        }
+        return
+    }
+    if p.diff {
+        p.m.diff.compare(p.bodies[0], p.bodies[1])
+    }
+}
+
+func shadowStatusName(status int) string {
+    if status == 0 {
+        return "error"
+    }
+    return strconv.Itoa(status)
+}
+
+// ShadowReport perbandingan target utama dengan host shadow
+type ShadowReport struct {
+    URL         string            `json:"url"`
+    Mirrored    int64             `json:"mirrored"`
+    Dropped     int64             `json:"dropped"`
+    Primary     ShadowSide        `json:"primary"`
+    Shadow      ShadowSide        `json:"shadow"`
+    Mismatches  int64             `json:"status_mismatches"`
+    MismatchPct float64           `json:"status_mismatch_pct"`
+    Pairs       map[string]int64  `json:"mismatch_pairs,omitempty"` // "200 → 500" -> jumlah
+    Diff        *ShadowDiffReport `json:"body_diff,omitempty"`
+}
+
+// ShadowSide ringkasan satu sisi untuk request yang disalin
+type ShadowSide struct {
+    Requests    int64            `json:"requests"`
+    ErrorRate   float64          `json:"error_rate"`
+    P50Ms       float64          `json:"p50_ms"`
+    P95Ms       float64          `json:"p95_ms"`
+    P99Ms       float64          `json:"p99_ms"`
+    StatusCodes map[string]int64 `json:"status_codes"`
+}
+
+func (s *shadowStats) side() ShadowSide {
+    side := ShadowSide{
+        Requests:    s.latency.count(),
+        P50Ms:       durationMs(s.latency.quantileDuration(0.50)),
+        P95Ms:       durationMs(s.latency.quantileDuration(0.95)),
+        P99Ms:       durationMs(s.latency.quantileDuration(0.99)),
+        StatusCodes: make(map[string]int64),
+    }
+    if side.Requests > 0 {
+        side.ErrorRate = float64(s.errors.Load()) / float64(side.Requests) * 100
+    }
+    s.mu.Lock()
+    for status, n := range s.statuses {
+        side.StatusCodes[shadowStatusName(status)] = n
+    }
+    s.mu.Unlock()
+    return side
+}
+
+// report menunggu request shadow yang masih berjalan lalu merangkum
+// perbandingannya
+func (m *shadowMirror) report() *ShadowReport {
+    if m == nil {
+        return nil
+    }
+    m.wg.Wait()
+    r := &ShadowReport{
+        URL:      m.target.String(),
+        Mirrored: m.mirrored.Load(),
+        Dropped:  m.dropped.Load(),
+        Primary:  m.primary.side(),
+        Shadow:   m.shadow.side(),
+        Diff:     m.diff.report(),
+    }
+    m.mu.Lock()
+    if len(m.mismatches) > 0 {
+        r.Pairs = make(map[string]int64, len(m.mismatches))
+        for pair, n := range m.mismatches {
+            r.Pairs[pair] = n
+            r.Mismatches += n
+        }
+    }
+    m.mu.Unlock()
+    if r.Mirrored > 0 {
+        r.MismatchPct = float64(r.Mismatches) / float64(r.Mirrored) * 100
+    }
+    return r
+}
+
+func (m *shadowMirror) close() {
+    if m != nil {
+        m.client.CloseIdleConnections()
+    }
+}
+
+// ShadowReporter diimplementasikan Requester yang mendukung -shadow
+type ShadowReporter interface {
+    ShadowReport() *ShadowReport
+}
+
+func printShadow(w io.Writer, report *Report) {
+    s := report.Shadow
+    if s == nil {
+        return
+    }
+    fmt.Fprintf(w, "\n🌗 Shadow Traffic (%s):\n", s.URL)
+    fmt.Fprintf(w, "  Disalin: %d, dilewati: %d\n", s.Mirrored, s.Dropped)
+    fmt.Fprintf(w, "  %-9s %9s %8s %9s %9s %9s\n", "", "Requests", "Error %", "p50 ms", "p95 ms", "p99 ms")
+    for _, row := range []struct {
+        name string
+        side ShadowSide
+    }{{"Utama", s.Primary}, {"Shadow", s.Shadow}} {
+        fmt.Fprintf(w, "  %-9s %9d %7.2f%% %9.2f %9.2f %9.2f\n", row.name, row.side.Requests, row.side.ErrorRate, row.side.P50Ms, row.side.P95Ms, row.side.P99Ms)
+    }
+    if s.Primary.P95Ms > 0 {
+        fmt.Fprintf(w, "  Selisih p95 shadow: %+.1f%%\n", (s.Shadow.P95Ms-s.Primary.P95Ms)/s.Primary.P95Ms*100)
+    }
+
+    icon := "✅"
+    if s.Mismatches > 0 {
+        icon = "⚠️ "
+    }
+    fmt.Fprintf(w, "  %s Status berbeda: %d (%.2f%%)\n", icon, s.Mismatches, s.MismatchPct)
+    pairs := make([]string, 0, len(s.Pairs))
+    for pair := range s.Pairs {
+        pairs = append(pairs, pair)
+    }
+    sort.Slice(pairs, func(i, j int) bool {
+        if s.Pairs[pairs[i]] != s.Pairs[pairs[j]] {
+            return s.Pairs[pairs[i]] > s.Pairs[pairs[j]]
+        }
+        return pairs[i] < pairs[j]
+    })
+    for i, pair := range pairs {
+        if i == 5 {
+            fmt.Fprintf(w, "       ... %d pasangan lain\n", len(pairs)-i)
+            break
+        }
+        fmt.Fprintf(w, "       %-18s %d\n", pair, s.Pairs[pair])
+    }
+    if s.Diff != nil {
+        printShadowDiff(w, s.Diff)
+    }
+}
@@ -0,0 +1,311 @@
+package engine
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+    "sync"
+    "sync/atomic"
+)
+
+const (
+    shadowDiffMaxBody  = 1 << 20 // Body lebih besar dari ini tidak dibandingkan
+    shadowDiffMaxPaths = 20      // Perbedaan yang dicatat per pasangan response
+)
+
+// shadowDiffer membandingkan body response utama dan shadow untuk sebagian
+// request (-shadow-diff). Body JSON dinormalisasi (urutan key, format
+// angka dan whitespace diabaikan) sehingga yang dilaporkan hanya perbedaan
+// isi; body lain dibandingkan byte per byte.
+type shadowDiffer struct {
+    rate   float64
+    ignore []string // Path yang diabaikan, index array boleh [*]
+
+    compared  atomic.Int64
+    different atomic.Int64
+    skipped   atomic.Int64 // Status berbeda atau body terlalu besar
+
+    mu    sync.Mutex
+    paths map[string]*ShadowDiffPath
+}
+
+func newShadowDiffer(config *Config) (*shadowDiffer, error) {
+    if config.ShadowDiff == 0 {
+        return nil, nil
+    }
+    if config.ShadowDiff < 0 || config.ShadowDiff > 1 {
+        return nil, fmt.Errorf("-shadow-diff harus antara 0 dan 1")
+    }
+    return &shadowDiffer{rate: config.ShadowDiff, ignore: jsonPaths(config.ShadowIgnore), paths: make(map[string]*ShadowDiffPath)}, nil
+}
+
+// jsonPaths melengkapi path dari flag dengan awalan "$."
+func jsonPaths(paths []string) []string {
+    var out []string
+    for _, path := range paths {
+        if !strings.HasPrefix(path, "$") {
+            path = "$." + path
+        }
+        out = append(out, path)
+    }
+    return out
+}
+
+// bodyCapture menyalin body yang dibaca ke buf, maksimal shadowDiffMaxBody
+type bodyCapture struct {
+    io.ReadCloser
+    buf  bytes.Buffer
+    over bool
+}
+
+func (c *bodyCapture) Read(p []byte) (int, error) {
+    n, err := c.ReadCloser.Read(p)
+    if n > 0 && !c.over {
+        if c.buf.Len()+n > shadowDiffMaxBody {
+            c.over = true
+            c.buf.Reset()
+        } else {
+            c.buf.Write(p[:n])
+        }
+    }
+    return n, err
+}
+
+// body isi body yang tertangkap, nil jika terlalu besar
+func (c *bodyCapture) body() []byte {
+    if c == nil || c.over {
+        return nil
+    }
+    return append([]byte{}, c.buf.Bytes()...)
+}
+
+// compare membandingkan satu pasangan response dengan status yang sama
+func (d *shadowDiffer) compare(primary, shadow []byte) {
+    if primary == nil || shadow == nil {
+        d.skipped.Add(1)
+        return
+    }
+    d.compared.Add(1)
+    diffs := diffPaths(primary, shadow, d.ignore)
+    if len(diffs) == 0 {
+        return
+    }
+    d.different.Add(1)
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    for _, diff := range diffs {
+        p := d.paths[diff.path]
+        if p == nil {
+            p = &ShadowDiffPath{Path: diff.path, Example: diff.String()}
+            d.paths[diff.path] = p
+        }
+        p.Count++
+    }
+}
+
+var arrayIndexPattern = regexp.MustCompile(`\[\d+\]`)
+
+// diffPaths perbedaan dua body dengan index array diganti [*], satu per
+// path, tanpa path yang diabaikan
+func diffPaths(a, b []byte, ignore []string) []jsonDiff {
+    var out []jsonDiff
+    seen := make(map[string]bool)
+    for _, diff := range diffBodies(a, b) {
+        diff.path = arrayIndexPattern.ReplaceAllString(diff.path, "[*]")
+        if ignoredPath(ignore, diff.path) || seen[diff.path] {
+            continue
+        }
+        seen[diff.path] = true
+        out = append(out, diff)
+    }
+    return out
+}
+
+// ignoredPath true jika path sama dengan, atau berada di dalam, salah satu
+// path yang diabaikan
+func ignoredPath(ignore []string, path string) bool {
+    for _, prefix := range ignore {
+        if path == prefix || strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"[") {
+            return true
+        }
+    }
+    return false
+}
+
+// jsonDiff satu perbedaan nilai pada path tertentu
+type jsonDiff struct {
+    path            string
+    primary, shadow string // Kosong jika field tidak ada di sisi itu
+}
+
+func (d jsonDiff) String() string {
+    show := func(v string) string {
+        if v == "" {
+            return "(tidak ada)"
+        }
+        return truncate(v, 40)
+    }
+    return show(d.primary) + " → " + show(d.shadow)
+}
+
+// diffBodies perbedaan semantik dua body. Jika salah satu bukan JSON,
+// perbedaan dilaporkan pada path $ saja.
+func diffBodies(primary, shadow []byte) []jsonDiff {
+    a, errA := decodeJSONBody(primary)
+    b, errB := decodeJSONBody(shadow)
+    if errA != nil || errB != nil {
+        if bytes.Equal(primary, shadow) {
+            return nil
+        }
+        return []jsonDiff{{path: "$", primary: fmt.Sprintf("%d byte", len(primary)), shadow: fmt.Sprintf("%d byte", len(shadow))}}
+    }
+    var diffs []jsonDiff
+    diffJSON("$", a, b, &diffs)
+    return diffs
+}
+
+func decodeJSONBody(body []byte) (any, error) {
+    dec := json.NewDecoder(bytes.NewReader(body))
+    dec.UseNumber()
+    var v any
+    if err := dec.Decode(&v); err != nil {
+        return nil, err
+    }
+    if _, err := dec.Token(); err != io.EOF {
+        return nil, fmt.Errorf("data setelah nilai JSON")
+    }
+    return v, nil
+}
+
+func diffJSON(path string, a, b any, diffs *[]jsonDiff) {
+    if len(*diffs) >= shadowDiffMaxPaths {
+        return
+    }
+    switch av := a.(type) {
+    case map[string]any:
+        if bv, ok := b.(map[string]any); ok {
+            keys := make([]string, 0, len(av)+len(bv))
+            for k := range av {
+                keys = append(keys, k)
+            }
+            for k := range bv {
+                if _, ok := av[k]; !ok {
+                    keys = append(keys, k)
+                }
+            }
+            sort.Strings(keys)
+            for _, k := range keys {
+                va, inA := av[k]
+                vb, inB := bv[k]
+                child := path + "." + k
+                switch {
+                case !inA:
+                    *diffs = append(*diffs, jsonDiff{path: child, shadow: jsonText(vb)})
+                case !inB:
+                    *diffs = append(*diffs, jsonDiff{path: child, primary: jsonText(va)})
+                default:
+                    diffJSON(child, va, vb, diffs)
+                }
+            }
+            return
+        }
+    case []any:
+        if bv, ok := b.([]any); ok {
+            for i := 0; i < max(len(av), len(bv)); i++ {
+                child := path + "[" + strconv.Itoa(i) + "]"
+                switch {
+                case i >= len(av):
+                    *diffs = append(*diffs, jsonDiff{path: child, shadow: jsonText(bv[i])})
+                case i >= len(bv):
+                    *diffs = append(*diffs, jsonDiff{path: child, primary: jsonText(av[i])})
+                default:
+                    diffJSON(child, av[i], bv[i], diffs)
+                }
+            }
+            return
+        }
+    case json.Number:
+        // 1, 1.0 dan 1e0 dianggap sama
+        if bv, ok := b.(json.Number); ok {
+            fa, errA := av.Float64()
+            fb, errB := bv.Float64()
+            if errA == nil && errB == nil && fa == fb {
+                return
+            }
+        }
+    default:
+        if a == b {
+            return
+        }
+    }
+    *diffs = append(*diffs, jsonDiff{path: path, primary: jsonText(a), shadow: jsonText(b)})
+}
+
+func jsonText(v any) string {
+    data, _ := json.Marshal(v)
+    return string(data)
+}
+
+// ShadowDiffReport hasil perbandingan body response (-shadow-diff)
+type ShadowDiffReport struct {
+    SampleRate   float64          `json:"sample_rate"`
+    Compared     int64            `json:"compared"`
+    Different    int64            `json:"different"`
+    DifferentPct float64          `json:"different_pct"`
+    Skipped      int64            `json:"skipped"` // Status berbeda atau body > 1 MiB
+    Paths        []ShadowDiffPath `json:"paths,omitempty"`
+}
+
+// ShadowDiffPath path JSON yang berbeda dan jumlah response yang terkena
+type ShadowDiffPath struct {
+    Path    string `json:"path"`
+    Count   int64  `json:"count"`
+    Example string `json:"example,omitempty"` // Nilai utama → shadow pertama yang ditemukan
+}
+
+func (d *shadowDiffer) report() *ShadowDiffReport {
+    if d == nil {
+        return nil
+    }
+    r := &ShadowDiffReport{
+        SampleRate: d.rate,
+        Compared:   d.compared.Load(),
+        Different:  d.different.Load(),
+        Skipped:    d.skipped.Load(),
+    }
+    if r.Compared > 0 {
+        r.DifferentPct = float64(r.Different) / float64(r.Compared) * 100
+    }
+    d.mu.Lock()
+    for _, p := range d.paths {
+        r.Paths = append(r.Paths, *p)
+    }
+    d.mu.Unlock()
+    sort.Slice(r.Paths, func(i, j int) bool {
+        if r.Paths[i].Count != r.Paths[j].Count {
+            return r.Paths[i].Count > r.Paths[j].Count
+        }
+        return r.Paths[i].Path < r.Paths[j].Path
+    })
+    return r
+}
+
+func printShadowDiff(w io.Writer, d *ShadowDiffReport) {
+    icon := "✅"
+    if d.Different > 0 {
+        icon = "⚠️ "
+    }
+    fmt.Fprintf(w, "  %s Body berbeda: %d dari %d yang dibandingkan (%.2f%%), dilewati %d\n", icon, d.Different, d.Compared, d.DifferentPct, d.Skipped)
+    for i, p := range d.Paths {
+        if i == 10 {
+            fmt.Fprintf(w, "       ... %d path lain\n", len(d.Paths)-i)
+            break
+        }
+        fmt.Fprintf(w, "       %-32s %6d  %s\n", truncate(p.Path, 32), p.Count, p.Example)
+    }
+}
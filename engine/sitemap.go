@@ -0,0 +1,212 @@
+package engine
+
+import (
+    "bufio"
+    "bytes"
+    "compress/gzip"
+    "context"
+    "encoding/xml"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+)
+
+const (
+    sitemapMaxBytes = 50 << 20 // Batas ukuran sitemap (tanpa kompresi) sesuai protokol sitemap
+    sitemapMaxFiles = 100      // Sitemap yang diambil dari sitemap index atau robots.txt
+    sitemapPriority = 0.5      // Bobot URL tanpa <priority>, default protokol sitemap
+    sitemapMaxPhase = 50       // Di atas jumlah URL ini latency per path tidak masuk tabel fase
+)
+
+// sitemapDoc isi sitemap: <urlset> berisi URL halaman, <sitemapindex>
+// berisi URL sitemap lain
+type sitemapDoc struct {
+    XMLName xml.Name
+    URLs    []struct {
+        Loc      string `xml:"loc"`
+        Priority string `xml:"priority"`
+    } `xml:"url"`
+    Sitemaps []struct {
+        Loc string `xml:"loc"`
+    } `xml:"sitemap"`
+}
+
+// sitemapOrigin URL target default untuk -from-sitemap tanpa URL: skema dan
+// host sitemap
+func sitemapOrigin(sitemap string) string {
+    u, err := url.Parse(sitemap)
+    if err != nil || u.Host == "" {
+        return ""
+    }
+    return u.Scheme + "://" + u.Host + "/"
+}
+
+// loadSitemap mengubah sitemap (-from-sitemap) menjadi mix request dengan
+// bobot dari <priority>. Sitemap index dan robots.txt (baris Sitemap:)
+// diikuti satu tingkat, sitemap di host lain harus lolos hostGuard. Hanya
+// URL di host target yang dipakai, difilter -sitemap-match jika diisi.
+func loadSitemap(config *Config, target *url.URL) (*requestMix, error) {
+    var match *regexp.Regexp
+    if config.SitemapMatch != "" {
+        var err error
+        if match, err = regexp.Compile(config.SitemapMatch); err != nil {
+            return nil, fmt.Errorf("-sitemap-match tidak valid: %w", err)
+        }
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+    defer cancel()
+
+    // nested true untuk sitemap dari sitemap index, index di dalamnya tidak
+    // diikuti lagi agar index yang saling merujuk tidak berputar
+    type sitemapRef struct {
+        loc    string
+        nested bool
+    }
+    queue := []sitemapRef{{loc: config.Sitemap}}
+    if strings.HasSuffix(strings.ToLower(config.Sitemap), "/robots.txt") {
+        sitemaps, err := robotsSitemaps(ctx, config.Sitemap)
+        if err != nil {
+            return nil, err
+        }
+        if len(sitemaps) == 0 {
+            return nil, fmt.Errorf("robots.txt %s tidak memuat baris Sitemap:", config.Sitemap)
+        }
+        queue = queue[:0]
+        for _, loc := range sitemaps {
+            queue = append(queue, sitemapRef{loc: loc})
+        }
+    }
+
+    guard := newHostGuard(config)
+    var entries []mixEntry
+    seen := make(map[string]bool)
+    var otherHost, filtered, fetched int
+    for len(queue) > 0 && fetched < sitemapMaxFiles {
+        ref := queue[0]
+        queue = queue[1:]
+        if u, err := url.Parse(ref.loc); err == nil && !guard.allowed(u.Host) {
+            continue
+        }
+        doc, err := fetchSitemap(ctx, ref.loc)
+        if err != nil {
+            return nil, err
+        }
+        fetched++
+        if doc.XMLName.Local == "sitemapindex" && !ref.nested {
+            for _, s := range doc.Sitemaps {
+                queue = append(queue, sitemapRef{loc: strings.TrimSpace(s.Loc), nested: true})
+            }
+            continue
+        }
+        for _, entry := range doc.URLs {
+            u, err := url.Parse(strings.TrimSpace(entry.Loc))
+            if err != nil || u.Host == "" {
+                continue
+            }
+            if !strings.EqualFold(u.Host, target.Host) {
+                otherHost++
+                continue
+            }
+            path := u.Path
+            if path == "" {
+                path = "/"
+            }
+            key := path + "?" + u.RawQuery
+            if seen[key] {
+                continue
+            }
+            seen[key] = true
+            if match != nil && !match.MatchString(u.RequestURI()) {
+                filtered++
+                continue
+            }
+            weight, err := strconv.ParseFloat(strings.TrimSpace(entry.Priority), 64)
+            if err != nil || weight <= 0 || weight > 1 {
+                weight = sitemapPriority
+            }
+            entries = append(entries, mixEntry{path: path, query: u.RawQuery, weight: weight})
+        }
+    }
+    if len(queue) > 0 {
+        fmt.Fprintf(config.out, "⚠️  Hanya %d sitemap pertama yang diambil, %d sisanya dilewati\n", sitemapMaxFiles, len(queue))
+    }
+    if otherHost > 0 {
+        fmt.Fprintf(config.out, "⚠️  %d URL sitemap dilewati karena host-nya bukan %s\n", otherHost, target.Host)
+    }
+    if len(entries) == 0 {
+        if filtered > 0 {
+            return nil, fmt.Errorf("tidak ada URL sitemap yang cocok dengan -sitemap-match %q", config.SitemapMatch)
+        }
+        return nil, fmt.Errorf("sitemap %s tidak memuat URL untuk host %s", config.Sitemap, target.Host)
+    }
+    mix := newRequestMix(entries)
+    mix.noPhases = len(entries) > sitemapMaxPhase
+    return mix, nil
+}
+
+// fetchSitemap mengambil dan mem-parse satu sitemap XML, termasuk yang
+// dikompres gzip (.xml.gz)
+func fetchSitemap(ctx context.Context, loc string) (*sitemapDoc, error) {
+    data, err := fetchSitemapFile(ctx, loc)
+    if err != nil {
+        return nil, err
+    }
+    if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+        zr, err := gzip.NewReader(bytes.NewReader(data))
+        if err != nil {
+            return nil, fmt.Errorf("sitemap %s: %w", loc, err)
+        }
+        if data, err = io.ReadAll(io.LimitReader(zr, sitemapMaxBytes)); err != nil {
+            return nil, fmt.Errorf("sitemap %s: %w", loc, err)
+        }
+    }
+    var doc sitemapDoc
+    if err := xml.Unmarshal(data, &doc); err != nil {
+        return nil, fmt.Errorf("sitemap %s bukan XML yang valid: %w", loc, err)
+    }
+    if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+        return nil, fmt.Errorf("sitemap %s: elemen root <%s>, seharusnya <urlset> atau <sitemapindex>", loc, doc.XMLName.Local)
+    }
+    return &doc, nil
+}
+
+func fetchSitemapFile(ctx context.Context, loc string) ([]byte, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, loc, nil)
+    if err != nil {
+        return nil, err
+    }
+    resp, err := http.DefaultClient.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("ambil sitemap: %w", err)
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("ambil %s: %s", loc, resp.Status)
+    }
+    return io.ReadAll(io.LimitReader(resp.Body, sitemapMaxBytes))
+}
+
+// robotsSitemaps URL sitemap dari baris "Sitemap:" di robots.txt
+func robotsSitemaps(ctx context.Context, loc string) ([]string, error) {
+    data, err := fetchSitemapFile(ctx, loc)
+    if err != nil {
+        return nil, err
+    }
+    var sitemaps []string
+    scanner := bufio.NewScanner(bytes.NewReader(data))
+    for scanner.Scan() {
+        key, value, found := strings.Cut(scanner.Text(), ":")
+        if found && strings.EqualFold(strings.TrimSpace(key), "sitemap") {
+            if value = strings.TrimSpace(value); value != "" {
+                sitemaps = append(sitemaps, value)
+            }
+        }
+    }
+    return sitemaps, scanner.Err()
+}
@@ -0,0 +1,144 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "net"
+    "sort"
+    "sync"
+)
+
+// sourceStats hasil request per IP sumber. IP diambil dari alamat lokal
+// socket yang benar-benar dipakai (bukan header seperti X-Forwarded-For),
+// jadi yang dilaporkan adalah IP yang terlihat oleh target atau NAT di
+// depannya.
+type sourceStats struct {
+    mu      sync.Mutex
+    sources map[string]*sourceCounter
+}
+
+type sourceCounter struct {
+    conns     int64
+    requests  int64
+    errors    int64
+    throttled int64 // 429 dan 503
+}
+
+// noSource kunci untuk request yang gagal sebelum mendapat koneksi
+const noSource = "-"
+
+func sourceIP(addr net.Addr) string {
+    if addr == nil {
+        return noSource
+    }
+    host, _, err := net.SplitHostPort(addr.String())
+    if err != nil {
+        return addr.String()
+    }
+    return host
+}
+
+func (s *sourceStats) counter(ip string) *sourceCounter {
+    if s.sources == nil {
+        s.sources = make(map[string]*sourceCounter)
+    }
+    c := s.sources[ip]
+    if c == nil {
+        c = &sourceCounter{}
+        s.sources[ip] = c
+    }
+    return c
+}
+
+// dialed mencatat koneksi baru dari alamat lokal addr
+func (s *sourceStats) dialed(addr net.Addr) {
+    s.mu.Lock()
+    s.counter(sourceIP(addr)).conns++
+    s.mu.Unlock()
+}
+
+// record mencatat hasil request yang dikirim lewat koneksi dengan alamat
+// lokal addr (nil jika request gagal sebelum mendapat koneksi)
+func (s *sourceStats) record(addr net.Addr, result Result) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    c := s.counter(sourceIP(addr))
+    c.requests++
+    if result.Err != nil || result.StatusCode >= 400 {
+        c.errors++
+    }
+    if result.StatusCode == 429 || result.StatusCode == 503 {
+        c.throttled++
+    }
+}
+
+// SourceReport hasil request dari satu IP sumber
+type SourceReport struct {
+    IP          string  `json:"ip"` // "-" untuk request yang gagal sebelum terhubung
+    Connections int64   `json:"connections"`
+    Requests    int64   `json:"requests"`
+    Errors      int64   `json:"errors"`
+    Throttled   int64   `json:"throttled"` // Response 429 atau 503
+    ErrorRate   float64 `json:"error_rate"`
+    // Suspect error rate sumber ini jauh di atas sumber lain, tanda WAF atau
+    // rate limiter membatasi IP tertentu
+    Suspect bool `json:"suspect,omitempty"`
+}
+
+// report daftar sumber diurutkan menurut IP. Sumber ditandai suspect jika
+// error rate-nya minimal 5 poin dan dua kali di atas gabungan sumber lain.
+func (s *sourceStats) report() []SourceReport {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    var out []SourceReport
+    var requests, errors int64
+    for ip, c := range s.sources {
+        if c.requests == 0 && ip == noSource {
+            continue
+        }
+        r := SourceReport{IP: ip, Connections: c.conns, Requests: c.requests, Errors: c.errors, Throttled: c.throttled}
+        if c.requests > 0 {
+            r.ErrorRate = float64(c.errors) / float64(c.requests) * 100
+        }
+        requests += c.requests
+        errors += c.errors
+        out = append(out, r)
+    }
+    sort.Slice(out, func(i, j int) bool { return out[i].IP < out[j].IP })
+
+    for i := range out {
+        r := &out[i]
+        others := requests - r.Requests
+        if r.IP == noSource || r.Requests < 20 || others < 20 {
+            continue
+        }
+        otherRate := float64(errors-r.Errors) / float64(others) * 100
+        r.Suspect = r.ErrorRate >= otherRate+5 && r.ErrorRate >= otherRate*2
+    }
+    return out
+}
+
+func printSources(w io.Writer, sources []SourceReport) {
+    if len(sources) == 0 {
+        return
+    }
+    if len(sources) == 1 {
+        s := sources[0]
+        fmt.Fprintf(w, "  IP sumber:             %s (error %.1f%%, throttled %d)\n", s.IP, s.ErrorRate, s.Throttled)
+        return
+    }
+    fmt.Fprintf(w, "  IP sumber:\n")
+    fmt.Fprintf(w, "     %-39s %7s %9s %8s %9s\n", "IP", "Koneksi", "Requests", "Error %", "429/503")
+    for _, s := range sources {
+        icon := "  "
+        if s.Suspect {
+            icon = "⚠️ "
+        }
+        fmt.Fprintf(w, "  %s %-39s %7d %9d %7.1f%% %9d\n", icon, s.IP, s.Connections, s.Requests, s.ErrorRate, s.Throttled)
+    }
+    for _, s := range sources {
+        if s.Suspect {
+            fmt.Fprintf(w, "  ⚠️  Error rate %s jauh di atas sumber lain, kemungkinan dibatasi WAF/rate limiter dan membuat hasil bias\n", s.IP)
+        }
+    }
+}
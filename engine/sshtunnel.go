@@ -0,0 +1,121 @@
+package engine
+
+import (
+    "fmt"
+    "net"
+    "net/url"
+    "os"
+    "os/exec"
+    "strings"
+    "time"
+)
+
+// sshTunnel dynamic port forwarding (SOCKS5) lewat binary ssh sistem,
+// sehingga konfigurasi ~/.ssh/config, key dan ssh-agent tetap berlaku
+type sshTunnel struct {
+    cmd  *exec.Cmd
+    addr string
+    done chan error
+}
+
+// startSSHTunnel menjalankan 'ssh -N -D' ke target (user@host[:port]) dan
+// menunggu sampai port SOCKS lokal siap
+func startSSHTunnel(target, jump string) (*sshTunnel, error) {
+    addr, err := freeLocalAddr()
+    if err != nil {
+        return nil, err
+    }
+
+    args := []string{"-N", "-D", addr,
+        "-o", "ExitOnForwardFailure=yes",
+        "-o", "ServerAliveInterval=15",
+    }
+    if jump != "" {
+        args = append(args, "-J", jump)
+    }
+    // user@host:port -> ssh -p port user@host
+    host := target
+    hostPart := target[strings.LastIndex(target, "@")+1:]
+    if h, port, err := net.SplitHostPort(hostPart); err == nil {
+        host = strings.TrimSuffix(target, hostPart) + h
+        args = append(args, "-p", port)
+    }
+    args = append(args, host)
+
+    cmd := exec.Command("ssh", args...)
+    cmd.Stdin = os.Stdin // Untuk prompt passphrase / konfirmasi host key
+    cmd.Stdout = os.Stdout
+    cmd.Stderr = os.Stderr
+    if err := cmd.Start(); err != nil {
+        return nil, fmt.Errorf("menjalankan ssh: %w", err)
+    }
+
+    tunnel := &sshTunnel{cmd: cmd, addr: addr, done: make(chan error, 1)}
+    go func() { tunnel.done <- cmd.Wait() }()
+
+    // Tunggu port SOCKS siap, atau ssh keluar lebih dulu
+    deadline := time.Now().Add(60 * time.Second)
+    for time.Now().Before(deadline) {
+        select {
+        case err := <-tunnel.done:
+            return nil, fmt.Errorf("ssh ke %s berhenti sebelum tunnel siap: %v", target, err)
+        default:
+        }
+        if conn, err := net.DialTimeout("tcp", addr, time.Second); err == nil {
+            conn.Close()
+            return tunnel, nil
+        }
+        time.Sleep(200 * time.Millisecond)
+    }
+    tunnel.Close()
+    return nil, fmt.Errorf("timeout menunggu tunnel SSH ke %s", target)
+}
+
+// freeLocalAddr mencari port TCP kosong di localhost
+func freeLocalAddr() (string, error) {
+    l, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        return "", err
+    }
+    defer l.Close()
+    return l.Addr().String(), nil
+}
+
+func (t *sshTunnel) Close() error {
+    select {
+    case <-t.done:
+        return nil
+    default:
+    }
+    t.cmd.Process.Signal(os.Interrupt)
+    select {
+    case <-t.done:
+    case <-time.After(5 * time.Second):
+        t.cmd.Process.Kill()
+        <-t.done
+    }
+    return nil
+}
+
+// validateProxyConfig memeriksa kombinasi opsi proxy/tunnel
+func validateProxyConfig(config *Config) error {
+    if config.SSHTunnel != "" && config.Proxy != "" {
+        return fmt.Errorf("-ssh-tunnel tidak bisa dipakai bersama -proxy")
+    }
+    if config.Proxy != "" {
+        proxyURL, err := url.Parse(config.Proxy)
+        if err != nil {
+            return fmt.Errorf("URL proxy tidak valid: %w", err)
+        }
+        if config.TunnelBench && proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+            return fmt.Errorf("-tunnel-bench membutuhkan HTTP proxy (CONNECT), bukan %s", proxyURL.Scheme)
+        }
+    }
+    if config.TunnelBench && config.SSHTunnel != "" {
+        return fmt.Errorf("-tunnel-bench membutuhkan HTTP proxy, tidak bisa dipakai dengan -ssh-tunnel")
+    }
+    if config.Preconnect && (config.Proxy != "" || config.SSHTunnel != "") {
+        return fmt.Errorf("-preconnect belum didukung bersama proxy atau tunnel")
+    }
+    return nil
+}
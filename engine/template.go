@@ -0,0 +1,128 @@
+package engine
+
+import (
+    "fmt"
+    mathrand "math/rand/v2"
+    "os"
+    "strings"
+    "sync"
+    "text/template"
+    "time"
+)
+
+// templateData nilai yang bisa dipakai di template per request, contoh
+// "user-{{.N}}"
+type templateData struct {
+    N    int               // Nomor request, mulai dari 0
+    VU   int               // Nomor worker (virtual user) pada skenario
+    Vars map[string]string // Variabel hasil extract pada skenario
+    // Status code step sebelumnya (pada until: step itu sendiri), untuk
+    // kondisi if/until pada skenario
+    Status int
+
+    rng *mathrand.Rand // Sumber acak fungsi template untuk request ini
+}
+
+// newTemplateData membuat data template untuk request ke-requestNum. Dengan
+// seed bukan nol, nilai acak hanya bergantung pada seed dan nomor request
+// sehingga sama di setiap run walau urutan eksekusi worker berbeda.
+func newTemplateData(requestNum int, seed int64) templateData {
+    var src mathrand.Source
+    if seed != 0 {
+        src = mathrand.NewPCG(uint64(seed), uint64(requestNum))
+    } else {
+        src = mathrand.NewPCG(mathrand.Uint64(), mathrand.Uint64())
+    }
+    return templateData{N: requestNum, rng: mathrand.New(src)}
+}
+
+// templateState diikat ke satu salinan template agar fungsi template memakai
+// sumber acak request yang sedang di-render
+type templateState struct {
+    rng *mathrand.Rand
+}
+
+func (s *templateState) funcs() template.FuncMap {
+    return template.FuncMap{
+        "uuid": func() string {
+            var b [16]byte
+            for i := range b {
+                b[i] = byte(s.rng.UintN(256))
+            }
+            b[6] = b[6]&0x0f | 0x40 // Versi 4
+            b[8] = b[8]&0x3f | 0x80 // Variant RFC 4122
+            return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+        },
+        // randInt angka acak min..max (inklusif)
+        "randInt": func(min, max int) int {
+            if max <= min {
+                return min
+            }
+            return min + s.rng.IntN(max-min+1)
+        },
+        // randString string alfanumerik acak sepanjang n
+        "randString": func(n int) string {
+            const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+            b := make([]byte, n)
+            for i := range b {
+                b[i] = letters[s.rng.IntN(len(letters))]
+            }
+            return string(b)
+        },
+        "unix": func() int64 { return time.Now().Unix() },
+        "now":  func() string { return time.Now().UTC().Format(time.RFC3339) },
+        // env nilai environment variable, agar token tidak ditulis ke file skenario
+        "env": os.Getenv,
+    }
+}
+
+// valueTemplate string yang boleh berisi template. String tanpa "{{" dipakai
+// apa adanya tanpa biaya render.
+type valueTemplate struct {
+    raw string
+    // Salinan template beserta state-nya, karena fungsi template terikat ke
+    // state dan render berjalan bersamaan di banyak worker
+    pool sync.Pool
+}
+
+type boundTemplate struct {
+    tmpl  *template.Template
+    state *templateState
+}
+
+func parseValueTemplate(name, raw string) (*valueTemplate, error) {
+    v := &valueTemplate{raw: raw}
+    if !strings.Contains(raw, "{{") {
+        return v, nil
+    }
+    parse := func() (*boundTemplate, error) {
+        state := &templateState{}
+        tmpl, err := template.New(name).Funcs(state.funcs()).Option("missingkey=error").Parse(raw)
+        return &boundTemplate{tmpl: tmpl, state: state}, err
+    }
+    bound, err := parse()
+    if err != nil {
+        return nil, fmt.Errorf("template %s tidak valid: %w", name, err)
+    }
+    v.pool.Put(bound)
+    v.pool.New = func() any {
+        bound, _ := parse()
+        return bound
+    }
+    return v, nil
+}
+
+func (v *valueTemplate) render(data templateData) (string, error) {
+    if v.pool.New == nil {
+        return v.raw, nil
+    }
+    bound := v.pool.Get().(*boundTemplate)
+    defer v.pool.Put(bound)
+
+    bound.state.rng = data.rng
+    var sb strings.Builder
+    if err := bound.tmpl.Execute(&sb, data); err != nil {
+        return "", err
+    }
+    return sb.String(), nil
+}
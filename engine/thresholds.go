@@ -0,0 +1,155 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// Threshold satu kriteria lulus/gagal (SLO) terhadap metrik hasil test,
+// contoh: avg<500ms, error_rate<1%, rps>=100
+type Threshold struct {
+    Metric string
+    Op     string
+    Value  float64
+    Raw    string
+}
+
+// ThresholdResult hasil evaluasi satu threshold
+type ThresholdResult struct {
+    Threshold string  `json:"threshold"`
+    Actual    float64 `json:"actual"`
+    Passed    bool    `json:"passed"`
+    NoData    bool    `json:"no_data,omitempty"` // Tidak ada request selesai, threshold dianggap gagal
+}
+
+// thresholdMetrics memetakan nama metrik ke nilainya di Report. Metrik
+// latency dalam milidetik, rate dalam persen.
+var thresholdMetrics = map[string]func(r *Report) float64{
+    "avg":          func(r *Report) float64 { return r.AvgLatencyMs },
+    "min":          func(r *Report) float64 { return r.MinLatencyMs },
+    "max":          func(r *Report) float64 { return r.MaxLatencyMs },
+    "p50":          func(r *Report) float64 { return r.P50LatencyMs },
+    "p90":          func(r *Report) float64 { return r.P90LatencyMs },
+    "p95":          func(r *Report) float64 { return r.P95LatencyMs },
+    "p99":          func(r *Report) float64 { return r.P99LatencyMs },
+    "rps":          func(r *Report) float64 { return r.RPS },
+    "error_rate":   func(r *Report) float64 { return r.ErrorRate },
+    "success_rate": func(r *Report) float64 { return r.SuccessRate },
+}
+
+// parseThresholds membaca daftar threshold dipisah koma
+func parseThresholds(value string) ([]Threshold, error) {
+    var thresholds []Threshold
+    for _, part := range strings.Split(value, ",") {
+        part = strings.TrimSpace(part)
+        if part == "" {
+            continue
+        }
+        t, err := parseThreshold(part)
+        if err != nil {
+            return nil, err
+        }
+        thresholds = append(thresholds, t)
+    }
+    return thresholds, nil
+}
+
+func parseThreshold(expr string) (Threshold, error) {
+    // Operator dua karakter dicek lebih dulu
+    for _, op := range []string{"<=", ">=", "<", ">"} {
+        metric, rawValue, found := strings.Cut(expr, op)
+        if !found {
+            continue
+        }
+        metric = strings.ToLower(strings.TrimSpace(metric))
+        if _, ok := thresholdMetrics[metric]; !ok {
+            return Threshold{}, fmt.Errorf("metrik threshold %q tidak dikenal", metric)
+        }
+        value, err := parseThresholdValue(strings.TrimSpace(rawValue))
+        if err != nil {
+            return Threshold{}, fmt.Errorf("threshold %q: %v", expr, err)
+        }
+        return Threshold{Metric: metric, Op: op, Value: value, Raw: expr}, nil
+    }
+    return Threshold{}, fmt.Errorf("threshold %q tidak valid (contoh: avg<500ms, error_rate<1%%)", expr)
+}
+
+// parseThresholdValue menerima durasi (dikonversi ke ms), persen, atau angka biasa
+func parseThresholdValue(value string) (float64, error) {
+    if strings.HasSuffix(value, "%") {
+        return strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+    }
+    if v, err := strconv.ParseFloat(value, 64); err == nil {
+        return v, nil
+    }
+    d, err := time.ParseDuration(value)
+    if err != nil {
+        return 0, fmt.Errorf("nilai %q tidak valid", value)
+    }
+    return durationMs(d), nil
+}
+
+func (t Threshold) Evaluate(report *Report) ThresholdResult {
+    // Tanpa request semua metrik bernilai 0 dan akan meloloskan threshold
+    // seperti p95<500ms, padahal target tidak pernah diuji
+    if report.TotalRequests == 0 {
+        return ThresholdResult{Threshold: t.Raw, NoData: true}
+    }
+    actual := thresholdMetrics[t.Metric](report)
+    var passed bool
+    switch t.Op {
+    case "<":
+        passed = actual < t.Value
+    case "<=":
+        passed = actual <= t.Value
+    case ">":
+        passed = actual > t.Value
+    case ">=":
+        passed = actual >= t.Value
+    }
+    return ThresholdResult{Threshold: t.Raw, Actual: actual, Passed: passed}
+}
+
+// actual nilai metrik untuk pesan, "-" jika tidak ada request selesai
+func (r ThresholdResult) actual() string {
+    if r.NoData {
+        return "-"
+    }
+    return fmt.Sprintf("%.2f", r.Actual)
+}
+
+// String threshold beserta nilainya, contoh: "p95<500ms (actual 612.40)"
+func (r ThresholdResult) String() string {
+    return fmt.Sprintf("%s (actual %s)", r.Threshold, r.actual())
+}
+
+// evaluateThresholds mengisi report.Thresholds dan mengembalikan threshold yang gagal
+func evaluateThresholds(thresholds []Threshold, report *Report) []ThresholdResult {
+    var breached []ThresholdResult
+    report.Thresholds = nil
+    for _, t := range thresholds {
+        result := t.Evaluate(report)
+        report.Thresholds = append(report.Thresholds, result)
+        if !result.Passed {
+            breached = append(breached, result)
+        }
+    }
+    return breached
+}
+
+func printThresholds(w io.Writer, report *Report) {
+    if len(report.Thresholds) == 0 {
+        return
+    }
+    fmt.Fprintln(w, "\n🎯 Thresholds:")
+    for _, result := range report.Thresholds {
+        status := "✅ PASS"
+        if !result.Passed {
+            status = "❌ FAIL"
+        }
+        fmt.Fprintf(w, "  %-30s actual %-12s %s\n", result.Threshold, result.actual(), status)
+    }
+}
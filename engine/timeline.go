@@ -0,0 +1,112 @@
+package engine
+
+import (
+    "sort"
+    "sync"
+    "time"
+)
+
+// timeline mengelompokkan hasil request per detik (berdasarkan waktu mulai
+// request) untuk metrik per interval. Zero value siap dipakai.
+type timeline struct {
+    mu      sync.Mutex
+    buckets map[int64]*timelineBucket
+}
+
+type timelineBucket struct {
+    requests      int64
+    failed        int64
+    totalDuration time.Duration
+    maxDuration   time.Duration
+    bytes         int64
+}
+
+func (t *timeline) add(result Result) {
+    second := result.Start.Unix()
+
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if t.buckets == nil {
+        t.buckets = make(map[int64]*timelineBucket)
+    }
+    b := t.buckets[second]
+    if b == nil {
+        b = &timelineBucket{}
+        t.buckets[second] = b
+    }
+    b.requests++
+    if result.Err != nil {
+        b.failed++
+    }
+    b.totalDuration += result.Duration
+    if result.Duration > b.maxDuration {
+        b.maxDuration = result.Duration
+    }
+    b.bytes += result.Bytes
+}
+
+// IntervalReport metrik agregat untuk satu interval waktu
+type IntervalReport struct {
+    Start        time.Time `json:"start"`
+    Requests     int64     `json:"requests"`
+    Failed       int64     `json:"failed"`
+    RPS          float64   `json:"requests_per_second"`
+    AvgLatencyMs float64   `json:"avg_latency_ms"`
+    MaxLatencyMs float64   `json:"max_latency_ms"`
+    Bytes        int64     `json:"bytes"`
+}
+
+// intervals mengagregasi bucket per detik menjadi interval dengan panjang
+// tertentu, dihitung dari detik pertama yang memiliki request
+func (t *timeline) intervals(interval time.Duration) []IntervalReport {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if len(t.buckets) == 0 {
+        return nil
+    }
+
+    seconds := make([]int64, 0, len(t.buckets))
+    for second := range t.buckets {
+        seconds = append(seconds, second)
+    }
+    sort.Slice(seconds, func(i, j int) bool { return seconds[i] < seconds[j] })
+
+    step := int64(interval / time.Second)
+    if step < 1 {
+        step = 1
+    }
+    first := seconds[0]
+
+    var reports []IntervalReport
+    var current *IntervalReport
+    var totalDuration time.Duration
+    flush := func() {
+        if current == nil {
+            return
+        }
+        current.RPS = float64(current.Requests) / float64(step)
+        if current.Requests > 0 {
+            current.AvgLatencyMs = durationMs(totalDuration / time.Duration(current.Requests))
+        }
+        reports = append(reports, *current)
+    }
+
+    for _, second := range seconds {
+        start := first + (second-first)/step*step
+        if current == nil || current.Start.Unix() != start {
+            flush()
+            current = &IntervalReport{Start: time.Unix(start, 0).UTC()}
+            totalDuration = 0
+        }
+        b := t.buckets[second]
+        current.Requests += b.requests
+        current.Failed += b.failed
+        current.Bytes += b.bytes
+        totalDuration += b.totalDuration
+        if ms := durationMs(b.maxDuration); ms > current.MaxLatencyMs {
+            current.MaxLatencyMs = ms
+        }
+    }
+    flush()
+    return reports
+}
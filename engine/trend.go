@@ -0,0 +1,124 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "math"
+    "sort"
+)
+
+const trendMinRuns = 3 // Run baseline minimal agar standar deviasi bermakna
+
+// trendMetrics metrik yang dipantau -trend; naik berarti memburuk. floor
+// batas bawah standar deviasi agar baseline yang sangat stabil (contoh error
+// rate selalu 0) tidak menandai perubahan sekecil apa pun.
+var trendMetrics = []struct {
+    name  string
+    value func(r *Report) float64
+    floor func(mean float64) float64
+}{
+    {"p95", func(r *Report) float64 { return r.P95LatencyMs }, func(mean float64) float64 { return mean * 0.01 }},
+    {"error_rate", func(r *Report) float64 { return r.ErrorRate }, func(float64) float64 { return 0.1 }},
+}
+
+// TrendReport perbandingan run ini dengan run sebelumnya dari -trend untuk
+// profil yang sama
+type TrendReport struct {
+    Baseline []string      `json:"baseline_runs"` // Run ID baseline, urut waktu
+    Sigma    float64       `json:"sigma"`
+    Metrics  []TrendMetric `json:"metrics"`
+}
+
+// TrendMetric satu metrik dibandingkan dengan baseline
+type TrendMetric struct {
+    Metric  string  `json:"metric"`
+    Value   float64 `json:"value"`
+    Mean    float64 `json:"baseline_mean"`
+    StdDev  float64 `json:"baseline_stddev"`
+    Z       float64 `json:"z"` // Jarak dari rata-rata baseline dalam standar deviasi
+    Anomaly bool    `json:"anomaly"`
+}
+
+// trendProfile identitas profil test: run hanya dibandingkan dengan run
+// yang target, concurrency dan jadwalnya sama
+func trendProfile(r *Report) string {
+    return fmt.Sprintf("%s %s|%s|%d|%s", r.Method, r.URL, r.Scenario, r.Concurrency, r.Schedule)
+}
+
+// evaluateTrend membandingkan p95 dan error rate report dengan -trend-window
+// run terakhir berprofil sama dari report JSON -trend. Nil jika -trend
+// kosong atau baseline kurang dari trendMinRuns.
+func evaluateTrend(config *Config, report *Report) *TrendReport {
+    if config.Trend == "" {
+        return nil
+    }
+    history, err := loadReportGroup(config.Trend)
+    if err != nil {
+        fmt.Fprintf(config.out, "⚠️  Tren: %v\n", err)
+        return nil
+    }
+    profile := trendProfile(report)
+    var baseline []*Report
+    for _, r := range history {
+        if r.RunID != report.RunID && trendProfile(r) == profile && r.StartTime.Before(report.StartTime) {
+            baseline = append(baseline, r)
+        }
+    }
+    if len(baseline) < trendMinRuns {
+        fmt.Fprintf(config.out, "ℹ️  Tren: baru %d run sebelumnya dengan profil yang sama (minimal %d), deteksi anomali dilewati\n", len(baseline), trendMinRuns)
+        return nil
+    }
+    sort.Slice(baseline, func(i, j int) bool { return baseline[i].StartTime.Before(baseline[j].StartTime) })
+    if len(baseline) > config.TrendWindow {
+        baseline = baseline[len(baseline)-config.TrendWindow:]
+    }
+
+    trend := &TrendReport{Sigma: config.TrendSigma}
+    for _, r := range baseline {
+        trend.Baseline = append(trend.Baseline, r.RunID)
+    }
+    for _, metric := range trendMetrics {
+        mean, sd := meanStd(reportValues(baseline, metric.value))
+        m := TrendMetric{Metric: metric.name, Value: metric.value(report), Mean: mean, StdDev: sd}
+        m.Z = (m.Value - mean) / math.Max(sd, metric.floor(mean))
+        if math.IsNaN(m.Z) {
+            m.Z = 0
+        }
+        m.Anomaly = m.Z > config.TrendSigma
+        trend.Metrics = append(trend.Metrics, m)
+    }
+    return trend
+}
+
+// thresholdResults hasil tren dalam bentuk threshold agar anomali ikut
+// exit code, notifikasi dan tabel threshold
+func (t *TrendReport) thresholdResults() []ThresholdResult {
+    if t == nil {
+        return nil
+    }
+    results := make([]ThresholdResult, 0, len(t.Metrics))
+    for _, m := range t.Metrics {
+        results = append(results, ThresholdResult{
+            Threshold: fmt.Sprintf("tren %s < %gσ", m.Metric, t.Sigma),
+            Actual:    m.Z,
+            Passed:    !m.Anomaly,
+        })
+    }
+    return results
+}
+
+func printTrend(w io.Writer, report *Report) {
+    t := report.Trend
+    if t == nil {
+        return
+    }
+    fmt.Fprintf(w, "\n📈 Tren Historis (%d run sebelumnya, batas %gσ):\n", len(t.Baseline), t.Sigma)
+    fmt.Fprintf(w, "  %-12s %12s %12s %10s %8s\n", "Metrik", "Run ini", "Rata-rata", "Std dev", "σ")
+    for _, m := range t.Metrics {
+        icon := "✅"
+        if m.Anomaly {
+            icon = "❌"
+        }
+        fmt.Fprintf(w, "  %-12s %12.2f %12.2f %10.2f %+8.1f %s\n", m.Metric, m.Value, m.Mean, m.StdDev, m.Z, icon)
+    }
+}
@@ -0,0 +1,165 @@
+package engine
+
+import (
+    "bufio"
+    "context"
+    "crypto/tls"
+    "encoding/base64"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "net/url"
+    "time"
+)
+
+// tunnelRequester mode benchmark proxy: setiap request membuka tunnel
+// CONNECT baru lewat proxy sehingga waktu pembentukan tunnel terukur
+// terpisah dari request end-to-end. Fase yang dicatat: proxy_connect (TCP ke
+// proxy), connect_tunnel (round trip CONNECT), tls (handshake ke target lewat
+// tunnel) dan request (kirim request sampai header response).
+type tunnelRequester struct {
+    proxy     *url.URL
+    requests  *requestBuilder
+    tlsConfig *tls.Config
+    dialer    *net.Dialer
+    timeout   time.Duration
+}
+
+func newTunnelRequester(config *Config) (Requester, error) {
+    if config.Proxy == "" {
+        return nil, fmt.Errorf("-tunnel-bench membutuhkan -proxy")
+    }
+    proxy, err := url.Parse(config.Proxy)
+    if err != nil {
+        return nil, fmt.Errorf("URL proxy tidak valid: %w", err)
+    }
+    requests, err := newRequestBuilder(config)
+    if err != nil {
+        return nil, err
+    }
+    return &tunnelRequester{
+        proxy:     proxy,
+        requests:  requests,
+        tlsConfig: &tls.Config{InsecureSkipVerify: true, ServerName: requests.URL().Hostname()},
+        dialer:    &net.Dialer{Timeout: 30 * time.Second},
+        timeout:   time.Duration(config.Timeout) * time.Second,
+    }, nil
+}
+
+// targetAddr host:port target, dengan port default sesuai scheme
+func targetAddr(u *url.URL) string {
+    if u.Port() != "" {
+        return u.Host
+    }
+    port := "80"
+    if u.Scheme == "https" {
+        port = "443"
+    }
+    return net.JoinHostPort(u.Hostname(), port)
+}
+
+func (t *tunnelRequester) Do(ctx context.Context, requestNum int) Result {
+    ctx, cancel := context.WithTimeout(ctx, t.timeout)
+    defer cancel()
+
+    phases := make(map[string]time.Duration)
+    start := time.Now()
+    fail := func(err error) Result {
+        return Result{Start: start, Duration: time.Since(start), Err: err, Phases: phases}
+    }
+
+    // 1. TCP ke proxy
+    mark := time.Now()
+    conn, err := t.dialer.DialContext(ctx, "tcp", targetAddr(t.proxy))
+    if err != nil {
+        return fail(fmt.Errorf("dial proxy: %w", err))
+    }
+    defer conn.Close()
+    if deadline, ok := ctx.Deadline(); ok {
+        conn.SetDeadline(deadline)
+    }
+    phases["proxy_connect"] = time.Since(mark)
+
+    // 2. CONNECT
+    mark = time.Now()
+    target := targetAddr(t.requests.URL())
+    connectReq := &http.Request{
+        Method: http.MethodConnect,
+        URL:    &url.URL{Opaque: target},
+        Host:   target,
+        Header: make(http.Header),
+    }
+    if user := t.proxy.User; user != nil {
+        password, _ := user.Password()
+        auth := base64.StdEncoding.EncodeToString([]byte(user.Username() + ":" + password))
+        connectReq.Header.Set("Proxy-Authorization", "Basic "+auth)
+    }
+    if err := connectReq.Write(conn); err != nil {
+        return fail(fmt.Errorf("kirim CONNECT: %w", err))
+    }
+    br := bufio.NewReader(conn)
+    connectResp, err := http.ReadResponse(br, connectReq)
+    if err != nil {
+        return fail(fmt.Errorf("baca respon CONNECT: %w", err))
+    }
+    connectResp.Body.Close()
+    phases["connect_tunnel"] = time.Since(mark)
+    if connectResp.StatusCode != http.StatusOK {
+        return fail(fmt.Errorf("proxy menolak CONNECT: %s", connectResp.Status))
+    }
+
+    // 3. TLS ke target lewat tunnel
+    var tunnel net.Conn = &bufferedConn{Conn: conn, r: br}
+    if t.requests.URL().Scheme == "https" {
+        mark = time.Now()
+        tlsConn := tls.Client(tunnel, t.tlsConfig)
+        if err := tlsConn.HandshakeContext(ctx); err != nil {
+            return fail(fmt.Errorf("TLS handshake: %w", err))
+        }
+        phases["tls"] = time.Since(mark)
+        tunnel = tlsConn
+    }
+
+    // 4. Request
+    mark = time.Now()
+    req, err := t.requests.build(ctx, requestNum)
+    if err != nil {
+        return fail(err)
+    }
+    req.Close = true
+    if err := req.Write(tunnel); err != nil {
+        return fail(fmt.Errorf("kirim request: %w", err))
+    }
+    resp, err := http.ReadResponse(bufio.NewReader(tunnel), req)
+    if err != nil {
+        return fail(fmt.Errorf("baca response: %w", err))
+    }
+    phases["request"] = time.Since(mark)
+    duration := time.Since(start)
+
+    n, _ := io.Copy(io.Discard, resp.Body)
+    resp.Body.Close()
+
+    return Result{
+        Start:      start,
+        Duration:   duration,
+        StatusCode: resp.StatusCode,
+        Bytes:      n,
+        Phases:     phases,
+    }
+}
+
+func (t *tunnelRequester) Close() error {
+    return nil
+}
+
+// bufferedConn net.Conn yang membaca sisa buffer reader lebih dulu
+type bufferedConn struct {
+    net.Conn
+    r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+    return c.r.Read(b)
+}
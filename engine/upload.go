@@ -0,0 +1,269 @@
+package engine
+
+import (
+    "bytes"
+    "context"
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "os"
+    "os/exec"
+    "path"
+    "strings"
+    "time"
+)
+
+// uploader mengunggah satu object ke object storage
+type uploader interface {
+    Upload(ctx context.Context, key string, body []byte, contentType string) error
+}
+
+// newUploader membuat uploader dari URL tujuan (s3://bucket/prefix/ atau
+// gs://bucket/prefix/) dan mengembalikan prefix key di dalam bucket
+func newUploader(dest string) (uploader, string, error) {
+    u, err := url.Parse(dest)
+    if err != nil {
+        return nil, "", fmt.Errorf("URL upload tidak valid: %w", err)
+    }
+    if u.Host == "" {
+        return nil, "", fmt.Errorf("URL upload %q tidak memiliki bucket", dest)
+    }
+    prefix := strings.TrimPrefix(u.Path, "/")
+
+    switch u.Scheme {
+    case "s3":
+        up, err := newS3Uploader(u.Host)
+        return up, prefix, err
+    case "gs":
+        up, err := newGCSUploader(u.Host)
+        return up, prefix, err
+    default:
+        return nil, "", fmt.Errorf("tujuan upload %q tidak didukung (gunakan s3:// atau gs://)", u.Scheme)
+    }
+}
+
+// uploadResults mengunggah file hasil test ke dest. Setiap run disimpan di
+// folder run ID (<hostname>-<timestamp>) agar hasil dari beberapa CI agent
+// tidak saling menimpa.
+func uploadResults(w io.Writer, dest, folder string, files map[string][]byte) error {
+    up, prefix, err := newUploader(dest)
+    if err != nil {
+        return err
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+    defer cancel()
+
+    for name, data := range files {
+        key := path.Join(prefix, folder, name)
+        if err := up.Upload(ctx, key, data, contentTypeFor(name)); err != nil {
+            return fmt.Errorf("upload %s: %w", name, err)
+        }
+        fmt.Fprintf(w, "☁️  Uploaded %s\n", strings.TrimSuffix(dest, "/")+"/"+path.Join(folder, name))
+    }
+    return nil
+}
+
+func contentTypeFor(name string) string {
+    switch strings.ToLower(path.Ext(name)) {
+    case ".json":
+        return "application/json"
+    case ".html", ".htm":
+        return "text/html; charset=utf-8"
+    case ".csv":
+        return "text/csv"
+    default:
+        return "application/octet-stream"
+    }
+}
+
+// s3Uploader mengunggah ke Amazon S3 (atau S3-compatible seperti MinIO)
+// menggunakan AWS Signature V4. Kredensial dibaca dari environment:
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN (opsional),
+// AWS_REGION dan AWS_ENDPOINT_URL_S3 (opsional, path-style).
+type s3Uploader struct {
+    bucket       string
+    region       string
+    endpoint     string
+    accessKey    string
+    secretKey    string
+    sessionToken string
+    client       *http.Client
+}
+
+func newS3Uploader(bucket string) (*s3Uploader, error) {
+    up := &s3Uploader{
+        bucket:       bucket,
+        region:       firstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+        endpoint:     firstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"),
+        accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+        secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+        sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+        client:       &http.Client{Timeout: 2 * time.Minute},
+    }
+    if up.accessKey == "" || up.secretKey == "" {
+        return nil, fmt.Errorf("AWS_ACCESS_KEY_ID dan AWS_SECRET_ACCESS_KEY harus diisi untuk upload ke S3")
+    }
+    if up.region == "" {
+        up.region = "us-east-1"
+    }
+    return up, nil
+}
+
+func firstEnv(names ...string) string {
+    for _, name := range names {
+        if v := os.Getenv(name); v != "" {
+            return v
+        }
+    }
+    return ""
+}
+
+// objectURL mengembalikan URL object; endpoint custom memakai path-style
+func (s *s3Uploader) objectURL(key string) string {
+    if s.endpoint != "" {
+        return strings.TrimSuffix(s.endpoint, "/") + "/" + s.bucket + "/" + awsURIEncode(key, false)
+    }
+    return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region, awsURIEncode(key, false))
+}
+
+func (s *s3Uploader) Upload(ctx context.Context, key string, body []byte, contentType string) error {
+    req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Content-Type", contentType)
+    s.sign(req, body, time.Now().UTC())
+
+    resp, err := s.client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode/100 != 2 {
+        msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+        return fmt.Errorf("S3 merespon %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+    }
+    return nil
+}
+
+// sign menandatangani request dengan AWS Signature V4 untuk service s3
+func (s *s3Uploader) sign(req *http.Request, body []byte, now time.Time) {
+    amzDate := now.Format("20060102T150405Z")
+    date := now.Format("20060102")
+    payloadHash := sha256Hex(body)
+
+    req.Header.Set("X-Amz-Date", amzDate)
+    req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+    if s.sessionToken != "" {
+        req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+    }
+
+    signedHeaders := []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+    if s.sessionToken != "" {
+        signedHeaders = append(signedHeaders, "x-amz-security-token")
+    }
+    var canonicalHeaders strings.Builder
+    for _, h := range signedHeaders {
+        value := req.Header.Get(h)
+        if h == "host" {
+            value = req.URL.Host
+        }
+        canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(value) + "\n")
+    }
+
+    canonicalRequest := strings.Join([]string{
+        req.Method,
+        req.URL.EscapedPath(),
+        req.URL.RawQuery,
+        canonicalHeaders.String(),
+        strings.Join(signedHeaders, ";"),
+        payloadHash,
+    }, "\n")
+
+    scope := date + "/" + s.region + "/s3/aws4_request"
+    stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+    key := hmacSHA256([]byte("AWS4"+s.secretKey), date)
+    key = hmacSHA256(key, s.region)
+    key = hmacSHA256(key, "s3")
+    key = hmacSHA256(key, "aws4_request")
+    signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+    req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+        s.accessKey, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+func sha256Hex(data []byte) string {
+    sum := sha256.Sum256(data)
+    return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+    mac := hmac.New(sha256.New, key)
+    mac.Write([]byte(data))
+    return mac.Sum(nil)
+}
+
+// awsURIEncode meng-encode string sesuai aturan URI encoding SigV4
+func awsURIEncode(value string, encodeSlash bool) string {
+    var sb strings.Builder
+    for _, b := range []byte(value) {
+        switch {
+        case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+            b == '-', b == '_', b == '.', b == '~':
+            sb.WriteByte(b)
+        case b == '/' && !encodeSlash:
+            sb.WriteByte(b)
+        default:
+            fmt.Fprintf(&sb, "%%%02X", b)
+        }
+    }
+    return sb.String()
+}
+
+// gcsUploader mengunggah ke Google Cloud Storage lewat JSON API. Access
+// token dibaca dari GOOGLE_OAUTH_ACCESS_TOKEN atau dari `gcloud auth print-access-token`.
+type gcsUploader struct {
+    bucket string
+    token  string
+    client *http.Client
+}
+
+func newGCSUploader(bucket string) (*gcsUploader, error) {
+    token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+    if token == "" {
+        out, err := exec.Command("gcloud", "auth", "print-access-token").Output()
+        if err != nil {
+            return nil, fmt.Errorf("GOOGLE_OAUTH_ACCESS_TOKEN kosong dan gcloud gagal memberikan token: %w", err)
+        }
+        token = strings.TrimSpace(string(out))
+    }
+    return &gcsUploader{bucket: bucket, token: token, client: &http.Client{Timeout: 2 * time.Minute}}, nil
+}
+
+func (g *gcsUploader) Upload(ctx context.Context, key string, body []byte, contentType string) error {
+    endpoint := fmt.Sprintf("https://storage.googleapis.com/upload/storage/v1/b/%s/o?uploadType=media&name=%s",
+        url.PathEscape(g.bucket), url.QueryEscape(key))
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+    if err != nil {
+        return err
+    }
+    req.Header.Set("Authorization", "Bearer "+g.token)
+    req.Header.Set("Content-Type", contentType)
+
+    resp, err := g.client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode/100 != 2 {
+        msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+        return fmt.Errorf("GCS merespon %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+    }
+    return nil
+}
@@ -0,0 +1,153 @@
+package engine
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "net/http"
+    "regexp"
+    "strconv"
+    "strings"
+    "sync/atomic"
+    "time"
+)
+
+// urlLimit batas request bersamaan untuk request yang cocok dengan satu
+// pola -url-limit. Request yang melebihi batas menunggu slot; waktu
+// tunggunya tidak ikut dalam latency.
+type urlLimit struct {
+    pattern string // Teks asli dari flag, untuk report
+    method  string // Kosong berarti semua method
+    path    *regexp.Regexp
+    slots   chan struct{}
+
+    requests atomic.Int64
+    queued   atomic.Int64 // Request yang harus menunggu slot
+    wait     histogram    // Waktu tunggu request yang antre
+}
+
+// urlLimiter kumpulan -url-limit, diperiksa berurutan dan pola pertama yang
+// cocok yang dipakai. Nil berarti tanpa batas.
+type urlLimiter struct {
+    limits []*urlLimit
+}
+
+// newURLLimiter mem-parse -url-limit dengan format '[METHOD ]pola=N',
+// contoh 'POST /reports/*=5'. Pola dicocokkan ke path URL, '*' cocok
+// dengan karakter apa pun termasuk '/'.
+func newURLLimiter(specs []string) (*urlLimiter, error) {
+    if len(specs) == 0 {
+        return nil, nil
+    }
+    l := &urlLimiter{}
+    for _, spec := range specs {
+        i := strings.LastIndexByte(spec, '=')
+        if i < 0 {
+            return nil, fmt.Errorf("-url-limit %q tidak valid (format: [METHOD ]pola=N, contoh: 'POST /reports/*=5')", spec)
+        }
+        n, err := strconv.Atoi(strings.TrimSpace(spec[i+1:]))
+        if err != nil || n < 1 {
+            return nil, fmt.Errorf("-url-limit %q: batas harus bilangan bulat >= 1", spec)
+        }
+        pattern := strings.TrimSpace(spec[:i])
+        limit := &urlLimit{pattern: pattern, slots: make(chan struct{}, n)}
+        if method, rest, found := strings.Cut(pattern, " "); found {
+            limit.method, pattern = strings.ToUpper(method), strings.TrimSpace(rest)
+        }
+        if !strings.HasPrefix(pattern, "/") {
+            return nil, fmt.Errorf("-url-limit %q: pola harus path yang diawali '/'", spec)
+        }
+        parts := strings.Split(pattern, "*")
+        for j, part := range parts {
+            parts[j] = regexp.QuoteMeta(part)
+        }
+        limit.path = regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+        l.limits = append(l.limits, limit)
+    }
+    return l, nil
+}
+
+func (l *urlLimiter) match(req *http.Request) *urlLimit {
+    for _, limit := range l.limits {
+        if (limit.method == "" || limit.method == req.Method) && limit.path.MatchString(req.URL.Path) {
+            return limit
+        }
+    }
+    return nil
+}
+
+// acquire menunggu slot untuk req jika cocok dengan salah satu batas.
+// release harus dipanggil setelah body response selesai dibaca; error
+// hanya jika ctx selesai selama menunggu.
+func (l *urlLimiter) acquire(ctx context.Context, req *http.Request) (release func(), err error) {
+    if l == nil {
+        return func() {}, nil
+    }
+    limit := l.match(req)
+    if limit == nil {
+        return func() {}, nil
+    }
+    limit.requests.Add(1)
+    select {
+    case limit.slots <- struct{}{}:
+    default:
+        limit.queued.Add(1)
+        start := time.Now()
+        select {
+        case limit.slots <- struct{}{}:
+            limit.wait.addDuration(time.Since(start))
+        case <-ctx.Done():
+            return nil, ctx.Err()
+        }
+    }
+    return func() { <-limit.slots }, nil
+}
+
+// URLLimitReport hasil satu batas -url-limit
+type URLLimitReport struct {
+    Pattern   string  `json:"pattern"`
+    Limit     int     `json:"limit"`
+    Requests  int64   `json:"requests"`
+    Queued    int64   `json:"queued"` // Request yang harus menunggu slot
+    QueuedPct float64 `json:"queued_pct"`
+    WaitP50Ms float64 `json:"wait_p50_ms"` // Waktu tunggu request yang antre
+    WaitP95Ms float64 `json:"wait_p95_ms"`
+}
+
+func (l *urlLimiter) report() []URLLimitReport {
+    if l == nil {
+        return nil
+    }
+    out := make([]URLLimitReport, 0, len(l.limits))
+    for _, limit := range l.limits {
+        r := URLLimitReport{
+            Pattern:   limit.pattern,
+            Limit:     cap(limit.slots),
+            Requests:  limit.requests.Load(),
+            Queued:    limit.queued.Load(),
+            WaitP50Ms: durationMs(limit.wait.quantileDuration(0.50)),
+            WaitP95Ms: durationMs(limit.wait.quantileDuration(0.95)),
+        }
+        if r.Requests > 0 {
+            r.QueuedPct = float64(r.Queued) / float64(r.Requests) * 100
+        }
+        out = append(out, r)
+    }
+    return out
+}
+
+// URLLimitReporter diimplementasikan Requester yang mendukung -url-limit
+type URLLimitReporter interface {
+    URLLimitReport() []URLLimitReport
+}
+
+func printURLLimits(w io.Writer, report *Report) {
+    if len(report.URLLimits) == 0 {
+        return
+    }
+    fmt.Fprintln(w, "\n🚦 Batas Concurrency per URL:")
+    fmt.Fprintf(w, "  %-28s %6s %9s %9s %12s %12s\n", "Pola", "Batas", "Requests", "Antre %", "Tunggu p50", "Tunggu p95")
+    for _, l := range report.URLLimits {
+        fmt.Fprintf(w, "  %-28s %6d %9d %8.1f%% %9.2f ms %9.2f ms\n", truncate(l.Pattern, 28), l.Limit, l.Requests, l.QueuedPct, l.WaitP50Ms, l.WaitP95Ms)
+    }
+}
@@ -0,0 +1,109 @@
+package engine
+
+import (
+    "encoding/json"
+    "flag"
+    "fmt"
+    "io"
+    "maps"
+    "os"
+    "runtime"
+    "runtime/debug"
+    "slices"
+    "strings"
+)
+
+// VersionInfo versi dan kemampuan binary loadtest (loadtest version -json),
+// agar automation bisa memastikan flag atau format yang dibutuhkan tersedia
+// sebelum menjalankan test
+type VersionInfo struct {
+    Version       string   `json:"version"`
+    Commit        string   `json:"commit,omitempty"` // Kosong jika dibangun tanpa info VCS
+    CommitTime    string   `json:"commit_time,omitempty"`
+    Modified      bool     `json:"modified,omitempty"` // Dibangun dari working tree yang belum di-commit
+    GoVersion     string   `json:"go_version"`
+    Platform      string   `json:"platform"`
+    ReportSchema  int      `json:"report_schema"`
+    Protocols     []string `json:"protocols"`      // URL scheme yang bisa ditarget
+    Modes         []string `json:"modes"`          // Mode selain request HTTP/1.1 tunggal
+    ReportFormats []string `json:"report_formats"` // Ekstensi -out
+    Subcommands   []string `json:"subcommands"`
+    Flags         []string `json:"flags"`
+}
+
+// versionModes mode request selain request HTTP/1.1 tunggal: -http2,
+// -scenario, -page, -tunnel-bench dan beberapa URL target
+var versionModes = []string{"http2", "scenario", "page", "tunnel", "multi-target"}
+
+func newVersionInfo() VersionInfo {
+    info := VersionInfo{
+        Version:       version,
+        GoVersion:     runtime.Version(),
+        Platform:      runtime.GOOS + "/" + runtime.GOARCH,
+        ReportSchema:  reportSchemaVersion,
+        Protocols:     slices.Sorted(maps.Keys(requesterFactories)),
+        Modes:         versionModes,
+        ReportFormats: flagValues["out"],
+        Subcommands:   subcommands,
+    }
+    if build, ok := debug.ReadBuildInfo(); ok {
+        for _, s := range build.Settings {
+            switch s.Key {
+            case "vcs.revision":
+                info.Commit = s.Value
+            case "vcs.time":
+                info.CommitTime = s.Value
+            case "vcs.modified":
+                info.Modified = s.Value == "true"
+            }
+        }
+    }
+    for _, f := range completionFlags() {
+        info.Flags = append(info.Flags, f.name)
+    }
+    return info
+}
+
+// runVersion menampilkan versi loadtest, untuk manusia atau JSON (-json)
+func runVersion(args []string) int {
+    fs := flag.NewFlagSet("version", flag.ExitOnError)
+    asJSON := fs.Bool("json", false, "Tampilkan sebagai JSON, termasuk daftar semua flag")
+    fs.Usage = func() {
+        fmt.Fprintf(os.Stderr, "Penggunaan: loadtest version [-json]\n")
+        fs.PrintDefaults()
+    }
+    fs.Parse(args)
+
+    info := newVersionInfo()
+    if *asJSON {
+        enc := json.NewEncoder(os.Stdout)
+        enc.SetIndent("", "  ")
+        if err := enc.Encode(info); err != nil {
+            fmt.Printf("Error: %v\n", err)
+            return 1
+        }
+        return 0
+    }
+    printVersion(os.Stdout, info)
+    return 0
+}
+
+func printVersion(w io.Writer, info VersionInfo) {
+    fmt.Fprintf(w, "loadtest %s\n", info.Version)
+    commit := info.Commit
+    if commit == "" {
+        commit = "tidak diketahui"
+    } else if info.Modified {
+        commit += " (ada perubahan belum di-commit)"
+    }
+    fmt.Fprintf(w, "  Commit:        %s\n", commit)
+    if info.CommitTime != "" {
+        fmt.Fprintf(w, "  Waktu commit:  %s\n", info.CommitTime)
+    }
+    fmt.Fprintf(w, "  Go:            %s (%s)\n", info.GoVersion, info.Platform)
+    fmt.Fprintf(w, "  Protokol:      %s\n", strings.Join(info.Protocols, ", "))
+    fmt.Fprintf(w, "  Mode:          %s\n", strings.Join(info.Modes, ", "))
+    fmt.Fprintf(w, "  Format report: %s (skema JSON v%d)\n", strings.Join(info.ReportFormats, ", "), info.ReportSchema)
+    fmt.Fprintf(w, "  Subcommand:    %s\n", strings.Join(info.Subcommands, ", "))
+    fmt.Fprintf(w, "  Flag:          %d (lihat -json untuk daftarnya)\n", len(info.Flags))
+}
@@ -0,0 +1,187 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "net/http"
+    "sort"
+    "strings"
+    "sync"
+)
+
+// challengePeekSize byte awal body yang diperiksa untuk tanda halaman
+// challenge. Halaman WAF biasanya kecil dan penandanya ada di awal.
+const challengePeekSize = 8 << 10
+
+// challengeSignature ciri response WAF, bot detection atau CAPTCHA. Cocok
+// jika salah satu header ada (dan berisi value, jika diisi) atau salah satu
+// potongan body ditemukan.
+type challengeSignature struct {
+    name    string
+    headers map[string]string // Nama header -> potongan nilai ("" = asal ada)
+    body    []string
+}
+
+// challengeSignatures diperiksa berurutan, vendor spesifik lebih dulu dari
+// CAPTCHA dan JS challenge generik
+var challengeSignatures = []challengeSignature{
+    {name: "cloudflare", headers: map[string]string{"Cf-Mitigated": "challenge"},
+        body: []string{"cf-chl-", "cf_chl_", "Attention Required! | Cloudflare", "cf-error-code", "challenges.cloudflare.com"}},
+    {name: "aws-waf", headers: map[string]string{"X-Amzn-Waf-Action": ""},
+        body: []string{"awswaf", "Request blocked.</h1>", "Generated by cloudfront (CloudFront)"}},
+    {name: "akamai", body: []string{"Reference&#32;&#35;", "AkamaiGHost", "errors.edgesuite.net"}},
+    {name: "imperva", headers: map[string]string{"X-Iinfo": ""},
+        body: []string{"Incapsula incident ID", "_Incapsula_Resource"}},
+    {name: "sucuri", headers: map[string]string{"X-Sucuri-Block": ""},
+        body: []string{"Sucuri WebSite Firewall"}},
+    {name: "f5-asm", body: []string{"The requested URL was rejected. Please consult with your administrator."}},
+    {name: "datadome", headers: map[string]string{"X-Datadome": ""},
+        body: []string{"captcha-delivery.com"}},
+    {name: "perimeterx", body: []string{"px-captcha", "_pxAppId"}},
+    {name: "modsecurity", body: []string{"ModSecurity", "Mod_Security"}},
+    {name: "captcha", body: []string{"g-recaptcha", "www.google.com/recaptcha", "hcaptcha.com", "h-captcha"}},
+    {name: "js-challenge", body: []string{"Checking your browser", "Please enable JavaScript and cookies", "enable JavaScript to continue"}},
+}
+
+// challengeStatus status yang dipakai WAF untuk memblokir atau memberi
+// challenge. Body response lain tidak diperiksa agar halaman biasa yang
+// memuat CAPTCHA (contoh form login) tidak salah terdeteksi.
+func challengeStatus(status int) bool {
+    switch status {
+    case http.StatusAccepted, http.StatusUnauthorized, http.StatusForbidden, http.StatusMethodNotAllowed,
+        http.StatusNotAcceptable, http.StatusTooManyRequests, http.StatusServiceUnavailable:
+        return true
+    }
+    return false
+}
+
+func (s challengeSignature) match(header http.Header, body string) bool {
+    for name, value := range s.headers {
+        if got := header.Get(name); got != "" && strings.Contains(strings.ToLower(got), value) {
+            return true
+        }
+    }
+    for _, marker := range s.body {
+        if strings.Contains(body, marker) {
+            return true
+        }
+    }
+    return false
+}
+
+// detectChallenge nama vendor/jenis challenge, kosong jika response bukan
+// challenge
+func detectChallenge(header http.Header, body []byte) string {
+    text := string(body)
+    for _, s := range challengeSignatures {
+        if s.match(header, text) {
+            return s.name
+        }
+    }
+    return ""
+}
+
+// challengeWatch menyimpan awal body response berstatus challengeStatus
+// selama body dibaca oleh requester
+type challengeWatch struct {
+    io.ReadCloser
+    resp *http.Response
+    head []byte
+}
+
+// watchChallenge membungkus resp.Body jika statusnya mungkin challenge,
+// nil jika tidak
+func watchChallenge(resp *http.Response) *challengeWatch {
+    if !challengeStatus(resp.StatusCode) {
+        return nil
+    }
+    w := &challengeWatch{ReadCloser: resp.Body, resp: resp}
+    resp.Body = w
+    return w
+}
+
+func (w *challengeWatch) Read(p []byte) (int, error) {
+    n, err := w.ReadCloser.Read(p)
+    if room := challengePeekSize - len(w.head); room > 0 && n > 0 {
+        w.head = append(w.head, p[:min(n, room)]...)
+    }
+    return n, err
+}
+
+// challengeError response challenge dengan status sukses (contoh 202 dari
+// AWS WAF), dihitung gagal karena request tidak sampai ke aplikasi
+type challengeError struct {
+    vendor string
+    status int
+}
+
+func (e *challengeError) Error() string {
+    return fmt.Sprintf("diblokir WAF/bot detection (%s, status %d)", e.vendor, e.status)
+}
+
+// check menandai result jika response adalah challenge, dipanggil setelah
+// body selesai dibaca
+func (w *challengeWatch) check(result Result) Result {
+    if w == nil {
+        return result
+    }
+    vendor := detectChallenge(w.resp.Header, w.head)
+    if vendor == "" {
+        return result
+    }
+    result.Challenge = vendor
+    if result.Err == nil && result.StatusCode < 400 {
+        result.Err = &challengeError{vendor: vendor, status: result.StatusCode}
+    }
+    return result
+}
+
+// challengeCounts jumlah response challenge per vendor. Zero value siap
+// dipakai.
+type challengeCounts struct {
+    mu     sync.Mutex
+    counts map[string]int64
+}
+
+func (c *challengeCounts) add(vendor string) {
+    c.mu.Lock()
+    if c.counts == nil {
+        c.counts = make(map[string]int64)
+    }
+    c.counts[vendor]++
+    c.mu.Unlock()
+}
+
+func (c *challengeCounts) report() map[string]int64 {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if len(c.counts) == 0 {
+        return nil
+    }
+    out := make(map[string]int64, len(c.counts))
+    for vendor, n := range c.counts {
+        out[vendor] = n
+    }
+    return out
+}
+
+func printChallenges(w io.Writer, report *Report) {
+    if len(report.Challenges) == 0 {
+        return
+    }
+    var total int64
+    vendors := make([]string, 0, len(report.Challenges))
+    for vendor, n := range report.Challenges {
+        vendors = append(vendors, vendor)
+        total += n
+    }
+    sort.Slice(vendors, func(i, j int) bool {
+        return report.Challenges[vendors[i]] > report.Challenges[vendors[j]]
+    })
+    fmt.Fprintf(w, "\n🧱 Diblokir WAF/bot detection: %d request (%.1f%%)\n", total, float64(total)/float64(max(report.TotalRequests, 1))*100)
+    for _, vendor := range vendors {
+        fmt.Fprintf(w, "  %-14s %d\n", vendor, report.Challenges[vendor])
+    }
+    fmt.Fprintln(w, "  ⚠️  Request ini ditolak sebelum sampai ke aplikasi; latency dan error rate tidak mencerminkan performa aplikasi.")
+    fmt.Fprintln(w, "     Minta IP generator di-allowlist atau gunakan environment tanpa WAF.")
+}
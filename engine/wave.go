@@ -0,0 +1,230 @@
+package engine
+
+import (
+    "context"
+    "fmt"
+    "html/template"
+    "io"
+    "math"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// waveShape bentuk beban periodik untuk soak test dengan pola harian
+type waveShape struct {
+    kind     string // sine atau saw
+    min, max float64
+    period   time.Duration
+}
+
+// parseWave membaca format 'sine:min=50,max=500,period=5m' atau 'saw:...'
+func parseWave(value string) (waveShape, error) {
+    kind, params, _ := strings.Cut(value, ":")
+    w := waveShape{kind: strings.ToLower(strings.TrimSpace(kind))}
+    if w.kind != "sine" && w.kind != "saw" {
+        return w, fmt.Errorf("bentuk wave %q tidak dikenal (gunakan sine atau saw)", kind)
+    }
+    for _, param := range strings.Split(params, ",") {
+        key, val, found := strings.Cut(strings.TrimSpace(param), "=")
+        if !found {
+            return w, fmt.Errorf("parameter wave %q tidak valid (format: key=value)", param)
+        }
+        var err error
+        switch key {
+        case "min":
+            w.min, err = strconv.ParseFloat(val, 64)
+        case "max":
+            w.max, err = strconv.ParseFloat(val, 64)
+        case "period":
+            w.period, err = time.ParseDuration(val)
+        default:
+            return w, fmt.Errorf("parameter wave %q tidak dikenal (min, max, period)", key)
+        }
+        if err != nil {
+            return w, fmt.Errorf("nilai %s %q tidak valid", key, val)
+        }
+    }
+    if w.min < 0 || w.max <= w.min {
+        return w, fmt.Errorf("wave membutuhkan 0 <= min < max")
+    }
+    if w.period <= 0 {
+        return w, fmt.Errorf("wave membutuhkan period > 0")
+    }
+    return w, nil
+}
+
+// expectedAt jumlah request kumulatif sampai elapsed, integral rate
+// gelombang. Sine mulai dari min dan memuncak di tengah periode, saw naik
+// linear dari min ke max lalu turun mendadak.
+func (w waveShape) expectedAt(elapsed time.Duration) float64 {
+    period := w.period.Seconds()
+    cycles := math.Floor(elapsed.Seconds() / period)
+    x := elapsed.Seconds() - cycles*period
+    full := (w.min + w.max) / 2 * period
+    var partial float64
+    if w.kind == "saw" {
+        partial = w.min*x + (w.max-w.min)*x*x/(2*period)
+    } else {
+        partial = w.min*x + (w.max-w.min)/2*(x-period/(2*math.Pi)*math.Sin(2*math.Pi*x/period))
+    }
+    return cycles*full + partial
+}
+
+func (w waveShape) String() string {
+    return fmt.Sprintf("%s %.0f-%.0f req/s periode %v", w.kind, w.min, w.max, w.period)
+}
+
+// waveScheduler mengirim request mengikuti waveShape selama durasi tertentu
+type waveScheduler struct {
+    wave     waveShape
+    duration time.Duration
+}
+
+func (s *waveScheduler) Run(ctx context.Context, jobs chan<- job) {
+    // Resolusi pengecekan rate sama seperti rampScheduler
+    const tick = 5 * time.Millisecond
+
+    start := time.Now()
+    sent := 0
+    for {
+        now := time.Now()
+        elapsed := min(now.Sub(start), s.duration)
+        expected := s.wave.expectedAt(elapsed)
+        for ; sent < int(expected); sent++ {
+            if !send(ctx, jobs, sent, now) {
+                return
+            }
+        }
+        if elapsed >= s.duration {
+            return
+        }
+        if !sleepUntil(ctx, time.Now().Add(tick)) {
+            return
+        }
+    }
+}
+
+func (s *waveScheduler) Total() int { return int(s.wave.expectedAt(s.duration)) }
+
+func (s *waveScheduler) String() string {
+    return fmt.Sprintf("wave %s selama %v", s.wave, s.duration)
+}
+
+// waveDeviationWarn rata-rata selisih rate tercapai terhadap target (persen)
+// yang dianggap tidak mengikuti bentuk wave
+const waveDeviationWarn = 10.0
+
+// WavePoint rate target dan tercapai pada satu interval
+type WavePoint struct {
+    OffsetS     float64 `json:"offset_s"`
+    TargetRPS   float64 `json:"target_rps"`
+    AchievedRPS float64 `json:"achieved_rps"`
+}
+
+// WaveReport perbandingan rate target dan tercapai untuk -wave
+type WaveReport struct {
+    Shape        string      `json:"shape"`
+    IntervalS    float64     `json:"interval_s"`
+    Points       []WavePoint `json:"points"`
+    AvgDeviation float64     `json:"avg_deviation_pct"` // Rata-rata |tercapai-target|/target
+    Warning      string      `json:"warning,omitempty"`
+}
+
+// report membandingkan rate target dengan request yang benar-benar dimulai
+// per interval. Interval sekitar 1/12 periode agar bentuk wave terlihat,
+// minimal 1 detik dan maksimal 300 titik untuk soak test panjang.
+func (s *waveScheduler) report(t *timeline, startTime time.Time) *WaveReport {
+    interval := max(time.Second, s.wave.period/12, s.duration/300).Truncate(time.Second)
+    intervals := t.intervals(interval)
+    if len(intervals) == 0 {
+        return nil
+    }
+
+    r := &WaveReport{Shape: s.wave.String(), IntervalS: interval.Seconds()}
+    var deviation float64
+    var counted int
+    for _, in := range intervals {
+        from := max(0, in.Start.Sub(startTime))
+        to := min(s.duration, in.Start.Add(interval).Sub(startTime))
+        if to <= from {
+            continue
+        }
+        target := (s.wave.expectedAt(to) - s.wave.expectedAt(from)) / (to - from).Seconds()
+        achieved := float64(in.Requests) / (to - from).Seconds()
+        r.Points = append(r.Points, WavePoint{OffsetS: from.Seconds(), TargetRPS: target, AchievedRPS: achieved})
+        if target > 0 {
+            deviation += math.Abs(achieved-target) / target * 100
+            counted++
+        }
+    }
+    if counted > 0 {
+        r.AvgDeviation = deviation / float64(counted)
+    }
+    if r.AvgDeviation > waveDeviationWarn {
+        r.Warning = fmt.Sprintf("rate tercapai rata-rata menyimpang %.0f%% dari target; naikkan -c atau periksa apakah target sudah jenuh", r.AvgDeviation)
+    }
+    return r
+}
+
+func printWave(w io.Writer, report *Report) {
+    wave := report.Wave
+    if wave == nil {
+        return
+    }
+    fmt.Fprintln(w, "\n🌊 Wave:")
+    fmt.Fprintf(w, "  %-23s %s\n", "Bentuk:", wave.Shape)
+    fmt.Fprintf(w, "  %-23s %.1f%% (per %.0fs)\n", "Deviasi rata-rata:", wave.AvgDeviation, wave.IntervalS)
+    if wave.Warning != "" {
+        fmt.Fprintf(w, "  ⚠️  %s\n", wave.Warning)
+    }
+}
+
+// WaveChart grafik SVG rate target vs tercapai untuk report HTML
+func (r *Report) WaveChart() template.HTML {
+    if r.Wave == nil || len(r.Wave.Points) == 0 {
+        return ""
+    }
+    const (
+        width, height = 720.0, 300.0
+        left, right   = 60.0, 20.0
+        top, bottom   = 30.0, 40.0
+    )
+    points := r.Wave.Points
+    maxX := points[len(points)-1].OffsetS + r.Wave.IntervalS
+    maxY := 1.0
+    for _, p := range points {
+        maxY = max(maxY, p.TargetRPS, p.AchievedRPS)
+    }
+    px := func(x float64) float64 { return left + x/maxX*(width-left-right) }
+    py := func(y float64) float64 { return height - bottom - y/maxY*(height-top-bottom) }
+
+    var sb strings.Builder
+    fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" font-family="sans-serif" font-size="12">`+"\n", width, height)
+    fmt.Fprintf(&sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#333"/>`+"\n", left, height-bottom, width-right, height-bottom)
+    fmt.Fprintf(&sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#333"/>`+"\n", left, top, left, height-bottom)
+    for i := 0; i <= 4; i++ {
+        x, y := maxX*float64(i)/4, maxY*float64(i)/4
+        fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="middle">%.0fs</text>`+"\n", px(x), height-bottom+16, x)
+        fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="end">%.0f</text>`+"\n", left-6, py(y)+4, y)
+    }
+
+    series := []struct {
+        name   string
+        color  string
+        metric func(p WavePoint) float64
+    }{
+        {"target", "#999", func(p WavePoint) float64 { return p.TargetRPS }},
+        {"tercapai", "#2a9d8f", func(p WavePoint) float64 { return p.AchievedRPS }},
+    }
+    for i, s := range series {
+        coords := make([]string, len(points))
+        for j, p := range points {
+            coords[j] = fmt.Sprintf("%.1f,%.1f", px(p.OffsetS+r.Wave.IntervalS/2), py(s.metric(p)))
+        }
+        fmt.Fprintf(&sb, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`+"\n", s.color, strings.Join(coords, " "))
+        fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" fill="%s">%s</text>`+"\n", width-right-130+float64(i)*50, top-10, s.color, s.name)
+    }
+    sb.WriteString("</svg>\n")
+    return template.HTML(sb.String())
+}
@@ -0,0 +1,286 @@
+package engine
+
+import (
+    "bufio"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
+    "net/url"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+)
+
+// prompter membaca jawaban wizard baris per baris. Jawaban kosong memakai
+// nilai default.
+type prompter struct {
+    in  *bufio.Reader
+    out io.Writer
+}
+
+func (p *prompter) ask(question, def string) (string, error) {
+    if def != "" {
+        fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+    } else {
+        fmt.Fprintf(p.out, "%s: ", question)
+    }
+    line, err := p.in.ReadString('\n')
+    if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+        if errors.Is(err, io.EOF) && def != "" {
+            fmt.Fprintln(p.out)
+            return def, nil
+        }
+        return "", err
+    }
+    if answer := strings.TrimSpace(line); answer != "" {
+        return answer, nil
+    }
+    return def, nil
+}
+
+// askValid mengulang pertanyaan sampai check menerima jawabannya
+func (p *prompter) askValid(question, def string, check func(string) error) (string, error) {
+    for {
+        answer, err := p.ask(question, def)
+        if err != nil {
+            return "", err
+        }
+        if err := check(answer); err != nil {
+            fmt.Fprintf(p.out, "  ⚠️  %v\n", err)
+            continue
+        }
+        return answer, nil
+    }
+}
+
+func (p *prompter) choose(question string, options []string, def string) (string, error) {
+    return p.askValid(fmt.Sprintf("%s (%s)", question, strings.Join(options, "/")), def, func(answer string) error {
+        for _, option := range options {
+            if answer == option {
+                return nil
+            }
+        }
+        return fmt.Errorf("pilih salah satu: %s", strings.Join(options, ", "))
+    })
+}
+
+func checkPositiveInt(answer string) error {
+    if n, err := strconv.Atoi(answer); err != nil || n < 1 {
+        return fmt.Errorf("harus angka bulat >= 1")
+    }
+    return nil
+}
+
+func checkDuration(answer string) error {
+    if d, err := time.ParseDuration(answer); err != nil || d <= 0 {
+        return fmt.Errorf("durasi tidak valid (contoh: 30s, 5m)")
+    }
+    return nil
+}
+
+func checkOptionalNumber(answer string) error {
+    if answer == "" {
+        return nil
+    }
+    if v, err := strconv.ParseFloat(answer, 64); err != nil || v < 0 {
+        return fmt.Errorf("harus angka >= 0, atau kosong untuk dilewati")
+    }
+    return nil
+}
+
+// runInit wizard interaktif untuk pengguna baru: menanyakan target, method,
+// auth, pola beban dan threshold, lalu menulis file skenario dan perintah
+// untuk menjalankannya
+func runInit(args []string) int {
+    fs := flag.NewFlagSet("init", flag.ExitOnError)
+    out := fs.String("o", "scenario.json", "File skenario yang ditulis")
+    force := fs.Bool("force", false, "Timpa file yang sudah ada")
+    fs.Usage = func() {
+        fmt.Fprintf(os.Stderr, "Usage: loadtest init [options]\n\n")
+        fmt.Fprintf(os.Stderr, "Menanyakan konfigurasi test lalu menulis file skenario JSON dan script\n")
+        fmt.Fprintf(os.Stderr, "untuk menjalankannya.\n\n")
+        fmt.Fprintf(os.Stderr, "Options:\n")
+        fs.PrintDefaults()
+    }
+    fs.Parse(args)
+
+    script := strings.TrimSuffix(*out, ".json") + ".sh"
+    if !*force {
+        for _, path := range []string{*out, script} {
+            if _, err := os.Stat(path); err == nil {
+                fmt.Printf("Error: %s sudah ada, pakai -force untuk menimpa\n", path)
+                return 1
+            }
+        }
+    }
+
+    p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+    fmt.Println("🧙 Konfigurasi load test baru (Enter untuk memakai nilai default)")
+    scenario, cmd, err := runWizard(p, *out)
+    if err != nil {
+        fmt.Printf("\nError: %v\n", err)
+        return 1
+    }
+
+    data, err := json.MarshalIndent(scenario, "", "  ")
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    if err := os.WriteFile(script, []byte("#!/bin/sh\n"+cmd+"\n"), 0o755); err != nil {
+        fmt.Printf("Error: %v\n", err)
+        return 1
+    }
+    fmt.Printf("\n✅ Skenario ditulis ke %s, perintah ke %s:\n\n  %s\n", *out, script, cmd)
+    return 0
+}
+
+// wizardScenario isi file skenario yang ditulis wizard, hanya field yang
+// dipakai agar file mudah dibaca dan diedit
+type wizardScenario struct {
+    Steps []wizardStep `json:"steps"`
+}
+
+type wizardStep struct {
+    Name    string            `json:"name"`
+    Method  string            `json:"method"`
+    URL     string            `json:"url"`
+    Headers map[string]string `json:"headers,omitempty"`
+    Body    string            `json:"body,omitempty"`
+}
+
+func runWizard(p *prompter, path string) (*wizardScenario, string, error) {
+    target, err := p.askValid("URL target", "", func(answer string) error {
+        u, err := url.Parse(answer)
+        if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+            return fmt.Errorf("URL harus diawali http:// atau https://")
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, "", err
+    }
+    method, err := p.choose("Method", []string{"GET", "POST", "PUT", "PATCH", "DELETE"}, "GET")
+    if err != nil {
+        return nil, "", err
+    }
+    step := wizardStep{Name: strings.ToLower(method), Method: method, URL: target, Headers: map[string]string{}}
+    if method != "GET" && method != "DELETE" {
+        if step.Body, err = p.ask("Body JSON (boleh berisi template, contoh {\"id\": \"{{uuid}}\"})", "{}"); err != nil {
+            return nil, "", err
+        }
+        step.Headers["Content-Type"] = "application/json"
+    }
+
+    // Kredensial tidak ditulis ke file, diambil dari environment saat run
+    auth, err := p.choose("Autentikasi", []string{"none", "bearer", "basic", "header"}, "none")
+    if err != nil {
+        return nil, "", err
+    }
+    switch auth {
+    case "bearer":
+        env, err := p.ask("Nama env variable berisi token", "API_TOKEN")
+        if err != nil {
+            return nil, "", err
+        }
+        step.Headers["Authorization"] = fmt.Sprintf(`Bearer {{env "%s"}}`, env)
+    case "basic":
+        env, err := p.ask("Nama env variable berisi base64(user:password)", "API_BASIC_AUTH")
+        if err != nil {
+            return nil, "", err
+        }
+        step.Headers["Authorization"] = fmt.Sprintf(`Basic {{env "%s"}}`, env)
+    case "header":
+        name, err := p.ask("Nama header", "X-API-Key")
+        if err != nil {
+            return nil, "", err
+        }
+        env, err := p.ask("Nama env variable berisi nilainya", "API_KEY")
+        if err != nil {
+            return nil, "", err
+        }
+        step.Headers[name] = fmt.Sprintf(`{{env "%s"}}`, env)
+    }
+
+    args := []string{"loadtest", "-scenario", shellQuote(path)}
+    shape, err := p.choose("Pola beban: jumlah request tetap, rate konstan, atau ramp naik-tahan-turun", []string{"count", "rate", "ramp"}, "rate")
+    if err != nil {
+        return nil, "", err
+    }
+    concurrency, err := p.askValid("Jumlah virtual user (concurrency)", "10", checkPositiveInt)
+    if err != nil {
+        return nil, "", err
+    }
+    args = append(args, "-c", concurrency)
+    switch shape {
+    case "count":
+        n, err := p.askValid("Jumlah iterasi", "1000", checkPositiveInt)
+        if err != nil {
+            return nil, "", err
+        }
+        args = append(args, "-n", n)
+    case "rate":
+        rate, err := p.askValid("Request per detik", "50", checkPositiveInt)
+        if err != nil {
+            return nil, "", err
+        }
+        duration, err := p.askValid("Durasi", "1m", checkDuration)
+        if err != nil {
+            return nil, "", err
+        }
+        args = append(args, "-rate", rate, "-duration", duration)
+    case "ramp":
+        peak, err := p.askValid("Rate puncak (request per detik)", "100", checkPositiveInt)
+        if err != nil {
+            return nil, "", err
+        }
+        ramp, err := p.askValid("Durasi naik/turun", "30s", checkDuration)
+        if err != nil {
+            return nil, "", err
+        }
+        hold, err := p.askValid("Durasi tahan di puncak", "2m", checkDuration)
+        if err != nil {
+            return nil, "", err
+        }
+        args = append(args, "-stages", shellQuote(fmt.Sprintf("%s:%s,%s:%s,%s:0", ramp, peak, hold, peak, ramp)))
+    }
+
+    var thresholds []string
+    p95, err := p.askValid("Batas p95 latency dalam ms (kosong = tanpa batas)", "", checkOptionalNumber)
+    if err != nil {
+        return nil, "", err
+    }
+    if p95 != "" {
+        thresholds = append(thresholds, "p95<"+p95+"ms")
+    }
+    errorRate, err := p.askValid("Batas error rate dalam persen (kosong = tanpa batas)", "1", checkOptionalNumber)
+    if err != nil {
+        return nil, "", err
+    }
+    if errorRate != "" {
+        thresholds = append(thresholds, "error_rate<"+errorRate+"%")
+    }
+    if len(thresholds) > 0 {
+        args = append(args, "-thresholds", shellQuote(strings.Join(thresholds, ",")))
+    }
+
+    if len(step.Headers) == 0 {
+        step.Headers = nil
+    }
+    return &wizardScenario{Steps: []wizardStep{step}}, strings.Join(args, " "), nil
+}
+
+// shellQuote membungkus nilai dengan tanda kutip tunggal jika perlu
+func shellQuote(s string) string {
+    if s != "" && !strings.ContainsAny(s, " '\"$`\\<>|&;*?()[]{}!#~%") {
+        return s
+    }
+    return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
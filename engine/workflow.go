@@ -0,0 +1,110 @@
+package engine
+
+import (
+    "fmt"
+    "io"
+    "sort"
+    "sync"
+    "sync/atomic"
+    "time"
+)
+
+// workflowSample latency bisnis satu workflow async: dari step submit
+// sampai kondisi until pada step polling terpenuhi, termasuk jeda polling
+type workflowSample struct {
+    name      string // Nama step polling
+    from      string // Nama step submit
+    duration  time.Duration
+    completed bool
+}
+
+// workflowStats agregat satu workflow
+type workflowStats struct {
+    from      string
+    latency   histogram // Hanya workflow yang selesai
+    completed atomic.Int64
+    failed    atomic.Int64
+    total     atomic.Int64 // Nanodetik, workflow yang selesai
+    max       atomic.Int64
+}
+
+// workflowSet kumpulan workflowStats per nama step polling. Zero value siap
+// dipakai.
+type workflowSet struct {
+    workflows sync.Map // string -> *workflowStats
+}
+
+func (s *workflowSet) add(sample workflowSample) {
+    w, ok := s.workflows.Load(sample.name)
+    if !ok {
+        w, _ = s.workflows.LoadOrStore(sample.name, &workflowStats{from: sample.from})
+    }
+    stats := w.(*workflowStats)
+    if !sample.completed {
+        stats.failed.Add(1)
+        return
+    }
+    stats.completed.Add(1)
+    stats.latency.addDuration(sample.duration)
+    stats.total.Add(int64(sample.duration))
+    for {
+        current := stats.max.Load()
+        if int64(sample.duration) <= current || stats.max.CompareAndSwap(current, int64(sample.duration)) {
+            break
+        }
+    }
+}
+
+// WorkflowReport latency end-to-end satu workflow async, terpisah dari
+// latency request HTTP-nya
+type WorkflowReport struct {
+    From      string  `json:"from"`
+    Completed int64   `json:"completed"`
+    Failed    int64   `json:"failed"` // Polling habis, error atau status >= 400
+    AvgMs     float64 `json:"avg_ms"`
+    P50Ms     float64 `json:"p50_ms"`
+    P95Ms     float64 `json:"p95_ms"`
+    P99Ms     float64 `json:"p99_ms"`
+    MaxMs     float64 `json:"max_ms"`
+}
+
+func (s *workflowSet) report() map[string]WorkflowReport {
+    reports := make(map[string]WorkflowReport)
+    s.workflows.Range(func(key, value any) bool {
+        w := value.(*workflowStats)
+        r := WorkflowReport{From: w.from, Completed: w.completed.Load(), Failed: w.failed.Load()}
+        if r.Completed > 0 {
+            maxMs := durationMs(time.Duration(w.max.Load()))
+            r.AvgMs = durationMs(time.Duration(w.total.Load() / r.Completed))
+            r.P50Ms = min(maxMs, durationMs(w.latency.quantileDuration(0.50)))
+            r.P95Ms = min(maxMs, durationMs(w.latency.quantileDuration(0.95)))
+            r.P99Ms = min(maxMs, durationMs(w.latency.quantileDuration(0.99)))
+            r.MaxMs = maxMs
+        }
+        reports[key.(string)] = r
+        return true
+    })
+    if len(reports) == 0 {
+        return nil
+    }
+    return reports
+}
+
+func printWorkflows(w io.Writer, report *Report) {
+    if len(report.Workflows) == 0 {
+        return
+    }
+    names := make([]string, 0, len(report.Workflows))
+    for name := range report.Workflows {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    fmt.Fprintln(w, "\n🔁 Latency Workflow (submit sampai selesai):")
+    fmt.Fprintf(w, "  %-24s %8s %7s %10s %10s %10s %10s %10s\n", "Workflow", "Selesai", "Gagal", "Avg ms", "p50 ms", "p95 ms", "p99 ms", "Max ms")
+    for _, name := range names {
+        flow := report.Workflows[name]
+        fmt.Fprintf(w, "  %-24s %8d %7d %10.1f %10.1f %10.1f %10.1f %10.1f\n",
+            flow.From+" → "+name, flow.Completed, flow.Failed, flow.AvgMs, flow.P50Ms, flow.P95Ms, flow.P99Ms, flow.MaxMs)
+    }
+}
@@ -1,3 +1,15 @@
 module loadtest
 
 go 1.24.6
+
+require (
+	github.com/antchfx/xmlquery v1.5.1
+	github.com/antchfx/xpath v1.3.6
+	golang.org/x/net v0.33.0
+	google.golang.org/protobuf v1.36.9
+)
+
+require (
+	github.com/golang/groupcache v0.0.0-20210331224755-41bb18bfe9da // indirect
+	golang.org/x/text v0.21.0 // indirect
+)